@@ -194,6 +194,7 @@ func AuthMiddlewareRole() gin.HandlerFunc {
 			logrus.Errorf("unauthorized %v", err)
 			response.JSONErrorResponse(c.Writer, false, http.StatusUnauthorized, "unauthorized")
 			c.Abort()
+			return
 		}
 
 		token, err := jwt.ParseWithClaims(tokenString, &jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -205,6 +206,7 @@ func AuthMiddlewareRole() gin.HandlerFunc {
 			logrus.Errorf("failed generate token")
 			response.JSONErrorResponse(c.Writer, false, http.StatusUnauthorized, "unauthorized")
 			c.Abort()
+			return
 		}
 
 		claims := token.Claims.(*jwt.MapClaims)
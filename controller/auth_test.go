@@ -96,6 +96,20 @@ func TestAuthMiddlewareRole_Unauthorized(t *testing.T) {
 	assert.JSONEq(t, `{"message":"request failed", "result":"you do not have permission to access this resource", "status":false, "statusCode":403}`, w.Body.String(), "Response body should be a JSON error message")
 }
 
+func TestAuthMiddlewareRole_MissingToken(t *testing.T) {
+	// Set up the test
+	r := setupTest()
+
+	req, _ := http.NewRequest(http.MethodGet, "/user/bank", nil)
+	w := httptest.NewRecorder()
+
+	// Perform the request
+	r.ServeHTTP(w, req)
+
+	// Check the response
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Response status code should be 401 Unauthorized")
+	assert.JSONEq(t, `{"status":false,"statusCode":401,"result":"unauthorized","message":"request failed"}`, w.Body.String(), "Response body should be a JSON unauthorized message")
+}
 func TestAuthMiddleware_Success(t *testing.T) {
 	// Set up the test
 	r := setupTest()
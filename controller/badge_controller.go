@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type BadgeController struct {
+	badgeUsecase usecase.BadgeUsecase
+	userUsecase  usecase.UserUseCase
+}
+
+// evaluateBadge moves the user up a badge after a successful transaction.
+// Failures are only logged because the transaction itself already went
+// through.
+func evaluateBadge(badgeUsecase usecase.BadgeUsecase, userUsecase usecase.UserUseCase, userID string) {
+	user, err := userUsecase.FindByiDToken(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get user for badge evaluation: %v", err)
+		return
+	}
+	if _, err := badgeUsecase.Evaluate(user); err != nil {
+		logrus.Errorf("Failed to evaluate badge: %v", err)
+	}
+}
+
+func (c *BadgeController) FindBadges(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	badges, err := c.badgeUsecase.FindBadges()
+	if err != nil {
+		logrus.Errorf("Failed to get badges: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get badges")
+		return
+	}
+
+	logrus.Info("Badges loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, badges)
+}
+
+func (c *BadgeController) FindHistory(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	history, err := c.badgeUsecase.FindHistory(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get badge history: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get badge history")
+		return
+	}
+
+	logrus.Info("Badge history loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, history)
+}
+
+func (c *BadgeController) FindProgress(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	progress, err := c.badgeUsecase.FindProgress(user)
+	if err != nil {
+		logrus.Errorf("Failed to get badge progress: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get badge progress")
+		return
+	}
+
+	logrus.Info("Badge progress loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, progress)
+}
+
+func NewBadgeController(u usecase.BadgeUsecase, uc usecase.UserUseCase) *BadgeController {
+	controller := BadgeController{
+		badgeUsecase: u,
+		userUsecase:  uc,
+	}
+	return &controller
+}
@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type BulkTransferController struct {
+	bulkUsecase usecase.BulkTransferUsecase
+	userUsecase usecase.UserUseCase
+	hooks       *transferHooks
+}
+
+func bulkTransferErrorStatus(err error) int {
+	switch err.Error() {
+	case "bulk transfer not found":
+		return http.StatusNotFound
+	case "insufficient balance", "bulk transfer already executed":
+		return http.StatusUnprocessableEntity
+	case "invalid csv file", "csv file is empty", "maximum 500 rows per bulk transfer", "no valid rows":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *BulkTransferController) Preview(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		logrus.Errorf("Failed to get file from request: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Failed to get file from request")
+		return
+	}
+	if filepath.Ext(fileHeader.Filename) != ".csv" {
+		logrus.Errorf("Extension file is not csv file")
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Extension file is not csv file")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logrus.Errorf("Failed to open file: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to open file")
+		return
+	}
+	defer file.Close()
+
+	batch, err := c.bulkUsecase.Preview(user, fileHeader.Filename, file)
+	if err != nil {
+		logrus.Errorf("Failed to preview bulk transfer: %v", err)
+		status := bulkTransferErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to preview bulk transfer"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Bulk transfer previewed Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, batch)
+}
+
+func (c *BulkTransferController) Execute(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	batchID, err := strconv.Atoi(ctx.Param("batch_id"))
+	if err != nil {
+		logrus.Errorf("Invalid batch_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid batch_id")
+		return
+	}
+
+	user, err := c.userUsecase.FindByiDToken(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	batch, err := c.bulkUsecase.Execute(user, batchID)
+	if err != nil {
+		logrus.Errorf("Failed to execute bulk transfer: %v", err)
+		status := bulkTransferErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to execute bulk transfer"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	// Every row is a regular transfer, so it gets the same follow-up as one
+	for _, item := range batch.Items {
+		if item.Status != "Success" {
+			continue
+		}
+		recipient, err := c.userUsecase.FindByiDToken(item.RecipientID)
+		if err != nil {
+			logrus.Errorf("Failed to get Recipient User: %v", err)
+			continue
+		}
+		c.hooks.afterTransfer(user, recipient, &model.Transfer{TransactionID: item.TransactionID, Amount: item.Amount, Note: item.Note})
+	}
+
+	body := fmt.Sprintf("%d dari %d transfer massal berhasil dikirim", batch.SuccessCount, batch.SuccessCount+batch.FailedCount)
+	err = model.SendFCMNotification(user.Token, "Transfer Massal Selesai", body)
+	if err != nil {
+		logrus.Errorf("failed to send FCM notification: %v", err)
+	}
+
+	logrus.Info("Bulk transfer executed Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, batch)
+}
+
+func (c *BulkTransferController) GetBatches(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	batches, err := c.bulkUsecase.FindBatches(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get bulk transfers: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get bulk transfers")
+		return
+	}
+
+	logrus.Info("Success get bulk transfers")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, batches)
+}
+
+func (c *BulkTransferController) GetBatch(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	batchID, err := strconv.Atoi(ctx.Param("batch_id"))
+	if err != nil {
+		logrus.Errorf("Invalid batch_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid batch_id")
+		return
+	}
+
+	batch, err := c.bulkUsecase.FindBatch(ctx.Param("user_id"), batchID)
+	if err != nil {
+		logrus.Errorf("Failed to get bulk transfer: %v", err)
+		if err.Error() == "bulk transfer not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Bulk transfer not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get bulk transfer")
+		return
+	}
+
+	logrus.Info("Success get bulk transfer")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, batch)
+}
+
+// DownloadResult writes the per-row outcome of a batch as a CSV file.
+func (c *BulkTransferController) DownloadResult(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	batchID, err := strconv.Atoi(ctx.Param("batch_id"))
+	if err != nil {
+		logrus.Errorf("Invalid batch_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid batch_id")
+		return
+	}
+
+	batch, err := c.bulkUsecase.FindBatch(ctx.Param("user_id"), batchID)
+	if err != nil {
+		logrus.Errorf("Failed to get bulk transfer: %v", err)
+		if err.Error() == "bulk transfer not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Bulk transfer not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get bulk transfer")
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=bulk-transfer-%d.csv", batch.BatchID))
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	_ = writer.Write([]string{"row_number", "phone_number", "recipient_name", "amount", "fee", "note", "status", "message", "transaction_id"})
+	for _, item := range batch.Items {
+		_ = writer.Write([]string{
+			strconv.Itoa(item.RowNumber),
+			csvCell(item.PhoneNumber),
+			csvCell(item.RecipientName),
+			strconv.Itoa(item.Amount),
+			strconv.Itoa(item.Fee),
+			csvCell(item.Note),
+			item.Status,
+			csvCell(item.Message),
+			strconv.Itoa(item.TransactionID),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logrus.Errorf("Failed to write bulk transfer result: %v", err)
+		return
+	}
+
+	logrus.Info("Bulk transfer result downloaded Successfully")
+}
+
+// csvCell keeps text taken from an upload from being run as a formula when
+// the result is opened in a spreadsheet.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func NewBulkTransferController(u usecase.BulkTransferUsecase, uc usecase.UserUseCase, pk usecase.PocketUsecase, pt usecase.PointUsecase, rf usecase.ReferralUsecase, ms usecase.MissionUsecase, bd usecase.BadgeUsecase) *BulkTransferController {
+	controller := BulkTransferController{
+		bulkUsecase: u,
+		userUsecase: uc,
+		hooks:       newTransferHooks(pk, pt, rf, ms, bd),
+	}
+	return &controller
+}
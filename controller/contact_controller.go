@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ContactController struct {
+	contactUsecase usecase.ContactUsecase
+}
+
+func (c *ContactController) FindContacts(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	userID := ctx.Param("user_id")
+
+	contacts, err := c.contactUsecase.FindContacts(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get contacts: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get contacts")
+		return
+	}
+
+	logrus.Info("Contacts loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, contacts)
+}
+
+func (c *ContactController) AddContact(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newContact model.Contact
+	if err := ctx.ShouldBindJSON(&newContact); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if newContact.PhoneNumber == "" {
+		logrus.Errorf("Invalid Input: Required fields are empty")
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid Input: Required fields are empty")
+		return
+	}
+	newContact.UserID = ctx.Param("user_id")
+
+	if err := c.contactUsecase.AddContact(&newContact); err != nil {
+		logrus.Errorf("Failed to add contact: %v", err)
+		switch err.Error() {
+		case "recipient not found":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, err.Error())
+		case "contact already exists":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusConflict, err.Error())
+		case "cannot add yourself as a contact", "alias must be at most 30 characters":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, err.Error())
+		default:
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to add contact")
+		}
+		return
+	}
+
+	logrus.Info("Contact created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newContact)
+}
+
+func (c *ContactController) RenameContact(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	contactID, err := strconv.Atoi(ctx.Param("contact_id"))
+	if err != nil {
+		logrus.Errorf("Invalid contact_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid contact_id")
+		return
+	}
+
+	var reqBody model.Contact
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.contactUsecase.RenameContact(contactID, ctx.Param("user_id"), reqBody.Alias); err != nil {
+		logrus.Errorf("Failed to rename contact: %v", err)
+		switch err.Error() {
+		case "contact not found":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, err.Error())
+		case "alias must be 1 - 30 characters":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, err.Error())
+		default:
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to rename contact")
+		}
+		return
+	}
+
+	logrus.Info("Contact renamed Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Contact renamed successfully")
+}
+
+func (c *ContactController) RemoveContact(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	contactID, err := strconv.Atoi(ctx.Param("contact_id"))
+	if err != nil {
+		logrus.Errorf("Invalid contact_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid contact_id")
+		return
+	}
+
+	if err := c.contactUsecase.RemoveContact(contactID, ctx.Param("user_id")); err != nil {
+		logrus.Errorf("Failed to delete contact: %v", err)
+		if err.Error() == "contact not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, err.Error())
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to delete contact")
+		return
+	}
+
+	logrus.Info("Contact deleted Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Contact deleted successfully")
+}
+
+func (c *ContactController) FindRecentRecipients(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	if err != nil {
+		logrus.Errorf("Invalid limit: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	recipients, err := c.contactUsecase.FindRecentRecipients(ctx.Param("user_id"), limit)
+	if err != nil {
+		logrus.Errorf("Failed to get recent recipients: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get recent recipients")
+		return
+	}
+
+	logrus.Info("Recent recipients loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, recipients)
+}
+
+func NewContactController(u usecase.ContactUsecase) *ContactController {
+	controller := ContactController{
+		contactUsecase: u,
+	}
+	return &controller
+}
@@ -0,0 +1,313 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type GroupWalletController struct {
+	groupUsecase usecase.GroupWalletUsecase
+	userUsecase  usecase.UserUseCase
+}
+
+func groupWalletErrorStatus(err error) int {
+	switch err.Error() {
+	case "group not found", "member not found", "user not found":
+		return http.StatusNotFound
+	case "only group owner or admin can do this":
+		return http.StatusForbidden
+	case "user is already a member":
+		return http.StatusConflict
+	case "insufficient balance", "group balance is empty", "all payouts completed", "round already paid out",
+		"no member scheduled for this round", "maximum 50 members per group", "schedule cannot be changed after the first payout",
+		"members cannot be removed after the first payout":
+		return http.StatusUnprocessableEntity
+	case "group name must be 1 - 50 characters", "minimum contribution is 10,000", "period must be Weekly or Monthly",
+		"start date must use YYYY-MM-DD format", "role must be Admin or Member", "group owner cannot be removed",
+		"schedule must list every member once", "amount must be greater than 0":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func groupWalletError(ctx *gin.Context, err error, fallback string) {
+	logrus.Errorf("%s: %v", fallback, err)
+	status := groupWalletErrorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		message = fallback
+	}
+	response.JSONErrorResponse(ctx.Writer, false, status, message)
+}
+
+func groupIDParam(ctx *gin.Context) (int, bool) {
+	groupID, err := strconv.Atoi(ctx.Param("group_id"))
+	if err != nil {
+		logrus.Errorf("Invalid group_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid group_id")
+		return 0, false
+	}
+	return groupID, true
+}
+
+func (c *GroupWalletController) FindGroups(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	groups, err := c.groupUsecase.FindGroups(ctx.Param("user_id"))
+	if err != nil {
+		groupWalletError(ctx, err, "Failed to get group wallets")
+		return
+	}
+
+	logrus.Info("Group wallets loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, groups)
+}
+
+func (c *GroupWalletController) CreateGroup(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newGroup model.GroupWallet
+	if err := ctx.ShouldBindJSON(&newGroup); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	newGroup.OwnerID = ctx.Param("user_id")
+
+	if err := c.groupUsecase.CreateGroup(&newGroup); err != nil {
+		groupWalletError(ctx, err, "Failed to create group wallet")
+		return
+	}
+
+	logrus.Info("Group wallet created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newGroup)
+}
+
+func (c *GroupWalletController) FindGroup(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	groupID, ok := groupIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	group, err := c.groupUsecase.FindGroup(ctx.Param("user_id"), groupID)
+	if err != nil {
+		groupWalletError(ctx, err, "Failed to get group wallet")
+		return
+	}
+
+	logrus.Info("Group wallet loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, group)
+}
+
+func (c *GroupWalletController) AddMember(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	groupID, ok := groupIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var reqBody model.GroupMember
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if reqBody.PhoneNumber == "" {
+		logrus.Errorf("Invalid Input: Required fields are empty")
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid Input: Required fields are empty")
+		return
+	}
+
+	member, err := c.groupUsecase.AddMember(ctx.Param("user_id"), groupID, reqBody.PhoneNumber, reqBody.Role)
+	if err != nil {
+		groupWalletError(ctx, err, "Failed to add group member")
+		return
+	}
+
+	logrus.Info("Group member added Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, member)
+}
+
+func (c *GroupWalletController) RemoveMember(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	groupID, ok := groupIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.groupUsecase.RemoveMember(ctx.Param("user_id"), groupID, ctx.Param("member_id")); err != nil {
+		groupWalletError(ctx, err, "Failed to remove group member")
+		return
+	}
+
+	logrus.Info("Group member removed Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Group member removed Successfully")
+}
+
+func (c *GroupWalletController) UpdateSchedule(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	groupID, ok := groupIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var reqBody model.GroupSchedule
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	group, err := c.groupUsecase.UpdateSchedule(ctx.Param("user_id"), groupID, reqBody.Order)
+	if err != nil {
+		groupWalletError(ctx, err, "Failed to update payout schedule")
+		return
+	}
+
+	logrus.Info("Payout schedule updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, group)
+}
+
+func (c *GroupWalletController) Contribute(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	groupID, ok := groupIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var reqBody model.GroupActivity
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	activity, err := c.groupUsecase.Contribute(user, groupID, reqBody.Amount)
+	if err != nil {
+		groupWalletError(ctx, err, "Failed to contribute to group wallet")
+		return
+	}
+
+	logrus.Info("Group contribution created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, activity)
+}
+
+func (c *GroupWalletController) Payout(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	groupID, ok := groupIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	activity, err := c.groupUsecase.Payout(ctx.Param("user_id"), groupID)
+	if err != nil {
+		groupWalletError(ctx, err, "Failed to pay out group wallet")
+		return
+	}
+
+	recipient, err := c.userUsecase.FindByiDToken(activity.UserID)
+	if err == nil {
+		amount := float64(activity.Amount) / 1000
+		formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+		err = model.SendFCMNotification(recipient.Token, "Arisan Diterima", "Anda menerima giliran arisan sebesar "+formattedAmount)
+		if err != nil {
+			logrus.Errorf("failed to send FCM notification: %v", err)
+		}
+	}
+
+	logrus.Info("Group payout created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, activity)
+}
+
+func (c *GroupWalletController) FindActivities(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	groupID, ok := groupIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	activities, err := c.groupUsecase.FindActivities(ctx.Param("user_id"), groupID)
+	if err != nil {
+		groupWalletError(ctx, err, "Failed to get group activities")
+		return
+	}
+
+	logrus.Info("Group activities loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, activities)
+}
+
+func NewGroupWalletController(u usecase.GroupWalletUsecase, uc usecase.UserUseCase) *GroupWalletController {
+	controller := GroupWalletController{
+		groupUsecase: u,
+		userUsecase:  uc,
+	}
+	return &controller
+}
@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type HoldController struct {
+	holdUsecase usecase.HoldUsecase
+	userUsecase usecase.UserUseCase
+}
+
+func holdErrorStatus(err error) int {
+	switch err.Error() {
+	case "hold not found":
+		return http.StatusNotFound
+	case "hold is no longer active":
+		return http.StatusConflict
+	case "insufficient balance":
+		return http.StatusUnprocessableEntity
+	case "amount must be greater than 0", "reference must be at most 50 characters", "description must be at most 100 characters",
+		"expiry must be 1 minute - 30 days", "capture amount exceeds held amount":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *HoldController) FindBalance(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	balance, err := c.holdUsecase.FindBalance(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get balance: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get balance")
+		return
+	}
+
+	logrus.Info("Balance loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, balance)
+}
+
+func (c *HoldController) FindHolds(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	holds, err := c.holdUsecase.FindHolds(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get holds: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get holds")
+		return
+	}
+
+	logrus.Info("Holds loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, holds)
+}
+
+func (c *HoldController) FindHold(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	holdID, err := strconv.Atoi(ctx.Param("hold_id"))
+	if err != nil {
+		logrus.Errorf("Invalid hold_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid hold_id")
+		return
+	}
+
+	hold, err := c.holdUsecase.FindHold(holdID, ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get hold: %v", err)
+		if err.Error() == "hold not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Hold not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get hold")
+		return
+	}
+
+	logrus.Info("Hold loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, hold)
+}
+
+func (c *HoldController) CreateHold(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newHold model.Hold
+	if err := ctx.ShouldBindJSON(&newHold); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	if err := c.holdUsecase.CreateHold(user, &newHold); err != nil {
+		logrus.Errorf("Failed to create hold: %v", err)
+		status := holdErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create hold"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Hold created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newHold)
+}
+
+// Capture takes an optional amount; an empty body captures the full hold.
+func (c *HoldController) Capture(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	holdID, err := strconv.Atoi(ctx.Param("hold_id"))
+	if err != nil {
+		logrus.Errorf("Invalid hold_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid hold_id")
+		return
+	}
+
+	var reqBody model.HoldCapture
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil && err != io.EOF {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	hold, err := c.holdUsecase.Capture(user, holdID, reqBody.Amount)
+	if err != nil {
+		logrus.Errorf("Failed to capture hold: %v", err)
+		status := holdErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to capture hold"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Hold captured Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, hold)
+}
+
+func (c *HoldController) Release(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	holdID, err := strconv.Atoi(ctx.Param("hold_id"))
+	if err != nil {
+		logrus.Errorf("Invalid hold_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid hold_id")
+		return
+	}
+
+	if err := c.holdUsecase.Release(holdID, ctx.Param("user_id")); err != nil {
+		logrus.Errorf("Failed to release hold: %v", err)
+		status := holdErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to release hold"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Hold released Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Hold released successfully")
+}
+
+func NewHoldController(u usecase.HoldUsecase, uc usecase.UserUseCase) *HoldController {
+	controller := HoldController{
+		holdUsecase: u,
+		userUsecase: uc,
+	}
+	return &controller
+}
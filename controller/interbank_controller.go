@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type InterbankController struct {
+	interbankUsecase usecase.InterbankUsecase
+	userUsecase      usecase.UserUseCase
+	badgeUsecase     usecase.BadgeUsecase
+}
+
+func interbankErrorStatus(err error) int {
+	switch err.Error() {
+	case "bank account not found", "inquiry not found":
+		return http.StatusNotFound
+	case "insufficient balance", "daily interbank limit exceeded":
+		return http.StatusUnprocessableEntity
+	case "minimum interbank transfer is 20,000", "maximum interbank transfer is 25,000,000", "inquiry expired or already used":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *InterbankController) Inquiry(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var reqBody model.InterbankInquiry
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Incorrect request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Incorrect request body")
+		return
+	}
+	if reqBody.BankCode == "" || reqBody.AccountNumber == "" {
+		logrus.Errorf("Invalid Input: Required fields are empty")
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid Input: Required fields are empty")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	inquiry, err := c.interbankUsecase.Inquiry(user, reqBody.BankCode, reqBody.AccountNumber, reqBody.Amount)
+	if err != nil {
+		logrus.Errorf("Failed to inquiry bank account: %v", err)
+		status := interbankErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to inquiry bank account"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Interbank inquiry created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, inquiry)
+}
+
+func (c *InterbankController) Transfer(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var reqBody model.InterbankTransfer
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Incorrect request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Incorrect request body")
+		return
+	}
+	if reqBody.InquiryToken == "" {
+		logrus.Errorf("Inquiry token is required")
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "inquiry_token is required")
+		return
+	}
+
+	user, err := c.userUsecase.FindByiDToken(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	transfer, err := c.interbankUsecase.Transfer(user, reqBody.InquiryToken)
+	if err != nil {
+		logrus.Errorf("Failed to create Interbank Transaction: %v", err)
+		status := interbankErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create Interbank Transaction"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	if _, err := c.badgeUsecase.Evaluate(user); err != nil {
+		logrus.Errorf("Failed to evaluate badge: %v", err)
+	}
+
+	amount := float64(transfer.Amount) / 1000
+	formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+
+	err = model.SendFCMNotification(user.Token, "Transfer Bank Berhasil", "Anda telah mengirim uang ke "+transfer.AccountHolderName+" ("+transfer.BankName+") sebesar "+formattedAmount)
+	if err != nil {
+		logrus.Errorf("failed to send FCM notification: %v", err)
+	}
+
+	logrus.Info("Interbank Transaction created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, transfer)
+}
+
+func NewInterbankController(u usecase.InterbankUsecase, uc usecase.UserUseCase, bd usecase.BadgeUsecase) *InterbankController {
+	controller := InterbankController{
+		interbankUsecase: u,
+		userUsecase:      uc,
+		badgeUsecase:     bd,
+	}
+	return &controller
+}
@@ -0,0 +1,316 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type InvoiceController struct {
+	invoiceUsecase usecase.InvoiceUsecase
+	userUsecase    usecase.UserUseCase
+	webhookUsecase usecase.WebhookUsecase
+	pointUsecase   usecase.PointUsecase
+	missionUsecase usecase.MissionUsecase
+	badgeUsecase   usecase.BadgeUsecase
+}
+
+func invoiceErrorStatus(err error) int {
+	switch err.Error() {
+	case "invoice not found", "merchant not found", "customer not found":
+		return http.StatusNotFound
+	case "invoice is no longer a draft", "invoice cannot be voided", "invoice is not payable":
+		return http.StatusConflict
+	case "insufficient balance":
+		return http.StatusUnprocessableEntity
+	case "invoice must have 1 - 50 items", "item description must be 1 - 100 characters", "item quantity must be greater than 0",
+		"item unit price must be greater than 0", "tax must be 0 - 5000 bps", "notes must be at most 255 characters",
+		"due date must use YYYY-MM-DD format", "due date must not be in the past", "cannot invoice your own account":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *InvoiceController) Create(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newInvoice model.Invoice
+	if err := ctx.ShouldBindJSON(&newInvoice); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.invoiceUsecase.Create(ctx.Param("user_id"), &newInvoice); err != nil {
+		logrus.Errorf("Failed to create invoice: %v", err)
+		status := invoiceErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create invoice"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Invoice created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newInvoice)
+}
+
+func (c *InvoiceController) Update(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var invoice model.Invoice
+	if err := ctx.ShouldBindJSON(&invoice); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	invoice.InvoiceID = ctx.Param("invoice_id")
+
+	if err := c.invoiceUsecase.Update(ctx.Param("user_id"), &invoice); err != nil {
+		logrus.Errorf("Failed to update invoice: %v", err)
+		status := invoiceErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to update invoice"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Invoice updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, invoice)
+}
+
+func (c *InvoiceController) FindByMerchant(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	invoices, err := c.invoiceUsecase.FindByMerchant(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get invoices: %v", err)
+		status := invoiceErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to get invoices"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Invoices loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, invoices)
+}
+
+func (c *InvoiceController) FindReceived(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	invoices, err := c.invoiceUsecase.FindReceived(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get invoices: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get invoices")
+		return
+	}
+
+	logrus.Info("Received invoices loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, invoices)
+}
+
+func (c *InvoiceController) FindInvoice(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	invoice, err := c.invoiceUsecase.FindInvoice(ctx.Param("user_id"), ctx.Param("invoice_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get invoice: %v", err)
+		status := invoiceErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to get invoice"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Invoice loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, invoice)
+}
+
+func (c *InvoiceController) Send(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	invoice, err := c.invoiceUsecase.Send(ctx.Param("user_id"), ctx.Param("invoice_id"))
+	if err != nil {
+		logrus.Errorf("Failed to send invoice: %v", err)
+		status := invoiceErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to send invoice"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	customer, err := c.userUsecase.FindByiDToken(invoice.CustomerID)
+	if err != nil {
+		logrus.Errorf("failed to get customer token: %v", err)
+	} else {
+		amount := float64(invoice.Total) / 1000
+		formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+
+		err = model.SendFCMNotification(customer.Token, "Tagihan Baru", "Anda menerima tagihan dari "+invoice.MerchantName+" sebesar "+formattedAmount+", jatuh tempo "+invoice.DueDate)
+		if err != nil {
+			logrus.Errorf("failed to send FCM notification: %v", err)
+		}
+	}
+
+	logrus.Info("Invoice sent Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, invoice)
+}
+
+func (c *InvoiceController) Void(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	if err := c.invoiceUsecase.Void(ctx.Param("user_id"), ctx.Param("invoice_id")); err != nil {
+		logrus.Errorf("Failed to void invoice: %v", err)
+		status := invoiceErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to void invoice"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Invoice voided Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Invoice voided successfully")
+}
+
+func (c *InvoiceController) Pay(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	invoice, err := c.invoiceUsecase.Pay(user, ctx.Param("invoice_id"))
+	if err != nil {
+		logrus.Errorf("Failed to pay invoice: %v", err)
+		status := invoiceErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to pay invoice"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	awardPoints(c.pointUsecase, user.ID, "Merchant Payment", invoice.TransactionID, invoice.Total)
+	trackMissions(c.missionUsecase, user.ID, "Merchant Payment", invoice.Total)
+	evaluateBadge(c.badgeUsecase, c.userUsecase, user.ID)
+
+	merchantUser, err := c.userUsecase.FindByiDToken(invoice.MerchantUserID)
+	if err != nil {
+		logrus.Errorf("failed to get merchant token: %v", err)
+	} else {
+		amount := float64(invoice.Total) / 1000
+		formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+
+		err = model.SendFCMNotification(merchantUser.Token, "Tagihan Dibayar", "Tagihan "+invoice.InvoiceID+" telah dibayar oleh "+invoice.CustomerName+" sebesar "+formattedAmount)
+		if err != nil {
+			logrus.Errorf("failed to send FCM notification: %v", err)
+		}
+	}
+	if err := c.webhookUsecase.Publish(invoice.MerchantUserID, usecase.WebhookPaymentSucceeded, invoice); err != nil {
+		logrus.Errorf("Failed to publish webhook event: %v", err)
+	}
+
+	logrus.Info("Invoice paid Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, invoice)
+}
+
+func (c *InvoiceController) DownloadPDF(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	pdf, err := c.invoiceUsecase.RenderPDF(ctx.Param("user_id"), ctx.Param("invoice_id"))
+	if err != nil {
+		logrus.Errorf("Failed to render invoice: %v", err)
+		status := invoiceErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to render invoice"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Invoice rendered Successfully")
+	ctx.Header("Content-Disposition", "attachment; filename=\""+strings.ToUpper(ctx.Param("invoice_id"))+".pdf\"")
+	ctx.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func NewInvoiceController(u usecase.InvoiceUsecase, uc usecase.UserUseCase, wh usecase.WebhookUsecase, pt usecase.PointUsecase, ms usecase.MissionUsecase, bd usecase.BadgeUsecase) *InvoiceController {
+	controller := InvoiceController{
+		invoiceUsecase: u,
+		userUsecase:    uc,
+		webhookUsecase: wh,
+		pointUsecase:   pt,
+		missionUsecase: ms,
+		badgeUsecase:   bd,
+	}
+	return &controller
+}
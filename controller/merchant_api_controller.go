@@ -0,0 +1,365 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type MerchantAPIController struct {
+	apiUsecase     usecase.MerchantAPIUsecase
+	userUsecase    usecase.UserUseCase
+	webhookUsecase usecase.WebhookUsecase
+	pointUsecase   usecase.PointUsecase
+	missionUsecase usecase.MissionUsecase
+	badgeUsecase   usecase.BadgeUsecase
+}
+
+func merchantAPIErrorStatus(err error) int {
+	switch err.Error() {
+	case "merchant not found", "api key not found", "charge not found", "user not found":
+		return http.StatusNotFound
+	case "duplicate reference", "charge is no longer pending", "charge is not refundable":
+		return http.StatusConflict
+	case "insufficient balance", "insufficient merchant balance", "refund exceeds charged amount":
+		return http.StatusUnprocessableEntity
+	case "too many active api keys", "reference must be 1 - 50 characters", "amount must be greater than 0",
+		"description must be at most 100 characters", "cannot charge your own account":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// AuthMiddlewareAPIKey authenticates merchant backends. Unlike the JWT
+// middlewares it checks X-Api-Key, X-Timestamp, a single-use X-Nonce and an
+// HMAC X-Signature over the request, then exposes the merchant as "merchant_id".
+func (c *MerchantAPIController) AuthMiddlewareAPIKey() gin.HandlerFunc {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+	return func(ctx *gin.Context) {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			logrus.Errorf("failed to read request body: %v", err)
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key, err := c.apiUsecase.Authenticate(ctx.GetHeader("X-Api-Key"), ctx.GetHeader("X-Timestamp"), ctx.GetHeader("X-Nonce"), ctx.GetHeader("X-Signature"),
+			ctx.Request.Method, ctx.Request.URL.RequestURI(), body)
+		if err != nil {
+			logrus.Errorf("unauthorized api request: %v", err)
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusUnauthorized, err.Error())
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set("merchant_id", key.MerchantID)
+		ctx.Next()
+	}
+}
+
+func (c *MerchantAPIController) IssueKey(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	key, err := c.apiUsecase.IssueKey(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to issue api key: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to issue api key"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Api key issued Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, key)
+}
+
+func (c *MerchantAPIController) FindKeys(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	keys, err := c.apiUsecase.FindKeys(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get api keys: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to get api keys"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Api keys loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, keys)
+}
+
+func (c *MerchantAPIController) RotateKey(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	key, err := c.apiUsecase.RotateKey(ctx.Param("user_id"), ctx.Param("key_id"))
+	if err != nil {
+		logrus.Errorf("Failed to rotate api key: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to rotate api key"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Api key rotated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, key)
+}
+
+func (c *MerchantAPIController) RevokeKey(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	if err := c.apiUsecase.RevokeKey(ctx.Param("user_id"), ctx.Param("key_id")); err != nil {
+		logrus.Errorf("Failed to revoke api key: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to revoke api key"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Api key revoked Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Api key revoked successfully")
+}
+
+func (c *MerchantAPIController) CreateCharge(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var charge model.MerchantCharge
+	if err := ctx.ShouldBindJSON(&charge); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.apiUsecase.CreateCharge(ctx.GetString("merchant_id"), &charge); err != nil {
+		logrus.Errorf("Failed to create charge: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create charge"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	user, err := c.userUsecase.FindByiDToken(charge.UserID)
+	if err != nil {
+		logrus.Errorf("failed to get user token: %v", err)
+	} else {
+		amount := float64(charge.Amount) / 1000
+		formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+
+		err = model.SendFCMNotification(user.Token, "Permintaan Pembayaran", charge.MerchantName+" meminta pembayaran sebesar "+formattedAmount)
+		if err != nil {
+			logrus.Errorf("failed to send FCM notification: %v", err)
+		}
+	}
+
+	logrus.Info("Charge created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, charge)
+}
+
+func (c *MerchantAPIController) FindCharge(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	charge, err := c.apiUsecase.FindCharge(ctx.GetString("merchant_id"), ctx.Param("charge_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get charge: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to get charge"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Charge loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, charge)
+}
+
+// RefundCharge takes an optional amount; an empty body refunds the rest.
+func (c *MerchantAPIController) RefundCharge(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var reqBody model.ChargeRefund
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil && err != io.EOF {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	refund, err := c.apiUsecase.RefundCharge(ctx.GetString("merchant_id"), ctx.Param("charge_id"), reqBody.Amount)
+	if err != nil {
+		logrus.Errorf("Failed to refund charge: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to refund charge"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	if err := c.webhookUsecase.Publish(refund.MerchantUserID, usecase.WebhookRefundCreated, refund); err != nil {
+		logrus.Errorf("Failed to publish webhook event: %v", err)
+	}
+
+	logrus.Info("Charge refunded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, refund)
+}
+
+func (c *MerchantAPIController) FindPendingCharges(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	charges, err := c.apiUsecase.FindPendingCharges(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get charges: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get charges")
+		return
+	}
+
+	logrus.Info("Charges loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, charges)
+}
+
+func (c *MerchantAPIController) ApproveCharge(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	charge, err := c.apiUsecase.ApproveCharge(user, ctx.Param("charge_id"))
+	if err != nil {
+		logrus.Errorf("Failed to approve charge: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to approve charge"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	awardPoints(c.pointUsecase, user.ID, "Merchant Payment", charge.TransactionID, charge.Amount)
+	trackMissions(c.missionUsecase, user.ID, "Merchant Payment", charge.Amount)
+	evaluateBadge(c.badgeUsecase, c.userUsecase, user.ID)
+
+	if err := c.webhookUsecase.Publish(charge.MerchantUserID, usecase.WebhookPaymentSucceeded, charge); err != nil {
+		logrus.Errorf("Failed to publish webhook event: %v", err)
+	}
+
+	logrus.Info("Charge approved Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, charge)
+}
+
+func (c *MerchantAPIController) DeclineCharge(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	if err := c.apiUsecase.DeclineCharge(ctx.Param("user_id"), ctx.Param("charge_id")); err != nil {
+		logrus.Errorf("Failed to decline charge: %v", err)
+		status := merchantAPIErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to decline charge"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Charge declined Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Charge declined successfully")
+}
+
+func NewMerchantAPIController(u usecase.MerchantAPIUsecase, uc usecase.UserUseCase, wh usecase.WebhookUsecase, pt usecase.PointUsecase, ms usecase.MissionUsecase, bd usecase.BadgeUsecase) *MerchantAPIController {
+	controller := MerchantAPIController{
+		apiUsecase:     u,
+		userUsecase:    uc,
+		webhookUsecase: wh,
+		pointUsecase:   pt,
+		missionUsecase: ms,
+		badgeUsecase:   bd,
+	}
+	return &controller
+}
@@ -0,0 +1,317 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type MerchantController struct {
+	merchantUsecase usecase.MerchantUsecase
+	userUsecase     usecase.UserUseCase
+	webhookUsecase  usecase.WebhookUsecase
+	pointUsecase    usecase.PointUsecase
+	missionUsecase  usecase.MissionUsecase
+	promoUsecase    usecase.PromoUsecase
+	badgeUsecase    usecase.BadgeUsecase
+}
+
+func merchantErrorStatus(err error) int {
+	switch err.Error() {
+	case "merchant not found", "settlement account not found":
+		return http.StatusNotFound
+	case "user is already a merchant":
+		return http.StatusConflict
+	case "insufficient balance":
+		return http.StatusUnprocessableEntity
+	case "business name must be 1 - 50 characters", "business category must be 1 - 30 characters", "business address must be at most 200 characters",
+		"mdr must be 0 - 1000 basis points", "invalid QR code", "merchant id or QR code is required", "amount must be greater than 0",
+		"note must be at most 100 characters", "cannot pay your own merchant", "date must use YYYY-MM-DD format", "from date must not be after to date":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *MerchantController) Register(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newMerchant model.Merchant
+	if err := ctx.ShouldBindJSON(&newMerchant); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	if err := c.merchantUsecase.Register(user, &newMerchant); err != nil {
+		logrus.Errorf("Failed to register merchant: %v", err)
+		status := merchantErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to register merchant"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Merchant registered Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newMerchant)
+}
+
+func (c *MerchantController) FindMerchant(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	merchant, err := c.merchantUsecase.FindByUserID(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get merchant: %v", err)
+		if err.Error() == "merchant not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Merchant not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get merchant")
+		return
+	}
+
+	logrus.Info("Merchant loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, merchant)
+}
+
+func (c *MerchantController) FindMerchantInfo(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	merchant, err := c.merchantUsecase.FindByID(ctx.Param("merchant_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get merchant: %v", err)
+		if err.Error() == "merchant not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Merchant not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get merchant")
+		return
+	}
+
+	logrus.Info("Merchant loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, merchant)
+}
+
+func (c *MerchantController) Update(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var reqBody model.Merchant
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	if err := c.merchantUsecase.Update(user, &reqBody); err != nil {
+		logrus.Errorf("Failed to update merchant: %v", err)
+		status := merchantErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to update merchant"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Merchant updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, reqBody)
+}
+
+func (c *MerchantController) SetMDR(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var reqBody model.MerchantMDR
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.merchantUsecase.SetMDR(ctx.Param("merchant_id"), reqBody.MDRBps); err != nil {
+		logrus.Errorf("Failed to update merchant mdr: %v", err)
+		status := merchantErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to update merchant mdr"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Merchant mdr updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Merchant mdr updated successfully")
+}
+
+func (c *MerchantController) Pay(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var payment model.MerchantPayment
+	if err := ctx.ShouldBindJSON(&payment); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	payer, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	if payment.PromoCode != "" {
+		redemption, ok := reservePromo(ctx, c.promoUsecase, payer, payment.PromoCode, "Merchant Payment", payment.Amount, 0)
+		if !ok {
+			return
+		}
+		payment.Promo = redemption
+	}
+
+	if err := c.merchantUsecase.Pay(payer, &payment); err != nil {
+		releasePromo(c.promoUsecase, payment.Promo)
+		logrus.Errorf("Failed to pay merchant: %v", err)
+		status := merchantErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to pay merchant"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	awardPoints(c.pointUsecase, payer.ID, "Merchant Payment", payment.TransactionID, payment.Amount)
+	trackMissions(c.missionUsecase, payer.ID, "Merchant Payment", payment.Amount)
+	evaluateBadge(c.badgeUsecase, c.userUsecase, payer.ID)
+	applyPromo(c.promoUsecase, payment.Promo, payment.TransactionID)
+
+	merchantUser, err := c.userUsecase.FindByiDToken(payment.MerchantUserID)
+	if err != nil {
+		logrus.Errorf("failed to get merchant token: %v", err)
+	} else {
+		amount := float64(payment.Amount) / 1000
+		formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+
+		err = model.SendFCMNotification(merchantUser.Token, "Pembayaran Diterima", "Anda menerima pembayaran dari "+payment.PayerName+" sebesar "+formattedAmount)
+		if err != nil {
+			logrus.Errorf("failed to send FCM notification: %v", err)
+		}
+	}
+	if err := c.webhookUsecase.Publish(payment.MerchantUserID, usecase.WebhookPaymentSucceeded, payment); err != nil {
+		logrus.Errorf("Failed to publish webhook event: %v", err)
+	}
+
+	logrus.Info("Merchant payment Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, payment)
+}
+
+func (c *MerchantController) FindSales(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	sales, err := c.merchantUsecase.FindSales(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get merchant sales: %v", err)
+		if err.Error() == "merchant not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Merchant not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get merchant sales")
+		return
+	}
+
+	logrus.Info("Merchant sales loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, sales)
+}
+
+func (c *MerchantController) FindSettlements(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	settlements, err := c.merchantUsecase.FindSettlements(ctx.Param("user_id"), ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		logrus.Errorf("Failed to get merchant settlements: %v", err)
+		status := merchantErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to get merchant settlements"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Merchant settlements loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, settlements)
+}
+
+func NewMerchantController(u usecase.MerchantUsecase, uc usecase.UserUseCase, wh usecase.WebhookUsecase, pt usecase.PointUsecase, ms usecase.MissionUsecase, pr usecase.PromoUsecase, bd usecase.BadgeUsecase) *MerchantController {
+	controller := MerchantController{
+		merchantUsecase: u,
+		userUsecase:     uc,
+		webhookUsecase:  wh,
+		pointUsecase:    pt,
+		missionUsecase:  ms,
+		promoUsecase:    pr,
+		badgeUsecase:    bd,
+	}
+	return &controller
+}
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type MissionController struct {
+	missionUsecase usecase.MissionUsecase
+}
+
+func missionErrorStatus(err error) int {
+	switch err.Error() {
+	case "mission not found", "id not found":
+		return http.StatusNotFound
+	case "title must be 1 - 50 characters", "description must be at most 200 characters", "transaction type must be Deposit, Transfer or Merchant Payment",
+		"metric must be Count or Amount", "target and reward points must be greater than 0", "min amount must not be negative", "mission must end after it starts":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// trackMissions moves the user's missions forward after a successful
+// transaction. Failures are only logged because the transaction itself
+// already went through.
+func trackMissions(missionUsecase usecase.MissionUsecase, userID string, transactionType string, amount int) {
+	completed, err := missionUsecase.Track(userID, transactionType, amount)
+	if err != nil {
+		logrus.Errorf("Failed to track missions: %v", err)
+	}
+	for _, mission := range completed {
+		logrus.Infof("Mission %d completed by %s", mission.MissionID, userID)
+	}
+}
+
+func (c *MissionController) FindMissions(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	missions, err := c.missionUsecase.FindMissions()
+	if err != nil {
+		logrus.Errorf("Failed to get missions: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get missions")
+		return
+	}
+
+	logrus.Info("Missions loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, missions)
+}
+
+func (c *MissionController) CreateMission(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newMission model.Mission
+	if err := ctx.ShouldBindJSON(&newMission); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.missionUsecase.CreateMission(&newMission); err != nil {
+		logrus.Errorf("Failed to create mission: %v", err)
+		status := missionErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create mission"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Mission created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newMission)
+}
+
+func (c *MissionController) UpdateMission(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	missionID, err := strconv.Atoi(ctx.Param("mission_id"))
+	if err != nil {
+		logrus.Errorf("Invalid mission id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid mission id")
+		return
+	}
+
+	var mission model.Mission
+	if err := ctx.ShouldBindJSON(&mission); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	mission.MissionID = missionID
+
+	if err := c.missionUsecase.UpdateMission(&mission); err != nil {
+		logrus.Errorf("Failed to update mission: %v", err)
+		status := missionErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to update mission"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Mission updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, mission)
+}
+
+func (c *MissionController) DeactivateMission(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	missionID, err := strconv.Atoi(ctx.Param("mission_id"))
+	if err != nil {
+		logrus.Errorf("Invalid mission id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid mission id")
+		return
+	}
+
+	if err := c.missionUsecase.DeactivateMission(missionID); err != nil {
+		logrus.Errorf("Failed to deactivate mission: %v", err)
+		if err.Error() == "mission not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Mission not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to deactivate mission")
+		return
+	}
+
+	logrus.Info("Mission deactivated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Mission deactivated successfully")
+}
+
+func (c *MissionController) FindActive(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	missions, err := c.missionUsecase.FindActive()
+	if err != nil {
+		logrus.Errorf("Failed to get active missions: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get active missions")
+		return
+	}
+
+	logrus.Info("Active missions loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, missions)
+}
+
+func (c *MissionController) FindProgress(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	progress, err := c.missionUsecase.FindProgress(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get mission progress: %v", err)
+		if err.Error() == "id not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get mission progress")
+		return
+	}
+
+	logrus.Info("Mission progress loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, progress)
+}
+
+func NewMissionController(u usecase.MissionUsecase) *MissionController {
+	controller := MissionController{
+		missionUsecase: u,
+	}
+	return &controller
+}
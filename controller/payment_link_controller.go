@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentLinkController struct {
+	linkUsecase    usecase.PaymentLinkUsecase
+	userUsecase    usecase.UserUseCase
+	webhookUsecase usecase.WebhookUsecase
+}
+
+func paymentLinkErrorStatus(err error) int {
+	switch err.Error() {
+	case "payment link not found":
+		return http.StatusNotFound
+	case "insufficient balance", "payment link is no longer available":
+		return http.StatusUnprocessableEntity
+	case "amount must be 0 or at least 1,000", "description must be 1 - 100 characters", "max uses must not be negative",
+		"expiry must be 1 - 90 days", "amount must match the payment link", "minimum payment amount is 1,000",
+		"minimum gateway payment is 10,000", "payer name must be 1 - 50 characters", "cannot pay your own payment link":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// notifyPaymentLinkOwner tells the link owner about a successful payment
+// through FCM and their webhook subscriptions.
+func notifyPaymentLinkOwner(userUsecase usecase.UserUseCase, webhookUsecase usecase.WebhookUsecase, payment *model.PaymentLinkPayment) {
+	owner, err := userUsecase.FindByiDToken(payment.OwnerID)
+	if err != nil {
+		logrus.Errorf("failed to get link owner token: %v", err)
+	} else {
+		amount := float64(payment.Amount) / 1000
+		formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+
+		err = model.SendFCMNotification(owner.Token, "Pembayaran Diterima", "Anda menerima pembayaran link dari "+payment.PayerName+" sebesar "+formattedAmount)
+		if err != nil {
+			logrus.Errorf("failed to send FCM notification: %v", err)
+		}
+	}
+	if err := webhookUsecase.Publish(payment.OwnerID, usecase.WebhookPaymentSucceeded, payment); err != nil {
+		logrus.Errorf("Failed to publish webhook event: %v", err)
+	}
+}
+
+func (c *PaymentLinkController) Create(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newLink model.PaymentLink
+	if err := ctx.ShouldBindJSON(&newLink); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	owner, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	if err := c.linkUsecase.Create(owner, &newLink); err != nil {
+		logrus.Errorf("Failed to create payment link: %v", err)
+		status := paymentLinkErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create payment link"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Payment link created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newLink)
+}
+
+func (c *PaymentLinkController) FindByOwner(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	links, err := c.linkUsecase.FindByOwner(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get payment links: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get payment links")
+		return
+	}
+
+	logrus.Info("Payment links loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, links)
+}
+
+func (c *PaymentLinkController) Deactivate(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	if err := c.linkUsecase.Deactivate(ctx.Param("user_id"), ctx.Param("link_id")); err != nil {
+		logrus.Errorf("Failed to deactivate payment link: %v", err)
+		status := paymentLinkErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to deactivate payment link"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Payment link deactivated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Payment link deactivated successfully")
+}
+
+func (c *PaymentLinkController) FindPayments(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	payments, err := c.linkUsecase.FindPayments(ctx.Param("user_id"), ctx.Param("link_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get payment link payments: %v", err)
+		status := paymentLinkErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to get payment link payments"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Payment link payments loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, payments)
+}
+
+// FindLink is public so anyone holding the link can see what they are paying.
+func (c *PaymentLinkController) FindLink(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	link, err := c.linkUsecase.FindByID(ctx.Param("link_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get payment link: %v", err)
+		if err.Error() == "payment link not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Payment link not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get payment link")
+		return
+	}
+	link.OwnerID = ""
+	link.OwnerName = utils.MaskName(link.OwnerName)
+	link.CollectedAmount = 0
+
+	logrus.Info("Payment link loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, link)
+}
+
+func (c *PaymentLinkController) PayWithWallet(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var reqBody model.PaymentLinkPayment
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil && err != io.EOF {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	payer, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	payment, err := c.linkUsecase.PayWithWallet(payer, ctx.Param("link_id"), reqBody.Amount)
+	if err != nil {
+		logrus.Errorf("Failed to pay payment link: %v", err)
+		status := paymentLinkErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to pay payment link"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	notifyPaymentLinkOwner(c.userUsecase, c.webhookUsecase, payment)
+
+	logrus.Info("Payment link paid Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, payment)
+}
+
+// GuestPay lets someone without an account pay a link through Midtrans. The
+// owner is credited when the settlement notification arrives.
+func (c *PaymentLinkController) GuestPay(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var payment model.PaymentLinkPayment
+	if err := ctx.ShouldBindJSON(&payment); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.linkUsecase.StartGatewayPayment(ctx.Param("link_id"), &payment); err != nil {
+		logrus.Errorf("Failed to start payment link payment: %v", err)
+		status := paymentLinkErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to start payment"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	token, err := model.CreateMidtransTransactionFromPaymentLink(&payment)
+	if err != nil {
+		logrus.Errorf("Failed to create Midtrans transaction: %v", err)
+		if err := c.linkUsecase.FailGatewayPayment(payment.OrderID); err != nil {
+			logrus.Errorf("Failed to cancel payment link payment: %v", err)
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to create Midtrans transaction")
+		return
+	}
+
+	logrus.Info("Payment link payment started Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, gin.H{
+		"order_id":      payment.OrderID,
+		"amount":        payment.Amount,
+		"body_midtrans": token,
+	})
+}
+
+func NewPaymentLinkController(u usecase.PaymentLinkUsecase, uc usecase.UserUseCase, wh usecase.WebhookUsecase) *PaymentLinkController {
+	controller := PaymentLinkController{
+		linkUsecase:    u,
+		userUsecase:    uc,
+		webhookUsecase: wh,
+	}
+	return &controller
+}
@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PocketController struct {
+	pocketUsecase usecase.PocketUsecase
+	userUsecase   usecase.UserUseCase
+}
+
+func pocketErrorStatus(err error) int {
+	switch err.Error() {
+	case "pocket not found":
+		return http.StatusNotFound
+	case "insufficient balance", "insufficient pocket balance", "maximum 10 pockets per user":
+		return http.StatusUnprocessableEntity
+	case "pocket name must be 1 - 30 characters", "target amount must not be negative", "auto save percent must be 0 - 50",
+		"deadline must use YYYY-MM-DD format", "deadline must not be in the past", "amount must be greater than 0":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *PocketController) FindPockets(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	pockets, err := c.pocketUsecase.FindPockets(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get pockets: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get pockets")
+		return
+	}
+
+	logrus.Info("Pockets loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, pockets)
+}
+
+func (c *PocketController) CreatePocket(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newPocket model.Pocket
+	if err := ctx.ShouldBindJSON(&newPocket); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	newPocket.UserID = ctx.Param("user_id")
+
+	if err := c.pocketUsecase.CreatePocket(&newPocket); err != nil {
+		logrus.Errorf("Failed to create pocket: %v", err)
+		status := pocketErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create pocket"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Pocket created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newPocket)
+}
+
+func (c *PocketController) UpdatePocket(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	pocketID, err := strconv.Atoi(ctx.Param("pocket_id"))
+	if err != nil {
+		logrus.Errorf("Invalid pocket_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid pocket_id")
+		return
+	}
+
+	var pocket model.Pocket
+	if err := ctx.ShouldBindJSON(&pocket); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	pocket.PocketID = pocketID
+	pocket.UserID = ctx.Param("user_id")
+
+	if err := c.pocketUsecase.UpdatePocket(&pocket); err != nil {
+		logrus.Errorf("Failed to update pocket: %v", err)
+		status := pocketErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to update pocket"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Pocket updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, pocket)
+}
+
+func (c *PocketController) DeletePocket(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	pocketID, err := strconv.Atoi(ctx.Param("pocket_id"))
+	if err != nil {
+		logrus.Errorf("Invalid pocket_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid pocket_id")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	if err := c.pocketUsecase.DeletePocket(user, pocketID); err != nil {
+		logrus.Errorf("Failed to delete pocket: %v", err)
+		status := pocketErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to delete pocket"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Pocket deleted Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Pocket deleted Successfully")
+}
+
+func (c *PocketController) MoveIn(ctx *gin.Context) {
+	c.move(ctx, true)
+}
+
+func (c *PocketController) MoveOut(ctx *gin.Context) {
+	c.move(ctx, false)
+}
+
+func (c *PocketController) move(ctx *gin.Context, in bool) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	pocketID, err := strconv.Atoi(ctx.Param("pocket_id"))
+	if err != nil {
+		logrus.Errorf("Invalid pocket_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid pocket_id")
+		return
+	}
+
+	var reqBody model.PocketMovement
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	var movement *model.PocketMovement
+	if in {
+		movement, err = c.pocketUsecase.MoveIn(user, pocketID, reqBody.Amount)
+	} else {
+		movement, err = c.pocketUsecase.MoveOut(user, pocketID, reqBody.Amount)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to move pocket money: %v", err)
+		status := pocketErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to move pocket money"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Pocket money moved Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, movement)
+}
+
+func NewPocketController(u usecase.PocketUsecase, uc usecase.UserUseCase) *PocketController {
+	controller := PocketController{
+		pocketUsecase: u,
+		userUsecase:   uc,
+	}
+	return &controller
+}
@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PointController struct {
+	pointUsecase usecase.PointUsecase
+	userUsecase  usecase.UserUseCase
+}
+
+func pointErrorStatus(err error) int {
+	switch err.Error() {
+	case "point rule not found", "id not found", "phone not found":
+		return http.StatusNotFound
+	case "insufficient points", "daily conversion limit reached", "daily transfer limit reached":
+		return http.StatusUnprocessableEntity
+	case "points must be greater than 0", "note must be at most 100 characters", "cannot transfer points to yourself", "points must not be 0", "description must be 1 - 100 characters", "rule name must be 1 - 50 characters", "transaction type must be Deposit, Transfer or Merchant Payment", "amounts and points must not be negative",
+		"percent must be 0 - 10000 bps", "rule must award fixed or percentage points", "campaign must end after it starts":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// awardPoints credits loyalty points for a successful transaction. Failures
+// are only logged because the transaction itself already went through.
+func awardPoints(pointUsecase usecase.PointUsecase, userID string, transactionType string, transactionID int, amount int) {
+	points, err := pointUsecase.Award(userID, transactionType, transactionID, amount)
+	if err != nil {
+		logrus.Errorf("Failed to award points: %v", err)
+		return
+	}
+	if points > 0 {
+		logrus.Infof("Awarded %d points to %s for transaction %d", points, userID, transactionID)
+	}
+}
+
+func (c *PointController) FindRules(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	rules, err := c.pointUsecase.FindRules()
+	if err != nil {
+		logrus.Errorf("Failed to get point rules: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get point rules")
+		return
+	}
+
+	logrus.Info("Point rules loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, rules)
+}
+
+func (c *PointController) CreateRule(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newRule model.PointRule
+	if err := ctx.ShouldBindJSON(&newRule); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.pointUsecase.CreateRule(&newRule); err != nil {
+		logrus.Errorf("Failed to create point rule: %v", err)
+		status := pointErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create point rule"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Point rule created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newRule)
+}
+
+func (c *PointController) UpdateRule(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	ruleID, err := strconv.Atoi(ctx.Param("rule_id"))
+	if err != nil {
+		logrus.Errorf("Invalid rule id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid rule id")
+		return
+	}
+
+	var rule model.PointRule
+	if err := ctx.ShouldBindJSON(&rule); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	rule.RuleID = ruleID
+
+	if err := c.pointUsecase.UpdateRule(&rule); err != nil {
+		logrus.Errorf("Failed to update point rule: %v", err)
+		status := pointErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to update point rule"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Point rule updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, rule)
+}
+
+func (c *PointController) DeactivateRule(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	ruleID, err := strconv.Atoi(ctx.Param("rule_id"))
+	if err != nil {
+		logrus.Errorf("Invalid rule id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid rule id")
+		return
+	}
+
+	if err := c.pointUsecase.DeactivateRule(ruleID); err != nil {
+		logrus.Errorf("Failed to deactivate point rule: %v", err)
+		if err.Error() == "point rule not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Point rule not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to deactivate point rule")
+		return
+	}
+
+	logrus.Info("Point rule deactivated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Point rule deactivated successfully")
+}
+
+func (c *PointController) FindHistory(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	history, err := c.pointUsecase.FindHistory(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get point history: %v", err)
+		if err.Error() == "id not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get point history")
+		return
+	}
+
+	logrus.Info("Point history loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, history)
+}
+
+func (c *PointController) Adjust(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var adjustment model.PointAdjustment
+	if err := ctx.ShouldBindJSON(&adjustment); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := c.pointUsecase.Adjust(ctx.Param("user_id"), &adjustment)
+	if err != nil {
+		logrus.Errorf("Failed to adjust points: %v", err)
+		status := pointErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to adjust points"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Points adjusted Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, entry)
+}
+
+func (c *PointController) Convert(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var conversion model.PointConversionRequest
+	if err := ctx.ShouldBindJSON(&conversion); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	movement, err := c.pointUsecase.Convert(user, conversion.Points)
+	if err != nil {
+		logrus.Errorf("Failed to convert points: %v", err)
+		status := pointErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to convert points"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Points converted Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, movement)
+}
+
+func (c *PointController) Transfer(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var transfer model.PointTransferRequest
+	if err := ctx.ShouldBindJSON(&transfer); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	sender, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	movement, err := c.pointUsecase.Transfer(sender, &transfer)
+	if err != nil {
+		logrus.Errorf("Failed to transfer points: %v", err)
+		status := pointErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to transfer points"
+		}
+		if err.Error() == "phone not found" {
+			message = "Recipient not found"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	recipient, err := c.userUsecase.FindByiDToken(movement.RecipientID)
+	if err != nil {
+		logrus.Errorf("Failed to get recipient token: %v", err)
+	} else {
+		err = model.SendFCMNotification(recipient.Token, "Poin Diterima", "Anda menerima "+strconv.Itoa(movement.Points)+" poin dari "+movement.SenderName)
+		if err != nil {
+			logrus.Errorf("failed to send FCM notification: %v", err)
+		}
+	}
+
+	logrus.Info("Points transferred Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, movement)
+}
+
+func NewPointController(u usecase.PointUsecase, uc usecase.UserUseCase) *PointController {
+	controller := PointController{
+		pointUsecase: u,
+		userUsecase:  uc,
+	}
+	return &controller
+}
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PointExchangeController struct {
+	pointExchangeUsecase usecase.PointExchangeUsecase
+}
+
+func pointExchangeErrorStatus(err error) int {
+	switch err.Error() {
+	case "point exchange not found":
+		return http.StatusNotFound
+	case "reward must be 1 - 100 characters", "price must be greater than 0", "category must be 1 - 30 characters",
+		"description must be at most 500 characters", "image url must be a valid http or https address", "stock must not be negative",
+		"fulfillment type must be None, Voucher Code or Provider", "no reward provider is configured", "reward must end after it starts":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *PointExchangeController) FindCatalog(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	catalog, err := c.pointExchangeUsecase.FindCatalog(ctx.Query("category"))
+	if err != nil {
+		logrus.Errorf("Failed to get point exchange catalog: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get point exchange catalog")
+		return
+	}
+
+	logrus.Info("Point exchange catalog loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, catalog)
+}
+
+func (c *PointExchangeController) FindCategories(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	categories, err := c.pointExchangeUsecase.FindCategories()
+	if err != nil {
+		logrus.Errorf("Failed to get point exchange categories: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get point exchange categories")
+		return
+	}
+
+	logrus.Info("Point exchange categories loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, categories)
+}
+
+func (c *PointExchangeController) FindReward(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	peID, err := strconv.Atoi(ctx.Param("pe_id"))
+	if err != nil {
+		logrus.Errorf("Invalid pe_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid pe_id")
+		return
+	}
+
+	pe, err := c.pointExchangeUsecase.FindReward(peID)
+	if err != nil {
+		logrus.Errorf("Failed to get point exchange: %v", err)
+		if err.Error() == "point exchange not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Point exchange not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get point exchange")
+		return
+	}
+
+	logrus.Info("Point exchange loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, pe)
+}
+
+func (c *PointExchangeController) FindAll(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	pointExchanges, err := c.pointExchangeUsecase.FindAll()
+	if err != nil {
+		logrus.Errorf("Failed to get point exchanges: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get point exchanges")
+		return
+	}
+
+	logrus.Info("Point exchanges loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, pointExchanges)
+}
+
+func (c *PointExchangeController) Create(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newPointExchange model.PointExchange
+	if err := ctx.ShouldBindJSON(&newPointExchange); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.pointExchangeUsecase.Create(&newPointExchange); err != nil {
+		logrus.Errorf("Failed to create point exchange: %v", err)
+		status := pointExchangeErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create point exchange"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Point exchange created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newPointExchange)
+}
+
+func (c *PointExchangeController) Update(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	peID, err := strconv.Atoi(ctx.Param("pe_id"))
+	if err != nil {
+		logrus.Errorf("Invalid pe_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid pe_id")
+		return
+	}
+
+	var pe model.PointExchange
+	if err := ctx.ShouldBindJSON(&pe); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	pe.PE_ID = peID
+
+	if err := c.pointExchangeUsecase.Update(&pe); err != nil {
+		logrus.Errorf("Failed to update point exchange: %v", err)
+		status := pointExchangeErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to update point exchange"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Point exchange updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, pe)
+}
+
+func (c *PointExchangeController) Deactivate(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	peID, err := strconv.Atoi(ctx.Param("pe_id"))
+	if err != nil {
+		logrus.Errorf("Invalid pe_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid pe_id")
+		return
+	}
+
+	if err := c.pointExchangeUsecase.Deactivate(peID); err != nil {
+		logrus.Errorf("Failed to deactivate point exchange: %v", err)
+		if err.Error() == "point exchange not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Point exchange not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to deactivate point exchange")
+		return
+	}
+
+	logrus.Info("Point exchange deactivated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Point exchange deactivated successfully")
+}
+
+func NewPointExchangeController(u usecase.PointExchangeUsecase) *PointExchangeController {
+	controller := PointExchangeController{
+		pointExchangeUsecase: u,
+	}
+	return &controller
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PromoController struct {
+	promoUsecase usecase.PromoUsecase
+}
+
+func promoErrorStatus(err error) int {
+	switch err.Error() {
+	case "promo code not found":
+		return http.StatusNotFound
+	case "promo code already exists":
+		return http.StatusConflict
+	case "promo code is not active", "promo code is not valid for this transaction", "minimum amount for this promo is not reached",
+		"promo code is not available for your badge", "promo code usage limit reached", "promo code gives no benefit for this transaction", "promo budget exhausted":
+		return http.StatusUnprocessableEntity
+	case "code must be 1 - 20 characters without spaces", "campaign name must be 1 - 50 characters", "transaction type must be Transfer, Deposit or Merchant Payment",
+		"fee waiver is only available for transfers", "cashback must be a fixed amount or a percentage", "benefit type must be Fee Waiver or Cashback",
+		"amounts and limits must not be negative", "percent must be 0 - 10000 bps", "campaign must end after it starts":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// reservePromo sets a promo code's benefit aside before a transaction and
+// writes the error response when the code cannot be used.
+func reservePromo(ctx *gin.Context, promoUsecase usecase.PromoUsecase, user *model.User, code string, transactionType string, amount int, fee int) (*model.PromoRedemption, bool) {
+	redemption, err := promoUsecase.Reserve(user, code, transactionType, amount, fee)
+	if err != nil {
+		logrus.Errorf("Failed to apply promo code: %v", err)
+		status := promoErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to apply promo code"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return nil, false
+	}
+	return redemption, true
+}
+
+// releasePromo gives a reserved benefit back when its transaction failed.
+func releasePromo(promoUsecase usecase.PromoUsecase, redemption *model.PromoRedemption) {
+	if redemption == nil {
+		return
+	}
+	if err := promoUsecase.Release(redemption); err != nil {
+		logrus.Errorf("Failed to release promo redemption: %v", err)
+	}
+}
+
+// applyPromo uses a reserved benefit after its transaction succeeded.
+// Failures are only logged because the transaction itself already went
+// through.
+func applyPromo(promoUsecase usecase.PromoUsecase, redemption *model.PromoRedemption, transactionID int) {
+	if redemption == nil {
+		return
+	}
+	if err := promoUsecase.Apply(redemption, transactionID); err != nil {
+		logrus.Errorf("Failed to apply promo redemption: %v", err)
+	}
+}
+
+func (c *PromoController) FindCampaigns(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	campaigns, err := c.promoUsecase.FindCampaigns()
+	if err != nil {
+		logrus.Errorf("Failed to get promo campaigns: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get promo campaigns")
+		return
+	}
+
+	logrus.Info("Promo campaigns loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, campaigns)
+}
+
+func (c *PromoController) CreateCampaign(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newCampaign model.PromoCampaign
+	if err := ctx.ShouldBindJSON(&newCampaign); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := c.promoUsecase.CreateCampaign(&newCampaign); err != nil {
+		logrus.Errorf("Failed to create promo campaign: %v", err)
+		status := promoErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create promo campaign"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Promo campaign created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newCampaign)
+}
+
+func (c *PromoController) UpdateCampaign(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	campaignID, err := strconv.Atoi(ctx.Param("campaign_id"))
+	if err != nil {
+		logrus.Errorf("Invalid campaign id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid campaign id")
+		return
+	}
+
+	var campaign model.PromoCampaign
+	if err := ctx.ShouldBindJSON(&campaign); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	campaign.CampaignID = campaignID
+
+	if err := c.promoUsecase.UpdateCampaign(&campaign); err != nil {
+		logrus.Errorf("Failed to update promo campaign: %v", err)
+		status := promoErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to update promo campaign"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Promo campaign updated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, campaign)
+}
+
+func (c *PromoController) DeactivateCampaign(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	campaignID, err := strconv.Atoi(ctx.Param("campaign_id"))
+	if err != nil {
+		logrus.Errorf("Invalid campaign id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid campaign id")
+		return
+	}
+
+	if err := c.promoUsecase.DeactivateCampaign(campaignID); err != nil {
+		logrus.Errorf("Failed to deactivate promo campaign: %v", err)
+		if err.Error() == "promo code not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Promo campaign not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to deactivate promo campaign")
+		return
+	}
+
+	logrus.Info("Promo campaign deactivated Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Promo campaign deactivated successfully")
+}
+
+func NewPromoController(u usecase.PromoUsecase) *PromoController {
+	controller := PromoController{
+		promoUsecase: u,
+	}
+	return &controller
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type RedEnvelopeController struct {
+	envelopeUsecase usecase.RedEnvelopeUsecase
+	userUsecase     usecase.UserUseCase
+}
+
+func redEnvelopeErrorStatus(err error) int {
+	switch err.Error() {
+	case "envelope not found":
+		return http.StatusNotFound
+	case "envelope already claimed":
+		return http.StatusConflict
+	case "insufficient balance", "envelope is no longer available":
+		return http.StatusUnprocessableEntity
+	case "split type must be Equal or Random", "slots must be 1 - 100", "each slot must get at least 1,000",
+		"message must be at most 100 characters", "cannot claim your own envelope":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *RedEnvelopeController) Create(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var newEnvelope model.RedEnvelope
+	if err := ctx.ShouldBindJSON(&newEnvelope); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	if err := c.envelopeUsecase.Create(user, &newEnvelope); err != nil {
+		logrus.Errorf("Failed to create red envelope: %v", err)
+		status := redEnvelopeErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create red envelope"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Red envelope created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newEnvelope)
+}
+
+func (c *RedEnvelopeController) FindByCreator(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	envelopes, err := c.envelopeUsecase.FindByCreator(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get red envelopes: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get red envelopes")
+		return
+	}
+
+	logrus.Info("Red envelopes loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, envelopes)
+}
+
+func (c *RedEnvelopeController) FindByCode(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	envelope, err := c.envelopeUsecase.FindByCode(ctx.Param("code"))
+	if err != nil {
+		logrus.Errorf("Failed to get red envelope: %v", err)
+		if err.Error() == "envelope not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Envelope not found")
+			return
+		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get red envelope")
+		return
+	}
+
+	logrus.Info("Red envelope loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, envelope)
+}
+
+func (c *RedEnvelopeController) Claim(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	user, err := c.userUsecase.FindByiDToken(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	transfer, err := c.envelopeUsecase.Claim(user, ctx.Param("code"))
+	if err != nil {
+		logrus.Errorf("Failed to claim red envelope: %v", err)
+		status := redEnvelopeErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to claim red envelope"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	amount := float64(transfer.Amount) / 1000
+	formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+
+	err = model.SendFCMNotification(user.Token, "Angpao Diterima", "Anda menerima angpao dari "+transfer.SenderName+" sebesar "+formattedAmount)
+	if err != nil {
+		logrus.Errorf("failed to send FCM notification: %v", err)
+	}
+
+	logrus.Info("Red envelope claimed Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, transfer)
+}
+
+func NewRedEnvelopeController(u usecase.RedEnvelopeUsecase, uc usecase.UserUseCase) *RedEnvelopeController {
+	controller := RedEnvelopeController{
+		envelopeUsecase: u,
+		userUsecase:     uc,
+	}
+	return &controller
+}
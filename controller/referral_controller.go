@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ReferralController struct {
+	referralUsecase usecase.ReferralUsecase
+	userUsecase     usecase.UserUseCase
+}
+
+// qualifyReferral completes the referee's pending referral after a
+// successful transaction. Failures are only logged because the transaction
+// itself already went through.
+func qualifyReferral(referralUsecase usecase.ReferralUsecase, userID string, transactionType string, transactionID int, amount int, counterpartyID string) {
+	referral, err := referralUsecase.Qualify(userID, transactionType, transactionID, amount, counterpartyID)
+	if err != nil {
+		logrus.Errorf("Failed to qualify referral: %v", err)
+		return
+	}
+	if referral != nil {
+		logrus.Infof("Referral %d of %s completed as %s", referral.ReferralID, userID, referral.Status)
+	}
+}
+
+func (c *ReferralController) FindDashboard(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	user, err := c.userUsecase.FindById(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("user_id not found: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "user_id not found")
+		return
+	}
+
+	dashboard, err := c.referralUsecase.FindDashboard(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get referral dashboard: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get referral dashboard")
+		return
+	}
+
+	logrus.Info("Referral dashboard loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, dashboard)
+}
+
+func NewReferralController(u usecase.ReferralUsecase, uc usecase.UserUseCase) *ReferralController {
+	controller := ReferralController{
+		referralUsecase: u,
+		userUsecase:     uc,
+	}
+	return &controller
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type RewardController struct {
+	rewardUsecase usecase.RewardUsecase
+}
+
+func rewardErrorStatus(err error) int {
+	switch err.Error() {
+	case "point exchange not found":
+		return http.StatusNotFound
+	case "reward does not use voucher codes":
+		return http.StatusUnprocessableEntity
+	case "invalid csv file", "csv file is empty", "maximum 5000 codes per upload":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *RewardController) UploadCodes(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	peID, err := strconv.Atoi(ctx.Param("pe_id"))
+	if err != nil {
+		logrus.Errorf("Invalid pe_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid pe_id")
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		logrus.Errorf("Failed to get file from request: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Failed to get file from request")
+		return
+	}
+	if filepath.Ext(fileHeader.Filename) != ".csv" {
+		logrus.Errorf("Extension file is not csv file")
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Extension file is not csv file")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logrus.Errorf("Failed to open file: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to open file")
+		return
+	}
+	defer file.Close()
+
+	upload, err := c.rewardUsecase.UploadCodes(peID, file)
+	if err != nil {
+		logrus.Errorf("Failed to upload voucher codes: %v", err)
+		status := rewardErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to upload voucher codes"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Voucher codes uploaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, upload)
+}
+
+func (c *RewardController) FindVouchers(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	vouchers, err := c.rewardUsecase.FindVouchers(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get vouchers: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get vouchers")
+		return
+	}
+
+	logrus.Info("Vouchers loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, vouchers)
+}
+
+func NewRewardController(u usecase.RewardUsecase) *RewardController {
+	controller := RewardController{
+		rewardUsecase: u,
+	}
+	return &controller
+}
@@ -2,28 +2,84 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/ReygaFitra/inc-final-project.git/model"
 	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
 	"github.com/ReygaFitra/inc-final-project.git/usecase"
 	"github.com/ReygaFitra/inc-final-project.git/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
-var depositToken string
-var userIDdepo string
-var depoAmount int
-var deviceToken string
-
 type TransactionController struct {
-	txUsecase   usecase.TransactionUseCase
-	userUsecase usecase.UserUseCase
-	bankUsecase usecase.BankAccUsecase
+	txUsecase       usecase.TransactionUseCase
+	userUsecase     usecase.UserUseCase
+	bankUsecase     usecase.BankAccUsecase
+	inquiryUsecase  usecase.TransferInquiryUseCase
+	pocketUsecase   usecase.PocketUsecase
+	webhookUsecase  usecase.WebhookUsecase
+	linkUsecase     usecase.PaymentLinkUsecase
+	pointUsecase    usecase.PointUsecase
+	rewardUsecase   usecase.RewardUsecase
+	badgeUsecase    usecase.BadgeUsecase
+	referralUsecase usecase.ReferralUsecase
+	missionUsecase  usecase.MissionUsecase
+	promoUsecase    usecase.PromoUsecase
+	hooks           *transferHooks
+}
+
+// transferHooks runs what follows a successful P2P transfer, whether it was
+// sent on its own or as a row of a bulk transfer.
+type transferHooks struct {
+	pocketUsecase   usecase.PocketUsecase
+	pointUsecase    usecase.PointUsecase
+	referralUsecase usecase.ReferralUsecase
+	missionUsecase  usecase.MissionUsecase
+	badgeUsecase    usecase.BadgeUsecase
+}
+
+// afterTransfer only logs failures because the money has already moved. The
+// sender is notified by the caller, who knows whether it was a bulk transfer.
+func (h *transferHooks) afterTransfer(sender *model.User, recipient *model.User, transfer *model.Transfer) {
+	if err := h.pocketUsecase.AutoSave(recipient.ID, transfer.Amount); err != nil {
+		logrus.Errorf("Failed to auto save into pockets: %v", err)
+	}
+	awardPoints(h.pointUsecase, sender.ID, "Transfer", transfer.TransactionID, transfer.Amount)
+	qualifyReferral(h.referralUsecase, sender.ID, "Transfer", transfer.TransactionID, transfer.Amount, recipient.ID)
+	trackMissions(h.missionUsecase, sender.ID, "Transfer", transfer.Amount)
+	if _, err := h.badgeUsecase.Evaluate(sender); err != nil {
+		logrus.Errorf("Failed to evaluate badge: %v", err)
+	}
+
+	formattedAmount := "Rp " + strconv.FormatFloat(float64(transfer.Amount)/1000, 'f', 3, 64)
+	note := ""
+	if transfer.Note != "" {
+		note = " - \"" + transfer.Note + "\""
+	}
+	if err := model.SendFCMNotification(recipient.Token, "Receive Berhasil", "Anda telah menerima uang dari "+sender.Name+" sebesar "+formattedAmount+note); err != nil {
+		logrus.Errorf("failed to send FCM notification: %v", err)
+	}
+}
+
+func newTransferHooks(pk usecase.PocketUsecase, pt usecase.PointUsecase, rf usecase.ReferralUsecase, ms usecase.MissionUsecase, bd usecase.BadgeUsecase) *transferHooks {
+	return &transferHooks{
+		pocketUsecase:   pk,
+		pointUsecase:    pt,
+		referralUsecase: rf,
+		missionUsecase:  ms,
+		badgeUsecase:    bd,
+	}
 }
 
 func (c *TransactionController) HandlePaymentNotification(ctx *gin.Context) {
@@ -58,29 +114,74 @@ func (c *TransactionController) HandlePaymentNotification(ctx *gin.Context) {
 		return
 	}
 
+	if !model.VerifyMidtransSignature(&notification) {
+		logrus.Errorf("Rejected payment notification with invalid signature for order: %s", notification.OrderID)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid notification signature"})
+		return
+	}
+
+	if strings.HasPrefix(notification.OrderID, usecase.PaymentLinkOrderPrefix) {
+		c.handlePaymentLinkNotification(notification)
+		ctx.JSON(http.StatusOK, gin.H{"message": "Notification received"})
+		return
+	}
+
 	if notification.TransactionStatus == "settlement" {
 		if len(notification.VANumbers) > 0 {
 			vaNumber := notification.VANumbers[0].VANumber
 
-			err = c.userUsecase.UpdateBalance(userIDdepo, depoAmount)
+			deposit, err := c.txUsecase.FindDepositByOrderID(notification.OrderID)
 			if err != nil {
-				logrus.Errorf("Failed to update balance user: %v", err)
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance user"})
+				logrus.Errorf("Failed to get deposit for order %s: %v", notification.OrderID, err)
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Deposit not found"})
+				return
+			}
+			user, err := c.userUsecase.FindById(deposit.UserID)
+			if err != nil {
+				logrus.Errorf("Failed to get deposit user: %v", err)
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to get deposit user"})
 				return
 			}
-			logrus.Infof("useridDEPO: %s", userIDdepo)
-			logrus.Infof("depoAmount: %d", depoAmount)
 
-			err = c.txUsecase.UpdateDepositStatus(vaNumber, depositToken)
+			// Settle first so a repeated notification cannot credit the deposit twice
+			err = c.txUsecase.UpdateDepositStatus(vaNumber, deposit.OrderID)
+			if errors.Is(err, repository.ErrDepositNotPending) {
+				logrus.Infof("Ignored notification for settled deposit: %s", deposit.OrderID)
+				ctx.JSON(http.StatusOK, gin.H{"message": "Notification received"})
+				return
+			}
 			if err != nil {
 				logrus.Errorf("Failed to update deposit status: %v", err)
 				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update deposit status"})
 				return
 			}
-			amount := float64(depoAmount) / 1000                               //
+
+			err = c.userUsecase.UpdateBalance(user.ID, user.Balance+deposit.Amount)
+			if err != nil {
+				logrus.Errorf("Failed to update balance user: %v", err)
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance user"})
+				return
+			}
+			logrus.Infof("Deposit of %d credited to user: %s", deposit.Amount, deposit.UserID)
+
+			awardPoints(c.pointUsecase, deposit.UserID, "Deposit", deposit.TransactionID, deposit.Amount)
+			qualifyReferral(c.referralUsecase, deposit.UserID, "Deposit", deposit.TransactionID, deposit.Amount, "")
+			trackMissions(c.missionUsecase, deposit.UserID, "Deposit", deposit.Amount)
+			evaluateBadge(c.badgeUsecase, c.userUsecase, deposit.UserID)
+			if _, err := c.promoUsecase.ApplyForTransaction(deposit.TransactionID); err != nil {
+				logrus.Errorf("Failed to apply promo redemption: %v", err)
+			}
+			if err := c.pocketUsecase.AutoSave(deposit.UserID, deposit.Amount); err != nil {
+				logrus.Errorf("Failed to auto save into pockets: %v", err)
+			}
+			depositEvent := gin.H{"user_id": deposit.UserID, "transaction_id": deposit.TransactionID, "amount": deposit.Amount, "va_number": vaNumber}
+			if err := c.webhookUsecase.Publish(deposit.UserID, usecase.WebhookDepositSettled, depositEvent); err != nil {
+				logrus.Errorf("Failed to publish webhook event: %v", err)
+			}
+			amount := float64(deposit.Amount) / 1000                           //
 			formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64) //
 
-			err = model.SendFCMNotification(deviceToken, "Deposit Berhasil", "Anda telah melakukan deposit sebesar "+formattedAmount)
+			err = model.SendFCMNotification(user.Token, "Deposit Berhasil", "Anda telah melakukan deposit sebesar "+formattedAmount)
 
 			if err != nil {
 				logrus.Errorf("failed to send FCM notification: %v", err)
@@ -96,6 +197,25 @@ func (c *TransactionController) HandlePaymentNotification(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Notification received"})
 }
 
+// handlePaymentLinkNotification settles or fails a guest payment made through
+// a payment link. Other statuses such as pending are ignored.
+func (c *TransactionController) handlePaymentLinkNotification(notification response.PaymentNotification) {
+	switch notification.TransactionStatus {
+	case "settlement":
+		payment, err := c.linkUsecase.SettleGatewayPayment(notification.OrderID, notification.GrossAmount)
+		if err != nil {
+			logrus.Errorf("Failed to settle payment link payment: %v", err)
+			return
+		}
+		notifyPaymentLinkOwner(c.userUsecase, c.webhookUsecase, payment)
+		logrus.Infof("Payment link payment settled for order: %s", notification.OrderID)
+	case "deny", "cancel", "expire", "failure":
+		if err := c.linkUsecase.FailGatewayPayment(notification.OrderID); err != nil {
+			logrus.Errorf("Failed to close payment link payment: %v", err)
+		}
+	}
+}
+
 func (c *TransactionController) CreateDepositBank(ctx *gin.Context) {
 	// Logging
 	logger, err := utils.CreateLogFile()
@@ -136,8 +256,6 @@ func (c *TransactionController) CreateDepositBank(ctx *gin.Context) {
 		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Incorrect request body")
 		return
 	}
-	depoAmount = reqBody.Amount
-	userIDdepo = userID
 	reqBody.UserID = userID
 	reqBody.BankName = bankAcc.BankName
 	reqBody.AccountHolderName = bankAcc.AccountHolderName
@@ -148,23 +266,36 @@ func (c *TransactionController) CreateDepositBank(ctx *gin.Context) {
 		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Minimum deposit 10.000")
 		return
 	}
+	var redemption *model.PromoRedemption
+	if reqBody.PromoCode != "" {
+		var ok bool
+		if redemption, ok = reservePromo(ctx, c.promoUsecase, user, reqBody.PromoCode, "Deposit", reqBody.Amount, 0); !ok {
+			return
+		}
+	}
 	token, err := model.CreateMidtransTransactionFromDeposit(&reqBody, user)
 	if err != nil {
+		releasePromo(c.promoUsecase, redemption)
 		logrus.Errorf("Failed to create Midtrans transaction: %v", err)
 		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to create Midtrans transaction")
 		return
 	}
 
 	reqBody.Token = token
-	depositToken = token
-	deviceToken = user.Token
 
 	// Create the deposit transaction
 	if err := c.txUsecase.CreateDepositBank(&reqBody); err != nil {
+		releasePromo(c.promoUsecase, redemption)
 		logrus.Errorf("Failed to create Deposit Transaction: %v", err)
 		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to create Deposit Transaction")
 		return
 	}
+	// The cashback is paid once the deposit settles
+	if redemption != nil {
+		if err := c.promoUsecase.Link(redemption, reqBody.TransactionID); err != nil {
+			logrus.Errorf("Failed to link promo redemption: %v", err)
+		}
+	}
 
 	amount := float64(reqBody.Amount) / 1000                           //
 	formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64) //
@@ -268,6 +399,56 @@ func (c *TransactionController) CreateWithdrawal(ctx *gin.Context) {
 	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, "Withdrawal Transaction created Succesfully")
 }
 
+func (c *TransactionController) InquiryTransfer(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var reqBody model.Transfer
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Failed to parse transfer data: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Failed to parse transfer data: invalid JSON format")
+		return
+	}
+
+	userID := ctx.Param("user_id")
+
+	sender, err := c.userUsecase.FindById(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get Sender User: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Failed to get Sender User")
+		return
+	}
+
+	// Recipient may be a phone number, username, email, @handle or QR payload
+	to := reqBody.Recipient
+	if to == "" {
+		to = reqBody.RecipientPhoneNumber
+	}
+
+	inquiry, err := c.inquiryUsecase.Inquiry(sender, to, reqBody.Amount)
+	if err != nil {
+		logrus.Errorf("Failed to create transfer inquiry: %v", err)
+		switch err.Error() {
+		case "recipient not found":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, err.Error())
+		case "cannot transfer to yourself":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusForbidden, err.Error())
+		case "minimum transfer amount is 10,000", "insufficient balance":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, err.Error())
+		default:
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to create transfer inquiry")
+		}
+		return
+	}
+
+	logrus.Info("Transfer inquiry created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, inquiry)
+}
+
 func (c *TransactionController) CreateTransferTransaction(ctx *gin.Context) {
 	logger, err := utils.CreateLogFile()
 	if err != nil {
@@ -283,6 +464,16 @@ func (c *TransactionController) CreateTransferTransaction(ctx *gin.Context) {
 		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Failed to parse transfer data: invalid JSON format")
 		return
 	}
+	if newTransfer.InquiryToken == "" {
+		logrus.Errorf("Inquiry token is required")
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "inquiry_token is required")
+		return
+	}
+	if err := c.txUsecase.ValidateTransfer(&newTransfer); err != nil {
+		logrus.Errorf("Invalid transfer data: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	userID := ctx.Param("user_id")
 
@@ -294,103 +485,195 @@ func (c *TransactionController) CreateTransferTransaction(ctx *gin.Context) {
 		return
 	}
 
-	recipient, err := c.userUsecase.FindByPhone(newTransfer.RecipientPhoneNumber)
+	// The inquiry fixes recipient, amount and fee that were shown to the sender.
+	// It is only claimed once the transfer passed its checks.
+	inquiry, err := c.inquiryUsecase.Find(sender.ID, newTransfer.InquiryToken)
+	if err != nil {
+		logrus.Errorf("Invalid transfer inquiry: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recipient, err := c.userUsecase.FindByiDToken(inquiry.RecipientID)
 	if err != nil {
 		logrus.Errorf("Failed to get Recipient User: %v", err)
 		response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Failed to get Recipient User")
 		return
 	}
 
-	if sender.Balance < newTransfer.Amount {
-		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Insufficient balance")
+	fee := inquiry.Fee
+	if newTransfer.PromoCode != "" {
+		redemption, ok := reservePromo(ctx, c.promoUsecase, sender, newTransfer.PromoCode, "Transfer", inquiry.Amount, inquiry.Fee)
+		if !ok {
+			return
+		}
+		if redemption.BenefitType == "Fee Waiver" {
+			fee -= redemption.Benefit
+		}
+		newTransfer.Promo = redemption
+	}
+
+	available, err := c.txUsecase.AvailableBalance(sender)
+	if err != nil {
+		releasePromo(c.promoUsecase, newTransfer.Promo)
+		logrus.Errorf("Failed to get available balance: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to create Transfer Transaction")
 		return
 	}
-	if sender.Phone_Number == recipient.Phone_Number {
-		response.JSONErrorResponse(ctx.Writer, false, http.StatusForbidden, "Input the recipient correctly")
+	if available < inquiry.Amount+fee {
+		releasePromo(c.promoUsecase, newTransfer.Promo)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Insufficient balance")
 		return
 	}
-
-	if newTransfer.Amount < 10000 {
-		logrus.Errorf("Minimum transfer amount is 10,000")
-		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Minimum transfer amount is 10,000")
+	if _, err := c.inquiryUsecase.Confirm(sender.ID, newTransfer.InquiryToken); err != nil {
+		releasePromo(c.promoUsecase, newTransfer.Promo)
+		logrus.Errorf("Invalid transfer inquiry: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, err.Error())
 		return
 	}
+
+	newTransfer.Amount = inquiry.Amount
+	newTransfer.Fee = fee
 	newTransfer.SenderName = sender.Name
 	newTransfer.RecipientName = recipient.Name
 	newTransfer.SenderPhoneNumber = sender.Phone_Number
 	newTransfer.RecipientPhoneNumber = recipient.Phone_Number
 	// Create transfer transaction in use case layer
-	err = c.txUsecase.CreateTransfer(sender, recipient, newTransfer.Amount)
+	err = c.txUsecase.CreateTransfer(sender, recipient, &newTransfer)
 	logrus.Info("Processing transfer transaction...")
 	logrus.Infof("Sender: %s, Recipient: %s, Amount: %d", sender.Name, recipient.Name, newTransfer.Amount)
 
 	if err != nil {
+		releasePromo(c.promoUsecase, newTransfer.Promo)
 		logrus.Errorf("Failed to create Transfer Transaction: %v", err)
 		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to create Transfer Transaction")
 		return
 	}
-	err = c.txUsecase.AssignBadge(sender)
+	applyPromo(c.promoUsecase, newTransfer.Promo, newTransfer.TransactionID)
+	c.hooks.afterTransfer(sender, recipient, &newTransfer)
+
+	amount := float64(newTransfer.Amount) / 1000
+	formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+	note := ""
+	if newTransfer.Note != "" {
+		note = " - \"" + newTransfer.Note + "\""
+	}
+
+	err = model.SendFCMNotification(sender.Token, "Transfer Berhasil", "Anda telah mengirim uang ke "+recipient.Name+" sebesar "+formattedAmount+note)
+
 	if err != nil {
-		logrus.Errorf("Failed to assign badge: %v", err)
-		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to asign badge")
+		logrus.Errorf("failed to send FCM notification: %v", err)
+
+		return
 	}
-	if sender.BadgeID == 2 {
-		err = model.SendFCMNotification(sender.Token, "Selamat", "Anda telah naik level menjadi Silver ")
 
-		if err != nil {
-			logrus.Errorf("failed to send FCM notification: %v", err)
+	logrus.Info("Transfer Transaction created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, newTransfer)
+}
 
-			return
-		}
+func (c *TransactionController) UploadTransferAttachment(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
 	}
-	if sender.BadgeID == 3 {
-		err = model.SendFCMNotification(sender.Token, "Selamat", "Anda telah naik level menjadi Gold ")
 
-		if err != nil {
-			logrus.Errorf("failed to send FCM notification: %v", err)
+	logrus.SetOutput(logger)
 
-			return
-		}
+	userID := ctx.Param("user_id")
+	txID, err := strconv.Atoi(ctx.Param("tx_id"))
+	if err != nil {
+		logrus.Errorf("Invalid tx_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid tx_id")
+		return
 	}
-	if sender.BadgeID == 4 {
-		err = model.SendFCMNotification(sender.Token, "Selamat", "Anda telah naik level menjadi Platinum ")
 
-		if err != nil {
-			logrus.Errorf("failed to send FCM notification: %v", err)
+	file, err := ctx.FormFile("attachment")
+	if err != nil {
+		logrus.Errorf("Failed to get file from request: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Failed to get file from request")
+		return
+	}
 
+	// Validasi ekstensi file
+	ext := filepath.Ext(file.Filename)
+	if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+		logrus.Errorf("Extension file is not image file")
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Extension file is not image file")
+		return
+	}
+
+	if err := c.txUsecase.CheckTransferSender(txID, userID); err != nil {
+		logrus.Errorf("Failed to attach file: %v", err)
+		if err.Error() == "transfer not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Transfer not found")
 			return
 		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to attach file")
+		return
 	}
-	if sender.BadgeID == 5 {
-		err = model.SendFCMNotification(sender.Token, "Selamat", "Anda telah naik level menjadi Diamond ")
 
-		if err != nil {
-			logrus.Errorf("failed to send FCM notification: %v", err)
+	// Every upload gets its own file so a receipt is never overwritten
+	path := fmt.Sprintf(utils.DotEnv("FILE_LOCATION"), fmt.Sprintf("tx-%d-%s%s", txID, uuid.New().String(), ext))
+	if err := ctx.SaveUploadedFile(file, path); err != nil {
+		logrus.Errorf("Failed to write file: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to write file")
+		return
+	}
 
+	if err := c.txUsecase.AttachTransferFile(txID, userID, path); err != nil {
+		_ = os.Remove(path)
+		logrus.Errorf("Failed to attach file: %v", err)
+		if err.Error() == "transfer not found" {
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Transfer not found")
 			return
 		}
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to attach file")
+		return
 	}
 
-	amount := float64(newTransfer.Amount) / 1000
-	formattedAmount := "Rp " + strconv.FormatFloat(amount, 'f', 3, 64)
+	logrus.Info("Transfer attachment uploaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, "Transfer attachment uploaded Successfully")
+}
 
-	err = model.SendFCMNotification(sender.Token, "Transfer Berhasil", "Anda telah mengirim uang ke "+recipient.Name+" sebesar "+formattedAmount)
+func (c *TransactionController) SetTransactionCategory(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
 
+	txID, err := strconv.Atoi(ctx.Param("tx_id"))
 	if err != nil {
-		logrus.Errorf("failed to send FCM notification: %v", err)
+		logrus.Errorf("Invalid tx_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid tx_id")
+		return
+	}
 
+	var reqBody model.TransactionCategory
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Incorrect request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Incorrect request body")
 		return
 	}
-	err = model.SendFCMNotification(recipient.Token, "Receive Berhasil", "Anda telah menerima uang dari "+sender.Name+" sebesar "+formattedAmount)
-	logrus.Info(recipient.Token)
-	if err != nil {
-		logrus.Errorf("failed to send FCM notification: %v", err)
+	reqBody.TransactionID = txID
+	reqBody.UserID = ctx.Param("user_id")
 
+	if err := c.txUsecase.SetCategory(&reqBody); err != nil {
+		logrus.Errorf("Failed to set category: %v", err)
+		switch err.Error() {
+		case "transaction not found":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusNotFound, "Transaction not found")
+		case "category must be 1 - 30 characters":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, err.Error())
+		default:
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to set category")
+		}
 		return
 	}
 
-	logrus.Info("Transfer Transaction created Successfully")
-	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, "Transfer Successfully")
+	logrus.Info("Transaction category set Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, reqBody)
 }
 
 func (c *TransactionController) CreateRedeemTransaction(ctx *gin.Context) {
@@ -455,12 +738,25 @@ func (c *TransactionController) CreateRedeemTransaction(ctx *gin.Context) {
 	err = c.txUsecase.CreateRedeem(&txData)
 	if err != nil {
 		logrus.Errorf("Failed to create redeem transaction: %v", err)
+		switch err.Error() {
+		case "reward is not available", "reward out of stock", "your point is not enough to redeem":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to create redeem transaction")
 		return
 	}
 
+	voucher, err := c.rewardUsecase.Fulfill(&txData)
+	if err != nil {
+		logrus.Errorf("Failed to fulfill reward: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadGateway, "Reward could not be delivered, your points have been returned")
+		return
+	}
+	txData.Voucher = voucher
+
 	logrus.Info("Redeem transaction created successfully")
-	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, "Redeem transaction created successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, txData)
 }
 
 func (c *TransactionController) GetTxBySenderId(ctx *gin.Context) {
@@ -497,11 +793,22 @@ func (c *TransactionController) GetTxBySenderId(ctx *gin.Context) {
 	response.JSONSuccess(ctx.Writer, true, http.StatusOK, txs)
 }
 
-func NewTransactionController(usecase usecase.TransactionUseCase, uc usecase.UserUseCase, bk usecase.BankAccUsecase) *TransactionController {
+func NewTransactionController(usecase usecase.TransactionUseCase, uc usecase.UserUseCase, bk usecase.BankAccUsecase, inq usecase.TransferInquiryUseCase, pk usecase.PocketUsecase, wh usecase.WebhookUsecase, pl usecase.PaymentLinkUsecase, pt usecase.PointUsecase, rw usecase.RewardUsecase, bd usecase.BadgeUsecase, rf usecase.ReferralUsecase, ms usecase.MissionUsecase, pr usecase.PromoUsecase) *TransactionController {
 	controller := TransactionController{
-		txUsecase:   usecase,
-		userUsecase: uc,
-		bankUsecase: bk,
+		txUsecase:       usecase,
+		userUsecase:     uc,
+		bankUsecase:     bk,
+		inquiryUsecase:  inq,
+		pocketUsecase:   pk,
+		webhookUsecase:  wh,
+		linkUsecase:     pl,
+		pointUsecase:    pt,
+		rewardUsecase:   rw,
+		badgeUsecase:    bd,
+		referralUsecase: rf,
+		missionUsecase:  ms,
+		promoUsecase:    pr,
+		hooks:           newTransferHooks(pk, pt, rf, ms, bd),
 	}
 	return &controller
 }
@@ -20,7 +20,8 @@ type UserController struct {
 	usecase     usecase.UserUseCase
 	bankusecase usecase.BankAccUsecase
 
-	photousecase usecase.PhotoUsecase
+	photousecase    usecase.PhotoUsecase
+	referralUsecase usecase.ReferralUsecase
 }
 
 func (c *UserController) FindUsers(ctx *gin.Context) {
@@ -119,6 +120,14 @@ func (c *UserController) Register(ctx *gin.Context) {
 		return
 	}
 
+	if newUser.ReferralCode != "" {
+		if _, err := c.referralUsecase.CheckCode(newUser.ReferralCode, newUser.Phone_Number); err != nil {
+			logrus.Errorf("Invalid referral code: %v", err)
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid referral code")
+			return
+		}
+	}
+
 	res, err := c.usecase.Register(&newUser)
 	if err != nil {
 		logrus.Errorf("Failed to Register User : %v", err)
@@ -126,6 +135,15 @@ func (c *UserController) Register(ctx *gin.Context) {
 		return
 	}
 
+	if newUser.ReferralCode != "" {
+		referee, err := c.usecase.FindByPhone(newUser.Phone_Number)
+		if err != nil {
+			logrus.Errorf("Failed to get registered user: %v", err)
+		} else if _, err := c.referralUsecase.Enroll(referee, newUser.ReferralCode); err != nil {
+			logrus.Errorf("Failed to enroll referral: %v", err)
+		}
+	}
+
 	logrus.Info("Success Register User")
 	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, res)
 }
@@ -259,12 +277,47 @@ func (c *UserController) Unreg(ctx *gin.Context) {
 	response.JSONSuccess(ctx.Writer, true, http.StatusOK, res)
 }
 
-func NewUserController(usercase usecase.UserUseCase, bank usecase.BankAccUsecase, photo usecase.PhotoUsecase) *UserController {
+func (c *UserController) ClaimPaymentHandle(ctx *gin.Context) {
+	// Logging
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Failed to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+	userID := ctx.Param("user_id")
+
+	var reqBody model.User
+	if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+		logrus.Errorf("Invalid input : %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := c.usecase.ClaimPaymentHandle(userID, reqBody.PaymentHandle); err != nil {
+		logrus.Errorf("Failed to claim payment handle : %v", err)
+		switch err.Error() {
+		case "handle already taken":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusConflict, err.Error())
+		case "handle must be 3 - 20 characters of letters, numbers, dot or underscore":
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, err.Error())
+		default:
+			response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to claim payment handle")
+		}
+		return
+	}
+
+	logrus.Info("Payment handle claimed Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "payment handle claimed successfully")
+}
+
+func NewUserController(usercase usecase.UserUseCase, bank usecase.BankAccUsecase, photo usecase.PhotoUsecase, referral usecase.ReferralUsecase) *UserController {
 	controller := UserController{
 		usecase:     usercase,
 		bankusecase: bank,
 
-		photousecase: photo,
+		photousecase:    photo,
+		referralUsecase: referral,
 	}
 	return &controller
 }
@@ -153,6 +153,11 @@ func (r *UserUseCaseMock) UpdatePoint(userID string, newPoint int) error {
 	return nil
 }
 
+func (r *UserUseCaseMock) ClaimPaymentHandle(userID string, handle string) error {
+	args := r.Called(userID, handle)
+	return args.Error(0)
+}
+
 type UserControllerTestSuite struct {
 	suite.Suite
 	routerMock  *gin.Engine
@@ -164,7 +169,7 @@ type UserControllerTestSuite struct {
 
 func (suite *UserControllerTestSuite) TestFindUsers_Success() {
 	Users := &dummyUser
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.GET("/user", controller.FindUsers)
 
@@ -176,7 +181,7 @@ func (suite *UserControllerTestSuite) TestFindUsers_Success() {
 	suite.useCaseMock.AssertExpectations(suite.T())
 }
 func (suite *UserControllerTestSuite) TestFindUsers_Failed() {
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.GET("/user", controller.FindUsers)
 
@@ -190,7 +195,7 @@ func (suite *UserControllerTestSuite) TestFindUsers_Failed() {
 
 func (suite *UserControllerTestSuite) TestFindByUsername_Success() {
 	Users := &dummyUser[0]
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.GET("/user/:username", controller.FindUserByUsername)
 
@@ -211,7 +216,7 @@ func (suite *UserControllerTestSuite) TestRegister_Success() {
 		Phone_Number: "081234567890",
 		Address:      "Jakarta",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.POST("/register", controller.Register)
 
@@ -234,7 +239,7 @@ func (suite *UserControllerTestSuite) TestRegister_Success() {
 }
 func (suite *UserControllerTestSuite) TestRegister_Failed() {
 	newUser := &model.User{}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.POST("/register", controller.Register)
 
@@ -248,7 +253,7 @@ func (suite *UserControllerTestSuite) TestRegister_Failed() {
 	assert.Equal(suite.T(), http.StatusBadRequest, r.Code)
 }
 func (suite *UserControllerTestSuite) TestRegisterBindJSON_Failed() {
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.POST("/register", controller.Register)
 
@@ -269,7 +274,7 @@ func (suite *UserControllerTestSuite) TestRegister_EmailInvalid() {
 		Phone_Number: "081234567890",
 		Address:      "Dummy Address",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.POST("/register", controller.Register)
 	r := httptest.NewRecorder()
@@ -290,7 +295,7 @@ func (suite *UserControllerTestSuite) TestRegister_InvalidPassword() {
 		Phone_Number: "081234567890",
 		Address:      "Jalan Dummy No. 123",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.POST("/register", controller.Register)
 
@@ -312,7 +317,7 @@ func (suite *UserControllerTestSuite) TestRegister_InvalidPasswordUppercase() {
 		Phone_Number: "081234567890",
 		Address:      "Jalan Dummy No. 123",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.POST("/register", controller.Register)
 
@@ -334,7 +339,7 @@ func (suite *UserControllerTestSuite) TestRegister_InvalidPhoneNumber() {
 		Phone_Number: "0812345",
 		Address:      "Jalan Dummy No. 123",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.POST("/register", controller.Register)
 
@@ -352,7 +357,7 @@ func (suite *UserControllerTestSuite) TestUnreg_Success() {
 	user := &model.User{
 		ID: "uint(1)",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.DELETE("/user/:user_id", controller.Unreg)
 	suite.bankMock.On("UnregAll", uint(1)).Return("s")
@@ -367,7 +372,7 @@ func (suite *UserControllerTestSuite) TestUnreg_Success() {
 }
 func (suite *UserControllerTestSuite) TestFindByUsername_Failed() {
 	Users := &dummyUser[0]
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.GET("/user/:username", controller.FindUserByUsername)
 
@@ -391,7 +396,7 @@ func (suite *UserControllerTestSuite) TestEditProfile_Success() {
 	mockUseCase := &UserUseCaseMock{}
 	mockUseCase.On("FindById", uint(1)).Return(&model.User{}, nil)
 	mockUseCase.On("EditProfile", updatedUser).Return(updatedUser)
-	controller := NewUserController(mockUseCase, suite.bankMock, suite.photoMock)
+	controller := NewUserController(mockUseCase, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/profile/:user_id", controller.EditProfile)
 
@@ -404,7 +409,7 @@ func (suite *UserControllerTestSuite) TestEditProfile_Success() {
 }
 func (suite *UserControllerTestSuite) TestEditProfile_UserNotFound() {
 	user := &dummyUser[0]
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/profile/:user_id", controller.EditProfile)
 
@@ -421,7 +426,7 @@ func (suite *UserControllerTestSuite) TestEditProfile_UserNotFound() {
 }
 func (suite *UserControllerTestSuite) TestEditProfile_InvalidUserID() {
 	user := &dummyUser[0]
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/profile/:user_id", controller.EditProfile)
 
@@ -442,7 +447,7 @@ func (suite *UserControllerTestSuite) TestEditProfile_InvalidPhoneNumber() {
 	}
 	mockUseCase := &UserUseCaseMock{}
 	mockUseCase.On("FindById", uint(1)).Return(&model.User{}, nil)
-	controller := NewUserController(mockUseCase, suite.bankMock, suite.photoMock)
+	controller := NewUserController(mockUseCase, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/profile/:user_id", controller.EditProfile)
 
@@ -458,7 +463,7 @@ func (suite *UserControllerTestSuite) TestEditProfile_InvalidPhoneNumber() {
 
 func (suite *UserControllerTestSuite) TestEditProfile_InvalidInput() {
 	user := &dummyUser[0]
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/profile/:user_id", controller.EditProfile)
 
@@ -487,7 +492,7 @@ func (suite *UserControllerTestSuite) TestEditEmailPassword_Success() {
 		Email:    "john@gmail.com",
 		Password: "Password123",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/pass/:user_id", controller.EditEmailPassword)
 
@@ -506,7 +511,7 @@ func (suite *UserControllerTestSuite) TestEditEmailPassword_Success() {
 }
 func (suite *UserControllerTestSuite) TestEditEmailPassword_UserNotFound() {
 	user := &dummyUser[0]
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/pass/:user_id", controller.EditEmailPassword)
 
@@ -523,7 +528,7 @@ func (suite *UserControllerTestSuite) TestEditEmailPassword_UserNotFound() {
 }
 func (suite *UserControllerTestSuite) TestEditEmailPassword_InvalidUserID() {
 	user := &dummyUser[0]
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/pass/:user_id", controller.EditEmailPassword)
 
@@ -539,7 +544,7 @@ func (suite *UserControllerTestSuite) TestEditEmailPassword_InvalidUserID() {
 }
 func (suite *UserControllerTestSuite) TestEditEmailPassword_InvalidInput() {
 	user := &dummyUser[0]
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/pass/:user_id", controller.EditEmailPassword)
 
@@ -561,7 +566,7 @@ func (suite *UserControllerTestSuite) TestEditEmailPassword_InvalidEmail() {
 		Email:    "johndoe@yahoo.com",
 		Password: "password123",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/pass/:user_id", controller.EditEmailPassword)
 
@@ -591,7 +596,7 @@ func (suite *UserControllerTestSuite) TestEditEmailPassword_InvalidPassword() {
 		Email:    "johndoe@gmail.com",
 		Password: "Pas1",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/pass/:user_id", controller.EditEmailPassword)
 
@@ -621,7 +626,7 @@ func (suite *UserControllerTestSuite) TestEditEmailPassword_InvalidPasswordForma
 		Email:    "johndoe@gmail.com",
 		Password: "password",
 	}
-	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock)
+	controller := NewUserController(suite.useCaseMock, suite.bankMock, suite.photoMock, nil)
 	router := setupRouter()
 	router.PUT("/user/pass/:user_id", controller.EditEmailPassword)
 
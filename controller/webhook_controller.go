@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/model/response"
+	"github.com/ReygaFitra/inc-final-project.git/usecase"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookController struct {
+	webhookUsecase usecase.WebhookUsecase
+}
+
+func webhookErrorStatus(err error) int {
+	switch err.Error() {
+	case "webhook subscription not found", "webhook delivery not found":
+		return http.StatusNotFound
+	case "webhook delivery is already pending":
+		return http.StatusConflict
+	case "maximum 10 webhook subscriptions per user":
+		return http.StatusUnprocessableEntity
+	case "url must be a valid http or https address", "url must not point to a private or local address", "at least one event type is required":
+		return http.StatusBadRequest
+	}
+	if strings.HasPrefix(err.Error(), "unknown event type") {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *WebhookController) Subscribe(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	var subscription model.WebhookSubscription
+	if err := ctx.ShouldBindJSON(&subscription); err != nil {
+		logrus.Errorf("Invalid request body: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	subscription.UserID = ctx.Param("user_id")
+
+	if err := c.webhookUsecase.Subscribe(&subscription); err != nil {
+		logrus.Errorf("Failed to create webhook subscription: %v", err)
+		status := webhookErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to create webhook subscription"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Webhook subscription created Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusCreated, subscription)
+}
+
+func (c *WebhookController) FindSubscriptions(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	subscriptions, err := c.webhookUsecase.FindSubscriptions(ctx.Param("user_id"))
+	if err != nil {
+		logrus.Errorf("Failed to get webhook subscriptions: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusInternalServerError, "Failed to get webhook subscriptions")
+		return
+	}
+
+	logrus.Info("Webhook subscriptions loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, subscriptions)
+}
+
+func (c *WebhookController) Unsubscribe(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	subscriptionID, err := strconv.Atoi(ctx.Param("subscription_id"))
+	if err != nil {
+		logrus.Errorf("Invalid subscription_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid subscription_id")
+		return
+	}
+
+	if err := c.webhookUsecase.Unsubscribe(ctx.Param("user_id"), subscriptionID); err != nil {
+		logrus.Errorf("Failed to delete webhook subscription: %v", err)
+		status := webhookErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to delete webhook subscription"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Webhook subscription deleted Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, "Webhook subscription deleted successfully")
+}
+
+func (c *WebhookController) FindDeliveries(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	subscriptionID, err := strconv.Atoi(ctx.Param("subscription_id"))
+	if err != nil {
+		logrus.Errorf("Invalid subscription_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid subscription_id")
+		return
+	}
+
+	deliveries, err := c.webhookUsecase.FindDeliveries(ctx.Param("user_id"), subscriptionID)
+	if err != nil {
+		logrus.Errorf("Failed to get webhook deliveries: %v", err)
+		status := webhookErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to get webhook deliveries"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Webhook deliveries loaded Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, deliveries)
+}
+
+func (c *WebhookController) Redeliver(ctx *gin.Context) {
+	logger, err := utils.CreateLogFile()
+	if err != nil {
+		log.Fatalf("Fatal to create log file: %v", err)
+	}
+
+	logrus.SetOutput(logger)
+
+	deliveryID, err := strconv.Atoi(ctx.Param("delivery_id"))
+	if err != nil {
+		logrus.Errorf("Invalid delivery_id: %v", err)
+		response.JSONErrorResponse(ctx.Writer, false, http.StatusBadRequest, "Invalid delivery_id")
+		return
+	}
+
+	delivery, err := c.webhookUsecase.Redeliver(ctx.Param("user_id"), deliveryID)
+	if err != nil {
+		logrus.Errorf("Failed to redeliver webhook: %v", err)
+		status := webhookErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to redeliver webhook"
+		}
+		response.JSONErrorResponse(ctx.Writer, false, status, message)
+		return
+	}
+
+	logrus.Info("Webhook redelivery queued Successfully")
+	response.JSONSuccess(ctx.Writer, true, http.StatusOK, delivery)
+}
+
+func NewWebhookController(u usecase.WebhookUsecase) *WebhookController {
+	controller := WebhookController{
+		webhookUsecase: u,
+	}
+	return &controller
+}
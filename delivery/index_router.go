@@ -2,9 +2,12 @@ package delivery
 
 import (
 	"log"
+	"strconv"
+	"time"
 
 	"github.com/ReygaFitra/inc-final-project.git/config"
 	"github.com/ReygaFitra/inc-final-project.git/controller"
+	"github.com/ReygaFitra/inc-final-project.git/model"
 	"github.com/ReygaFitra/inc-final-project.git/repository"
 	"github.com/ReygaFitra/inc-final-project.git/usecase"
 
@@ -19,7 +22,7 @@ func RunServer() {
 
 	authMiddlewareUsername := controller.AuthMiddleware()
 
-	// authMiddlewareRole := controller.AuthMiddlewareRole()
+	authMiddlewareRole := controller.AuthMiddlewareRole()
 
 	r := gin.Default()
 
@@ -30,6 +33,7 @@ func RunServer() {
 	// USER DEPEDENCY
 	userRepo := repository.NewUserRepository(db)
 	userUsecase := usecase.NewUserUseCase(userRepo)
+	holdRepo := repository.NewHoldRepository(db)
 	userAuth := controller.NewUserAuth(userUsecase)
 	bankAccRepo := repository.NewBankAccRepository(db)
 	bankAccusecase := usecase.NewBankAccUsecase(bankAccRepo)
@@ -38,7 +42,28 @@ func RunServer() {
 	photoRepo := repository.NewPhotoRepository(db)
 	photoUsecase := usecase.NewPhotoUseCase(photoRepo)
 	photoController := controller.NewPhotoController(photoUsecase)
-	userController := controller.NewUserController(userUsecase, bankAccusecase, photoUsecase)
+	// Point and Referral Depedency
+	var pointConfig usecase.PointConfig
+	pointConfig.ExpiryMonths, _ = strconv.Atoi(utils.DotEnv("POINT_EXPIRY_MONTHS"))
+	pointConfig.ConversionRate, _ = strconv.Atoi(utils.DotEnv("POINT_CONVERSION_RATE"))
+	pointConfig.ConversionDailyCap, _ = strconv.Atoi(utils.DotEnv("POINT_CONVERSION_DAILY_CAP"))
+	pointConfig.TransferDailyCap, _ = strconv.Atoi(utils.DotEnv("POINT_TRANSFER_DAILY_CAP"))
+	pointRuleRepo := repository.NewPointRuleRepository(db)
+	pointLedgerRepo := repository.NewPointLedgerRepository(db)
+	pointUsecase := usecase.NewPointUsecase(pointRuleRepo, pointLedgerRepo, userRepo, pointConfig)
+	if err := pointUsecase.SeedDefaultRules(); err != nil {
+		log.Printf("Failed to seed default point rules: %v", err)
+	}
+	var referralConfig usecase.ReferralConfig
+	referralConfig.RewardType = utils.DotEnv("REFERRAL_REWARD_TYPE")
+	referralConfig.ReferrerReward, _ = strconv.Atoi(utils.DotEnv("REFERRER_REWARD"))
+	referralConfig.RefereeReward, _ = strconv.Atoi(utils.DotEnv("REFEREE_REWARD"))
+	referralConfig.MinAmount, _ = strconv.Atoi(utils.DotEnv("REFERRAL_MIN_AMOUNT"))
+	referralRepo := repository.NewReferralRepository(db)
+	referralUsecase := usecase.NewReferralUsecase(referralRepo, userRepo, pointUsecase, referralConfig)
+	referralController := controller.NewReferralController(referralUsecase, userUsecase)
+
+	userController := controller.NewUserController(userUsecase, bankAccusecase, photoUsecase, referralUsecase)
 	authMiddlewareIdExist := userController.AuthMiddlewareIDExist()
 
 	// USER GROUP
@@ -52,6 +77,8 @@ func RunServer() {
 	r.PUT("user/pass/:user_id", authMiddlewareIdExist, userController.EditEmailPassword)
 	r.PUT("user/profile/:user_id", authMiddlewareIdExist, userController.EditProfile)
 	r.DELETE("user/:user_id", authMiddlewareIdExist, userController.Unreg)
+	r.PUT("user/handle/:user_id", authMiddlewareIdExist, userController.ClaimPaymentHandle)
+	r.GET("user/referral/:user_id", authMiddlewareIdExist, referralController.FindDashboard)
 
 	// Bank Accont Router
 	bankAccRouter := r.Group("/user/bank")
@@ -76,21 +103,367 @@ func RunServer() {
 	photoRouter.PUT("/:user_id", photoController.Edit)
 	photoRouter.DELETE("/:user_id", photoController.Remove)
 
+	// Contact Router
+	contactRouter := r.Group("/user/contact")
+	contactRouter.Use(authMiddlewareIdExist)
+
+	// Contact Depedency
+	contactRepo := repository.NewContactRepository(db)
+	contactUsecase := usecase.NewContactUsecase(contactRepo, userRepo)
+	contactController := controller.NewContactController(contactUsecase)
+
+	contactRouter.GET("/:user_id", contactController.FindContacts)
+	contactRouter.GET("/recent/:user_id", contactController.FindRecentRecipients)
+	contactRouter.POST("/:user_id", contactController.AddContact)
+	contactRouter.PUT("/:user_id/:contact_id", contactController.RenameContact)
+	contactRouter.DELETE("/:user_id/:contact_id", contactController.RemoveContact)
+
+	// Pocket Router
+	pocketRouter := r.Group("/user/pocket")
+	pocketRouter.Use(authMiddlewareIdExist)
+
+	// Pocket Depedency
+	pocketRepo := repository.NewPocketRepository(db)
+	pocketUsecase := usecase.NewPocketUsecase(pocketRepo, userRepo, holdRepo)
+	pocketController := controller.NewPocketController(pocketUsecase, userUsecase)
+
+	pocketRouter.GET("/:user_id", pocketController.FindPockets)
+	pocketRouter.POST("/:user_id", pocketController.CreatePocket)
+	pocketRouter.PUT("/:user_id/:pocket_id", pocketController.UpdatePocket)
+	pocketRouter.DELETE("/:user_id/:pocket_id", pocketController.DeletePocket)
+	pocketRouter.POST("/in/:user_id/:pocket_id", pocketController.MoveIn)
+	pocketRouter.POST("/out/:user_id/:pocket_id", pocketController.MoveOut)
+
+	// Group Wallet Router
+	groupRouter := r.Group("/user/group")
+	groupRouter.Use(authMiddlewareIdExist)
+
+	// Group Wallet Depedency
+	groupRepo := repository.NewGroupWalletRepository(db)
+	groupUsecase := usecase.NewGroupWalletUsecase(groupRepo, userRepo, holdRepo)
+	groupController := controller.NewGroupWalletController(groupUsecase, userUsecase)
+
+	groupRouter.GET("/:user_id", groupController.FindGroups)
+	groupRouter.POST("/:user_id", groupController.CreateGroup)
+	groupRouter.GET("/:user_id/:group_id", groupController.FindGroup)
+	groupRouter.POST("/member/:user_id/:group_id", groupController.AddMember)
+	groupRouter.DELETE("/member/:user_id/:group_id/:member_id", groupController.RemoveMember)
+	groupRouter.PUT("/schedule/:user_id/:group_id", groupController.UpdateSchedule)
+	groupRouter.POST("/contribute/:user_id/:group_id", groupController.Contribute)
+	groupRouter.POST("/payout/:user_id/:group_id", groupController.Payout)
+	groupRouter.GET("/activity/:user_id/:group_id", groupController.FindActivities)
+
+	// Red Envelope Router
+	envelopeRouter := r.Group("/user/envelope")
+	envelopeRouter.Use(authMiddlewareIdExist)
+
+	// Red Envelope Depedency
+	envelopeRepo := repository.NewRedEnvelopeRepository(db)
+	envelopeUsecase := usecase.NewRedEnvelopeUsecase(envelopeRepo, userRepo, holdRepo)
+	envelopeController := controller.NewRedEnvelopeController(envelopeUsecase, userUsecase)
+
+	envelopeRouter.GET("/:user_id", envelopeController.FindByCreator)
+	envelopeRouter.POST("/:user_id", envelopeController.Create)
+	envelopeRouter.GET("/code/:user_id/:code", envelopeController.FindByCode)
+	envelopeRouter.POST("/claim/:user_id/:code", envelopeController.Claim)
+
+	runEvery(10*time.Minute, "refund expired red envelopes", func() error {
+		_, err := envelopeUsecase.RefundExpired()
+		return err
+	})
+
+	// Hold Router
+	holdRouter := r.Group("/user/hold")
+	holdRouter.Use(authMiddlewareIdExist)
+
+	// Hold Depedency
+	holdUsecase := usecase.NewHoldUsecase(holdRepo, userRepo)
+	holdController := controller.NewHoldController(holdUsecase, userUsecase)
+
+	holdRouter.GET("/balance/:user_id", holdController.FindBalance)
+	holdRouter.GET("/:user_id", holdController.FindHolds)
+	holdRouter.POST("/:user_id", holdController.CreateHold)
+	holdRouter.GET("/:user_id/:hold_id", holdController.FindHold)
+	holdRouter.POST("/capture/:user_id/:hold_id", holdController.Capture)
+	holdRouter.POST("/release/:user_id/:hold_id", holdController.Release)
+
+	runEvery(5*time.Minute, "expire funds holds", func() error {
+		_, err := holdUsecase.ExpireHolds()
+		return err
+	})
+
+	// Webhook Router
+	webhookRouter := r.Group("/user/webhook")
+	webhookRouter.Use(authMiddlewareIdExist)
+
+	// Webhook Depedency
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, model.NewHTTPWebhookSender())
+	webhookController := controller.NewWebhookController(webhookUsecase)
+
+	webhookRouter.GET("/:user_id", webhookController.FindSubscriptions)
+	webhookRouter.POST("/:user_id", webhookController.Subscribe)
+	webhookRouter.DELETE("/:user_id/:subscription_id", webhookController.Unsubscribe)
+	webhookRouter.GET("/delivery/:user_id/:subscription_id", webhookController.FindDeliveries)
+	webhookRouter.POST("/redeliver/:user_id/:delivery_id", webhookController.Redeliver)
+
+	runEvery(30*time.Second, "deliver webhooks", func() error {
+		_, err := webhookUsecase.DeliverDue()
+		return err
+	})
+
+	// Point Router
+	pointRouter := r.Group("/user/point")
+	pointRouter.Use(authMiddlewareIdExist)
+	pointRuleRouter := r.Group("/admin/point/rule")
+	pointRuleRouter.Use(authMiddlewareRole)
+
+	// Point Depedency
+	pointController := controller.NewPointController(pointUsecase, userUsecase)
+
+	pointRouter.GET("/:user_id", pointController.FindHistory)
+	pointRouter.POST("/convert/:user_id", pointController.Convert)
+	pointRouter.POST("/transfer/:user_id", pointController.Transfer)
+	r.POST("/admin/point/adjust/:user_id", authMiddlewareRole, pointController.Adjust)
+	pointRuleRouter.GET("", pointController.FindRules)
+	pointRuleRouter.POST("", pointController.CreateRule)
+	pointRuleRouter.PUT("/:rule_id", pointController.UpdateRule)
+	pointRuleRouter.DELETE("/:rule_id", pointController.DeactivateRule)
+
+	// Mission Router
+	missionRouter := r.Group("/user/mission")
+	missionRouter.Use(authMiddlewareIdExist)
+	missionAdminRouter := r.Group("/admin/mission")
+	missionAdminRouter.Use(authMiddlewareRole)
+
+	// Mission Depedency
+	missionRepo := repository.NewMissionRepository(db)
+	missionUsecase := usecase.NewMissionUsecase(missionRepo, userRepo, pointUsecase)
+	missionController := controller.NewMissionController(missionUsecase)
+
+	r.GET("/mission", missionController.FindActive)
+	missionRouter.GET("/:user_id", missionController.FindProgress)
+	missionAdminRouter.GET("", missionController.FindMissions)
+	missionAdminRouter.POST("", missionController.CreateMission)
+	missionAdminRouter.PUT("/:mission_id", missionController.UpdateMission)
+	missionAdminRouter.DELETE("/:mission_id", missionController.DeactivateMission)
+
+	// Promo Router
+	promoRouter := r.Group("/admin/promo")
+	promoRouter.Use(authMiddlewareRole)
+
+	// Promo Depedency
+	promoRepo := repository.NewPromoRepository(db)
+	promoUsecase := usecase.NewPromoUsecase(promoRepo, userRepo)
+	promoController := controller.NewPromoController(promoUsecase)
+
+	promoRouter.GET("", promoController.FindCampaigns)
+	promoRouter.POST("", promoController.CreateCampaign)
+	promoRouter.PUT("/:campaign_id", promoController.UpdateCampaign)
+	promoRouter.DELETE("/:campaign_id", promoController.DeactivateCampaign)
+
+	runEvery(time.Hour, "release promo reservations", func() error {
+		_, err := promoUsecase.ReleaseStale()
+		return err
+	})
+
+	// Point Exchange Router
+	pointExchangeRouter := r.Group("/admin/point/exchange")
+	pointExchangeRouter.Use(authMiddlewareRole)
+
+	// Point Exchange Depedency
+	// No reward partner is integrated yet, so rewards cannot use the Provider fulfillment
+	var rewardProvider model.RewardProvider
+	pointExchangeRepo := repository.NewPointExchangeRepository(db)
+	pointExchangeUsecase := usecase.NewPointExchangeUsecase(pointExchangeRepo, rewardProvider != nil)
+	pointExchangeController := controller.NewPointExchangeController(pointExchangeUsecase)
+
+	r.GET("/point/catalog", pointExchangeController.FindCatalog)
+	r.GET("/point/catalog/category", pointExchangeController.FindCategories)
+	r.GET("/point/catalog/:pe_id", pointExchangeController.FindReward)
+	pointExchangeRouter.GET("", pointExchangeController.FindAll)
+	pointExchangeRouter.POST("", pointExchangeController.Create)
+	pointExchangeRouter.PUT("/:pe_id", pointExchangeController.Update)
+	pointExchangeRouter.DELETE("/:pe_id", pointExchangeController.Deactivate)
+
+	// Reward Router
+	voucherRouter := r.Group("/user/voucher")
+	voucherRouter.Use(authMiddlewareIdExist)
+
+	// Reward Depedency
+	voucherCodeRepo := repository.NewVoucherCodeRepository(db)
+	rewardUsecase := usecase.NewRewardUsecase(pointExchangeRepo, voucherCodeRepo, pointUsecase, rewardProvider)
+	rewardController := controller.NewRewardController(rewardUsecase)
+
+	voucherRouter.GET("/:user_id", rewardController.FindVouchers)
+	pointExchangeRouter.POST("/code/:pe_id", rewardController.UploadCodes)
+
+	// Badge Router
+	badgeRouter := r.Group("/user/badge")
+	badgeRouter.Use(authMiddlewareIdExist)
+
+	// Badge Depedency
+	badgeRepo := repository.NewBadgeRepository(db)
+	badgeUsecase := usecase.NewBadgeUsecase(badgeRepo)
+	badgeController := controller.NewBadgeController(badgeUsecase, userUsecase)
+
+	r.GET("/badge", badgeController.FindBadges)
+	badgeRouter.GET("/progress/:user_id", badgeController.FindProgress)
+	badgeRouter.GET("/history/:user_id", badgeController.FindHistory)
+
+	runDaily(3, "review badges", func() error {
+		_, err := badgeUsecase.ReviewBadges()
+		return err
+	})
+
+	runDaily(1, "expire points", func() error {
+		_, err := pointUsecase.ExpirePoints()
+		return err
+	})
+	runDaily(9, "notify expiring points", func() error {
+		_, err := pointUsecase.NotifyExpiringSoon()
+		return err
+	})
+
+	// Merchant Router
+	merchantRouter := r.Group("/user/merchant")
+	merchantRouter.Use(authMiddlewareIdExist)
+
+	// Merchant Depedency
+	merchantRepo := repository.NewMerchantRepository(db)
+	merchantUsecase := usecase.NewMerchantUsecase(merchantRepo, userRepo, bankAccRepo, holdRepo)
+	merchantController := controller.NewMerchantController(merchantUsecase, userUsecase, webhookUsecase, pointUsecase, missionUsecase, promoUsecase, badgeUsecase)
+
+	merchantRouter.GET("/:user_id", merchantController.FindMerchant)
+	merchantRouter.POST("/:user_id", merchantController.Register)
+	merchantRouter.PUT("/:user_id", merchantController.Update)
+	merchantRouter.GET("/info/:user_id/:merchant_id", merchantController.FindMerchantInfo)
+	merchantRouter.GET("/sales/:user_id", merchantController.FindSales)
+	merchantRouter.GET("/settlement/:user_id", merchantController.FindSettlements)
+	r.PUT("/merchant/mdr/:merchant_id", authMiddlewareRole, merchantController.SetMDR)
+
+	// Merchant API Depedency
+	merchantAPIRepo := repository.NewMerchantAPIRepository(db)
+	merchantAPIUsecase := usecase.NewMerchantAPIUsecase(merchantAPIRepo, merchantRepo, userRepo, holdRepo, merchantUsecase)
+	merchantAPIController := controller.NewMerchantAPIController(merchantAPIUsecase, userUsecase, webhookUsecase, pointUsecase, missionUsecase, badgeUsecase)
+
+	merchantRouter.GET("/key/:user_id", merchantAPIController.FindKeys)
+	merchantRouter.POST("/key/:user_id", merchantAPIController.IssueKey)
+	merchantRouter.PUT("/key/:user_id/:key_id", merchantAPIController.RotateKey)
+	merchantRouter.DELETE("/key/:user_id/:key_id", merchantAPIController.RevokeKey)
+
+	// Charge Router
+	chargeRouter := r.Group("/user/charge")
+	chargeRouter.Use(authMiddlewareIdExist)
+
+	chargeRouter.GET("/:user_id", merchantAPIController.FindPendingCharges)
+	chargeRouter.POST("/approve/:user_id/:charge_id", merchantAPIController.ApproveCharge)
+	chargeRouter.POST("/decline/:user_id/:charge_id", merchantAPIController.DeclineCharge)
+
+	// Merchant API Router
+	merchantAPIRouter := r.Group("/api/v1")
+	merchantAPIRouter.Use(merchantAPIController.AuthMiddlewareAPIKey())
+
+	merchantAPIRouter.POST("/charges", merchantAPIController.CreateCharge)
+	merchantAPIRouter.GET("/charges/:charge_id", merchantAPIController.FindCharge)
+	merchantAPIRouter.POST("/charges/:charge_id/refund", merchantAPIController.RefundCharge)
+
+	runEvery(time.Minute, "expire merchant charges", func() error {
+		_, err := merchantAPIUsecase.ExpireCharges()
+		return err
+	})
+	runEvery(time.Hour, "purge merchant api nonces", func() error {
+		_, err := merchantAPIUsecase.PurgeNonces()
+		return err
+	})
+
+	// Invoice Router
+	invoiceRouter := r.Group("/user/invoice")
+	invoiceRouter.Use(authMiddlewareIdExist)
+
+	// Invoice Depedency
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	invoiceUsecase := usecase.NewInvoiceUsecase(invoiceRepo, merchantRepo, userRepo, merchantUsecase)
+	invoiceController := controller.NewInvoiceController(invoiceUsecase, userUsecase, webhookUsecase, pointUsecase, missionUsecase, badgeUsecase)
+
+	invoiceRouter.GET("/:user_id", invoiceController.FindByMerchant)
+	invoiceRouter.POST("/:user_id", invoiceController.Create)
+	invoiceRouter.PUT("/:user_id/:invoice_id", invoiceController.Update)
+	invoiceRouter.GET("/:user_id/:invoice_id", invoiceController.FindInvoice)
+	invoiceRouter.GET("/received/:user_id", invoiceController.FindReceived)
+	invoiceRouter.GET("/pdf/:user_id/:invoice_id", invoiceController.DownloadPDF)
+	invoiceRouter.POST("/send/:user_id/:invoice_id", invoiceController.Send)
+	invoiceRouter.POST("/void/:user_id/:invoice_id", invoiceController.Void)
+
+	runEvery(time.Hour, "remind overdue invoices", func() error {
+		_, err := invoiceUsecase.RemindOverdue()
+		return err
+	})
+
+	// Payment Link Router
+	paymentLinkRouter := r.Group("/user/paylink")
+	paymentLinkRouter.Use(authMiddlewareIdExist)
+
+	// Payment Link Depedency
+	paymentLinkRepo := repository.NewPaymentLinkRepository(db)
+	paymentLinkUsecase := usecase.NewPaymentLinkUsecase(paymentLinkRepo, userRepo, holdRepo)
+	paymentLinkController := controller.NewPaymentLinkController(paymentLinkUsecase, userUsecase, webhookUsecase)
+
+	paymentLinkRouter.GET("/:user_id", paymentLinkController.FindByOwner)
+	paymentLinkRouter.POST("/:user_id", paymentLinkController.Create)
+	paymentLinkRouter.DELETE("/:user_id/:link_id", paymentLinkController.Deactivate)
+	paymentLinkRouter.GET("/payment/:user_id/:link_id", paymentLinkController.FindPayments)
+	r.GET("/pay/:link_id", paymentLinkController.FindLink)
+	r.POST("/pay/:link_id", paymentLinkController.GuestPay)
+
+	runEvery(10*time.Minute, "expire gateway payments", func() error {
+		_, err := paymentLinkUsecase.ExpireGatewayPayments()
+		return err
+	})
+
 	//TX Router
 	txRouter := r.Group("/user/tx")
 	txRouter.Use(authMiddlewareIdExist)
 
 	// TX Depedency
 	txRepo := repository.NewTxRepository(db)
-	txUsecase := usecase.NewTransactionUseCase(txRepo, userRepo)
-	txController := controller.NewTransactionController(txUsecase, userUsecase, bankAccusecase)
+	txUsecase := usecase.NewTransactionUseCase(txRepo, userRepo, holdRepo, pointLedgerRepo)
+	inquiryRepo := repository.NewTransferInquiryRepository(db)
+	recipientResolver := usecase.NewRecipientResolver(userRepo)
+	inquiryUsecase := usecase.NewTransferInquiryUseCase(inquiryRepo, holdRepo, badgeRepo, recipientResolver)
+	txController := controller.NewTransactionController(txUsecase, userUsecase, bankAccusecase, inquiryUsecase, pocketUsecase, webhookUsecase, paymentLinkUsecase, pointUsecase, rewardUsecase, badgeUsecase, referralUsecase, missionUsecase, promoUsecase)
 
+	txRouter.POST("/tf/inquiry/:user_id", txController.InquiryTransfer)
 	txRouter.POST("/tf/:user_id", txController.CreateTransferTransaction)
+	txRouter.POST("/tf/attachment/:user_id/:tx_id", txController.UploadTransferAttachment)
+	txRouter.PUT("/category/:user_id/:tx_id", txController.SetTransactionCategory)
 	txRouter.POST("depo/bank/:user_id/:bank_account_id", txController.CreateDepositBank)
 
 	txRouter.POST("wd/:user_id/:bank_account_id", txController.CreateWithdrawal)
 	txRouter.POST("redeem/:user_id/:pe_id", txController.CreateRedeemTransaction)
 	txRouter.GET(":user_id", txController.GetTxBySenderId)
+	txRouter.POST("/merchant/:user_id", merchantController.Pay)
+	txRouter.POST("/paylink/:user_id/:link_id", paymentLinkController.PayWithWallet)
+	txRouter.POST("/invoice/:user_id/:invoice_id", invoiceController.Pay)
+
+	// Interbank Depedency
+	interbankRepo := repository.NewInterbankRepository(db)
+	interbankUsecase := usecase.NewInterbankUsecase(interbankRepo, userRepo, holdRepo, badgeRepo, model.NewHTTPBankInquiryProvider())
+	interbankController := controller.NewInterbankController(interbankUsecase, userUsecase, badgeUsecase)
+
+	txRouter.POST("/interbank/inquiry/:user_id", interbankController.Inquiry)
+	txRouter.POST("/interbank/:user_id", interbankController.Transfer)
+
+	// Bulk Transfer Depedency
+	bulkTransferRepo := repository.NewBulkTransferRepository(db)
+	bulkTransferUsecase := usecase.NewBulkTransferUsecase(bulkTransferRepo, userRepo, txUsecase, inquiryUsecase)
+	bulkTransferController := controller.NewBulkTransferController(bulkTransferUsecase, userUsecase, pocketUsecase, pointUsecase, referralUsecase, missionUsecase, badgeUsecase)
+
+	txRouter.POST("/bulk/preview/:user_id", bulkTransferController.Preview)
+	txRouter.POST("/bulk/execute/:user_id/:batch_id", bulkTransferController.Execute)
+	txRouter.GET("/bulk/:user_id", bulkTransferController.GetBatches)
+	txRouter.GET("/bulk/:user_id/:batch_id", bulkTransferController.GetBatch)
+	txRouter.GET("/bulk/result/:user_id/:batch_id", bulkTransferController.DownloadResult)
 	r.POST("notif/midtrans", txController.HandlePaymentNotification)
 
 	if err := r.Run(utils.DotEnv("SERVER_PORT")); err != nil {
@@ -0,0 +1,41 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// runEvery starts a background job that runs at a fixed interval for as long
+// as the server is up.
+func runEvery(interval time.Duration, name string, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := job(); err != nil {
+				logrus.Errorf("Job %s failed: %v", name, err)
+			}
+		}
+	}()
+}
+
+// runDaily starts a background job that runs once a day at the given hour,
+// server time.
+func runDaily(hour int, name string, job func() error) {
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			time.Sleep(time.Until(next))
+
+			if err := job(); err != nil {
+				logrus.Errorf("Job %s failed: %v", name, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+)
+
+// BankInquiryProvider looks up the holder name of an account at another bank.
+type BankInquiryProvider interface {
+	InquiryAccount(bankCode string, accountNumber string) (*BankAccountInquiry, error)
+}
+
+type httpBankInquiryProvider struct {
+	client *http.Client
+}
+
+func (p *httpBankInquiryProvider) InquiryAccount(bankCode string, accountNumber string) (*BankAccountInquiry, error) {
+	query := url.Values{}
+	query.Set("bank_code", bankCode)
+	query.Set("account_number", accountNumber)
+
+	req, err := http.NewRequest("GET", utils.DotEnv("BANK_INQUIRY_URL")+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bank inquiry request: %v", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("key=%s", utils.DotEnv("BANK_INQUIRY_KEY")))
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send bank inquiry request: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("bank account not found")
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bank inquiry failed with status: %s", res.Status)
+	}
+
+	var account BankAccountInquiry
+	if err := json.NewDecoder(res.Body).Decode(&account); err != nil {
+		return nil, fmt.Errorf("failed to decode bank inquiry response: %v", err)
+	}
+	if account.AccountHolderName == "" {
+		return nil, fmt.Errorf("bank account not found")
+	}
+	return &account, nil
+}
+
+func NewHTTPBankInquiryProvider() BankInquiryProvider {
+	return &httpBankInquiryProvider{client: &http.Client{}}
+}
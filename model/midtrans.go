@@ -1,27 +1,24 @@
 package model
 
 import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"math/rand"
-	"time"
 
 	"github.com/ReygaFitra/inc-final-project.git/model/response"
 	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/google/uuid"
 	"github.com/midtrans/midtrans-go"
 	"github.com/midtrans/midtrans-go/snap"
 )
 
-func generateSerialNumber() int {
-	// Generate random 4-digit number
-	rand.Seed(time.Now().UnixNano())
-	serialNumber := rand.Intn(10000)
-
-	return serialNumber
-}
 func CreateMidtransTransactionFromDeposit(depo *Deposit, user *User) (string, error) {
-	serialNumber := generateSerialNumber()
-	orderID := fmt.Sprintf("DEPOSIT-%04d", serialNumber)
+	// The notification is matched back to the deposit by its order ID, so it
+	// has to be unique
+	orderID := "DEPOSIT-" + uuid.NewString()
+	depo.OrderID = orderID
 
 	customerName := depo.AccountHolderName
 	totalAmount := int64(depo.Amount)
@@ -43,6 +40,40 @@ func CreateMidtransTransactionFromDeposit(depo *Deposit, user *User) (string, er
 		},
 	}
 
+	return createSnapTransaction(payment, customer)
+}
+
+// CreateMidtransTransactionFromPaymentLink opens a Snap transaction for a
+// guest paying a payment link. The order ID ties the notification back to
+// the pending payment.
+func CreateMidtransTransactionFromPaymentLink(linkPayment *PaymentLinkPayment) (string, error) {
+	midtrans.ServerKey = utils.DotEnv("SERVER_KEY")
+	midtrans.ClientKey = utils.DotEnv("CLIENT_KEY")
+
+	payment := midtrans.TransactionDetails{
+		OrderID:  linkPayment.OrderID,
+		GrossAmt: int64(linkPayment.Amount),
+	}
+
+	customer := &midtrans.CustomerDetails{
+		FName: linkPayment.PayerName,
+		Email: linkPayment.PayerEmail,
+		Phone: linkPayment.PayerPhone,
+	}
+
+	return createSnapTransaction(payment, customer)
+}
+
+// VerifyMidtransSignature checks the signature_key Midtrans puts on every
+// notification: the hex SHA512 of order_id, status_code, gross_amount and our
+// server key. Anything that fails it did not come from Midtrans.
+func VerifyMidtransSignature(notification *response.PaymentNotification) bool {
+	digest := sha512.Sum512([]byte(notification.OrderID + notification.StatusCode + notification.GrossAmount + utils.DotEnv("SERVER_KEY")))
+	expected := hex.EncodeToString(digest[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(notification.SignatureKey)) == 1
+}
+
+func createSnapTransaction(payment midtrans.TransactionDetails, customer *midtrans.CustomerDetails) (string, error) {
 	request := &snap.Request{
 		TransactionDetails: payment,
 		CustomerDetail:     customer,
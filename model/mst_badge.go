@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Badge is a loyalty tier. A user holds the highest badge whose count and
+// volume thresholds are both met by their transactions over the rolling
+// evaluation window. The benefit columns are read by the fee, limit and
+// point subsystems; 0 means the tier does not change the default. Reaching
+// a badge with an UpgradeMessage sends it to the user.
+type Badge struct {
+	BadgeID             int    `json:"badge_id"`
+	BadgeName           string `json:"badge_name"`
+	MinTxCount          int    `json:"min_tx_count"`
+	MinTxVolume         int    `json:"min_tx_volume"`
+	FeeDiscountPercent  int    `json:"fee_discount_percent"`
+	DailyInterbankLimit int    `json:"daily_interbank_limit"`
+	PointMultiplier     int    `json:"point_multiplier"`
+	UpgradeMessage      string `json:"upgrade_message"`
+}
+
+// BadgeActivity is what a user did over the evaluation window.
+type BadgeActivity struct {
+	TxCount  int `json:"tx_count"`
+	TxVolume int `json:"tx_volume"`
+}
+
+// BadgeProgress shows where a user stands against the next badge. The
+// remaining values are 0 once a threshold is met or at the top tier.
+type BadgeProgress struct {
+	CurrentBadge      *Badge        `json:"current_badge"`
+	NextBadge         *Badge        `json:"next_badge"`
+	Activity          BadgeActivity `json:"activity"`
+	WindowDays        int           `json:"window_days"`
+	RemainingTxCount  int           `json:"remaining_tx_count"`
+	RemainingTxVolume int           `json:"remaining_tx_volume"`
+	Progress          int           `json:"progress"`
+	Tiers             []*Badge      `json:"tiers"`
+}
+
+// BadgeHolder is a user whose badge is up for the periodic review.
+type BadgeHolder struct {
+	UserID         string
+	BadgeID        int
+	Token          string
+	BadgeChangedAt *time.Time
+}
+
+// BadgeChange is one entry of a user's tier history.
+type BadgeChange struct {
+	ChangeID    int       `json:"change_id"`
+	UserID      string    `json:"user_id"`
+	FromBadgeID int       `json:"from_badge_id"`
+	FromBadge   string    `json:"from_badge"`
+	ToBadgeID   int       `json:"to_badge_id"`
+	ToBadge     string    `json:"to_badge"`
+	Direction   string    `json:"direction"`
+	TxCount     int       `json:"tx_count"`
+	TxVolume    int       `json:"tx_volume"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
@@ -0,0 +1,18 @@
+package model
+
+type Contact struct {
+	ContactID     int    `json:"contact_id"`
+	UserID        string `json:"user_id"`
+	RecipientID   string `json:"recipient_id"`
+	PhoneNumber   string `json:"phone_number"`
+	Alias         string `json:"alias"`
+	RecipientName string `json:"recipient_name"`
+}
+
+type RecentRecipient struct {
+	RecipientID      string `json:"recipient_id"`
+	RecipientName    string `json:"recipient_name"`
+	PhoneNumber      string `json:"phone_number"`
+	TransferCount    int    `json:"transfer_count"`
+	LastTransferDate string `json:"last_transfer_date"`
+}
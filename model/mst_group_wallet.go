@@ -0,0 +1,38 @@
+package model
+
+type GroupWallet struct {
+	GroupID            int            `json:"group_id"`
+	Name               string         `json:"name"`
+	OwnerID            string         `json:"owner_id"`
+	Balance            int            `json:"balance"`
+	ContributionAmount int            `json:"contribution_amount"`
+	Period             string         `json:"period"`
+	StartDate          string         `json:"start_date"`
+	CurrentRound       int            `json:"current_round"`
+	Members            []*GroupMember `json:"members,omitempty"`
+}
+
+type GroupMember struct {
+	GroupID     int    `json:"group_id"`
+	UserID      string `json:"user_id"`
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+	Role        string `json:"role"`
+	PayoutOrder int    `json:"payout_order"`
+	PayoutDate  string `json:"payout_date"`
+}
+
+type GroupActivity struct {
+	TransactionID int    `json:"transaction_id"`
+	GroupID       int    `json:"group_id"`
+	UserID        string `json:"user_id"`
+	UserName      string `json:"user_name"`
+	Activity      string `json:"activity"`
+	Amount        int    `json:"amount"`
+	Round         int    `json:"round"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type GroupSchedule struct {
+	Order []string `json:"order"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+type Merchant struct {
+	MerchantID              string    `json:"merchant_id"`
+	UserID                  string    `json:"user_id"`
+	BusinessName            string    `json:"business_name"`
+	BusinessCategory        string    `json:"business_category"`
+	BusinessAddress         string    `json:"business_address"`
+	SettlementAccountID     uint      `json:"settlement_account_id,omitempty"`
+	SettlementBankName      string    `json:"settlement_bank_name,omitempty"`
+	SettlementAccountNumber string    `json:"settlement_account_number,omitempty"`
+	MDRBps                  int       `json:"mdr_bps"`
+	QRPayload               string    `json:"qr_payload"`
+	CreatedAt               time.Time `json:"created_at"`
+}
+
+type MerchantPayment struct {
+	TransactionID  int              `json:"transaction_id"`
+	MerchantID     string           `json:"merchant_id"`
+	MerchantName   string           `json:"merchant_name"`
+	MerchantUserID string           `json:"-"`
+	QRPayload      string           `json:"qr_payload,omitempty"`
+	PayerID        string           `json:"payer_id"`
+	PayerName      string           `json:"payer_name"`
+	Amount         int              `json:"amount"`
+	MDRAmount      int              `json:"mdr_amount"`
+	NetAmount      int              `json:"net_amount"`
+	Note           string           `json:"note"`
+	PaidAt         time.Time        `json:"paid_at"`
+	PromoCode      string           `json:"promo_code,omitempty"`
+	Promo          *PromoRedemption `json:"promo,omitempty"`
+}
+
+// MerchantSettlement totals one day of merchant payments.
+type MerchantSettlement struct {
+	MerchantID       string `json:"merchant_id"`
+	Date             string `json:"date"`
+	TransactionCount int    `json:"transaction_count"`
+	GrossAmount      int    `json:"gross_amount"`
+	MDRAmount        int    `json:"mdr_amount"`
+	NetAmount        int    `json:"net_amount"`
+}
+
+type MerchantMDR struct {
+	MDRBps int `json:"mdr_bps"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+type MerchantAPIKey struct {
+	KeyID      string     `json:"key_id"`
+	MerchantID string     `json:"merchant_id"`
+	Secret     string     `json:"secret,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// MerchantCharge is a payment request a merchant backend sends to a user.
+// Money only moves once the user approves it in the app.
+type MerchantCharge struct {
+	ChargeID       string    `json:"charge_id"`
+	MerchantID     string    `json:"merchant_id"`
+	MerchantName   string    `json:"merchant_name"`
+	Reference      string    `json:"reference"`
+	UserID         string    `json:"user_id"`
+	PhoneNumber    string    `json:"phone_number"`
+	Amount         int       `json:"amount"`
+	RefundedAmount int       `json:"refunded_amount"`
+	Description    string    `json:"description"`
+	Status         string    `json:"status"`
+	ExpiredAt      time.Time `json:"expired_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	TransactionID  int       `json:"transaction_id"`
+
+	MerchantUserID string `json:"-"`
+}
+
+type ChargeRefund struct {
+	ChargeID      string `json:"charge_id"`
+	Amount        int    `json:"amount"`
+	TransactionID int    `json:"transaction_id"`
+
+	MerchantUserID string `json:"-"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Mission is a time-boxed challenge such as "make 5 transfers this week" or
+// "top up 100k". Count missions add one per qualifying transaction, Amount
+// missions add the transaction amount. Transactions below MinAmount do not
+// count.
+type Mission struct {
+	MissionID       int       `json:"mission_id"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	TransactionType string    `json:"transaction_type"`
+	Metric          string    `json:"metric"`
+	Target          int       `json:"target"`
+	MinAmount       int       `json:"min_amount"`
+	RewardPoints    int       `json:"reward_points"`
+	StartsAt        time.Time `json:"starts_at"`
+	EndsAt          time.Time `json:"ends_at"`
+	Active          bool      `json:"active"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// MissionProgress is how far a user is in an active mission.
+type MissionProgress struct {
+	Mission
+	Progress    int        `json:"progress"`
+	Percent     int        `json:"percent"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
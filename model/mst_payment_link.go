@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// PaymentLink is a shareable request for money. An Amount of 0 lets the
+// payer choose how much to pay, and MaxUses of 0 allows unlimited payments.
+type PaymentLink struct {
+	LinkID          string    `json:"link_id"`
+	OwnerID         string    `json:"owner_id"`
+	OwnerName       string    `json:"owner_name"`
+	Amount          int       `json:"amount"`
+	Description     string    `json:"description"`
+	MaxUses         int       `json:"max_uses"`
+	UseCount        int       `json:"use_count"`
+	CollectedAmount int       `json:"collected_amount"`
+	Status          string    `json:"status"`
+	ExpiresInDays   int       `json:"expires_in_days,omitempty"`
+	ExpiredAt       time.Time `json:"expired_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type PaymentLinkPayment struct {
+	PaymentID     int        `json:"payment_id"`
+	LinkID        string     `json:"link_id"`
+	PayerID       string     `json:"payer_id"`
+	PayerName     string     `json:"payer_name"`
+	PayerEmail    string     `json:"payer_email"`
+	PayerPhone    string     `json:"payer_phone"`
+	Amount        int        `json:"amount"`
+	Method        string     `json:"method"`
+	Status        string     `json:"status"`
+	OrderID       string     `json:"order_id"`
+	Token         string     `json:"token,omitempty"`
+	TransactionID int        `json:"transaction_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+
+	OwnerID string `json:"-"`
+}
@@ -0,0 +1,22 @@
+package model
+
+type Pocket struct {
+	PocketID        int    `json:"pocket_id"`
+	UserID          string `json:"user_id"`
+	Name            string `json:"name"`
+	Balance         int    `json:"balance"`
+	TargetAmount    int    `json:"target_amount"`
+	Deadline        string `json:"deadline"`
+	AutoSavePercent int    `json:"auto_save_percent"`
+	Progress        int    `json:"progress"`
+}
+
+type PocketMovement struct {
+	TransactionID   int    `json:"transaction_id"`
+	PocketID        int    `json:"pocket_id"`
+	UserID          string `json:"user_id"`
+	PocketName      string `json:"pocket_name"`
+	Amount          int    `json:"amount"`
+	TransactionType string `json:"transaction_type"`
+	TransactionDate string `json:"transaction_date"`
+}
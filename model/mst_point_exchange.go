@@ -1,7 +1,27 @@
 package model
 
+import "time"
+
+// PointExchange is a reward in the point exchange catalog. A nil Stock means
+// the reward never runs out; a reward with a date window is only listed while
+// the window is open. FulfillmentType decides how a redeemed reward reaches
+// the user: None, Voucher Code (from an uploaded pool) or Provider.
 type PointExchange struct {
-	PE_ID  int    `json:"pe_id"`
-	Reward string `json:"reward"`
-	Price  int    `json:"price"`
+	PE_ID           int        `json:"pe_id"`
+	Reward          string     `json:"reward"`
+	Price           int        `json:"price"`
+	Category        string     `json:"category"`
+	Description     string     `json:"description"`
+	ImageURL        string     `json:"image_url"`
+	Stock           *int       `json:"stock"`
+	FulfillmentType string     `json:"fulfillment_type"`
+	StartsAt        *time.Time `json:"starts_at"`
+	EndsAt          *time.Time `json:"ends_at"`
+	Active          bool       `json:"active"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type PointExchangeCategory struct {
+	Category string `json:"category"`
+	Rewards  int    `json:"rewards"`
 }
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// PointRule awards loyalty points for a transaction type. Rules sharing a
+// name are tiers of each other; a rule with a date window is a campaign.
+type PointRule struct {
+	RuleID          int        `json:"rule_id"`
+	Name            string     `json:"name"`
+	TransactionType string     `json:"transaction_type"`
+	MinAmount       int        `json:"min_amount"`
+	Points          int        `json:"points"`
+	PercentBps      int        `json:"percent_bps"`
+	MaxPoints       int        `json:"max_points"`
+	StartsAt        *time.Time `json:"starts_at"`
+	EndsAt          *time.Time `json:"ends_at"`
+	Active          bool       `json:"active"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
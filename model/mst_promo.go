@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// PromoCampaign is a marketing campaign behind a promo code. A Fee Waiver
+// campaign waives the transfer fee, a Cashback campaign pays back a fixed
+// amount plus a percentage of the transaction, capped at MaxCashback.
+// PerUserLimit, Budget and EligibleBadgeIDs are unlimited when empty or 0.
+type PromoCampaign struct {
+	CampaignID       int       `json:"campaign_id"`
+	Code             string    `json:"code"`
+	Name             string    `json:"name"`
+	TransactionType  string    `json:"transaction_type"`
+	BenefitType      string    `json:"benefit_type"`
+	CashbackAmount   int       `json:"cashback_amount"`
+	CashbackBps      int       `json:"cashback_bps"`
+	MaxCashback      int       `json:"max_cashback"`
+	MinAmount        int       `json:"min_amount"`
+	PerUserLimit     int       `json:"per_user_limit"`
+	Budget           int       `json:"budget"`
+	BudgetUsed       int       `json:"budget_used"`
+	RedemptionCount  int       `json:"redemption_count"`
+	EligibleBadgeIDs []int     `json:"eligible_badge_ids"`
+	StartsAt         time.Time `json:"starts_at"`
+	EndsAt           time.Time `json:"ends_at"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// PromoRedemption is one use of a promo code. Its benefit is reserved from
+// the campaign budget before the transaction and becomes Applied once the
+// transaction succeeds, or Released when it does not.
+type PromoRedemption struct {
+	RedemptionID    int       `json:"redemption_id"`
+	CampaignID      int       `json:"campaign_id"`
+	Code            string    `json:"code"`
+	UserID          string    `json:"user_id"`
+	TransactionType string    `json:"transaction_type"`
+	TransactionID   int       `json:"transaction_id,omitempty"`
+	Amount          int       `json:"amount"`
+	BenefitType     string    `json:"benefit_type"`
+	Benefit         int       `json:"benefit"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -15,6 +15,11 @@ type User struct {
 	Badge        string `json:"badge_name"`
 	BadgeID      int    `json:"badge_id"`
 	TxCount      int    `json:"tx_count"`
+
+	PaymentHandle string `json:"payment_handle"`
+	// ReferralCode is only read at registration: the code of the user who
+	// invited this one.
+	ReferralCode string `json:"referral_code,omitempty"`
 }
 
 // Define the table name for the User struct
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// VoucherCode is a code handed to a user for a redeemed reward. Codes of an
+// uploaded pool stay unassigned until a redemption takes one; codes from a
+// fulfillment provider are stored already assigned.
+type VoucherCode struct {
+	CodeID        int        `json:"code_id"`
+	PEID          int        `json:"pe_id"`
+	Reward        string     `json:"reward"`
+	Code          string     `json:"code"`
+	ValidUntil    *time.Time `json:"valid_until"`
+	UserID        string     `json:"-"`
+	TransactionID int        `json:"transaction_id"`
+	AssignedAt    *time.Time `json:"assigned_at"`
+	Status        string     `json:"status"`
+}
+
+type VoucherCodeUpload struct {
+	PEID    int `json:"pe_id"`
+	Added   int `json:"added"`
+	Skipped int `json:"skipped"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+type WebhookSubscription struct {
+	SubscriptionID int       `json:"subscription_id"`
+	UserID         string    `json:"user_id"`
+	URL            string    `json:"url"`
+	EventTypes     []string  `json:"event_types"`
+	Secret         string    `json:"secret,omitempty"`
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID     int        `json:"delivery_id"`
+	SubscriptionID int        `json:"subscription_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookEvent is the JSON body posted to subscribers.
+type WebhookEvent struct {
+	EventID   string      `json:"event_id"`
+	EventType string      `json:"event_type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
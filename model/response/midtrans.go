@@ -20,5 +20,9 @@ type PaymentNotification struct {
 	TransactionTime   string `json:"transaction_time"`
 	TransactionStatus string `json:"transaction_status"`
 	TransactionID     string `json:"transaction_id"`
+	OrderID           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
 	// ... tambahkan field lainnya sesuai kebutuhan
 }
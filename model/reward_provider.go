@@ -0,0 +1,9 @@
+package model
+
+// RewardProvider issues a voucher for a redeemed reward at an external
+// partner. The reference identifies the redemption so a retry can be
+// recognised by the partner. No partner is integrated yet, so none is wired
+// and rewards cannot be fulfilled through one.
+type RewardProvider interface {
+	Issue(reward *PointExchange, userID string, reference string) (*VoucherCode, error)
+}
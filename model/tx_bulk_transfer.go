@@ -0,0 +1,31 @@
+package model
+
+type BulkTransfer struct {
+	BatchID      int                 `json:"batch_id"`
+	UserID       string              `json:"user_id"`
+	FileName     string              `json:"file_name"`
+	Status       string              `json:"status"`
+	TotalRows    int                 `json:"total_rows"`
+	ValidRows    int                 `json:"valid_rows"`
+	TotalAmount  int                 `json:"total_amount"`
+	TotalFee     int                 `json:"total_fee"`
+	SuccessCount int                 `json:"success_count"`
+	FailedCount  int                 `json:"failed_count"`
+	CreatedAt    string              `json:"created_at"`
+	Items        []*BulkTransferItem `json:"items,omitempty"`
+}
+
+type BulkTransferItem struct {
+	ItemID        int    `json:"item_id"`
+	BatchID       int    `json:"batch_id"`
+	RowNumber     int    `json:"row_number"`
+	PhoneNumber   string `json:"phone_number"`
+	RecipientID   string `json:"-"`
+	RecipientName string `json:"recipient_name"`
+	Amount        int    `json:"amount"`
+	Fee           int    `json:"fee"`
+	Note          string `json:"note"`
+	Status        string `json:"status"`
+	Message       string `json:"message"`
+	TransactionID int    `json:"transaction_id"`
+}
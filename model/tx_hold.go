@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type Hold struct {
+	HoldID           int       `json:"hold_id"`
+	UserID           string    `json:"user_id"`
+	Amount           int       `json:"amount"`
+	CapturedAmount   int       `json:"captured_amount"`
+	Reference        string    `json:"reference"`
+	Description      string    `json:"description"`
+	Status           string    `json:"status"`
+	ExpiresInMinutes int       `json:"expires_in_minutes,omitempty"`
+	ExpiredAt        time.Time `json:"expired_at"`
+	CreatedAt        time.Time `json:"created_at"`
+	TransactionID    int       `json:"transaction_id"`
+}
+
+type HoldCapture struct {
+	Amount int `json:"amount"`
+}
+
+// WalletBalance splits the ledger balance into the part reserved by active
+// holds and the part the user can still spend.
+type WalletBalance struct {
+	UserID           string `json:"user_id"`
+	LedgerBalance    int    `json:"ledger_balance"`
+	HeldAmount       int    `json:"held_amount"`
+	AvailableBalance int    `json:"available_balance"`
+}
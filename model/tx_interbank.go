@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+type BankAccountInquiry struct {
+	BankCode          string `json:"bank_code"`
+	BankName          string `json:"bank_name"`
+	AccountNumber     string `json:"account_number"`
+	AccountHolderName string `json:"account_holder_name"`
+}
+
+type InterbankInquiry struct {
+	InquiryToken      string    `json:"inquiry_token"`
+	UserID            string    `json:"user_id"`
+	BankCode          string    `json:"bank_code"`
+	BankName          string    `json:"bank_name"`
+	AccountNumber     string    `json:"account_number"`
+	AccountHolderName string    `json:"account_holder_name"`
+	Amount            int       `json:"amount"`
+	Fee               int       `json:"fee"`
+	TotalAmount       int       `json:"total_amount"`
+	Status            string    `json:"status"`
+	ExpiredAt         time.Time `json:"expired_at"`
+}
+
+type InterbankTransfer struct {
+	TransactionID     int    `json:"transaction_id"`
+	UserID            string `json:"user_id"`
+	InquiryToken      string `json:"inquiry_token"`
+	BankCode          string `json:"bank_code"`
+	BankName          string `json:"bank_name"`
+	AccountNumber     string `json:"account_number"`
+	AccountHolderName string `json:"account_holder_name"`
+	Amount            int    `json:"amount"`
+	Fee               int    `json:"fee"`
+	TransactionType   string `json:"transaction_type"`
+	TransactionDate   string `json:"transaction_date"`
+	Status            string `json:"status"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Invoice is a bill a merchant sends to a customer. Amounts are derived from
+// the line items: Total = Subtotal + TaxAmount.
+type Invoice struct {
+	InvoiceID      string         `json:"invoice_id"`
+	MerchantID     string         `json:"merchant_id"`
+	MerchantName   string         `json:"merchant_name"`
+	CustomerID     string         `json:"customer_id"`
+	CustomerName   string         `json:"customer_name"`
+	PhoneNumber    string         `json:"phone_number"`
+	Items          []*InvoiceItem `json:"items"`
+	Subtotal       int            `json:"subtotal"`
+	TaxBps         int            `json:"tax_bps"`
+	TaxAmount      int            `json:"tax_amount"`
+	Total          int            `json:"total"`
+	Notes          string         `json:"notes"`
+	DueDate        string         `json:"due_date"`
+	Status         string         `json:"status"`
+	TransactionID  int            `json:"transaction_id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	SentAt         *time.Time     `json:"sent_at,omitempty"`
+	PaidAt         *time.Time     `json:"paid_at,omitempty"`
+	LastRemindedAt *time.Time     `json:"last_reminded_at,omitempty"`
+
+	MerchantUserID string `json:"-"`
+}
+
+type InvoiceItem struct {
+	ItemID      int    `json:"item_id"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   int    `json:"unit_price"`
+	Amount      int    `json:"amount"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// PointLedger is one movement of a user's points. Earn and positive Adjust
+// entries are lots that keep their Remaining points until used or expired.
+type PointLedger struct {
+	LedgerID      int        `json:"ledger_id"`
+	UserID        string     `json:"user_id"`
+	TransactionID int        `json:"transaction_id"`
+	EntryType     string     `json:"entry_type"`
+	Points        int        `json:"points"`
+	Remaining     int        `json:"remaining"`
+	Description   string     `json:"description"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type PointExpiry struct {
+	UserID    string `json:"-"`
+	Points    int    `json:"points"`
+	ExpiresOn string `json:"expires_on"`
+}
+
+type PointHistory struct {
+	Balance          int            `json:"balance"`
+	Entries          []*PointLedger `json:"entries"`
+	UpcomingExpiries []*PointExpiry `json:"upcoming_expiries"`
+}
+
+type PointAdjustment struct {
+	Points      int    `json:"points"`
+	Description string `json:"description"`
+}
+
+// PointMovement is a point conversion into balance or a point transfer
+// between users. Amount is the balance a conversion credits.
+type PointMovement struct {
+	TransactionID   int    `json:"transaction_id"`
+	TransactionType string `json:"transaction_type"`
+	TransactionDate string `json:"transaction_date"`
+	SenderID        string `json:"sender_id"`
+	RecipientID     string `json:"recipient_id"`
+	SenderName      string `json:"sender_name"`
+	RecipientName   string `json:"recipient_name"`
+	Points          int    `json:"points"`
+	Amount          int    `json:"amount"`
+	Note            string `json:"note"`
+}
+
+type PointTransferRequest struct {
+	RecipientPhoneNumber string `json:"recipient_phone_number"`
+	Points               int    `json:"points"`
+	Note                 string `json:"note"`
+}
+
+type PointConversionRequest struct {
+	Points int `json:"points"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type RedEnvelope struct {
+	EnvelopeID      int                 `json:"envelope_id"`
+	ClaimCode       string              `json:"claim_code"`
+	CreatorID       string              `json:"creator_id"`
+	CreatorName     string              `json:"creator_name"`
+	TotalAmount     int                 `json:"total_amount"`
+	RemainingAmount int                 `json:"remaining_amount"`
+	Slots           int                 `json:"slots"`
+	RemainingSlots  int                 `json:"remaining_slots"`
+	SplitType       string              `json:"split_type"`
+	Message         string              `json:"message"`
+	Status          string              `json:"status"`
+	ExpiredAt       time.Time           `json:"expired_at"`
+	TransactionID   int                 `json:"transaction_id"`
+	Claims          []*RedEnvelopeClaim `json:"claims,omitempty"`
+}
+
+type RedEnvelopeClaim struct {
+	EnvelopeID    int    `json:"envelope_id"`
+	UserID        string `json:"user_id"`
+	UserName      string `json:"user_name"`
+	Amount        int    `json:"amount"`
+	TransactionID int    `json:"transaction_id"`
+	ClaimedAt     string `json:"claimed_at"`
+}
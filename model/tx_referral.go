@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Referral links a new user to the user whose code they registered with.
+// It stays Pending until the referee's first qualifying deposit or transfer,
+// then becomes Rewarded, or Rejected when a fraud guard trips.
+type Referral struct {
+	ReferralID     int        `json:"referral_id"`
+	ReferrerID     string     `json:"referrer_id"`
+	RefereeID      string     `json:"referee_id"`
+	RefereeName    string     `json:"referee_name"`
+	Status         string     `json:"status"`
+	RejectReason   string     `json:"reject_reason,omitempty"`
+	RewardType     string     `json:"reward_type,omitempty"`
+	ReferrerReward int        `json:"referrer_reward"`
+	RefereeReward  int        `json:"referee_reward"`
+	TransactionID  int        `json:"transaction_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+}
+
+// ReferralReward is a referral bonus paid into the wallet balance.
+type ReferralReward struct {
+	TransactionID   int    `json:"transaction_id"`
+	TransactionDate string `json:"transaction_date"`
+	ReferralID      int    `json:"referral_id"`
+	UserID          string `json:"user_id"`
+	Amount          int    `json:"amount"`
+}
+
+type ReferralDashboard struct {
+	ReferralCode string      `json:"referral_code"`
+	Pending      int         `json:"pending"`
+	Rewarded     int         `json:"rewarded"`
+	Rejected     int         `json:"rejected"`
+	RewardType   string      `json:"reward_type"`
+	TotalEarned  int         `json:"total_earned"`
+	Referrals    []*Referral `json:"referrals"`
+}
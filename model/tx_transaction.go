@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type Transaction struct {
 	TxID                    int    `json:"tx_id"`
 	TransactionType         string `json:"transaction_type"`
@@ -20,11 +22,49 @@ type Transaction struct {
 	TransferRecipientPhone  string `json:"transfer_recipient_phone"`
 	TransferAmount          int    `json:"transfer_amount"`
 	TransferStatus          int    `json:"transfer_status"`
+	TransferNote            string `json:"transfer_note"`
+	TransferAttachmentUrl   string `json:"transfer_attachment_url"`
 
 	RedeemPEID   string `json:"redeem_pe_id"`
 	RedeemAmount int    `json:"redeem_amount"`
 	RedeemReward string `json:"redeem_reward"`
 	RedeemStatus int    `json:"redeem_status"`
+
+	InterbankBankName          string `json:"interbank_bank_name"`
+	InterbankAccountNumber     string `json:"interbank_account_number"`
+	InterbankAccountHolderName string `json:"interbank_account_holder_name"`
+	InterbankAmount            int    `json:"interbank_amount"`
+	InterbankFee               int    `json:"interbank_fee"`
+
+	PocketName   string `json:"pocket_name"`
+	PocketAmount int    `json:"pocket_amount"`
+
+	GroupName   string `json:"group_name"`
+	GroupAmount int    `json:"group_amount"`
+
+	RedEnvelopeAmount int `json:"red_envelope_amount"`
+
+	HoldReference string `json:"hold_reference"`
+	HoldAmount    int    `json:"hold_amount"`
+
+	MerchantName   string `json:"merchant_name"`
+	MerchantAmount int    `json:"merchant_amount"`
+	MerchantMDR    int    `json:"merchant_mdr"`
+
+	MerchantRefundAmount int `json:"merchant_refund_amount"`
+
+	PaymentLinkDescription string `json:"payment_link_description"`
+	PaymentLinkAmount      int    `json:"payment_link_amount"`
+	PointPoints            int    `json:"point_points"`
+	PointAmount            int    `json:"point_amount"`
+	PointSenderName        string `json:"point_sender_name"`
+	PointRecipientName     string `json:"point_recipient_name"`
+	PointNote              string `json:"point_note"`
+
+	ReferralRewardAmount int `json:"referral_reward_amount"`
+	CashbackAmount       int `json:"cashback_amount"`
+
+	Category string `json:"category"`
 }
 
 type Deposit struct {
@@ -41,6 +81,8 @@ type Deposit struct {
 	Status            string `json:"status"`
 	VaNumber          string `json:"va_number"`
 	Token             string `json:"token"`
+	OrderID           string `json:"order_id"`
+	PromoCode         string `json:"promo_code,omitempty"`
 }
 
 type Withdraw struct {
@@ -57,25 +99,42 @@ type Withdraw struct {
 	Status            string `json:"status"`
 }
 type Transfer struct {
-	TransferID           int    `json:"transfer_id"`
-	SenderID             string `json:"sender_id"`
-	RecipientID          string `json:"recipient_id"`
-	Amount               int    `json:"amount"`
-	SenderPhoneNumber    string `json:"sender_phone_number"`
-	RecipientPhoneNumber string `json:"recipient_phone_number"`
-	SenderName           string `json:"sender_name"`
-	RecipientName        string `json:"recipient_name"`
-	TransactionID        int    `json:"transaction_id"`
-	TxID                 int    `json:"tx_id"`
-	TransactionType      string `json:"transaction_type"`
-	TransactionDate      string `json:"transaction_date"`
-	Status               string `json:"status"`
+	TransferID           int              `json:"transfer_id"`
+	SenderID             string           `json:"sender_id"`
+	RecipientID          string           `json:"recipient_id"`
+	Amount               int              `json:"amount"`
+	SenderPhoneNumber    string           `json:"sender_phone_number"`
+	RecipientPhoneNumber string           `json:"recipient_phone_number"`
+	SenderName           string           `json:"sender_name"`
+	RecipientName        string           `json:"recipient_name"`
+	TransactionID        int              `json:"transaction_id"`
+	TxID                 int              `json:"tx_id"`
+	TransactionType      string           `json:"transaction_type"`
+	TransactionDate      string           `json:"transaction_date"`
+	Status               string           `json:"status"`
+	InquiryToken         string           `json:"inquiry_token"`
+	Fee                  int              `json:"fee"`
+	Recipient            string           `json:"recipient"`
+	Note                 string           `json:"note"`
+	AttachmentUrl        string           `json:"attachment_url"`
+	PromoCode            string           `json:"promo_code,omitempty"`
+	Promo                *PromoRedemption `json:"promo,omitempty"`
 }
 
-type Redeem struct {
+type TransactionCategory struct {
 	TransactionID int    `json:"transaction_id"`
 	UserID        string `json:"user_id"`
-	PEID          int    `json:"pe_id"`
-	Amount        int    `json:"amount"`
-	Status        string `json:"status"`
+	Category      string `json:"category"`
+}
+
+type Redeem struct {
+	TransactionID int          `json:"transaction_id"`
+	UserID        string       `json:"user_id"`
+	PEID          int          `json:"pe_id"`
+	Amount        int          `json:"amount"`
+	Status        string       `json:"status"`
+	Voucher       *VoucherCode `json:"voucher,omitempty"`
+	// PointsExpireAt is the earliest expiry among the lots the redemption
+	// used, kept so a refund does not extend the points' life.
+	PointsExpireAt *time.Time `json:"-"`
 }
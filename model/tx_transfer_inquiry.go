@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type TransferInquiry struct {
+	InquiryToken         string    `json:"inquiry_token"`
+	SenderID             string    `json:"sender_id"`
+	RecipientID          string    `json:"-"`
+	RecipientPhoneNumber string    `json:"recipient_phone_number"`
+	RecipientName        string    `json:"recipient_name"`
+	Amount               int       `json:"amount"`
+	Fee                  int       `json:"fee"`
+	TotalAmount          int       `json:"total_amount"`
+	Status               string    `json:"status"`
+	ExpiredAt            time.Time `json:"expired_at"`
+}
+
+type TransferFee struct {
+	FeeID     int `json:"fee_id"`
+	MinAmount int `json:"min_amount"`
+	MaxAmount int `json:"max_amount"`
+	Fee       int `json:"fee"`
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// WebhookSender posts a signed webhook body and reports the HTTP status code.
+type WebhookSender interface {
+	Send(url string, headers map[string]string, body []byte) (int, error)
+}
+
+type httpWebhookSender struct {
+	client *http.Client
+}
+
+func (s *httpWebhookSender) Send(url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %v", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	return res.StatusCode, nil
+}
+
+// PublicWebhookIP reports whether webhooks may be sent to ip. Loopback,
+// private, link-local (which includes the cloud metadata address), multicast
+// and unspecified addresses are refused.
+func PublicWebhookIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// NewHTTPWebhookSender checks the resolved address of every connection, so a
+// subscriber host that later resolves to an internal address (or redirects to
+// one) is refused as well.
+func NewHTTPWebhookSender() WebhookSender {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !PublicWebhookIP(ip) {
+				return fmt.Errorf("webhook address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &httpWebhookSender{client: &http.Client{Timeout: 10 * time.Second, Transport: transport}}
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+// ErrBadgeChanged is returned by ChangeBadge when the user no longer holds
+// the badge the change started from.
+var ErrBadgeChanged = errors.New("badge changed in the meantime")
+
+type BadgeRepository interface {
+	GetAll() ([]*model.Badge, error)
+	GetByID(badgeID int) (*model.Badge, error)
+	GetActivity(userID string, since string) (*model.BadgeActivity, error)
+	GetHolders() ([]*model.BadgeHolder, error)
+	UpdateTxCount(userID string, txCount int) error
+	ChangeBadge(change *model.BadgeChange) error
+	GetHistory(userID string) ([]*model.BadgeChange, error)
+}
+
+type badgeRepository struct {
+	db *sql.DB
+}
+
+const badgeColumns = "badge_id, badge_name, threshold, volume_threshold, fee_discount_percent, daily_interbank_limit, point_multiplier, COALESCE(upgrade_message, '')"
+
+func scanBadge(scanner interface{ Scan(...interface{}) error }, badge *model.Badge) error {
+	return scanner.Scan(&badge.BadgeID, &badge.BadgeName, &badge.MinTxCount, &badge.MinTxVolume, &badge.FeeDiscountPercent, &badge.DailyInterbankLimit, &badge.PointMultiplier, &badge.UpgradeMessage)
+}
+
+// GetAll returns the badges from the lowest tier to the highest.
+func (r *badgeRepository) GetAll() ([]*model.Badge, error) {
+	rows, err := r.db.Query("SELECT " + badgeColumns + " FROM mst_badges ORDER BY threshold, volume_threshold, badge_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get badges: %v", err)
+	}
+	defer rows.Close()
+
+	var badges []*model.Badge
+	for rows.Next() {
+		badge := &model.Badge{}
+		if err := scanBadge(rows, badge); err != nil {
+			return nil, fmt.Errorf("failed to scan badge: %v", err)
+		}
+		badges = append(badges, badge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get badges: %v", err)
+	}
+	return badges, nil
+}
+
+func (r *badgeRepository) GetByID(badgeID int) (*model.Badge, error) {
+	badge := &model.Badge{}
+	err := scanBadge(r.db.QueryRow("SELECT "+badgeColumns+" FROM mst_badges WHERE badge_id = $1", badgeID), badge)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("badge not found")
+		}
+		return nil, fmt.Errorf("failed to get badge: %v", err)
+	}
+	return badge, nil
+}
+
+// GetActivity counts and sums the money movements a user started since the
+// given date, across deposits, withdrawals, transfers, interbank transfers,
+// merchant and payment link payments. Pending deposits do not count, nor do
+// guest gateway payments, which are recorded under the link owner.
+func (r *badgeRepository) GetActivity(userID string, since string) (*model.BadgeActivity, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(a.amount), 0) FROM (
+	SELECT COALESCE(d.amount, w.amount, tr.amount, ib.amount, mp.amount, plp.amount) AS amount
+	FROM tx_transaction t
+	LEFT JOIN tx_deposit d ON t.tx_id = d.transaction_id AND d.status = 'Success'
+	LEFT JOIN tx_withdraw w ON t.tx_id = w.transaction_id
+	LEFT JOIN tx_transfer tr ON t.tx_id = tr.transaction_id
+	LEFT JOIN tx_interbank ib ON t.tx_id = ib.transaction_id
+	LEFT JOIN tx_merchant_payment mp ON t.tx_id = mp.transaction_id
+	LEFT JOIN tx_payment_link_payment plp ON t.tx_id = plp.transaction_id AND plp.method = 'Wallet'
+	WHERE t.sender_id = $1 AND t.transaction_date >= $2
+	) a WHERE a.amount IS NOT NULL`
+
+	var activity model.BadgeActivity
+	err := r.db.QueryRow(query, userID, since).Scan(&activity.TxCount, &activity.TxVolume)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get badge activity: %v", err)
+	}
+	return &activity, nil
+}
+
+// GetHolders returns every user with the badge they hold, for the periodic
+// review.
+func (r *badgeRepository) GetHolders() ([]*model.BadgeHolder, error) {
+	rows, err := r.db.Query("SELECT user_id, badge_id, COALESCE(token, ''), badge_changed_at FROM mst_users ORDER BY user_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get badge holders: %v", err)
+	}
+	defer rows.Close()
+
+	var holders []*model.BadgeHolder
+	for rows.Next() {
+		holder := &model.BadgeHolder{}
+		if err := rows.Scan(&holder.UserID, &holder.BadgeID, &holder.Token, &holder.BadgeChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan badge holder: %v", err)
+		}
+		holders = append(holders, holder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get badge holders: %v", err)
+	}
+	return holders, nil
+}
+
+func (r *badgeRepository) UpdateTxCount(userID string, txCount int) error {
+	_, err := r.db.Exec("UPDATE mst_users SET tx_count = $1 WHERE user_id = $2", txCount, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+	return nil
+}
+
+// ChangeBadge moves a user from FromBadgeID to ToBadgeID and records the
+// change in the tier history. It fails when the user no longer holds
+// FromBadgeID, so two evaluations running at once cannot both apply.
+func (r *badgeRepository) ChangeBadge(change *model.BadgeChange) error {
+	query := "UPDATE mst_users SET badge_id = $1, tx_count = $2, badge_changed_at = $3 WHERE user_id = $4 AND badge_id = $5"
+	res, err := r.db.Exec(query, change.ToBadgeID, change.TxCount, change.ChangedAt, change.UserID, change.FromBadgeID)
+	if err != nil {
+		return fmt.Errorf("failed to update user badge: %v", err)
+	}
+	if err := affectedOr(res, "update user badge", ErrBadgeChanged); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO tx_badge_history (user_id, from_badge_id, to_badge_id, direction, tx_count, tx_volume, changed_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING change_id`
+	err = r.db.QueryRow(query, change.UserID, change.FromBadgeID, change.ToBadgeID, change.Direction, change.TxCount, change.TxVolume, change.ChangedAt).Scan(&change.ChangeID)
+	if err != nil {
+		return fmt.Errorf("failed to insert badge history: %v", err)
+	}
+	return nil
+}
+
+func (r *badgeRepository) GetHistory(userID string) ([]*model.BadgeChange, error) {
+	query := `SELECT h.change_id, h.user_id, h.from_badge_id, COALESCE(fb.badge_name, ''), h.to_badge_id, tb.badge_name, h.direction, h.tx_count, h.tx_volume, h.changed_at
+	FROM tx_badge_history h
+	LEFT JOIN mst_badges fb ON h.from_badge_id = fb.badge_id
+	JOIN mst_badges tb ON h.to_badge_id = tb.badge_id
+	WHERE h.user_id = $1 ORDER BY h.change_id DESC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get badge history: %v", err)
+	}
+	defer rows.Close()
+
+	var changes []*model.BadgeChange
+	for rows.Next() {
+		change := &model.BadgeChange{}
+		err := rows.Scan(&change.ChangeID, &change.UserID, &change.FromBadgeID, &change.FromBadge, &change.ToBadgeID, &change.ToBadge, &change.Direction, &change.TxCount, &change.TxVolume, &change.ChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan badge history: %v", err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get badge history: %v", err)
+	}
+	return changes, nil
+}
+
+func NewBadgeRepository(db *sql.DB) BadgeRepository {
+	return &badgeRepository{db: db}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var badgeTestColumns = []string{"badge_id", "badge_name", "threshold", "volume_threshold", "fee_discount_percent", "daily_interbank_limit", "point_multiplier", "upgrade_message"}
+
+type BadgeRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *BadgeRepositoryTestSuite) TestGetAll_Success() {
+	rows := sqlmock.NewRows(badgeTestColumns).
+		AddRow(1, "Bronze", 0, 0, 0, 0, 100, "").
+		AddRow(2, "Silver", 5, 1000000, 10, 0, 110, "Anda telah naik level menjadi Silver")
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM mst_badges ORDER BY threshold").WillReturnRows(rows)
+	repo := NewBadgeRepository(suite.mockDB)
+	res, err := repo.GetAll()
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 2)
+	assert.Equal(suite.T(), 1000000, res[1].MinTxVolume)
+	assert.Equal(suite.T(), 10, res[1].FeeDiscountPercent)
+	assert.Equal(suite.T(), "Anda telah naik level menjadi Silver", res[1].UpgradeMessage)
+}
+
+func (suite *BadgeRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM mst_badges WHERE badge_id = \\$1").WithArgs(9).WillReturnError(sql.ErrNoRows)
+	repo := NewBadgeRepository(suite.mockDB)
+	res, err := repo.GetByID(9)
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "badge not found")
+}
+
+func (suite *BadgeRepositoryTestSuite) TestGetActivity_Success() {
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE\\(SUM\\(a.amount\\), 0\\)").WithArgs("1", "2026-07-21").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "sum"}).AddRow(7, 3500000))
+	repo := NewBadgeRepository(suite.mockDB)
+	res, err := repo.GetActivity("1", "2026-07-21")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &model.BadgeActivity{TxCount: 7, TxVolume: 3500000}, res)
+}
+
+func (suite *BadgeRepositoryTestSuite) TestChangeBadge_Success() {
+	change := model.BadgeChange{UserID: "1", FromBadgeID: 1, ToBadgeID: 2, Direction: "Upgrade", TxCount: 7, TxVolume: 3500000, ChangedAt: time.Now()}
+	suite.mockSql.ExpectExec("UPDATE mst_users SET badge_id = \\$1").WithArgs(2, 7, change.ChangedAt, "1", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("INSERT INTO tx_badge_history").WithArgs("1", 1, 2, "Upgrade", 7, 3500000, change.ChangedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"change_id"}).AddRow(4))
+	repo := NewBadgeRepository(suite.mockDB)
+	err := repo.ChangeBadge(&change)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, change.ChangeID)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BadgeRepositoryTestSuite) TestChangeBadge_ChangedInTheMeantime() {
+	change := model.BadgeChange{UserID: "1", FromBadgeID: 1, ToBadgeID: 2, Direction: "Upgrade", ChangedAt: time.Now()}
+	suite.mockSql.ExpectExec("UPDATE mst_users SET badge_id = \\$1").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewBadgeRepository(suite.mockDB)
+	err := repo.ChangeBadge(&change)
+	assert.EqualError(suite.T(), err, "badge changed in the meantime")
+}
+
+func (suite *BadgeRepositoryTestSuite) TestGetHistory_Success() {
+	changedAt := time.Now()
+	rows := sqlmock.NewRows([]string{"change_id", "user_id", "from_badge_id", "from_badge", "to_badge_id", "to_badge", "direction", "tx_count", "tx_volume", "changed_at"}).
+		AddRow(4, "1", 1, "Bronze", 2, "Silver", "Upgrade", 7, 3500000, changedAt)
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM tx_badge_history h").WithArgs("1").WillReturnRows(rows)
+	repo := NewBadgeRepository(suite.mockDB)
+	res, err := repo.GetHistory("1")
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "Silver", res[0].ToBadge)
+}
+
+func (suite *BadgeRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *BadgeRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestBadgeRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(BadgeRepositoryTestSuite))
+}
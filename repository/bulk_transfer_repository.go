@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type BulkTransferRepository interface {
+	Create(batch *model.BulkTransfer) error
+	GetByID(batchID int, userID string) (*model.BulkTransfer, error)
+	GetByUserID(userID string) ([]*model.BulkTransfer, error)
+	Claim(batchID int, userID string) error
+	UpdateItem(item *model.BulkTransferItem) error
+	Complete(batch *model.BulkTransfer) error
+}
+
+type bulkTransferRepository struct {
+	db *sql.DB
+}
+
+func (r *bulkTransferRepository) Create(batch *model.BulkTransfer) error {
+	query := "INSERT INTO tx_bulk_transfer (user_id, file_name, status, total_rows, valid_rows, total_amount, total_fee, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING batch_id"
+	err := r.db.QueryRow(query, batch.UserID, batch.FileName, batch.Status, batch.TotalRows, batch.ValidRows, batch.TotalAmount, batch.TotalFee, today()).Scan(&batch.BatchID)
+	if err != nil {
+		return fmt.Errorf("failed to insert bulk transfer: %v", err)
+	}
+	batch.CreatedAt = today()
+
+	query = "INSERT INTO tx_bulk_transfer_item (batch_id, row_number, phone_number, recipient_id, recipient_name, amount, fee, note, status, message) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10) RETURNING item_id"
+	for _, item := range batch.Items {
+		item.BatchID = batch.BatchID
+		err = r.db.QueryRow(query, item.BatchID, item.RowNumber, item.PhoneNumber, item.RecipientID, item.RecipientName, item.Amount, item.Fee, item.Note, item.Status, item.Message).Scan(&item.ItemID)
+		if err != nil {
+			return fmt.Errorf("failed to insert bulk transfer item: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *bulkTransferRepository) GetByID(batchID int, userID string) (*model.BulkTransfer, error) {
+	var batch model.BulkTransfer
+	query := "SELECT batch_id, user_id, file_name, status, total_rows, valid_rows, total_amount, total_fee, success_count, failed_count, created_at FROM tx_bulk_transfer WHERE batch_id = $1 AND user_id = $2"
+	err := r.db.QueryRow(query, batchID, userID).Scan(&batch.BatchID, &batch.UserID, &batch.FileName, &batch.Status, &batch.TotalRows, &batch.ValidRows, &batch.TotalAmount, &batch.TotalFee, &batch.SuccessCount, &batch.FailedCount, &batch.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("bulk transfer not found")
+		}
+		return nil, fmt.Errorf("failed to get bulk transfer: %v", err)
+	}
+
+	query = "SELECT item_id, batch_id, row_number, phone_number, COALESCE(recipient_id, ''), recipient_name, amount, fee, note, status, message, COALESCE(transaction_id, 0) FROM tx_bulk_transfer_item WHERE batch_id = $1 ORDER BY row_number"
+	rows, err := r.db.Query(query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk transfer items: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := &model.BulkTransferItem{}
+		err := rows.Scan(&item.ItemID, &item.BatchID, &item.RowNumber, &item.PhoneNumber, &item.RecipientID, &item.RecipientName, &item.Amount, &item.Fee, &item.Note, &item.Status, &item.Message, &item.TransactionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bulk transfer item: %v", err)
+		}
+		batch.Items = append(batch.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get bulk transfer items: %v", err)
+	}
+	return &batch, nil
+}
+
+func (r *bulkTransferRepository) GetByUserID(userID string) ([]*model.BulkTransfer, error) {
+	query := "SELECT batch_id, user_id, file_name, status, total_rows, valid_rows, total_amount, total_fee, success_count, failed_count, created_at FROM tx_bulk_transfer WHERE user_id = $1 ORDER BY batch_id DESC"
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk transfers: %v", err)
+	}
+	defer rows.Close()
+
+	var batches []*model.BulkTransfer
+	for rows.Next() {
+		batch := &model.BulkTransfer{}
+		err := rows.Scan(&batch.BatchID, &batch.UserID, &batch.FileName, &batch.Status, &batch.TotalRows, &batch.ValidRows, &batch.TotalAmount, &batch.TotalFee, &batch.SuccessCount, &batch.FailedCount, &batch.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bulk transfer: %v", err)
+		}
+		batches = append(batches, batch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get bulk transfers: %v", err)
+	}
+	return batches, nil
+}
+
+// Claim moves a previewed batch to Processing so it can only be executed once.
+func (r *bulkTransferRepository) Claim(batchID int, userID string) error {
+	query := "UPDATE tx_bulk_transfer SET status = $1 WHERE batch_id = $2 AND user_id = $3 AND status = $4"
+	res, err := r.db.Exec(query, "Processing", batchID, userID, "Preview")
+	if err != nil {
+		return fmt.Errorf("failed to claim bulk transfer: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to claim bulk transfer: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("bulk transfer already executed")
+	}
+	return nil
+}
+
+func (r *bulkTransferRepository) UpdateItem(item *model.BulkTransferItem) error {
+	query := "UPDATE tx_bulk_transfer_item SET status = $1, message = $2, transaction_id = NULLIF($3, 0) WHERE item_id = $4"
+	_, err := r.db.Exec(query, item.Status, item.Message, item.TransactionID, item.ItemID)
+	if err != nil {
+		return fmt.Errorf("failed to update bulk transfer item: %v", err)
+	}
+	return nil
+}
+
+func (r *bulkTransferRepository) Complete(batch *model.BulkTransfer) error {
+	query := "UPDATE tx_bulk_transfer SET status = $1, success_count = $2, failed_count = $3 WHERE batch_id = $4"
+	_, err := r.db.Exec(query, batch.Status, batch.SuccessCount, batch.FailedCount, batch.BatchID)
+	if err != nil {
+		return fmt.Errorf("failed to complete bulk transfer: %v", err)
+	}
+	return nil
+}
+
+func NewBulkTransferRepository(db *sql.DB) BulkTransferRepository {
+	return &bulkTransferRepository{db: db}
+}
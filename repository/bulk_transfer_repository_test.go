@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var bulkTransferColumns = []string{"batch_id", "user_id", "file_name", "status", "total_rows", "valid_rows", "total_amount", "total_fee", "success_count", "failed_count", "created_at"}
+
+var dummyBulkItem = model.BulkTransferItem{
+	ItemID:        1,
+	BatchID:       1,
+	RowNumber:     1,
+	PhoneNumber:   "08111111112",
+	RecipientID:   "2",
+	RecipientName: "name2",
+	Amount:        20000,
+	Fee:           1000,
+	Note:          "gaji",
+	Status:        "Valid",
+}
+
+type BulkTransferRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *BulkTransferRepositoryTestSuite) TestCreate_Success() {
+	item := dummyBulkItem
+	batch := model.BulkTransfer{UserID: "1", FileName: "payroll.csv", Status: "Preview", TotalRows: 1, ValidRows: 1, TotalAmount: 20000, TotalFee: 1000, Items: []*model.BulkTransferItem{&item}}
+	suite.mockSql.ExpectQuery("INSERT INTO tx_bulk_transfer ").WithArgs(batch.UserID, batch.FileName, batch.Status, batch.TotalRows, batch.ValidRows, batch.TotalAmount, batch.TotalFee, today()).WillReturnRows(sqlmock.NewRows([]string{"batch_id"}).AddRow(3))
+	suite.mockSql.ExpectQuery("INSERT INTO tx_bulk_transfer_item").WithArgs(3, item.RowNumber, item.PhoneNumber, item.RecipientID, item.RecipientName, item.Amount, item.Fee, item.Note, item.Status, item.Message).WillReturnRows(sqlmock.NewRows([]string{"item_id"}).AddRow(5))
+	repo := NewBulkTransferRepository(suite.mockDB)
+	err := repo.Create(&batch)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, batch.BatchID)
+	assert.Equal(suite.T(), 5, item.ItemID)
+}
+
+func (suite *BulkTransferRepositoryTestSuite) TestCreate_Failed() {
+	batch := model.BulkTransfer{UserID: "1"}
+	suite.mockSql.ExpectQuery("INSERT INTO tx_bulk_transfer ").WillReturnError(errors.New("failed"))
+	repo := NewBulkTransferRepository(suite.mockDB)
+	err := repo.Create(&batch)
+	assert.EqualError(suite.T(), err, "failed to insert bulk transfer: failed")
+}
+
+func (suite *BulkTransferRepositoryTestSuite) TestGetByID_Success() {
+	item := dummyBulkItem
+	suite.mockSql.ExpectQuery("SELECT batch_id").WithArgs(1, "1").WillReturnRows(sqlmock.NewRows(bulkTransferColumns).AddRow(1, "1", "payroll.csv", "Preview", 1, 1, 20000, 1000, 0, 0, "2026-01-01"))
+	suite.mockSql.ExpectQuery("SELECT item_id").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"item_id", "batch_id", "row_number", "phone_number", "recipient_id", "recipient_name", "amount", "fee", "note", "status", "message", "transaction_id"}).
+		AddRow(item.ItemID, item.BatchID, item.RowNumber, item.PhoneNumber, item.RecipientID, item.RecipientName, item.Amount, item.Fee, item.Note, item.Status, item.Message, item.TransactionID))
+	repo := NewBulkTransferRepository(suite.mockDB)
+	res, err := repo.GetByID(1, "1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "payroll.csv", res.FileName)
+	assert.Equal(suite.T(), []*model.BulkTransferItem{&item}, res.Items)
+}
+
+func (suite *BulkTransferRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT batch_id").WithArgs(1, "1").WillReturnError(sql.ErrNoRows)
+	repo := NewBulkTransferRepository(suite.mockDB)
+	res, err := repo.GetByID(1, "1")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "bulk transfer not found")
+}
+
+func (suite *BulkTransferRepositoryTestSuite) TestGetByUserID_Success() {
+	suite.mockSql.ExpectQuery("SELECT batch_id").WithArgs("1").WillReturnRows(sqlmock.NewRows(bulkTransferColumns).AddRow(1, "1", "payroll.csv", "Completed", 1, 1, 20000, 1000, 1, 0, "2026-01-01"))
+	repo := NewBulkTransferRepository(suite.mockDB)
+	res, err := repo.GetByUserID("1")
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "Completed", res[0].Status)
+}
+
+func (suite *BulkTransferRepositoryTestSuite) TestClaim_AlreadyExecuted() {
+	suite.mockSql.ExpectExec("UPDATE tx_bulk_transfer SET status").WithArgs("Processing", 1, "1", "Preview").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewBulkTransferRepository(suite.mockDB)
+	err := repo.Claim(1, "1")
+	assert.EqualError(suite.T(), err, "bulk transfer already executed")
+}
+
+func (suite *BulkTransferRepositoryTestSuite) TestUpdateItem_Success() {
+	item := dummyBulkItem
+	item.Status = "Success"
+	item.TransactionID = 10
+	suite.mockSql.ExpectExec("UPDATE tx_bulk_transfer_item").WithArgs(item.Status, item.Message, item.TransactionID, item.ItemID).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewBulkTransferRepository(suite.mockDB)
+	err := repo.UpdateItem(&item)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *BulkTransferRepositoryTestSuite) TestComplete_Success() {
+	batch := model.BulkTransfer{BatchID: 1, Status: "Completed", SuccessCount: 1}
+	suite.mockSql.ExpectExec("UPDATE tx_bulk_transfer SET status").WithArgs(batch.Status, batch.SuccessCount, batch.FailedCount, batch.BatchID).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewBulkTransferRepository(suite.mockDB)
+	err := repo.Complete(&batch)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *BulkTransferRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *BulkTransferRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestBulkTransferRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(BulkTransferRepositoryTestSuite))
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type ContactRepository interface {
+	GetByUserID(userID string) ([]*model.Contact, error)
+	Create(contact *model.Contact) error
+	UpdateAlias(contactID int, userID string, alias string) error
+	Delete(contactID int, userID string) error
+	GetRecentRecipients(userID string, limit int) ([]*model.RecentRecipient, error)
+}
+
+type contactRepository struct {
+	db *sql.DB
+}
+
+func (r *contactRepository) GetByUserID(userID string) ([]*model.Contact, error) {
+	query := `
+	SELECT c.contact_id, c.user_id, c.recipient_id, c.phone_number, c.alias, u.name
+	FROM mst_contact c
+	JOIN mst_users u ON c.recipient_id = u.user_id
+	WHERE c.user_id = $1
+	ORDER BY c.alias`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contacts: %v", err)
+	}
+	defer rows.Close()
+
+	contacts := []*model.Contact{}
+	for rows.Next() {
+		contact := &model.Contact{}
+		err := rows.Scan(&contact.ContactID, &contact.UserID, &contact.RecipientID, &contact.PhoneNumber, &contact.Alias, &contact.RecipientName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contact: %v", err)
+		}
+		contacts = append(contacts, contact)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate through result set: %v", err)
+	}
+	return contacts, nil
+}
+
+func (r *contactRepository) Create(contact *model.Contact) error {
+	query := "INSERT INTO mst_contact (user_id, recipient_id, phone_number, alias) VALUES ($1, $2, $3, $4) RETURNING contact_id"
+	err := r.db.QueryRow(query, contact.UserID, contact.RecipientID, contact.PhoneNumber, contact.Alias).Scan(&contact.ContactID)
+	if err != nil {
+		return fmt.Errorf("failed to insert contact: %v", err)
+	}
+	return nil
+}
+
+func (r *contactRepository) UpdateAlias(contactID int, userID string, alias string) error {
+	query := "UPDATE mst_contact SET alias = $1 WHERE contact_id = $2 AND user_id = $3"
+	res, err := r.db.Exec(query, alias, contactID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update contact: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update contact: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("contact not found")
+	}
+	return nil
+}
+
+func (r *contactRepository) Delete(contactID int, userID string) error {
+	query := "DELETE FROM mst_contact WHERE contact_id = $1 AND user_id = $2"
+	res, err := r.db.Exec(query, contactID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete contact: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete contact: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("contact not found")
+	}
+	return nil
+}
+
+// GetRecentRecipients derives the recent recipient list from the user's
+// outgoing transfers, most recent first.
+func (r *contactRepository) GetRecentRecipients(userID string, limit int) ([]*model.RecentRecipient, error) {
+	query := `
+	SELECT tr.recipient_id, u.name, u.phone_number, COUNT(*), MAX(t.transaction_date)
+	FROM tx_transfer tr
+	JOIN tx_transaction t ON tr.transaction_id = t.tx_id
+	JOIN mst_users u ON tr.recipient_id = u.user_id
+	WHERE tr.sender_id = $1
+	GROUP BY tr.recipient_id, u.name, u.phone_number
+	ORDER BY MAX(tr.transaction_id) DESC
+	LIMIT $2`
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent recipients: %v", err)
+	}
+	defer rows.Close()
+
+	recipients := []*model.RecentRecipient{}
+	for rows.Next() {
+		recipient := &model.RecentRecipient{}
+		err := rows.Scan(&recipient.RecipientID, &recipient.RecipientName, &recipient.PhoneNumber, &recipient.TransferCount, &recipient.LastTransferDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recent recipient: %v", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate through result set: %v", err)
+	}
+	return recipients, nil
+}
+
+func NewContactRepository(db *sql.DB) ContactRepository {
+	return &contactRepository{db: db}
+}
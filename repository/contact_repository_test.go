@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyContact = []model.Contact{
+	{
+		ContactID:     1,
+		UserID:        "1",
+		RecipientID:   "2",
+		PhoneNumber:   "08111111112",
+		Alias:         "Ibu",
+		RecipientName: "name2",
+	},
+}
+
+type ContactRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *ContactRepositoryTestSuite) TestGetByUserID_Success() {
+	contact := dummyContact[0]
+	rows := sqlmock.NewRows([]string{"contact_id", "user_id", "recipient_id", "phone_number", "alias", "name"}).
+		AddRow(contact.ContactID, contact.UserID, contact.RecipientID, contact.PhoneNumber, contact.Alias, contact.RecipientName)
+	suite.mockSql.ExpectQuery("SELECT c.contact_id").WithArgs(contact.UserID).WillReturnRows(rows)
+	repo := NewContactRepository(suite.mockDB)
+	res, err := repo.GetByUserID(contact.UserID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.Contact{&contact}, res)
+}
+func (suite *ContactRepositoryTestSuite) TestGetByUserID_Failed() {
+	suite.mockSql.ExpectQuery("SELECT c.contact_id").WithArgs("1").WillReturnError(errors.New("failed"))
+	repo := NewContactRepository(suite.mockDB)
+	res, err := repo.GetByUserID("1")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "failed to get contacts: failed")
+}
+
+func (suite *ContactRepositoryTestSuite) TestCreate_Success() {
+	contact := dummyContact[0]
+	contact.ContactID = 0
+	suite.mockSql.ExpectQuery("INSERT INTO mst_contact").WithArgs(contact.UserID, contact.RecipientID, contact.PhoneNumber, contact.Alias).WillReturnRows(sqlmock.NewRows([]string{"contact_id"}).AddRow(7))
+	repo := NewContactRepository(suite.mockDB)
+	err := repo.Create(&contact)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 7, contact.ContactID)
+}
+
+func (suite *ContactRepositoryTestSuite) TestUpdateAlias_NotFound() {
+	suite.mockSql.ExpectExec("UPDATE mst_contact SET alias").WithArgs("Ayah", 1, "3").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewContactRepository(suite.mockDB)
+	err := repo.UpdateAlias(1, "3", "Ayah")
+	assert.EqualError(suite.T(), err, "contact not found")
+}
+
+func (suite *ContactRepositoryTestSuite) TestDelete_Success() {
+	suite.mockSql.ExpectExec("DELETE FROM mst_contact").WithArgs(1, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewContactRepository(suite.mockDB)
+	err := repo.Delete(1, "1")
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *ContactRepositoryTestSuite) TestGetRecentRecipients_Success() {
+	rows := sqlmock.NewRows([]string{"recipient_id", "name", "phone_number", "count", "max"}).
+		AddRow("2", "name2", "08111111112", 3, "2023-05-20").
+		AddRow("3", "name3", "08111111113", 1, "2023-05-18")
+	suite.mockSql.ExpectQuery("SELECT tr.recipient_id").WithArgs("1", 5).WillReturnRows(rows)
+	repo := NewContactRepository(suite.mockDB)
+	res, err := repo.GetRecentRecipients("1", 5)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 2)
+	assert.Equal(suite.T(), 3, res[0].TransferCount)
+}
+
+func (suite *ContactRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *ContactRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestContactRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ContactRepositoryTestSuite))
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type GroupWalletRepository interface {
+	Create(group *model.GroupWallet) error
+	GetByID(groupID int) (*model.GroupWallet, error)
+	GetByUserID(userID string) ([]*model.GroupWallet, error)
+	GetMembers(groupID int) ([]*model.GroupMember, error)
+	GetMember(groupID int, userID string) (*model.GroupMember, error)
+	AddMember(member *model.GroupMember) error
+	RemoveMember(groupID int, userID string) error
+	UpdatePayoutOrder(groupID int, userID string, order int) error
+	Contribute(activity *model.GroupActivity) error
+	Payout(activity *model.GroupActivity) error
+	GetActivities(groupID int) ([]*model.GroupActivity, error)
+}
+
+type groupWalletRepository struct {
+	db *sql.DB
+}
+
+func (r *groupWalletRepository) Create(group *model.GroupWallet) error {
+	query := "INSERT INTO mst_group_wallet (name, owner_id, balance, contribution_amount, period, start_date, current_round) VALUES ($1, $2, 0, $3, $4, $5, 1) RETURNING group_id"
+	err := r.db.QueryRow(query, group.Name, group.OwnerID, group.ContributionAmount, group.Period, group.StartDate).Scan(&group.GroupID)
+	if err != nil {
+		return fmt.Errorf("failed to create group wallet: %v", err)
+	}
+	group.CurrentRound = 1
+
+	owner := &model.GroupMember{GroupID: group.GroupID, UserID: group.OwnerID, Role: "Owner", PayoutOrder: 1}
+	return r.AddMember(owner)
+}
+
+func (r *groupWalletRepository) GetByID(groupID int) (*model.GroupWallet, error) {
+	var group model.GroupWallet
+	query := "SELECT group_id, name, owner_id, balance, contribution_amount, period, CAST(start_date AS VARCHAR), current_round FROM mst_group_wallet WHERE group_id = $1"
+	err := r.db.QueryRow(query, groupID).Scan(&group.GroupID, &group.Name, &group.OwnerID, &group.Balance, &group.ContributionAmount, &group.Period, &group.StartDate, &group.CurrentRound)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("group not found")
+		}
+		return nil, fmt.Errorf("failed to get group wallet: %v", err)
+	}
+	return &group, nil
+}
+
+func (r *groupWalletRepository) GetByUserID(userID string) ([]*model.GroupWallet, error) {
+	query := `SELECT g.group_id, g.name, g.owner_id, g.balance, g.contribution_amount, g.period, CAST(g.start_date AS VARCHAR), g.current_round
+	FROM mst_group_wallet g
+	JOIN mst_group_member m ON g.group_id = m.group_id
+	WHERE m.user_id = $1
+	ORDER BY g.group_id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group wallets: %v", err)
+	}
+	defer rows.Close()
+
+	var groups []*model.GroupWallet
+	for rows.Next() {
+		group := &model.GroupWallet{}
+		err := rows.Scan(&group.GroupID, &group.Name, &group.OwnerID, &group.Balance, &group.ContributionAmount, &group.Period, &group.StartDate, &group.CurrentRound)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group wallet: %v", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get group wallets: %v", err)
+	}
+	return groups, nil
+}
+
+func (r *groupWalletRepository) GetMembers(groupID int) ([]*model.GroupMember, error) {
+	query := `SELECT m.group_id, m.user_id, u.name, u.phone_number, m.role, m.payout_order
+	FROM mst_group_member m
+	JOIN mst_users u ON m.user_id = u.user_id
+	WHERE m.group_id = $1
+	ORDER BY m.payout_order`
+	rows, err := r.db.Query(query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %v", err)
+	}
+	defer rows.Close()
+
+	var members []*model.GroupMember
+	for rows.Next() {
+		member := &model.GroupMember{}
+		err := rows.Scan(&member.GroupID, &member.UserID, &member.Name, &member.PhoneNumber, &member.Role, &member.PayoutOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %v", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get group members: %v", err)
+	}
+	return members, nil
+}
+
+func (r *groupWalletRepository) GetMember(groupID int, userID string) (*model.GroupMember, error) {
+	var member model.GroupMember
+	query := `SELECT m.group_id, m.user_id, u.name, u.phone_number, m.role, m.payout_order
+	FROM mst_group_member m
+	JOIN mst_users u ON m.user_id = u.user_id
+	WHERE m.group_id = $1 AND m.user_id = $2`
+	err := r.db.QueryRow(query, groupID, userID).Scan(&member.GroupID, &member.UserID, &member.Name, &member.PhoneNumber, &member.Role, &member.PayoutOrder)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("member not found")
+		}
+		return nil, fmt.Errorf("failed to get group member: %v", err)
+	}
+	return &member, nil
+}
+
+func (r *groupWalletRepository) AddMember(member *model.GroupMember) error {
+	query := "INSERT INTO mst_group_member (group_id, user_id, role, payout_order) VALUES ($1, $2, $3, $4)"
+	_, err := r.db.Exec(query, member.GroupID, member.UserID, member.Role, member.PayoutOrder)
+	if err != nil {
+		return fmt.Errorf("failed to add group member: %v", err)
+	}
+	return nil
+}
+
+func (r *groupWalletRepository) RemoveMember(groupID int, userID string) error {
+	res, err := r.db.Exec("DELETE FROM mst_group_member WHERE group_id = $1 AND user_id = $2", groupID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove group member: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to remove group member: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("member not found")
+	}
+	return nil
+}
+
+func (r *groupWalletRepository) UpdatePayoutOrder(groupID int, userID string, order int) error {
+	_, err := r.db.Exec("UPDATE mst_group_member SET payout_order = $1 WHERE group_id = $2 AND user_id = $3", order, groupID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update payout order: %v", err)
+	}
+	return nil
+}
+
+func (r *groupWalletRepository) record(activity *model.GroupActivity, txType string) error {
+	today := time.Now().Format("2006-01-02")
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
+	_, err := r.db.Exec(query, txType, today, activity.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_group_wallet (transaction_id, group_id, user_id, activity, amount, round, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	_, err = r.db.Exec(query, txID, activity.GroupID, activity.UserID, activity.Activity, activity.Amount, activity.Round, today)
+	if err != nil {
+		return fmt.Errorf("failed to insert group activity: %v", err)
+	}
+
+	activity.TransactionID = txID
+	activity.CreatedAt = today
+	return nil
+}
+
+func (r *groupWalletRepository) Contribute(activity *model.GroupActivity) error {
+	_, err := r.db.Exec("UPDATE mst_group_wallet SET balance = balance + $1 WHERE group_id = $2", activity.Amount, activity.GroupID)
+	if err != nil {
+		return fmt.Errorf("failed to update group balance: %v", err)
+	}
+	return r.record(activity, "Group Contribution")
+}
+
+// Payout takes the pot of the current round and advances the rotation. The
+// round condition keeps a round from being paid out twice.
+func (r *groupWalletRepository) Payout(activity *model.GroupActivity) error {
+	query := "UPDATE mst_group_wallet SET balance = balance - $1, current_round = current_round + 1 WHERE group_id = $2 AND current_round = $3 AND balance >= $1"
+	res, err := r.db.Exec(query, activity.Amount, activity.GroupID, activity.Round)
+	if err != nil {
+		return fmt.Errorf("failed to update group balance: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update group balance: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("round already paid out")
+	}
+	return r.record(activity, "Group Payout")
+}
+
+func (r *groupWalletRepository) GetActivities(groupID int) ([]*model.GroupActivity, error) {
+	query := `SELECT a.transaction_id, a.group_id, a.user_id, u.name, a.activity, a.amount, a.round, CAST(a.created_at AS VARCHAR)
+	FROM tx_group_wallet a
+	JOIN mst_users u ON a.user_id = u.user_id
+	WHERE a.group_id = $1
+	ORDER BY a.transaction_id DESC`
+	rows, err := r.db.Query(query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group activities: %v", err)
+	}
+	defer rows.Close()
+
+	var activities []*model.GroupActivity
+	for rows.Next() {
+		activity := &model.GroupActivity{}
+		err := rows.Scan(&activity.TransactionID, &activity.GroupID, &activity.UserID, &activity.UserName, &activity.Activity, &activity.Amount, &activity.Round, &activity.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group activity: %v", err)
+		}
+		activities = append(activities, activity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get group activities: %v", err)
+	}
+	return activities, nil
+}
+
+func NewGroupWalletRepository(db *sql.DB) GroupWalletRepository {
+	return &groupWalletRepository{db: db}
+}
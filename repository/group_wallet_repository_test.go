@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyGroupWallet = model.GroupWallet{
+	GroupID:            1,
+	Name:               "Arisan RT",
+	OwnerID:            "1",
+	Balance:            200000,
+	ContributionAmount: 100000,
+	Period:             "Monthly",
+	StartDate:          "2026-01-10",
+	CurrentRound:       1,
+}
+
+var groupWalletColumns = []string{"group_id", "name", "owner_id", "balance", "contribution_amount", "period", "start_date", "current_round"}
+
+type GroupWalletRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *GroupWalletRepositoryTestSuite) TestCreate_Success() {
+	group := dummyGroupWallet
+	group.GroupID = 0
+	suite.mockSql.ExpectQuery("INSERT INTO mst_group_wallet").WithArgs(group.Name, group.OwnerID, group.ContributionAmount, group.Period, group.StartDate).WillReturnRows(sqlmock.NewRows([]string{"group_id"}).AddRow(3))
+	suite.mockSql.ExpectExec("INSERT INTO mst_group_member").WithArgs(3, group.OwnerID, "Owner", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewGroupWalletRepository(suite.mockDB)
+	err := repo.Create(&group)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, group.GroupID)
+}
+
+func (suite *GroupWalletRepositoryTestSuite) TestGetByID_Success() {
+	group := dummyGroupWallet
+	rows := sqlmock.NewRows(groupWalletColumns).AddRow(group.GroupID, group.Name, group.OwnerID, group.Balance, group.ContributionAmount, group.Period, group.StartDate, group.CurrentRound)
+	suite.mockSql.ExpectQuery("SELECT group_id").WithArgs(1).WillReturnRows(rows)
+	repo := NewGroupWalletRepository(suite.mockDB)
+	res, err := repo.GetByID(1)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &group, res)
+}
+
+func (suite *GroupWalletRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT group_id").WithArgs(1).WillReturnError(sql.ErrNoRows)
+	repo := NewGroupWalletRepository(suite.mockDB)
+	res, err := repo.GetByID(1)
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "group not found")
+}
+
+func (suite *GroupWalletRepositoryTestSuite) TestGetMember_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT m.group_id").WithArgs(1, "3").WillReturnError(sql.ErrNoRows)
+	repo := NewGroupWalletRepository(suite.mockDB)
+	res, err := repo.GetMember(1, "3")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "member not found")
+}
+
+func (suite *GroupWalletRepositoryTestSuite) TestContribute_Success() {
+	activity := model.GroupActivity{GroupID: 1, UserID: "1", Activity: "Contribution", Amount: 100000, Round: 1}
+	suite.mockSql.ExpectExec("UPDATE mst_group_wallet SET balance").WithArgs(activity.Amount, activity.GroupID).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Group Contribution", time.Now().Format("2006-01-02"), activity.UserID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(8))
+	suite.mockSql.ExpectExec("INSERT INTO tx_group_wallet").WithArgs(8, activity.GroupID, activity.UserID, activity.Activity, activity.Amount, activity.Round, time.Now().Format("2006-01-02")).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewGroupWalletRepository(suite.mockDB)
+	err := repo.Contribute(&activity)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 8, activity.TransactionID)
+}
+
+func (suite *GroupWalletRepositoryTestSuite) TestPayout_AlreadyPaid() {
+	activity := model.GroupActivity{GroupID: 1, UserID: "1", Activity: "Payout", Amount: 200000, Round: 1}
+	suite.mockSql.ExpectExec("UPDATE mst_group_wallet SET balance").WithArgs(activity.Amount, activity.GroupID, activity.Round).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewGroupWalletRepository(suite.mockDB)
+	err := repo.Payout(&activity)
+	assert.EqualError(suite.T(), err, "round already paid out")
+}
+
+func (suite *GroupWalletRepositoryTestSuite) TestGetActivities_Success() {
+	rows := sqlmock.NewRows([]string{"transaction_id", "group_id", "user_id", "name", "activity", "amount", "round", "created_at"}).
+		AddRow(8, 1, "1", "name1", "Contribution", 100000, 1, "2026-01-10")
+	suite.mockSql.ExpectQuery("SELECT a.transaction_id").WithArgs(1).WillReturnRows(rows)
+	repo := NewGroupWalletRepository(suite.mockDB)
+	res, err := repo.GetActivities(1)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "name1", res[0].UserName)
+}
+
+func (suite *GroupWalletRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *GroupWalletRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestGroupWalletRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(GroupWalletRepositoryTestSuite))
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type HoldRepository interface {
+	Create(hold *model.Hold) error
+	GetByID(holdID int, userID string) (*model.Hold, error)
+	GetByUserID(userID string) ([]*model.Hold, error)
+	GetHeldAmount(userID string) (int, error)
+	Capture(hold *model.Hold, amount int) error
+	Release(holdID int, userID string) error
+	ExpireAll(now time.Time) (int, error)
+}
+
+type holdRepository struct {
+	db *sql.DB
+}
+
+const holdColumns = "hold_id, user_id, amount, captured_amount, reference, description, status, expired_at, created_at, COALESCE(transaction_id, 0)"
+
+func scanHold(scanner interface{ Scan(...interface{}) error }, hold *model.Hold) error {
+	return scanner.Scan(&hold.HoldID, &hold.UserID, &hold.Amount, &hold.CapturedAmount, &hold.Reference, &hold.Description, &hold.Status, &hold.ExpiredAt, &hold.CreatedAt, &hold.TransactionID)
+}
+
+func (r *holdRepository) Create(hold *model.Hold) error {
+	query := "INSERT INTO tx_hold (user_id, amount, captured_amount, reference, description, status, expired_at, created_at) VALUES ($1, $2, 0, $3, $4, $5, $6, $7) RETURNING hold_id"
+	err := r.db.QueryRow(query, hold.UserID, hold.Amount, hold.Reference, hold.Description, hold.Status, hold.ExpiredAt, hold.CreatedAt).Scan(&hold.HoldID)
+	if err != nil {
+		return fmt.Errorf("failed to create hold: %v", err)
+	}
+	return nil
+}
+
+func (r *holdRepository) GetByID(holdID int, userID string) (*model.Hold, error) {
+	var hold model.Hold
+	row := r.db.QueryRow("SELECT "+holdColumns+" FROM tx_hold WHERE hold_id = $1 AND user_id = $2", holdID, userID)
+	if err := scanHold(row, &hold); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("hold not found")
+		}
+		return nil, fmt.Errorf("failed to get hold: %v", err)
+	}
+	return &hold, nil
+}
+
+func (r *holdRepository) GetByUserID(userID string) ([]*model.Hold, error) {
+	rows, err := r.db.Query("SELECT "+holdColumns+" FROM tx_hold WHERE user_id = $1 ORDER BY hold_id DESC", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holds: %v", err)
+	}
+	defer rows.Close()
+
+	var holds []*model.Hold
+	for rows.Next() {
+		hold := &model.Hold{}
+		if err := scanHold(rows, hold); err != nil {
+			return nil, fmt.Errorf("failed to scan hold: %v", err)
+		}
+		holds = append(holds, hold)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get holds: %v", err)
+	}
+	return holds, nil
+}
+
+// GetHeldAmount sums the active holds that have not passed their expiry yet,
+// so a hold stops reserving funds even before the expiry job marks it.
+func (r *holdRepository) GetHeldAmount(userID string) (int, error) {
+	var held int
+	query := "SELECT COALESCE(SUM(amount), 0) FROM tx_hold WHERE user_id = $1 AND status = 'Active' AND expired_at > $2"
+	err := r.db.QueryRow(query, userID, time.Now()).Scan(&held)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get held amount: %v", err)
+	}
+	return held, nil
+}
+
+// Capture settles an active hold for the given amount and records the debit
+// as a transaction. Whatever was held above the captured amount is released.
+func (r *holdRepository) Capture(hold *model.Hold, amount int) error {
+	query := "UPDATE tx_hold SET status = 'Captured', captured_amount = $1 WHERE hold_id = $2 AND user_id = $3 AND status = 'Active' AND expired_at > $4"
+	res, err := r.db.Exec(query, amount, hold.HoldID, hold.UserID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to capture hold: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to capture hold: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("hold is no longer active")
+	}
+
+	query = "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
+	_, err = r.db.Exec(query, "Hold Capture", today(), hold.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	_, err = r.db.Exec("UPDATE tx_hold SET transaction_id = $1 WHERE hold_id = $2", txID, hold.HoldID)
+	if err != nil {
+		return fmt.Errorf("failed to update hold transaction: %v", err)
+	}
+
+	hold.Status = "Captured"
+	hold.CapturedAmount = amount
+	hold.TransactionID = txID
+	return nil
+}
+
+func (r *holdRepository) Release(holdID int, userID string) error {
+	res, err := r.db.Exec("UPDATE tx_hold SET status = 'Released' WHERE hold_id = $1 AND user_id = $2 AND status = 'Active'", holdID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to release hold: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to release hold: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("hold is no longer active")
+	}
+	return nil
+}
+
+func (r *holdRepository) ExpireAll(now time.Time) (int, error) {
+	res, err := r.db.Exec("UPDATE tx_hold SET status = 'Expired' WHERE status = 'Active' AND expired_at <= $1", now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire holds: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire holds: %v", err)
+	}
+	return int(affected), nil
+}
+
+func NewHoldRepository(db *sql.DB) HoldRepository {
+	return &holdRepository{db: db}
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyHold = model.Hold{
+	HoldID:      1,
+	UserID:      "1",
+	Amount:      30000,
+	Reference:   "ORDER-1",
+	Description: "pending purchase",
+	Status:      "Active",
+	ExpiredAt:   time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC),
+	CreatedAt:   time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type HoldRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *HoldRepositoryTestSuite) TestCreate_Success() {
+	hold := dummyHold
+	hold.HoldID = 0
+	suite.mockSql.ExpectQuery("INSERT INTO tx_hold").WithArgs(hold.UserID, hold.Amount, hold.Reference, hold.Description, hold.Status, hold.ExpiredAt, hold.CreatedAt).WillReturnRows(sqlmock.NewRows([]string{"hold_id"}).AddRow(4))
+	repo := NewHoldRepository(suite.mockDB)
+	err := repo.Create(&hold)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, hold.HoldID)
+}
+
+func (suite *HoldRepositoryTestSuite) TestGetByID_Success() {
+	h := dummyHold
+	rows := sqlmock.NewRows([]string{"hold_id", "user_id", "amount", "captured_amount", "reference", "description", "status", "expired_at", "created_at", "transaction_id"}).
+		AddRow(h.HoldID, h.UserID, h.Amount, h.CapturedAmount, h.Reference, h.Description, h.Status, h.ExpiredAt, h.CreatedAt, h.TransactionID)
+	suite.mockSql.ExpectQuery("SELECT hold_id").WithArgs(1, "1").WillReturnRows(rows)
+	repo := NewHoldRepository(suite.mockDB)
+	res, err := repo.GetByID(1, "1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &h, res)
+}
+
+func (suite *HoldRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT hold_id").WithArgs(9, "1").WillReturnError(sql.ErrNoRows)
+	repo := NewHoldRepository(suite.mockDB)
+	res, err := repo.GetByID(9, "1")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "hold not found")
+}
+
+func (suite *HoldRepositoryTestSuite) TestGetHeldAmount_Success() {
+	suite.mockSql.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\), 0\\) FROM tx_hold").WithArgs("1", sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(45000))
+	repo := NewHoldRepository(suite.mockDB)
+	held, err := repo.GetHeldAmount("1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 45000, held)
+}
+
+func (suite *HoldRepositoryTestSuite) TestCapture_Success() {
+	hold := dummyHold
+	suite.mockSql.ExpectExec("UPDATE tx_hold SET status = 'Captured'").WithArgs(12000, hold.HoldID, hold.UserID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Hold Capture", today(), hold.UserID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(7))
+	suite.mockSql.ExpectExec("UPDATE tx_hold SET transaction_id").WithArgs(7, hold.HoldID).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewHoldRepository(suite.mockDB)
+	err := repo.Capture(&hold, 12000)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Captured", hold.Status)
+	assert.Equal(suite.T(), 12000, hold.CapturedAmount)
+	assert.Equal(suite.T(), 7, hold.TransactionID)
+}
+
+func (suite *HoldRepositoryTestSuite) TestCapture_NotActive() {
+	hold := dummyHold
+	suite.mockSql.ExpectExec("UPDATE tx_hold SET status = 'Captured'").WithArgs(hold.Amount, hold.HoldID, hold.UserID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewHoldRepository(suite.mockDB)
+	err := repo.Capture(&hold, hold.Amount)
+	assert.EqualError(suite.T(), err, "hold is no longer active")
+}
+
+func (suite *HoldRepositoryTestSuite) TestRelease_NotActive() {
+	suite.mockSql.ExpectExec("UPDATE tx_hold SET status = 'Released'").WithArgs(1, "1").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewHoldRepository(suite.mockDB)
+	err := repo.Release(1, "1")
+	assert.EqualError(suite.T(), err, "hold is no longer active")
+}
+
+func (suite *HoldRepositoryTestSuite) TestExpireAll_Success() {
+	now := time.Now()
+	suite.mockSql.ExpectExec("UPDATE tx_hold SET status = 'Expired'").WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 2))
+	repo := NewHoldRepository(suite.mockDB)
+	expired, err := repo.ExpireAll(now)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, expired)
+}
+
+func (suite *HoldRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *HoldRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestHoldRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(HoldRepositoryTestSuite))
+}
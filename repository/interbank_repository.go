@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type InterbankRepository interface {
+	CreateInquiry(inquiry *model.InterbankInquiry) error
+	GetInquiryByToken(token string) (*model.InterbankInquiry, error)
+	ClaimInquiry(token string, userID string) error
+	Create(tx *model.InterbankTransfer) error
+	GetDailyTotal(userID string) (int, error)
+	ReserveDailyAmount(userID string, amount int, limit int) error
+	ReleaseDailyAmount(userID string, amount int) error
+}
+
+type interbankRepository struct {
+	db *sql.DB
+}
+
+func (r *interbankRepository) CreateInquiry(inquiry *model.InterbankInquiry) error {
+	query := "INSERT INTO tx_interbank_inquiry (inquiry_token, user_id, bank_code, bank_name, account_number, account_holder_name, amount, fee, status, expired_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	_, err := r.db.Exec(query, inquiry.InquiryToken, inquiry.UserID, inquiry.BankCode, inquiry.BankName, inquiry.AccountNumber, inquiry.AccountHolderName, inquiry.Amount, inquiry.Fee, inquiry.Status, inquiry.ExpiredAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert interbank inquiry: %v", err)
+	}
+	return nil
+}
+
+func (r *interbankRepository) GetInquiryByToken(token string) (*model.InterbankInquiry, error) {
+	var inquiry model.InterbankInquiry
+	query := "SELECT inquiry_token, user_id, bank_code, bank_name, account_number, account_holder_name, amount, fee, status, expired_at FROM tx_interbank_inquiry WHERE inquiry_token = $1"
+	err := r.db.QueryRow(query, token).Scan(&inquiry.InquiryToken, &inquiry.UserID, &inquiry.BankCode, &inquiry.BankName, &inquiry.AccountNumber, &inquiry.AccountHolderName, &inquiry.Amount, &inquiry.Fee, &inquiry.Status, &inquiry.ExpiredAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("inquiry not found")
+		}
+		return nil, fmt.Errorf("failed to get interbank inquiry: %v", err)
+	}
+	inquiry.TotalAmount = inquiry.Amount + inquiry.Fee
+	return &inquiry, nil
+}
+
+func (r *interbankRepository) ClaimInquiry(token string, userID string) error {
+	query := "UPDATE tx_interbank_inquiry SET status = $1 WHERE inquiry_token = $2 AND user_id = $3 AND status = $4 AND expired_at > $5"
+	res, err := r.db.Exec(query, "Used", token, userID, "Pending", time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to claim interbank inquiry: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to claim interbank inquiry: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("inquiry expired or already used")
+	}
+	return nil
+}
+
+func (r *interbankRepository) Create(tx *model.InterbankTransfer) error {
+	today := time.Now().Format("2006-01-02")
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
+	_, err := r.db.Exec(query, "Interbank", today, tx.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_interbank (transaction_id, bank_code, bank_name, account_number, account_holder_name, amount, fee, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	_, err = r.db.Exec(query, txID, tx.BankCode, tx.BankName, tx.AccountNumber, tx.AccountHolderName, tx.Amount, tx.Fee, "Success")
+	if err != nil {
+		return fmt.Errorf("failed to insert interbank transfer: %v", err)
+	}
+
+	tx.TransactionID = txID
+	tx.TransactionType = "Interbank"
+	tx.TransactionDate = today
+	tx.Status = "Success"
+	return nil
+}
+
+// GetDailyTotal returns the interbank amount a user has reserved today.
+func (r *interbankRepository) GetDailyTotal(userID string) (int, error) {
+	var total int
+	query := "SELECT COALESCE((SELECT amount FROM tx_interbank_daily_usage WHERE user_id = $1 AND usage_date = $2), 0)"
+	err := r.db.QueryRow(query, userID, time.Now().Format("2006-01-02")).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get daily interbank total: %v", err)
+	}
+	return total, nil
+}
+
+// ReserveDailyAmount adds amount to the user's interbank total for today. The
+// update is guarded, so concurrent transfers can never go past limit.
+func (r *interbankRepository) ReserveDailyAmount(userID string, amount int, limit int) error {
+	query := `INSERT INTO tx_interbank_daily_usage (user_id, usage_date, amount) SELECT $1, $2, $3 WHERE $3 <= $4
+	ON CONFLICT (user_id, usage_date) DO UPDATE SET amount = tx_interbank_daily_usage.amount + EXCLUDED.amount
+	WHERE tx_interbank_daily_usage.amount + EXCLUDED.amount <= $4`
+	res, err := r.db.Exec(query, userID, time.Now().Format("2006-01-02"), amount, limit)
+	if err != nil {
+		return fmt.Errorf("failed to reserve daily interbank amount: %v", err)
+	}
+	return affectedOrError(res, "reserve daily interbank amount", "daily interbank limit exceeded")
+}
+
+// ReleaseDailyAmount gives back an amount reserved today for a transfer that
+// did not go through.
+func (r *interbankRepository) ReleaseDailyAmount(userID string, amount int) error {
+	query := "UPDATE tx_interbank_daily_usage SET amount = GREATEST(amount - $1, 0) WHERE user_id = $2 AND usage_date = $3"
+	_, err := r.db.Exec(query, amount, userID, time.Now().Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("failed to release daily interbank amount: %v", err)
+	}
+	return nil
+}
+
+func NewInterbankRepository(db *sql.DB) InterbankRepository {
+	return &interbankRepository{db: db}
+}
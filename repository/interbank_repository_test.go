@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyInterbankInquiry = model.InterbankInquiry{
+	InquiryToken:      "token-1",
+	UserID:            "1",
+	BankCode:          "014",
+	BankName:          "BCA",
+	AccountNumber:     "1234567890",
+	AccountHolderName: "name2",
+	Amount:            50000,
+	Fee:               6500,
+	TotalAmount:       56500,
+	Status:            "Pending",
+	ExpiredAt:         time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type InterbankRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *InterbankRepositoryTestSuite) TestCreateInquiry_Success() {
+	inquiry := dummyInterbankInquiry
+	suite.mockSql.ExpectExec("INSERT INTO tx_interbank_inquiry").WithArgs(inquiry.InquiryToken, inquiry.UserID, inquiry.BankCode, inquiry.BankName, inquiry.AccountNumber, inquiry.AccountHolderName, inquiry.Amount, inquiry.Fee, inquiry.Status, inquiry.ExpiredAt).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewInterbankRepository(suite.mockDB)
+	err := repo.CreateInquiry(&inquiry)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *InterbankRepositoryTestSuite) TestGetInquiryByToken_Success() {
+	inquiry := dummyInterbankInquiry
+	rows := sqlmock.NewRows([]string{"inquiry_token", "user_id", "bank_code", "bank_name", "account_number", "account_holder_name", "amount", "fee", "status", "expired_at"}).
+		AddRow(inquiry.InquiryToken, inquiry.UserID, inquiry.BankCode, inquiry.BankName, inquiry.AccountNumber, inquiry.AccountHolderName, inquiry.Amount, inquiry.Fee, inquiry.Status, inquiry.ExpiredAt)
+	suite.mockSql.ExpectQuery("SELECT inquiry_token").WithArgs(inquiry.InquiryToken).WillReturnRows(rows)
+	repo := NewInterbankRepository(suite.mockDB)
+	res, err := repo.GetInquiryByToken(inquiry.InquiryToken)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &inquiry, res)
+}
+
+func (suite *InterbankRepositoryTestSuite) TestGetInquiryByToken_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT inquiry_token").WithArgs("token-x").WillReturnError(sql.ErrNoRows)
+	repo := NewInterbankRepository(suite.mockDB)
+	res, err := repo.GetInquiryByToken("token-x")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "inquiry not found")
+}
+
+func (suite *InterbankRepositoryTestSuite) TestClaimInquiry_Success() {
+	suite.mockSql.ExpectExec("UPDATE tx_interbank_inquiry").WithArgs("Used", "token-1", "1", "Pending", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewInterbankRepository(suite.mockDB)
+	err := repo.ClaimInquiry("token-1", "1")
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *InterbankRepositoryTestSuite) TestClaimInquiry_AlreadyUsed() {
+	suite.mockSql.ExpectExec("UPDATE tx_interbank_inquiry").WithArgs("Used", "token-1", "1", "Pending", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewInterbankRepository(suite.mockDB)
+	err := repo.ClaimInquiry("token-1", "1")
+	assert.EqualError(suite.T(), err, "inquiry expired or already used")
+}
+
+func (suite *InterbankRepositoryTestSuite) TestCreate_Success() {
+	transfer := model.InterbankTransfer{UserID: "1", BankCode: "014", BankName: "BCA", AccountNumber: "1234567890", AccountHolderName: "name2", Amount: 50000, Fee: 6500}
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Interbank", time.Now().Format("2006-01-02"), transfer.UserID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(9))
+	suite.mockSql.ExpectExec("INSERT INTO tx_interbank").WithArgs(9, transfer.BankCode, transfer.BankName, transfer.AccountNumber, transfer.AccountHolderName, transfer.Amount, transfer.Fee, "Success").WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewInterbankRepository(suite.mockDB)
+	err := repo.Create(&transfer)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 9, transfer.TransactionID)
+	assert.Equal(suite.T(), "Success", transfer.Status)
+}
+
+func (suite *InterbankRepositoryTestSuite) TestCreate_Failed() {
+	transfer := model.InterbankTransfer{UserID: "1"}
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Interbank", time.Now().Format("2006-01-02"), transfer.UserID).WillReturnError(errors.New("failed"))
+	repo := NewInterbankRepository(suite.mockDB)
+	err := repo.Create(&transfer)
+	assert.EqualError(suite.T(), err, "failed to insert transaction: failed")
+}
+
+func (suite *InterbankRepositoryTestSuite) TestGetDailyTotal_Success() {
+	suite.mockSql.ExpectQuery("SELECT COALESCE").WithArgs("1", time.Now().Format("2006-01-02")).WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(75000))
+	repo := NewInterbankRepository(suite.mockDB)
+	total, err := repo.GetDailyTotal("1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 75000, total)
+}
+
+func (suite *InterbankRepositoryTestSuite) TestReserveDailyAmount_LimitExceeded() {
+	suite.mockSql.ExpectExec("INSERT INTO tx_interbank_daily_usage").WithArgs("1", time.Now().Format("2006-01-02"), 60000, 50000000).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewInterbankRepository(suite.mockDB)
+	err := repo.ReserveDailyAmount("1", 60000, 50000000)
+	assert.EqualError(suite.T(), err, "daily interbank limit exceeded")
+}
+
+func (suite *InterbankRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *InterbankRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestInterbankRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(InterbankRepositoryTestSuite))
+}
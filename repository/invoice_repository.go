@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type InvoiceRepository interface {
+	Create(invoice *model.Invoice) error
+	Update(invoice *model.Invoice) error
+	GetByID(invoiceID string) (*model.Invoice, error)
+	GetByMerchant(merchantID string) ([]*model.Invoice, error)
+	GetByCustomer(customerID string) ([]*model.Invoice, error)
+	Send(invoiceID string, merchantID string, sentAt time.Time) error
+	Void(invoiceID string, merchantID string) error
+	Claim(invoiceID string, customerID string) error
+	SetStatus(invoiceID string, status string) error
+	SetPaid(invoiceID string, transactionID int, paidAt time.Time) error
+	MarkOverdue(today string) (int, error)
+	GetReminderDue(before time.Time) ([]*model.Invoice, error)
+	SetReminded(invoiceID string, remindedAt time.Time) error
+}
+
+type invoiceRepository struct {
+	db *sql.DB
+}
+
+const invoiceQuery = `SELECT i.invoice_id, i.merchant_id, m.business_name, m.user_id, i.customer_id, u.name, u.phone_number, i.subtotal, i.tax_bps, i.tax_amount, i.total,
+	i.notes, CAST(i.due_date AS VARCHAR), i.status, COALESCE(i.transaction_id, 0), i.created_at, i.sent_at, i.paid_at, i.last_reminded_at
+FROM tx_invoice i
+JOIN mst_merchant m ON i.merchant_id = m.merchant_id
+JOIN mst_users u ON i.customer_id = u.user_id`
+
+func scanInvoice(scanner interface{ Scan(...interface{}) error }, invoice *model.Invoice) error {
+	return scanner.Scan(&invoice.InvoiceID, &invoice.MerchantID, &invoice.MerchantName, &invoice.MerchantUserID, &invoice.CustomerID, &invoice.CustomerName, &invoice.PhoneNumber,
+		&invoice.Subtotal, &invoice.TaxBps, &invoice.TaxAmount, &invoice.Total, &invoice.Notes, &invoice.DueDate, &invoice.Status, &invoice.TransactionID,
+		&invoice.CreatedAt, &invoice.SentAt, &invoice.PaidAt, &invoice.LastRemindedAt)
+}
+
+func (r *invoiceRepository) queryInvoices(query string, args ...interface{}) ([]*model.Invoice, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoices: %v", err)
+	}
+	defer rows.Close()
+
+	var invoices []*model.Invoice
+	for rows.Next() {
+		invoice := &model.Invoice{}
+		if err := scanInvoice(rows, invoice); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %v", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get invoices: %v", err)
+	}
+	return invoices, nil
+}
+
+func (r *invoiceRepository) insertItems(invoice *model.Invoice) error {
+	query := "INSERT INTO tx_invoice_item (invoice_id, description, quantity, unit_price, amount) VALUES ($1, $2, $3, $4, $5) RETURNING item_id"
+	for _, item := range invoice.Items {
+		err := r.db.QueryRow(query, invoice.InvoiceID, item.Description, item.Quantity, item.UnitPrice, item.Amount).Scan(&item.ItemID)
+		if err != nil {
+			return fmt.Errorf("failed to insert invoice item: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *invoiceRepository) Create(invoice *model.Invoice) error {
+	query := `INSERT INTO tx_invoice (invoice_id, merchant_id, customer_id, subtotal, tax_bps, tax_amount, total, notes, due_date, status, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.Exec(query, invoice.InvoiceID, invoice.MerchantID, invoice.CustomerID, invoice.Subtotal, invoice.TaxBps, invoice.TaxAmount, invoice.Total,
+		invoice.Notes, invoice.DueDate, invoice.Status, invoice.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create invoice: %v", err)
+	}
+	return r.insertItems(invoice)
+}
+
+// Update replaces the details and line items of a draft invoice.
+func (r *invoiceRepository) Update(invoice *model.Invoice) error {
+	query := `UPDATE tx_invoice SET customer_id = $1, subtotal = $2, tax_bps = $3, tax_amount = $4, total = $5, notes = $6, due_date = $7
+	WHERE invoice_id = $8 AND merchant_id = $9 AND status = 'Draft'`
+	res, err := r.db.Exec(query, invoice.CustomerID, invoice.Subtotal, invoice.TaxBps, invoice.TaxAmount, invoice.Total, invoice.Notes, invoice.DueDate,
+		invoice.InvoiceID, invoice.MerchantID)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice: %v", err)
+	}
+	if err := affectedOrError(res, "update invoice", "invoice is no longer a draft"); err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec("DELETE FROM tx_invoice_item WHERE invoice_id = $1", invoice.InvoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete invoice items: %v", err)
+	}
+	return r.insertItems(invoice)
+}
+
+func (r *invoiceRepository) GetByID(invoiceID string) (*model.Invoice, error) {
+	var invoice model.Invoice
+	if err := scanInvoice(r.db.QueryRow(invoiceQuery+" WHERE i.invoice_id = $1", invoiceID), &invoice); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, fmt.Errorf("failed to get invoice: %v", err)
+	}
+
+	query := "SELECT item_id, description, quantity, unit_price, amount FROM tx_invoice_item WHERE invoice_id = $1 ORDER BY item_id"
+	rows, err := r.db.Query(query, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice items: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := &model.InvoiceItem{}
+		if err := rows.Scan(&item.ItemID, &item.Description, &item.Quantity, &item.UnitPrice, &item.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice item: %v", err)
+		}
+		invoice.Items = append(invoice.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get invoice items: %v", err)
+	}
+	return &invoice, nil
+}
+
+// GetByMerchant lists a merchant's invoices without their line items.
+func (r *invoiceRepository) GetByMerchant(merchantID string) ([]*model.Invoice, error) {
+	return r.queryInvoices(invoiceQuery+" WHERE i.merchant_id = $1 ORDER BY i.created_at DESC", merchantID)
+}
+
+// GetByCustomer lists the invoices sent to a customer; drafts stay private to
+// the merchant.
+func (r *invoiceRepository) GetByCustomer(customerID string) ([]*model.Invoice, error) {
+	return r.queryInvoices(invoiceQuery+" WHERE i.customer_id = $1 AND i.status <> 'Draft' ORDER BY i.created_at DESC", customerID)
+}
+
+func (r *invoiceRepository) Send(invoiceID string, merchantID string, sentAt time.Time) error {
+	res, err := r.db.Exec("UPDATE tx_invoice SET status = 'Sent', sent_at = $1 WHERE invoice_id = $2 AND merchant_id = $3 AND status = 'Draft'", sentAt, invoiceID, merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to send invoice: %v", err)
+	}
+	return affectedOrError(res, "send invoice", "invoice is no longer a draft")
+}
+
+func (r *invoiceRepository) Void(invoiceID string, merchantID string) error {
+	query := "UPDATE tx_invoice SET status = 'Void' WHERE invoice_id = $1 AND merchant_id = $2 AND status IN ('Draft', 'Sent', 'Overdue')"
+	res, err := r.db.Exec(query, invoiceID, merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to void invoice: %v", err)
+	}
+	return affectedOrError(res, "void invoice", "invoice cannot be voided")
+}
+
+// Claim moves a sent or overdue invoice to Processing so it cannot be paid
+// or voided twice while the payment runs.
+func (r *invoiceRepository) Claim(invoiceID string, customerID string) error {
+	query := "UPDATE tx_invoice SET status = 'Processing' WHERE invoice_id = $1 AND customer_id = $2 AND status IN ('Sent', 'Overdue')"
+	res, err := r.db.Exec(query, invoiceID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to claim invoice: %v", err)
+	}
+	return affectedOrError(res, "claim invoice", "invoice is not payable")
+}
+
+func (r *invoiceRepository) SetStatus(invoiceID string, status string) error {
+	_, err := r.db.Exec("UPDATE tx_invoice SET status = $1 WHERE invoice_id = $2", status, invoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice status: %v", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) SetPaid(invoiceID string, transactionID int, paidAt time.Time) error {
+	_, err := r.db.Exec("UPDATE tx_invoice SET status = 'Paid', transaction_id = $1, paid_at = $2 WHERE invoice_id = $3", transactionID, paidAt, invoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice status: %v", err)
+	}
+	return nil
+}
+
+// MarkOverdue flags sent invoices whose due date is before today.
+func (r *invoiceRepository) MarkOverdue(today string) (int, error) {
+	res, err := r.db.Exec("UPDATE tx_invoice SET status = 'Overdue' WHERE status = 'Sent' AND due_date < $1", today)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark overdue invoices: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark overdue invoices: %v", err)
+	}
+	return int(affected), nil
+}
+
+// GetReminderDue returns overdue invoices that were never reminded or last
+// reminded before the given time.
+func (r *invoiceRepository) GetReminderDue(before time.Time) ([]*model.Invoice, error) {
+	query := invoiceQuery + " WHERE i.status = 'Overdue' AND (i.last_reminded_at IS NULL OR i.last_reminded_at < $1) ORDER BY i.due_date"
+	return r.queryInvoices(query, before)
+}
+
+func (r *invoiceRepository) SetReminded(invoiceID string, remindedAt time.Time) error {
+	_, err := r.db.Exec("UPDATE tx_invoice SET last_reminded_at = $1 WHERE invoice_id = $2", remindedAt, invoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice reminder: %v", err)
+	}
+	return nil
+}
+
+func NewInvoiceRepository(db *sql.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
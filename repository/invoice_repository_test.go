@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyInvoice = model.Invoice{
+	InvoiceID:      "INVABCDEFGH23",
+	MerchantID:     "MRC0123456789",
+	MerchantName:   "Warung Makan",
+	MerchantUserID: "1",
+	CustomerID:     "2",
+	CustomerName:   "name2",
+	PhoneNumber:    "08123",
+	Subtotal:       50000,
+	TaxBps:         1100,
+	TaxAmount:      5500,
+	Total:          55500,
+	DueDate:        "2026-05-01",
+	Status:         "Sent",
+	CreatedAt:      time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var invoiceColumns = []string{"invoice_id", "merchant_id", "business_name", "user_id", "customer_id", "name", "phone_number", "subtotal", "tax_bps", "tax_amount", "total",
+	"notes", "due_date", "status", "transaction_id", "created_at", "sent_at", "paid_at", "last_reminded_at"}
+
+func invoiceRow(rows *sqlmock.Rows, i model.Invoice) *sqlmock.Rows {
+	return rows.AddRow(i.InvoiceID, i.MerchantID, i.MerchantName, i.MerchantUserID, i.CustomerID, i.CustomerName, i.PhoneNumber, i.Subtotal, i.TaxBps, i.TaxAmount, i.Total,
+		i.Notes, i.DueDate, i.Status, i.TransactionID, i.CreatedAt, i.SentAt, i.PaidAt, i.LastRemindedAt)
+}
+
+type InvoiceRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *InvoiceRepositoryTestSuite) TestCreate_Success() {
+	invoice := dummyInvoice
+	invoice.Status = "Draft"
+	invoice.Items = []*model.InvoiceItem{{Description: "Nasi goreng", Quantity: 2, UnitPrice: 25000, Amount: 50000}}
+	suite.mockSql.ExpectExec("INSERT INTO tx_invoice").
+		WithArgs(invoice.InvoiceID, invoice.MerchantID, invoice.CustomerID, 50000, 1100, 5500, 55500, "", invoice.DueDate, "Draft", invoice.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("INSERT INTO tx_invoice_item").WithArgs(invoice.InvoiceID, "Nasi goreng", 2, 25000, 50000).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id"}).AddRow(7))
+	repo := NewInvoiceRepository(suite.mockDB)
+	err := repo.Create(&invoice)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 7, invoice.Items[0].ItemID)
+}
+
+func (suite *InvoiceRepositoryTestSuite) TestUpdate_NoLongerDraft() {
+	invoice := dummyInvoice
+	suite.mockSql.ExpectExec("UPDATE tx_invoice SET customer_id").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewInvoiceRepository(suite.mockDB)
+	err := repo.Update(&invoice)
+	assert.EqualError(suite.T(), err, "invoice is no longer a draft")
+}
+
+func (suite *InvoiceRepositoryTestSuite) TestGetByID_Success() {
+	expected := dummyInvoice
+	expected.Items = []*model.InvoiceItem{{ItemID: 7, Description: "Nasi goreng", Quantity: 2, UnitPrice: 25000, Amount: 50000}}
+	suite.mockSql.ExpectQuery("SELECT i.invoice_id").WithArgs(expected.InvoiceID).WillReturnRows(invoiceRow(sqlmock.NewRows(invoiceColumns), dummyInvoice))
+	suite.mockSql.ExpectQuery("SELECT item_id").WithArgs(expected.InvoiceID).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "description", "quantity", "unit_price", "amount"}).AddRow(7, "Nasi goreng", 2, 25000, 50000))
+	repo := NewInvoiceRepository(suite.mockDB)
+	res, err := repo.GetByID(expected.InvoiceID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &expected, res)
+}
+
+func (suite *InvoiceRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT i.invoice_id").WithArgs("INVX").WillReturnError(sql.ErrNoRows)
+	repo := NewInvoiceRepository(suite.mockDB)
+	res, err := repo.GetByID("INVX")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "invoice not found")
+}
+
+func (suite *InvoiceRepositoryTestSuite) TestGetByCustomer_Success() {
+	suite.mockSql.ExpectQuery("SELECT i.invoice_id(.+)WHERE i.customer_id = \\$1 AND i.status <> 'Draft'").WithArgs("2").
+		WillReturnRows(invoiceRow(sqlmock.NewRows(invoiceColumns), dummyInvoice))
+	repo := NewInvoiceRepository(suite.mockDB)
+	res, err := repo.GetByCustomer("2")
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "Warung Makan", res[0].MerchantName)
+}
+
+func (suite *InvoiceRepositoryTestSuite) TestSend_NoLongerDraft() {
+	now := time.Now()
+	suite.mockSql.ExpectExec("UPDATE tx_invoice SET status = 'Sent'").WithArgs(now, dummyInvoice.InvoiceID, dummyInvoice.MerchantID).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewInvoiceRepository(suite.mockDB)
+	err := repo.Send(dummyInvoice.InvoiceID, dummyInvoice.MerchantID, now)
+	assert.EqualError(suite.T(), err, "invoice is no longer a draft")
+}
+
+func (suite *InvoiceRepositoryTestSuite) TestClaim_NotPayable() {
+	suite.mockSql.ExpectExec("UPDATE tx_invoice SET status = 'Processing'").WithArgs(dummyInvoice.InvoiceID, "2").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewInvoiceRepository(suite.mockDB)
+	err := repo.Claim(dummyInvoice.InvoiceID, "2")
+	assert.EqualError(suite.T(), err, "invoice is not payable")
+}
+
+func (suite *InvoiceRepositoryTestSuite) TestMarkOverdue_Success() {
+	suite.mockSql.ExpectExec("UPDATE tx_invoice SET status = 'Overdue'").WithArgs("2026-05-02").WillReturnResult(sqlmock.NewResult(0, 3))
+	repo := NewInvoiceRepository(suite.mockDB)
+	res, err := repo.MarkOverdue("2026-05-02")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, res)
+}
+
+func (suite *InvoiceRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *InvoiceRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestInvoiceRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(InvoiceRepositoryTestSuite))
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type MerchantAPIRepository interface {
+	CreateKey(key *model.MerchantAPIKey) error
+	GetKey(keyID string) (*model.MerchantAPIKey, error)
+	GetKeys(merchantID string) ([]*model.MerchantAPIKey, error)
+	CountActiveKeys(merchantID string) (int, error)
+	RevokeKey(keyID string, merchantID string) error
+	UseNonce(keyID string, nonce string, now time.Time) error
+	PurgeNonces(before time.Time) (int, error)
+
+	CreateCharge(charge *model.MerchantCharge) error
+	GetCharge(chargeID string) (*model.MerchantCharge, error)
+	GetChargeByReference(merchantID string, reference string) (*model.MerchantCharge, error)
+	GetPendingCharges(userID string) ([]*model.MerchantCharge, error)
+	ClaimCharge(chargeID string, userID string) error
+	SetChargeResult(chargeID string, status string, transactionID int) error
+	DeclineCharge(chargeID string, userID string) error
+	Refund(charge *model.MerchantCharge, refund *model.ChargeRefund, merchantUserID string) error
+	ExpireCharges(now time.Time) (int, error)
+}
+
+type merchantAPIRepository struct {
+	db *sql.DB
+}
+
+const chargeQuery = `SELECT c.charge_id, c.merchant_id, m.business_name, c.reference, c.user_id, u.phone_number, c.amount, c.refunded_amount,
+	c.description, c.status, c.expired_at, c.created_at, COALESCE(c.transaction_id, 0)
+FROM tx_merchant_charge c
+JOIN mst_merchant m ON c.merchant_id = m.merchant_id
+JOIN mst_users u ON c.user_id = u.user_id`
+
+func scanCharge(scanner interface{ Scan(...interface{}) error }, charge *model.MerchantCharge) error {
+	return scanner.Scan(&charge.ChargeID, &charge.MerchantID, &charge.MerchantName, &charge.Reference, &charge.UserID, &charge.PhoneNumber, &charge.Amount, &charge.RefundedAmount,
+		&charge.Description, &charge.Status, &charge.ExpiredAt, &charge.CreatedAt, &charge.TransactionID)
+}
+
+// affectedOrError turns an UPDATE that touched no rows into notFound.
+func affectedOrError(res sql.Result, action string, notFound string) error {
+	return affectedOr(res, action, errors.New(notFound))
+}
+
+// affectedOr is affectedOrError for outcomes callers check with errors.Is.
+func affectedOr(res sql.Result, action string, notAffected error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to %s: %v", action, err)
+	}
+	if affected == 0 {
+		return notAffected
+	}
+	return nil
+}
+
+func (r *merchantAPIRepository) CreateKey(key *model.MerchantAPIKey) error {
+	query := "INSERT INTO mst_merchant_api_key (key_id, merchant_id, secret, status, created_at) VALUES ($1, $2, $3, $4, $5)"
+	_, err := r.db.Exec(query, key.KeyID, key.MerchantID, key.Secret, key.Status, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %v", err)
+	}
+	return nil
+}
+
+func (r *merchantAPIRepository) GetKey(keyID string) (*model.MerchantAPIKey, error) {
+	var key model.MerchantAPIKey
+	query := "SELECT key_id, merchant_id, secret, status, created_at, revoked_at FROM mst_merchant_api_key WHERE key_id = $1"
+	err := r.db.QueryRow(query, keyID).Scan(&key.KeyID, &key.MerchantID, &key.Secret, &key.Status, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("api key not found")
+		}
+		return nil, fmt.Errorf("failed to get api key: %v", err)
+	}
+	return &key, nil
+}
+
+// GetKeys lists a merchant's keys without their secrets.
+func (r *merchantAPIRepository) GetKeys(merchantID string) ([]*model.MerchantAPIKey, error) {
+	query := "SELECT key_id, merchant_id, status, created_at, revoked_at FROM mst_merchant_api_key WHERE merchant_id = $1 ORDER BY created_at DESC"
+	rows, err := r.db.Query(query, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []*model.MerchantAPIKey
+	for rows.Next() {
+		key := &model.MerchantAPIKey{}
+		if err := rows.Scan(&key.KeyID, &key.MerchantID, &key.Status, &key.CreatedAt, &key.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %v", err)
+	}
+	return keys, nil
+}
+
+func (r *merchantAPIRepository) CountActiveKeys(merchantID string) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM mst_merchant_api_key WHERE merchant_id = $1 AND status = 'Active'", merchantID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %v", err)
+	}
+	return count, nil
+}
+
+func (r *merchantAPIRepository) RevokeKey(keyID string, merchantID string) error {
+	query := "UPDATE mst_merchant_api_key SET status = 'Revoked', revoked_at = $1 WHERE key_id = $2 AND merchant_id = $3 AND status = 'Active'"
+	res, err := r.db.Exec(query, time.Now(), keyID, merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %v", err)
+	}
+	return affectedOrError(res, "revoke api key", "api key not found")
+}
+
+func (r *merchantAPIRepository) CreateCharge(charge *model.MerchantCharge) error {
+	query := "INSERT INTO tx_merchant_charge (charge_id, merchant_id, reference, user_id, amount, refunded_amount, description, status, expired_at, created_at) VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $8, $9)"
+	_, err := r.db.Exec(query, charge.ChargeID, charge.MerchantID, charge.Reference, charge.UserID, charge.Amount, charge.Description, charge.Status, charge.ExpiredAt, charge.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create charge: %v", err)
+	}
+	return nil
+}
+
+func (r *merchantAPIRepository) GetCharge(chargeID string) (*model.MerchantCharge, error) {
+	var charge model.MerchantCharge
+	if err := scanCharge(r.db.QueryRow(chargeQuery+" WHERE c.charge_id = $1", chargeID), &charge); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("charge not found")
+		}
+		return nil, fmt.Errorf("failed to get charge: %v", err)
+	}
+	return &charge, nil
+}
+
+func (r *merchantAPIRepository) GetChargeByReference(merchantID string, reference string) (*model.MerchantCharge, error) {
+	var charge model.MerchantCharge
+	if err := scanCharge(r.db.QueryRow(chargeQuery+" WHERE c.merchant_id = $1 AND c.reference = $2", merchantID, reference), &charge); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("charge not found")
+		}
+		return nil, fmt.Errorf("failed to get charge: %v", err)
+	}
+	return &charge, nil
+}
+
+func (r *merchantAPIRepository) GetPendingCharges(userID string) ([]*model.MerchantCharge, error) {
+	rows, err := r.db.Query(chargeQuery+" WHERE c.user_id = $1 AND c.status = 'Pending' AND c.expired_at > $2 ORDER BY c.created_at DESC", userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get charges: %v", err)
+	}
+	defer rows.Close()
+
+	var charges []*model.MerchantCharge
+	for rows.Next() {
+		charge := &model.MerchantCharge{}
+		if err := scanCharge(rows, charge); err != nil {
+			return nil, fmt.Errorf("failed to scan charge: %v", err)
+		}
+		charges = append(charges, charge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get charges: %v", err)
+	}
+	return charges, nil
+}
+
+// ClaimCharge moves a pending charge to Processing so it can only be paid once.
+func (r *merchantAPIRepository) ClaimCharge(chargeID string, userID string) error {
+	query := "UPDATE tx_merchant_charge SET status = 'Processing' WHERE charge_id = $1 AND user_id = $2 AND status = 'Pending' AND expired_at > $3"
+	res, err := r.db.Exec(query, chargeID, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to claim charge: %v", err)
+	}
+	return affectedOrError(res, "claim charge", "charge is no longer pending")
+}
+
+func (r *merchantAPIRepository) SetChargeResult(chargeID string, status string, transactionID int) error {
+	_, err := r.db.Exec("UPDATE tx_merchant_charge SET status = $1, transaction_id = NULLIF($2, 0) WHERE charge_id = $3", status, transactionID, chargeID)
+	if err != nil {
+		return fmt.Errorf("failed to update charge: %v", err)
+	}
+	return nil
+}
+
+func (r *merchantAPIRepository) DeclineCharge(chargeID string, userID string) error {
+	res, err := r.db.Exec("UPDATE tx_merchant_charge SET status = 'Declined' WHERE charge_id = $1 AND user_id = $2 AND status = 'Pending'", chargeID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to decline charge: %v", err)
+	}
+	return affectedOrError(res, "decline charge", "charge is no longer pending")
+}
+
+// Refund adds to the refunded amount of a paid charge and records the money
+// going back from the merchant to the user.
+func (r *merchantAPIRepository) Refund(charge *model.MerchantCharge, refund *model.ChargeRefund, merchantUserID string) error {
+	query := `UPDATE tx_merchant_charge SET refunded_amount = refunded_amount + $1,
+		status = CASE WHEN refunded_amount + $1 = amount THEN 'Refunded' ELSE 'Partially Refunded' END
+	WHERE charge_id = $2 AND status IN ('Success', 'Partially Refunded') AND refunded_amount + $1 <= amount`
+	res, err := r.db.Exec(query, refund.Amount, charge.ChargeID)
+	if err != nil {
+		return fmt.Errorf("failed to refund charge: %v", err)
+	}
+	if err := affectedOrError(res, "refund charge", "refund exceeds charged amount"); err != nil {
+		return err
+	}
+
+	query = "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id, recipient_id) VALUES ($1, $2, $3, $4)"
+	_, err = r.db.Exec(query, "Merchant Refund", today(), merchantUserID, charge.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	_, err = r.db.Exec("INSERT INTO tx_merchant_refund (transaction_id, charge_id, amount) VALUES ($1, $2, $3)", txID, charge.ChargeID, refund.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to insert merchant refund: %v", err)
+	}
+
+	refund.ChargeID = charge.ChargeID
+	refund.TransactionID = txID
+	return nil
+}
+
+func (r *merchantAPIRepository) ExpireCharges(now time.Time) (int, error) {
+	res, err := r.db.Exec("UPDATE tx_merchant_charge SET status = 'Expired' WHERE status = 'Pending' AND expired_at <= $1", now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire charges: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire charges: %v", err)
+	}
+	return int(affected), nil
+}
+
+// UseNonce records the nonce of a signed request. A nonce can only be used
+// once per key, so a captured request cannot be replayed.
+func (r *merchantAPIRepository) UseNonce(keyID string, nonce string, now time.Time) error {
+	query := "INSERT INTO tx_merchant_api_nonce (key_id, nonce, used_at) VALUES ($1, $2, $3) ON CONFLICT (key_id, nonce) DO NOTHING"
+	res, err := r.db.Exec(query, keyID, nonce, now)
+	if err != nil {
+		return fmt.Errorf("failed to use nonce: %v", err)
+	}
+	return affectedOrError(res, "use nonce", "nonce already used")
+}
+
+// PurgeNonces deletes nonces used before the given time, once their requests
+// are too old to pass the timestamp check anyway.
+func (r *merchantAPIRepository) PurgeNonces(before time.Time) (int, error) {
+	res, err := r.db.Exec("DELETE FROM tx_merchant_api_nonce WHERE used_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge nonces: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge nonces: %v", err)
+	}
+	return int(affected), nil
+}
+
+func NewMerchantAPIRepository(db *sql.DB) MerchantAPIRepository {
+	return &merchantAPIRepository{db: db}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyCharge = model.MerchantCharge{
+	ChargeID:     "ch_1",
+	MerchantID:   "MRC0123456789",
+	MerchantName: "Warung Makan",
+	Reference:    "INV-1",
+	UserID:       "2",
+	PhoneNumber:  "08222222222",
+	Amount:       40000,
+	Description:  "order 1",
+	Status:       "Success",
+	ExpiredAt:    time.Date(2026, 4, 1, 0, 15, 0, 0, time.UTC),
+	CreatedAt:    time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type MerchantAPIRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) TestGetKey_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT key_id, merchant_id, secret").WithArgs("pk_x").WillReturnError(sql.ErrNoRows)
+	repo := NewMerchantAPIRepository(suite.mockDB)
+	res, err := repo.GetKey("pk_x")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "api key not found")
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) TestRevokeKey_Success() {
+	suite.mockSql.ExpectExec("UPDATE mst_merchant_api_key SET status = 'Revoked'").WithArgs(sqlmock.AnyArg(), "pk_1", "MRC0123456789").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewMerchantAPIRepository(suite.mockDB)
+	err := repo.RevokeKey("pk_1", "MRC0123456789")
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) TestGetCharge_Success() {
+	c := dummyCharge
+	rows := sqlmock.NewRows([]string{"charge_id", "merchant_id", "business_name", "reference", "user_id", "phone_number", "amount", "refunded_amount", "description", "status", "expired_at", "created_at", "transaction_id"}).
+		AddRow(c.ChargeID, c.MerchantID, c.MerchantName, c.Reference, c.UserID, c.PhoneNumber, c.Amount, c.RefundedAmount, c.Description, c.Status, c.ExpiredAt, c.CreatedAt, c.TransactionID)
+	suite.mockSql.ExpectQuery("SELECT c.charge_id").WithArgs("ch_1").WillReturnRows(rows)
+	repo := NewMerchantAPIRepository(suite.mockDB)
+	res, err := repo.GetCharge("ch_1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &c, res)
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) TestClaimCharge_NoLongerPending() {
+	suite.mockSql.ExpectExec("UPDATE tx_merchant_charge SET status = 'Processing'").WithArgs("ch_1", "2", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewMerchantAPIRepository(suite.mockDB)
+	err := repo.ClaimCharge("ch_1", "2")
+	assert.EqualError(suite.T(), err, "charge is no longer pending")
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) TestRefund_Success() {
+	charge := dummyCharge
+	refund := model.ChargeRefund{Amount: 15000}
+	suite.mockSql.ExpectExec("UPDATE tx_merchant_charge SET refunded_amount").WithArgs(refund.Amount, charge.ChargeID).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Merchant Refund", today(), "1", charge.UserID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(21))
+	suite.mockSql.ExpectExec("INSERT INTO tx_merchant_refund").WithArgs(21, charge.ChargeID, refund.Amount).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewMerchantAPIRepository(suite.mockDB)
+	err := repo.Refund(&charge, &refund, "1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 21, refund.TransactionID)
+	assert.Equal(suite.T(), charge.ChargeID, refund.ChargeID)
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) TestRefund_Exceeds() {
+	charge := dummyCharge
+	refund := model.ChargeRefund{Amount: 50000}
+	suite.mockSql.ExpectExec("UPDATE tx_merchant_charge SET refunded_amount").WithArgs(refund.Amount, charge.ChargeID).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewMerchantAPIRepository(suite.mockDB)
+	err := repo.Refund(&charge, &refund, "1")
+	assert.EqualError(suite.T(), err, "refund exceeds charged amount")
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) TestExpireCharges_Success() {
+	now := time.Now()
+	suite.mockSql.ExpectExec("UPDATE tx_merchant_charge SET status = 'Expired'").WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 3))
+	repo := NewMerchantAPIRepository(suite.mockDB)
+	expired, err := repo.ExpireCharges(now)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, expired)
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) TestUseNonce_Replayed() {
+	now := time.Now()
+	suite.mockSql.ExpectExec("INSERT INTO tx_merchant_api_nonce").WithArgs("pk_test", "n-1", now).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewMerchantAPIRepository(suite.mockDB)
+	err := repo.UseNonce("pk_test", "n-1", now)
+	assert.EqualError(suite.T(), err, "nonce already used")
+}
+
+func (suite *MerchantAPIRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *MerchantAPIRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestMerchantAPIRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MerchantAPIRepositoryTestSuite))
+}
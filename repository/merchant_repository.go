@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type MerchantRepository interface {
+	Create(merchant *model.Merchant) error
+	GetByID(merchantID string) (*model.Merchant, error)
+	GetByUserID(userID string) (*model.Merchant, error)
+	Update(merchant *model.Merchant) error
+	UpdateMDR(merchantID string, mdrBps int) error
+	CreatePayment(payment *model.MerchantPayment) error
+	GetPayments(merchantID string) ([]*model.MerchantPayment, error)
+	GetSettlements(merchantID string, from string, to string) ([]*model.MerchantSettlement, error)
+}
+
+type merchantRepository struct {
+	db *sql.DB
+}
+
+const merchantQuery = `SELECT m.merchant_id, m.user_id, m.business_name, m.business_category, m.business_address,
+	m.settlement_account_id, COALESCE(b.bank_name, ''), COALESCE(b.account_number, ''), m.mdr_bps, m.created_at
+FROM mst_merchant m
+LEFT JOIN mst_bank_account b ON m.settlement_account_id = b.account_id`
+
+func (r *merchantRepository) getOne(where string, arg string) (*model.Merchant, error) {
+	var merchant model.Merchant
+	err := r.db.QueryRow(merchantQuery+" WHERE "+where, arg).Scan(&merchant.MerchantID, &merchant.UserID, &merchant.BusinessName, &merchant.BusinessCategory, &merchant.BusinessAddress,
+		&merchant.SettlementAccountID, &merchant.SettlementBankName, &merchant.SettlementAccountNumber, &merchant.MDRBps, &merchant.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("merchant not found")
+		}
+		return nil, fmt.Errorf("failed to get merchant: %v", err)
+	}
+	return &merchant, nil
+}
+
+func (r *merchantRepository) Create(merchant *model.Merchant) error {
+	query := "INSERT INTO mst_merchant (merchant_id, user_id, business_name, business_category, business_address, settlement_account_id, mdr_bps, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	_, err := r.db.Exec(query, merchant.MerchantID, merchant.UserID, merchant.BusinessName, merchant.BusinessCategory, merchant.BusinessAddress, merchant.SettlementAccountID, merchant.MDRBps, merchant.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create merchant: %v", err)
+	}
+
+	_, err = r.db.Exec("UPDATE mst_users SET role = 'merchant' WHERE user_id = $1", merchant.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %v", err)
+	}
+	return nil
+}
+
+func (r *merchantRepository) GetByID(merchantID string) (*model.Merchant, error) {
+	return r.getOne("m.merchant_id = $1", merchantID)
+}
+
+func (r *merchantRepository) GetByUserID(userID string) (*model.Merchant, error) {
+	return r.getOne("m.user_id = $1", userID)
+}
+
+func (r *merchantRepository) Update(merchant *model.Merchant) error {
+	query := "UPDATE mst_merchant SET business_name = $1, business_category = $2, business_address = $3, settlement_account_id = $4 WHERE merchant_id = $5"
+	res, err := r.db.Exec(query, merchant.BusinessName, merchant.BusinessCategory, merchant.BusinessAddress, merchant.SettlementAccountID, merchant.MerchantID)
+	if err != nil {
+		return fmt.Errorf("failed to update merchant: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update merchant: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("merchant not found")
+	}
+	return nil
+}
+
+func (r *merchantRepository) UpdateMDR(merchantID string, mdrBps int) error {
+	res, err := r.db.Exec("UPDATE mst_merchant SET mdr_bps = $1 WHERE merchant_id = $2", mdrBps, merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to update merchant mdr: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update merchant mdr: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("merchant not found")
+	}
+	return nil
+}
+
+func (r *merchantRepository) CreatePayment(payment *model.MerchantPayment) error {
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id, recipient_id) VALUES ($1, $2, $3, $4)"
+	_, err := r.db.Exec(query, "Merchant Payment", today(), payment.PayerID, payment.MerchantUserID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_merchant_payment (transaction_id, merchant_id, payer_id, amount, mdr_amount, net_amount, note, paid_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	_, err = r.db.Exec(query, txID, payment.MerchantID, payment.PayerID, payment.Amount, payment.MDRAmount, payment.NetAmount, payment.Note, payment.PaidAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert merchant payment: %v", err)
+	}
+
+	payment.TransactionID = txID
+	return nil
+}
+
+func (r *merchantRepository) GetPayments(merchantID string) ([]*model.MerchantPayment, error) {
+	query := `SELECT p.transaction_id, p.merchant_id, m.business_name, p.payer_id, u.name, p.amount, p.mdr_amount, p.net_amount, p.note, p.paid_at
+	FROM tx_merchant_payment p
+	JOIN mst_merchant m ON p.merchant_id = m.merchant_id
+	JOIN mst_users u ON p.payer_id = u.user_id
+	WHERE p.merchant_id = $1
+	ORDER BY p.transaction_id DESC`
+	rows, err := r.db.Query(query, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant payments: %v", err)
+	}
+	defer rows.Close()
+
+	var payments []*model.MerchantPayment
+	for rows.Next() {
+		payment := &model.MerchantPayment{}
+		err := rows.Scan(&payment.TransactionID, &payment.MerchantID, &payment.MerchantName, &payment.PayerID, &payment.PayerName, &payment.Amount, &payment.MDRAmount, &payment.NetAmount, &payment.Note, &payment.PaidAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan merchant payment: %v", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get merchant payments: %v", err)
+	}
+	return payments, nil
+}
+
+// GetSettlements groups payments per day between from and to (inclusive,
+// YYYY-MM-DD), newest day first.
+func (r *merchantRepository) GetSettlements(merchantID string, from string, to string) ([]*model.MerchantSettlement, error) {
+	query := `SELECT CAST(DATE(paid_at) AS VARCHAR), COUNT(*), SUM(amount), SUM(mdr_amount), SUM(net_amount)
+	FROM tx_merchant_payment
+	WHERE merchant_id = $1 AND DATE(paid_at) BETWEEN $2::DATE AND $3::DATE
+	GROUP BY DATE(paid_at)
+	ORDER BY DATE(paid_at) DESC`
+	rows, err := r.db.Query(query, merchantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant settlements: %v", err)
+	}
+	defer rows.Close()
+
+	var settlements []*model.MerchantSettlement
+	for rows.Next() {
+		settlement := &model.MerchantSettlement{MerchantID: merchantID}
+		err := rows.Scan(&settlement.Date, &settlement.TransactionCount, &settlement.GrossAmount, &settlement.MDRAmount, &settlement.NetAmount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan merchant settlement: %v", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get merchant settlements: %v", err)
+	}
+	return settlements, nil
+}
+
+func NewMerchantRepository(db *sql.DB) MerchantRepository {
+	return &merchantRepository{db: db}
+}
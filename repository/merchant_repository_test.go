@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyMerchant = model.Merchant{
+	MerchantID:              "MRC0123456789",
+	UserID:                  "1",
+	BusinessName:            "Warung Makan",
+	BusinessCategory:        "Food",
+	BusinessAddress:         "Jl. Merdeka 1",
+	SettlementAccountID:     3,
+	SettlementBankName:      "BCA",
+	SettlementAccountNumber: "1234567890",
+	MDRBps:                  70,
+	CreatedAt:               time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type MerchantRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *MerchantRepositoryTestSuite) TestCreate_Success() {
+	m := dummyMerchant
+	suite.mockSql.ExpectExec("INSERT INTO mst_merchant").WithArgs(m.MerchantID, m.UserID, m.BusinessName, m.BusinessCategory, m.BusinessAddress, m.SettlementAccountID, m.MDRBps, m.CreatedAt).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_users SET role = 'merchant'").WithArgs(m.UserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewMerchantRepository(suite.mockDB)
+	err := repo.Create(&m)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *MerchantRepositoryTestSuite) TestGetByUserID_Success() {
+	m := dummyMerchant
+	rows := sqlmock.NewRows([]string{"merchant_id", "user_id", "business_name", "business_category", "business_address", "settlement_account_id", "bank_name", "account_number", "mdr_bps", "created_at"}).
+		AddRow(m.MerchantID, m.UserID, m.BusinessName, m.BusinessCategory, m.BusinessAddress, m.SettlementAccountID, m.SettlementBankName, m.SettlementAccountNumber, m.MDRBps, m.CreatedAt)
+	suite.mockSql.ExpectQuery("SELECT m.merchant_id").WithArgs("1").WillReturnRows(rows)
+	repo := NewMerchantRepository(suite.mockDB)
+	res, err := repo.GetByUserID("1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &m, res)
+}
+
+func (suite *MerchantRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT m.merchant_id").WithArgs("MRC0").WillReturnError(sql.ErrNoRows)
+	repo := NewMerchantRepository(suite.mockDB)
+	res, err := repo.GetByID("MRC0")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "merchant not found")
+}
+
+func (suite *MerchantRepositoryTestSuite) TestUpdateMDR_NotFound() {
+	suite.mockSql.ExpectExec("UPDATE mst_merchant SET mdr_bps").WithArgs(100, "MRC0").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewMerchantRepository(suite.mockDB)
+	err := repo.UpdateMDR("MRC0", 100)
+	assert.EqualError(suite.T(), err, "merchant not found")
+}
+
+func (suite *MerchantRepositoryTestSuite) TestCreatePayment_Success() {
+	payment := model.MerchantPayment{MerchantID: dummyMerchant.MerchantID, MerchantUserID: "1", PayerID: "2", Amount: 50000, MDRAmount: 350, NetAmount: 49650, Note: "kopi", PaidAt: time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)}
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Merchant Payment", today(), payment.PayerID, payment.MerchantUserID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(12))
+	suite.mockSql.ExpectExec("INSERT INTO tx_merchant_payment").WithArgs(12, payment.MerchantID, payment.PayerID, payment.Amount, payment.MDRAmount, payment.NetAmount, payment.Note, payment.PaidAt).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewMerchantRepository(suite.mockDB)
+	err := repo.CreatePayment(&payment)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 12, payment.TransactionID)
+}
+
+func (suite *MerchantRepositoryTestSuite) TestGetSettlements_Success() {
+	rows := sqlmock.NewRows([]string{"date", "count", "gross", "mdr", "net"}).
+		AddRow("2026-04-01", 2, 80000, 560, 79440)
+	suite.mockSql.ExpectQuery("SELECT CAST\\(DATE\\(paid_at\\) AS VARCHAR\\)").WithArgs(dummyMerchant.MerchantID, "2026-03-26", "2026-04-01").WillReturnRows(rows)
+	repo := NewMerchantRepository(suite.mockDB)
+	res, err := repo.GetSettlements(dummyMerchant.MerchantID, "2026-03-26", "2026-04-01")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.MerchantSettlement{{MerchantID: dummyMerchant.MerchantID, Date: "2026-04-01", TransactionCount: 2, GrossAmount: 80000, MDRAmount: 560, NetAmount: 79440}}, res)
+}
+
+func (suite *MerchantRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *MerchantRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestMerchantRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MerchantRepositoryTestSuite))
+}
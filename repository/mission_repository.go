@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+// ErrMissionCompleted is returned by AddProgress and Complete once the user
+// has completed the mission.
+var ErrMissionCompleted = errors.New("mission already completed")
+
+type MissionRepository interface {
+	GetAll() ([]*model.Mission, error)
+	GetByID(missionID int) (*model.Mission, error)
+	GetActive(at time.Time) ([]*model.Mission, error)
+	Create(mission *model.Mission) error
+	Update(mission *model.Mission) error
+	Deactivate(missionID int) error
+	GetProgress(userID string, at time.Time) ([]*model.MissionProgress, error)
+	AddProgress(missionID int, userID string, delta int, at time.Time) (int, error)
+	Complete(missionID int, userID string, at time.Time) error
+}
+
+type missionRepository struct {
+	db *sql.DB
+}
+
+const missionColumns = "m.mission_id, m.title, m.description, m.transaction_type, m.metric, m.target, m.min_amount, m.reward_points, m.starts_at, m.ends_at, m.active, m.created_at"
+
+func scanMission(scanner interface{ Scan(...interface{}) error }, mission *model.Mission, extra ...interface{}) error {
+	dest := []interface{}{&mission.MissionID, &mission.Title, &mission.Description, &mission.TransactionType, &mission.Metric, &mission.Target,
+		&mission.MinAmount, &mission.RewardPoints, &mission.StartsAt, &mission.EndsAt, &mission.Active, &mission.CreatedAt}
+	return scanner.Scan(append(dest, extra...)...)
+}
+
+func (r *missionRepository) queryMissions(query string, args ...interface{}) ([]*model.Mission, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get missions: %v", err)
+	}
+	defer rows.Close()
+
+	var missions []*model.Mission
+	for rows.Next() {
+		mission := &model.Mission{}
+		if err := scanMission(rows, mission); err != nil {
+			return nil, fmt.Errorf("failed to scan mission: %v", err)
+		}
+		missions = append(missions, mission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get missions: %v", err)
+	}
+	return missions, nil
+}
+
+func (r *missionRepository) GetAll() ([]*model.Mission, error) {
+	return r.queryMissions("SELECT " + missionColumns + " FROM mst_mission m ORDER BY m.starts_at DESC, m.mission_id DESC")
+}
+
+func (r *missionRepository) GetByID(missionID int) (*model.Mission, error) {
+	var mission model.Mission
+	if err := scanMission(r.db.QueryRow("SELECT "+missionColumns+" FROM mst_mission m WHERE m.mission_id = $1", missionID), &mission); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("mission not found")
+		}
+		return nil, fmt.Errorf("failed to get mission: %v", err)
+	}
+	return &mission, nil
+}
+
+// GetActive returns the active missions whose window contains the given time.
+func (r *missionRepository) GetActive(at time.Time) ([]*model.Mission, error) {
+	query := "SELECT " + missionColumns + ` FROM mst_mission m
+	WHERE m.active = TRUE AND m.starts_at <= $1 AND m.ends_at > $1 ORDER BY m.ends_at, m.mission_id`
+	return r.queryMissions(query, at)
+}
+
+func (r *missionRepository) Create(mission *model.Mission) error {
+	query := `INSERT INTO mst_mission (title, description, transaction_type, metric, target, min_amount, reward_points, starts_at, ends_at, active, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING mission_id`
+	err := r.db.QueryRow(query, mission.Title, mission.Description, mission.TransactionType, mission.Metric, mission.Target, mission.MinAmount,
+		mission.RewardPoints, mission.StartsAt, mission.EndsAt, mission.Active, mission.CreatedAt).Scan(&mission.MissionID)
+	if err != nil {
+		return fmt.Errorf("failed to create mission: %v", err)
+	}
+	return nil
+}
+
+func (r *missionRepository) Update(mission *model.Mission) error {
+	query := `UPDATE mst_mission SET title = $1, description = $2, transaction_type = $3, metric = $4, target = $5, min_amount = $6,
+	reward_points = $7, starts_at = $8, ends_at = $9, active = $10 WHERE mission_id = $11`
+	res, err := r.db.Exec(query, mission.Title, mission.Description, mission.TransactionType, mission.Metric, mission.Target, mission.MinAmount,
+		mission.RewardPoints, mission.StartsAt, mission.EndsAt, mission.Active, mission.MissionID)
+	if err != nil {
+		return fmt.Errorf("failed to update mission: %v", err)
+	}
+	return affectedOrError(res, "update mission", "mission not found")
+}
+
+func (r *missionRepository) Deactivate(missionID int) error {
+	res, err := r.db.Exec("UPDATE mst_mission SET active = FALSE WHERE mission_id = $1", missionID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate mission: %v", err)
+	}
+	return affectedOrError(res, "deactivate mission", "mission not found")
+}
+
+// GetProgress returns the active missions together with the user's progress
+// in each of them.
+func (r *missionRepository) GetProgress(userID string, at time.Time) ([]*model.MissionProgress, error) {
+	query := "SELECT " + missionColumns + `, COALESCE(p.progress, 0), p.completed_at FROM mst_mission m
+	LEFT JOIN tx_mission_progress p ON p.mission_id = m.mission_id AND p.user_id = $1
+	WHERE m.active = TRUE AND m.starts_at <= $2 AND m.ends_at > $2 ORDER BY m.ends_at, m.mission_id`
+	rows, err := r.db.Query(query, userID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mission progress: %v", err)
+	}
+	defer rows.Close()
+
+	var progress []*model.MissionProgress
+	for rows.Next() {
+		p := &model.MissionProgress{}
+		if err := scanMission(rows, &p.Mission, &p.Progress, &p.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mission progress: %v", err)
+		}
+		p.Completed = p.CompletedAt != nil
+		progress = append(progress, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get mission progress: %v", err)
+	}
+	return progress, nil
+}
+
+// AddProgress adds delta to the user's progress in a mission and returns the
+// new total. Completed missions no longer move.
+func (r *missionRepository) AddProgress(missionID int, userID string, delta int, at time.Time) (int, error) {
+	query := `INSERT INTO tx_mission_progress (mission_id, user_id, progress, updated_at) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (mission_id, user_id) DO UPDATE SET progress = tx_mission_progress.progress + EXCLUDED.progress, updated_at = EXCLUDED.updated_at
+	WHERE tx_mission_progress.completed_at IS NULL RETURNING progress`
+	var progress int
+	err := r.db.QueryRow(query, missionID, userID, delta, at).Scan(&progress)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrMissionCompleted
+		}
+		return 0, fmt.Errorf("failed to update mission progress: %v", err)
+	}
+	return progress, nil
+}
+
+// Complete marks a mission as completed for a user. Only one caller can
+// complete it, so the reward is never granted twice.
+func (r *missionRepository) Complete(missionID int, userID string, at time.Time) error {
+	res, err := r.db.Exec("UPDATE tx_mission_progress SET completed_at = $1 WHERE mission_id = $2 AND user_id = $3 AND completed_at IS NULL", at, missionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to complete mission: %v", err)
+	}
+	return affectedOr(res, "complete mission", ErrMissionCompleted)
+}
+
+func NewMissionRepository(db *sql.DB) MissionRepository {
+	return &missionRepository{db: db}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var missionTestColumns = []string{"mission_id", "title", "description", "transaction_type", "metric", "target", "min_amount", "reward_points", "starts_at", "ends_at", "active", "created_at"}
+
+type MissionRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *MissionRepositoryTestSuite) TestGetActive_Success() {
+	now := time.Now()
+	rows := sqlmock.NewRows(missionTestColumns).
+		AddRow(1, "5 transfer minggu ini", "", "Transfer", "Count", 5, 0, 50, now.AddDate(0, 0, -1), now.AddDate(0, 0, 6), true, now)
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM mst_mission m WHERE m.active = TRUE").WithArgs(now).WillReturnRows(rows)
+	repo := NewMissionRepository(suite.mockDB)
+	res, err := repo.GetActive(now)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), "Count", res[0].Metric)
+}
+
+func (suite *MissionRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM mst_mission m WHERE m.mission_id = \\$1").WithArgs(9).WillReturnError(sql.ErrNoRows)
+	repo := NewMissionRepository(suite.mockDB)
+	res, err := repo.GetByID(9)
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "mission not found")
+}
+
+func (suite *MissionRepositoryTestSuite) TestCreate_Success() {
+	now := time.Now()
+	mission := model.Mission{Title: "Top up 100rb", TransactionType: "Deposit", Metric: "Amount", Target: 100000, RewardPoints: 100, StartsAt: now, EndsAt: now.AddDate(0, 0, 7), Active: true, CreatedAt: now}
+	suite.mockSql.ExpectQuery("INSERT INTO mst_mission").
+		WithArgs("Top up 100rb", "", "Deposit", "Amount", 100000, 0, 100, mission.StartsAt, mission.EndsAt, true, now).
+		WillReturnRows(sqlmock.NewRows([]string{"mission_id"}).AddRow(3))
+	repo := NewMissionRepository(suite.mockDB)
+	err := repo.Create(&mission)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, mission.MissionID)
+}
+
+func (suite *MissionRepositoryTestSuite) TestDeactivate_NotFound() {
+	suite.mockSql.ExpectExec("UPDATE mst_mission SET active = FALSE").WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewMissionRepository(suite.mockDB)
+	err := repo.Deactivate(9)
+	assert.EqualError(suite.T(), err, "mission not found")
+}
+
+func (suite *MissionRepositoryTestSuite) TestGetProgress_Success() {
+	now := time.Now()
+	columns := append(append([]string{}, missionTestColumns...), "progress", "completed_at")
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "5 transfer minggu ini", "", "Transfer", "Count", 5, 0, 50, now, now.AddDate(0, 0, 7), true, now, 5, now).
+		AddRow(2, "Top up 100rb", "", "Deposit", "Amount", 100000, 0, 100, now, now.AddDate(0, 0, 7), true, now, 0, nil)
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM mst_mission m\\s+LEFT JOIN tx_mission_progress p").WithArgs("1", now).WillReturnRows(rows)
+	repo := NewMissionRepository(suite.mockDB)
+	res, err := repo.GetProgress("1", now)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 2)
+	assert.True(suite.T(), res[0].Completed)
+	assert.False(suite.T(), res[1].Completed)
+}
+
+func (suite *MissionRepositoryTestSuite) TestAddProgress_AlreadyCompleted() {
+	now := time.Now()
+	suite.mockSql.ExpectQuery("INSERT INTO tx_mission_progress").WithArgs(1, "1", 1, now).WillReturnError(sql.ErrNoRows)
+	repo := NewMissionRepository(suite.mockDB)
+	res, err := repo.AddProgress(1, "1", 1, now)
+	assert.Equal(suite.T(), 0, res)
+	assert.EqualError(suite.T(), err, "mission already completed")
+}
+
+func (suite *MissionRepositoryTestSuite) TestComplete_Success() {
+	now := time.Now()
+	suite.mockSql.ExpectExec("UPDATE tx_mission_progress SET completed_at = \\$1").WithArgs(now, 1, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewMissionRepository(suite.mockDB)
+	err := repo.Complete(1, "1", now)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *MissionRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *MissionRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestMissionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MissionRepositoryTestSuite))
+}
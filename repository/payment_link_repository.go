@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type PaymentLinkRepository interface {
+	Create(link *model.PaymentLink) error
+	GetByID(linkID string) (*model.PaymentLink, error)
+	GetByOwner(ownerID string) ([]*model.PaymentLink, error)
+	Deactivate(linkID string, ownerID string) error
+	ReserveUse(linkID string, now time.Time) error
+	ReleaseUse(linkID string) error
+
+	CreateWalletPayment(payment *model.PaymentLinkPayment) error
+	CreateGatewayPayment(payment *model.PaymentLinkPayment) error
+	GetPaymentByOrderID(orderID string) (*model.PaymentLinkPayment, error)
+	SettleGatewayPayment(payment *model.PaymentLinkPayment) error
+	FailGatewayPayment(orderID string) error
+	GetStaleGatewayOrders(before time.Time) ([]string, error)
+	GetPayments(linkID string) ([]*model.PaymentLinkPayment, error)
+}
+
+type paymentLinkRepository struct {
+	db *sql.DB
+}
+
+const paymentLinkQuery = `SELECT l.link_id, l.owner_id, u.name, l.amount, l.description, l.max_uses, l.use_count, l.collected_amount, l.status, l.expired_at, l.created_at
+FROM mst_payment_link l
+JOIN mst_users u ON l.owner_id = u.user_id`
+
+const paymentLinkPaymentQuery = `SELECT p.payment_id, p.link_id, COALESCE(p.payer_id, ''), p.payer_name, p.payer_email, p.payer_phone, p.amount, p.method, p.status, p.order_id,
+	COALESCE(p.transaction_id, 0), p.created_at, p.paid_at, l.owner_id
+FROM tx_payment_link_payment p
+JOIN mst_payment_link l ON p.link_id = l.link_id`
+
+func scanPaymentLink(scanner interface{ Scan(...interface{}) error }, link *model.PaymentLink) error {
+	return scanner.Scan(&link.LinkID, &link.OwnerID, &link.OwnerName, &link.Amount, &link.Description, &link.MaxUses, &link.UseCount, &link.CollectedAmount, &link.Status, &link.ExpiredAt, &link.CreatedAt)
+}
+
+func scanPaymentLinkPayment(scanner interface{ Scan(...interface{}) error }, payment *model.PaymentLinkPayment) error {
+	return scanner.Scan(&payment.PaymentID, &payment.LinkID, &payment.PayerID, &payment.PayerName, &payment.PayerEmail, &payment.PayerPhone, &payment.Amount, &payment.Method, &payment.Status, &payment.OrderID,
+		&payment.TransactionID, &payment.CreatedAt, &payment.PaidAt, &payment.OwnerID)
+}
+
+func (r *paymentLinkRepository) Create(link *model.PaymentLink) error {
+	query := "INSERT INTO mst_payment_link (link_id, owner_id, amount, description, max_uses, use_count, collected_amount, status, expired_at, created_at) VALUES ($1, $2, $3, $4, $5, 0, 0, $6, $7, $8)"
+	_, err := r.db.Exec(query, link.LinkID, link.OwnerID, link.Amount, link.Description, link.MaxUses, link.Status, link.ExpiredAt, link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment link: %v", err)
+	}
+	return nil
+}
+
+func (r *paymentLinkRepository) GetByID(linkID string) (*model.PaymentLink, error) {
+	var link model.PaymentLink
+	if err := scanPaymentLink(r.db.QueryRow(paymentLinkQuery+" WHERE l.link_id = $1", linkID), &link); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("payment link not found")
+		}
+		return nil, fmt.Errorf("failed to get payment link: %v", err)
+	}
+	return &link, nil
+}
+
+func (r *paymentLinkRepository) GetByOwner(ownerID string) ([]*model.PaymentLink, error) {
+	rows, err := r.db.Query(paymentLinkQuery+" WHERE l.owner_id = $1 ORDER BY l.created_at DESC", ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment links: %v", err)
+	}
+	defer rows.Close()
+
+	var links []*model.PaymentLink
+	for rows.Next() {
+		link := &model.PaymentLink{}
+		if err := scanPaymentLink(rows, link); err != nil {
+			return nil, fmt.Errorf("failed to scan payment link: %v", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get payment links: %v", err)
+	}
+	return links, nil
+}
+
+func (r *paymentLinkRepository) Deactivate(linkID string, ownerID string) error {
+	res, err := r.db.Exec("UPDATE mst_payment_link SET status = 'Inactive' WHERE link_id = $1 AND owner_id = $2 AND status = 'Active'", linkID, ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate payment link: %v", err)
+	}
+	return affectedOrError(res, "deactivate payment link", "payment link not found")
+}
+
+// ReserveUse takes one use of the link, failing when the link is inactive,
+// expired or has reached its maximum number of uses.
+func (r *paymentLinkRepository) ReserveUse(linkID string, now time.Time) error {
+	query := `UPDATE mst_payment_link SET use_count = use_count + 1
+	WHERE link_id = $1 AND status = 'Active' AND expired_at > $2 AND (max_uses = 0 OR use_count < max_uses)`
+	res, err := r.db.Exec(query, linkID, now)
+	if err != nil {
+		return fmt.Errorf("failed to reserve payment link: %v", err)
+	}
+	return affectedOrError(res, "reserve payment link", "payment link is no longer available")
+}
+
+// ReleaseUse gives back a use taken by a payment that did not go through.
+func (r *paymentLinkRepository) ReleaseUse(linkID string) error {
+	_, err := r.db.Exec("UPDATE mst_payment_link SET use_count = use_count - 1 WHERE link_id = $1 AND use_count > 0", linkID)
+	if err != nil {
+		return fmt.Errorf("failed to release payment link: %v", err)
+	}
+	return nil
+}
+
+// CreateWalletPayment records a wallet payment from a registered payer to the
+// link owner.
+func (r *paymentLinkRepository) CreateWalletPayment(payment *model.PaymentLinkPayment) error {
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id, recipient_id) VALUES ($1, $2, $3, $4)"
+	_, err := r.db.Exec(query, "Payment Link", today(), payment.PayerID, payment.OwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = `INSERT INTO tx_payment_link_payment (link_id, payer_id, payer_name, payer_email, payer_phone, amount, method, status, order_id, transaction_id, created_at, paid_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING payment_id`
+	err = r.db.QueryRow(query, payment.LinkID, payment.PayerID, payment.PayerName, payment.PayerEmail, payment.PayerPhone, payment.Amount, payment.Method, payment.Status, payment.OrderID, txID, payment.CreatedAt, payment.PaidAt).
+		Scan(&payment.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to insert payment link payment: %v", err)
+	}
+
+	_, err = r.db.Exec("UPDATE mst_payment_link SET collected_amount = collected_amount + $1 WHERE link_id = $2", payment.Amount, payment.LinkID)
+	if err != nil {
+		return fmt.Errorf("failed to update payment link: %v", err)
+	}
+
+	payment.TransactionID = txID
+	return nil
+}
+
+// CreateGatewayPayment stores a pending guest payment until the payment
+// gateway reports its outcome.
+func (r *paymentLinkRepository) CreateGatewayPayment(payment *model.PaymentLinkPayment) error {
+	query := `INSERT INTO tx_payment_link_payment (link_id, payer_name, payer_email, payer_phone, amount, method, status, order_id, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING payment_id`
+	err := r.db.QueryRow(query, payment.LinkID, payment.PayerName, payment.PayerEmail, payment.PayerPhone, payment.Amount, payment.Method, payment.Status, payment.OrderID, payment.CreatedAt).
+		Scan(&payment.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to insert payment link payment: %v", err)
+	}
+	return nil
+}
+
+func (r *paymentLinkRepository) GetPaymentByOrderID(orderID string) (*model.PaymentLinkPayment, error) {
+	var payment model.PaymentLinkPayment
+	if err := scanPaymentLinkPayment(r.db.QueryRow(paymentLinkPaymentQuery+" WHERE p.order_id = $1", orderID), &payment); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("payment not found")
+		}
+		return nil, fmt.Errorf("failed to get payment link payment: %v", err)
+	}
+	return &payment, nil
+}
+
+// SettleGatewayPayment marks a pending guest payment as paid and records the
+// incoming money as a transaction of the link owner.
+func (r *paymentLinkRepository) SettleGatewayPayment(payment *model.PaymentLinkPayment) error {
+	res, err := r.db.Exec("UPDATE tx_payment_link_payment SET status = 'Success', paid_at = $1 WHERE payment_id = $2 AND status = 'Pending'", payment.PaidAt, payment.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to settle payment link payment: %v", err)
+	}
+	if err := affectedOrError(res, "settle payment link payment", "payment is no longer pending"); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
+	_, err = r.db.Exec(query, "Payment Link", today(), payment.OwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	_, err = r.db.Exec("UPDATE tx_payment_link_payment SET transaction_id = $1 WHERE payment_id = $2", txID, payment.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to update payment link payment: %v", err)
+	}
+
+	_, err = r.db.Exec("UPDATE mst_payment_link SET collected_amount = collected_amount + $1 WHERE link_id = $2", payment.Amount, payment.LinkID)
+	if err != nil {
+		return fmt.Errorf("failed to update payment link: %v", err)
+	}
+
+	payment.TransactionID = txID
+	payment.Status = "Success"
+	return nil
+}
+
+// FailGatewayPayment closes a pending guest payment the gateway rejected or
+// expired, and gives its use back to the link.
+func (r *paymentLinkRepository) FailGatewayPayment(orderID string) error {
+	var linkID string
+	err := r.db.QueryRow("UPDATE tx_payment_link_payment SET status = 'Failed' WHERE order_id = $1 AND status = 'Pending' RETURNING link_id", orderID).Scan(&linkID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("payment is no longer pending")
+		}
+		return fmt.Errorf("failed to fail payment link payment: %v", err)
+	}
+	return r.ReleaseUse(linkID)
+}
+
+// GetStaleGatewayOrders returns the order IDs of guest payments still pending
+// since before the given time.
+func (r *paymentLinkRepository) GetStaleGatewayOrders(before time.Time) ([]string, error) {
+	rows, err := r.db.Query("SELECT order_id FROM tx_payment_link_payment WHERE method = 'Gateway' AND status = 'Pending' AND created_at <= $1", before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale gateway payments: %v", err)
+	}
+	defer rows.Close()
+
+	var orderIDs []string
+	for rows.Next() {
+		var orderID string
+		if err := rows.Scan(&orderID); err != nil {
+			return nil, fmt.Errorf("failed to scan stale gateway payment: %v", err)
+		}
+		orderIDs = append(orderIDs, orderID)
+	}
+	return orderIDs, rows.Err()
+}
+
+func (r *paymentLinkRepository) GetPayments(linkID string) ([]*model.PaymentLinkPayment, error) {
+	rows, err := r.db.Query(paymentLinkPaymentQuery+" WHERE p.link_id = $1 ORDER BY p.payment_id DESC", linkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment link payments: %v", err)
+	}
+	defer rows.Close()
+
+	var payments []*model.PaymentLinkPayment
+	for rows.Next() {
+		payment := &model.PaymentLinkPayment{}
+		if err := scanPaymentLinkPayment(rows, payment); err != nil {
+			return nil, fmt.Errorf("failed to scan payment link payment: %v", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get payment link payments: %v", err)
+	}
+	return payments, nil
+}
+
+func NewPaymentLinkRepository(db *sql.DB) PaymentLinkRepository {
+	return &paymentLinkRepository{db: db}
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyPaymentLink = model.PaymentLink{
+	LinkID:      "PLABCDEFGH2",
+	OwnerID:     "1",
+	OwnerName:   "name1",
+	Amount:      25000,
+	Description: "Iuran futsal",
+	MaxUses:     10,
+	Status:      "Active",
+	ExpiredAt:   time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC),
+	CreatedAt:   time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type PaymentLinkRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *PaymentLinkRepositoryTestSuite) TestGetByID_Success() {
+	l := dummyPaymentLink
+	rows := sqlmock.NewRows([]string{"link_id", "owner_id", "name", "amount", "description", "max_uses", "use_count", "collected_amount", "status", "expired_at", "created_at"}).
+		AddRow(l.LinkID, l.OwnerID, l.OwnerName, l.Amount, l.Description, l.MaxUses, l.UseCount, l.CollectedAmount, l.Status, l.ExpiredAt, l.CreatedAt)
+	suite.mockSql.ExpectQuery("SELECT l.link_id").WithArgs(l.LinkID).WillReturnRows(rows)
+	repo := NewPaymentLinkRepository(suite.mockDB)
+	res, err := repo.GetByID(l.LinkID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &l, res)
+}
+
+func (suite *PaymentLinkRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT l.link_id").WithArgs("PLX").WillReturnError(sql.ErrNoRows)
+	repo := NewPaymentLinkRepository(suite.mockDB)
+	res, err := repo.GetByID("PLX")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "payment link not found")
+}
+
+func (suite *PaymentLinkRepositoryTestSuite) TestReserveUse_NoLongerAvailable() {
+	now := time.Now()
+	suite.mockSql.ExpectExec("UPDATE mst_payment_link SET use_count = use_count \\+ 1").WithArgs("PLABCDEFGH2", now).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPaymentLinkRepository(suite.mockDB)
+	err := repo.ReserveUse("PLABCDEFGH2", now)
+	assert.EqualError(suite.T(), err, "payment link is no longer available")
+}
+
+func (suite *PaymentLinkRepositoryTestSuite) TestCreateWalletPayment_Success() {
+	paidAt := time.Now()
+	payment := model.PaymentLinkPayment{LinkID: "PLABCDEFGH2", PayerID: "2", PayerName: "name2", Amount: 25000, Method: "Wallet", Status: "Success", CreatedAt: paidAt, PaidAt: &paidAt, OwnerID: "1"}
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Payment Link", today(), "2", "1").WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(31))
+	suite.mockSql.ExpectQuery("INSERT INTO tx_payment_link_payment").
+		WithArgs(payment.LinkID, "2", "name2", "", "", 25000, "Wallet", "Success", "", 31, paidAt, &paidAt).
+		WillReturnRows(sqlmock.NewRows([]string{"payment_id"}).AddRow(4))
+	suite.mockSql.ExpectExec("UPDATE mst_payment_link SET collected_amount").WithArgs(25000, payment.LinkID).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPaymentLinkRepository(suite.mockDB)
+	err := repo.CreateWalletPayment(&payment)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, payment.PaymentID)
+	assert.Equal(suite.T(), 31, payment.TransactionID)
+}
+
+func (suite *PaymentLinkRepositoryTestSuite) TestSettleGatewayPayment_AlreadySettled() {
+	paidAt := time.Now()
+	payment := model.PaymentLinkPayment{PaymentID: 4, LinkID: "PLABCDEFGH2", Amount: 25000, OwnerID: "1", PaidAt: &paidAt}
+	suite.mockSql.ExpectExec("UPDATE tx_payment_link_payment SET status = 'Success'").WithArgs(&paidAt, 4).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPaymentLinkRepository(suite.mockDB)
+	err := repo.SettleGatewayPayment(&payment)
+	assert.EqualError(suite.T(), err, "payment is no longer pending")
+}
+
+func (suite *PaymentLinkRepositoryTestSuite) TestSettleGatewayPayment_Success() {
+	paidAt := time.Now()
+	payment := model.PaymentLinkPayment{PaymentID: 4, LinkID: "PLABCDEFGH2", Amount: 25000, OwnerID: "1", PaidAt: &paidAt}
+	suite.mockSql.ExpectExec("UPDATE tx_payment_link_payment SET status = 'Success'").WithArgs(&paidAt, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Payment Link", today(), "1").WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(32))
+	suite.mockSql.ExpectExec("UPDATE tx_payment_link_payment SET transaction_id").WithArgs(32, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_payment_link SET collected_amount").WithArgs(25000, payment.LinkID).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPaymentLinkRepository(suite.mockDB)
+	err := repo.SettleGatewayPayment(&payment)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 32, payment.TransactionID)
+	assert.Equal(suite.T(), "Success", payment.Status)
+}
+
+func (suite *PaymentLinkRepositoryTestSuite) TestFailGatewayPayment_ReleasesUse() {
+	suite.mockSql.ExpectQuery("UPDATE tx_payment_link_payment SET status = 'Failed'").WithArgs("PAYLINK-1").
+		WillReturnRows(sqlmock.NewRows([]string{"link_id"}).AddRow("PLABCDEFGH2"))
+	suite.mockSql.ExpectExec("UPDATE mst_payment_link SET use_count = use_count - 1").WithArgs("PLABCDEFGH2").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPaymentLinkRepository(suite.mockDB)
+	err := repo.FailGatewayPayment("PAYLINK-1")
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *PaymentLinkRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *PaymentLinkRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestPaymentLinkRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentLinkRepositoryTestSuite))
+}
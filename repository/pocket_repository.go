@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type PocketRepository interface {
+	GetByUserID(userID string) ([]*model.Pocket, error)
+	GetByID(pocketID int, userID string) (*model.Pocket, error)
+	GetAutoSavePockets(userID string) ([]*model.Pocket, error)
+	Create(pocket *model.Pocket) error
+	Update(pocket *model.Pocket) error
+	Delete(pocketID int, userID string) error
+	Move(movement *model.PocketMovement) error
+}
+
+type pocketRepository struct {
+	db *sql.DB
+}
+
+const pocketColumns = "pocket_id, user_id, name, balance, target_amount, COALESCE(CAST(deadline AS VARCHAR), ''), auto_save_percent"
+
+func scanPockets(rows *sql.Rows) ([]*model.Pocket, error) {
+	defer rows.Close()
+
+	var pockets []*model.Pocket
+	for rows.Next() {
+		pocket := &model.Pocket{}
+		err := rows.Scan(&pocket.PocketID, &pocket.UserID, &pocket.Name, &pocket.Balance, &pocket.TargetAmount, &pocket.Deadline, &pocket.AutoSavePercent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pocket: %v", err)
+		}
+		pockets = append(pockets, pocket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get pockets: %v", err)
+	}
+	return pockets, nil
+}
+
+func (r *pocketRepository) GetByUserID(userID string) ([]*model.Pocket, error) {
+	rows, err := r.db.Query("SELECT "+pocketColumns+" FROM mst_pocket WHERE user_id = $1 ORDER BY pocket_id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pockets: %v", err)
+	}
+	return scanPockets(rows)
+}
+
+func (r *pocketRepository) GetByID(pocketID int, userID string) (*model.Pocket, error) {
+	var pocket model.Pocket
+	err := r.db.QueryRow("SELECT "+pocketColumns+" FROM mst_pocket WHERE pocket_id = $1 AND user_id = $2", pocketID, userID).
+		Scan(&pocket.PocketID, &pocket.UserID, &pocket.Name, &pocket.Balance, &pocket.TargetAmount, &pocket.Deadline, &pocket.AutoSavePercent)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("pocket not found")
+		}
+		return nil, fmt.Errorf("failed to get pocket: %v", err)
+	}
+	return &pocket, nil
+}
+
+func (r *pocketRepository) GetAutoSavePockets(userID string) ([]*model.Pocket, error) {
+	rows, err := r.db.Query("SELECT "+pocketColumns+" FROM mst_pocket WHERE user_id = $1 AND auto_save_percent > 0 ORDER BY pocket_id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pockets: %v", err)
+	}
+	return scanPockets(rows)
+}
+
+func (r *pocketRepository) Create(pocket *model.Pocket) error {
+	query := "INSERT INTO mst_pocket (user_id, name, balance, target_amount, deadline, auto_save_percent) VALUES ($1, $2, 0, $3, NULLIF($4, '')::DATE, $5) RETURNING pocket_id"
+	err := r.db.QueryRow(query, pocket.UserID, pocket.Name, pocket.TargetAmount, pocket.Deadline, pocket.AutoSavePercent).Scan(&pocket.PocketID)
+	if err != nil {
+		return fmt.Errorf("failed to create pocket: %v", err)
+	}
+	return nil
+}
+
+func (r *pocketRepository) Update(pocket *model.Pocket) error {
+	query := "UPDATE mst_pocket SET name = $1, target_amount = $2, deadline = NULLIF($3, '')::DATE, auto_save_percent = $4 WHERE pocket_id = $5 AND user_id = $6"
+	res, err := r.db.Exec(query, pocket.Name, pocket.TargetAmount, pocket.Deadline, pocket.AutoSavePercent, pocket.PocketID, pocket.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update pocket: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update pocket: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("pocket not found")
+	}
+	return nil
+}
+
+// Delete removes an empty pocket; money must be moved out first.
+func (r *pocketRepository) Delete(pocketID int, userID string) error {
+	res, err := r.db.Exec("DELETE FROM mst_pocket WHERE pocket_id = $1 AND user_id = $2 AND balance = 0", pocketID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete pocket: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete pocket: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("pocket not found")
+	}
+	return nil
+}
+
+// Move adds a signed amount to the pocket balance and records it as a
+// transaction. A negative amount moves money out of the pocket.
+func (r *pocketRepository) Move(movement *model.PocketMovement) error {
+	query := "UPDATE mst_pocket SET balance = balance + $1 WHERE pocket_id = $2 AND user_id = $3 AND balance + $1 >= 0"
+	res, err := r.db.Exec(query, movement.Amount, movement.PocketID, movement.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update pocket balance: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update pocket balance: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("insufficient pocket balance")
+	}
+
+	query = "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
+	_, err = r.db.Exec(query, movement.TransactionType, today(), movement.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_pocket (transaction_id, pocket_id, pocket_name, amount) VALUES ($1, $2, $3, $4)"
+	_, err = r.db.Exec(query, txID, movement.PocketID, movement.PocketName, movement.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to insert pocket movement: %v", err)
+	}
+
+	movement.TransactionID = txID
+	movement.TransactionDate = today()
+	return nil
+}
+
+func NewPocketRepository(db *sql.DB) PocketRepository {
+	return &pocketRepository{db: db}
+}
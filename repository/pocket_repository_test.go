@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyPocket = model.Pocket{
+	PocketID:        1,
+	UserID:          "1",
+	Name:            "Liburan",
+	Balance:         50000,
+	TargetAmount:    1000000,
+	Deadline:        "2026-12-31",
+	AutoSavePercent: 10,
+}
+
+var pocketRowColumns = []string{"pocket_id", "user_id", "name", "balance", "target_amount", "deadline", "auto_save_percent"}
+
+type PocketRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *PocketRepositoryTestSuite) TestGetByUserID_Success() {
+	pocket := dummyPocket
+	rows := sqlmock.NewRows(pocketRowColumns).AddRow(pocket.PocketID, pocket.UserID, pocket.Name, pocket.Balance, pocket.TargetAmount, pocket.Deadline, pocket.AutoSavePercent)
+	suite.mockSql.ExpectQuery("SELECT pocket_id").WithArgs(pocket.UserID).WillReturnRows(rows)
+	repo := NewPocketRepository(suite.mockDB)
+	res, err := repo.GetByUserID(pocket.UserID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.Pocket{&pocket}, res)
+}
+
+func (suite *PocketRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT pocket_id").WithArgs(1, "1").WillReturnError(sql.ErrNoRows)
+	repo := NewPocketRepository(suite.mockDB)
+	res, err := repo.GetByID(1, "1")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "pocket not found")
+}
+
+func (suite *PocketRepositoryTestSuite) TestCreate_Success() {
+	pocket := dummyPocket
+	pocket.PocketID = 0
+	suite.mockSql.ExpectQuery("INSERT INTO mst_pocket").WithArgs(pocket.UserID, pocket.Name, pocket.TargetAmount, pocket.Deadline, pocket.AutoSavePercent).WillReturnRows(sqlmock.NewRows([]string{"pocket_id"}).AddRow(4))
+	repo := NewPocketRepository(suite.mockDB)
+	err := repo.Create(&pocket)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, pocket.PocketID)
+}
+
+func (suite *PocketRepositoryTestSuite) TestUpdate_NotFound() {
+	pocket := dummyPocket
+	suite.mockSql.ExpectExec("UPDATE mst_pocket SET name").WithArgs(pocket.Name, pocket.TargetAmount, pocket.Deadline, pocket.AutoSavePercent, pocket.PocketID, pocket.UserID).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPocketRepository(suite.mockDB)
+	err := repo.Update(&pocket)
+	assert.EqualError(suite.T(), err, "pocket not found")
+}
+
+func (suite *PocketRepositoryTestSuite) TestDelete_Success() {
+	suite.mockSql.ExpectExec("DELETE FROM mst_pocket").WithArgs(1, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPocketRepository(suite.mockDB)
+	err := repo.Delete(1, "1")
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *PocketRepositoryTestSuite) TestMove_Success() {
+	movement := model.PocketMovement{PocketID: 1, UserID: "1", PocketName: "Liburan", Amount: 20000, TransactionType: "Pocket In"}
+	suite.mockSql.ExpectExec("UPDATE mst_pocket SET balance").WithArgs(movement.Amount, movement.PocketID, movement.UserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs(movement.TransactionType, today(), movement.UserID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(12))
+	suite.mockSql.ExpectExec("INSERT INTO tx_pocket").WithArgs(12, movement.PocketID, movement.PocketName, movement.Amount).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewPocketRepository(suite.mockDB)
+	err := repo.Move(&movement)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 12, movement.TransactionID)
+}
+
+func (suite *PocketRepositoryTestSuite) TestMove_InsufficientPocketBalance() {
+	movement := model.PocketMovement{PocketID: 1, UserID: "1", Amount: -20000, TransactionType: "Pocket Out"}
+	suite.mockSql.ExpectExec("UPDATE mst_pocket SET balance").WithArgs(movement.Amount, movement.PocketID, movement.UserID).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPocketRepository(suite.mockDB)
+	err := repo.Move(&movement)
+	assert.EqualError(suite.T(), err, "insufficient pocket balance")
+}
+
+func (suite *PocketRepositoryTestSuite) TestMove_Failed() {
+	movement := model.PocketMovement{PocketID: 1, UserID: "1", Amount: 20000}
+	suite.mockSql.ExpectExec("UPDATE mst_pocket SET balance").WillReturnError(errors.New("failed"))
+	repo := NewPocketRepository(suite.mockDB)
+	err := repo.Move(&movement)
+	assert.EqualError(suite.T(), err, "failed to update pocket balance: failed")
+}
+
+func (suite *PocketRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *PocketRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestPocketRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PocketRepositoryTestSuite))
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type PointExchangeRepository interface {
+	GetCatalog(category string, at time.Time) ([]*model.PointExchange, error)
+	GetCategories(at time.Time) ([]*model.PointExchangeCategory, error)
+	GetAll() ([]*model.PointExchange, error)
+	GetByID(peID int) (*model.PointExchange, error)
+	Create(pe *model.PointExchange) error
+	Update(pe *model.PointExchange) error
+	Deactivate(peID int) error
+}
+
+type pointExchangeRepository struct {
+	db *sql.DB
+}
+
+const pointExchangeColumns = "pe_id, reward, price, category, description, image_url, stock, fulfillment_type, starts_at, ends_at, active, created_at"
+
+// pointExchangeAvailable filters the rewards that can be redeemed at $1.
+const pointExchangeAvailable = "active = TRUE AND (starts_at IS NULL OR starts_at <= $1) AND (ends_at IS NULL OR ends_at > $1)"
+
+func scanPointExchange(scanner interface{ Scan(...interface{}) error }, pe *model.PointExchange) error {
+	return scanner.Scan(&pe.PE_ID, &pe.Reward, &pe.Price, &pe.Category, &pe.Description, &pe.ImageURL, &pe.Stock, &pe.FulfillmentType,
+		&pe.StartsAt, &pe.EndsAt, &pe.Active, &pe.CreatedAt)
+}
+
+func (r *pointExchangeRepository) queryPointExchanges(query string, args ...interface{}) ([]*model.PointExchange, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point exchanges: %v", err)
+	}
+	defer rows.Close()
+
+	var pointExchanges []*model.PointExchange
+	for rows.Next() {
+		pe := &model.PointExchange{}
+		if err := scanPointExchange(rows, pe); err != nil {
+			return nil, fmt.Errorf("failed to scan point exchange: %v", err)
+		}
+		pointExchanges = append(pointExchanges, pe)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get point exchanges: %v", err)
+	}
+	return pointExchanges, nil
+}
+
+// GetCatalog returns the rewards that can be redeemed at the given time,
+// optionally limited to one category.
+func (r *pointExchangeRepository) GetCatalog(category string, at time.Time) ([]*model.PointExchange, error) {
+	query := "SELECT " + pointExchangeColumns + " FROM mst_point_exchange WHERE " + pointExchangeAvailable +
+		" AND ($2 = '' OR category = $2) ORDER BY category, price, pe_id"
+	return r.queryPointExchanges(query, at, category)
+}
+
+func (r *pointExchangeRepository) GetCategories(at time.Time) ([]*model.PointExchangeCategory, error) {
+	query := "SELECT category, COUNT(*) FROM mst_point_exchange WHERE " + pointExchangeAvailable + " GROUP BY category ORDER BY category"
+	rows, err := r.db.Query(query, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point exchange categories: %v", err)
+	}
+	defer rows.Close()
+
+	var categories []*model.PointExchangeCategory
+	for rows.Next() {
+		category := &model.PointExchangeCategory{}
+		if err := rows.Scan(&category.Category, &category.Rewards); err != nil {
+			return nil, fmt.Errorf("failed to scan point exchange category: %v", err)
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get point exchange categories: %v", err)
+	}
+	return categories, nil
+}
+
+func (r *pointExchangeRepository) GetAll() ([]*model.PointExchange, error) {
+	return r.queryPointExchanges("SELECT " + pointExchangeColumns + " FROM mst_point_exchange ORDER BY pe_id")
+}
+
+func (r *pointExchangeRepository) GetByID(peID int) (*model.PointExchange, error) {
+	var pe model.PointExchange
+	if err := scanPointExchange(r.db.QueryRow("SELECT "+pointExchangeColumns+" FROM mst_point_exchange WHERE pe_id = $1", peID), &pe); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("point exchange not found")
+		}
+		return nil, fmt.Errorf("failed to get point exchange: %v", err)
+	}
+	return &pe, nil
+}
+
+func (r *pointExchangeRepository) Create(pe *model.PointExchange) error {
+	query := `INSERT INTO mst_point_exchange (reward, price, category, description, image_url, stock, fulfillment_type, starts_at, ends_at, active, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING pe_id`
+	err := r.db.QueryRow(query, pe.Reward, pe.Price, pe.Category, pe.Description, pe.ImageURL, pe.Stock, pe.FulfillmentType,
+		pe.StartsAt, pe.EndsAt, pe.Active, pe.CreatedAt).Scan(&pe.PE_ID)
+	if err != nil {
+		return fmt.Errorf("failed to create point exchange: %v", err)
+	}
+	return nil
+}
+
+func (r *pointExchangeRepository) Update(pe *model.PointExchange) error {
+	query := `UPDATE mst_point_exchange SET reward = $1, price = $2, category = $3, description = $4, image_url = $5, stock = $6,
+	fulfillment_type = $7, starts_at = $8, ends_at = $9, active = $10 WHERE pe_id = $11`
+	res, err := r.db.Exec(query, pe.Reward, pe.Price, pe.Category, pe.Description, pe.ImageURL, pe.Stock, pe.FulfillmentType,
+		pe.StartsAt, pe.EndsAt, pe.Active, pe.PE_ID)
+	if err != nil {
+		return fmt.Errorf("failed to update point exchange: %v", err)
+	}
+	return affectedOrError(res, "update point exchange", "point exchange not found")
+}
+
+// Deactivate hides a reward from the catalog. Rewards are never deleted
+// because past redemptions point at them.
+func (r *pointExchangeRepository) Deactivate(peID int) error {
+	res, err := r.db.Exec("UPDATE mst_point_exchange SET active = FALSE WHERE pe_id = $1", peID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate point exchange: %v", err)
+	}
+	return affectedOrError(res, "deactivate point exchange", "point exchange not found")
+}
+
+func NewPointExchangeRepository(db *sql.DB) PointExchangeRepository {
+	return &pointExchangeRepository{db: db}
+}
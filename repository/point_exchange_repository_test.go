@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var pointExchangeTestColumns = []string{"pe_id", "reward", "price", "category", "description", "image_url", "stock", "fulfillment_type", "starts_at", "ends_at", "active", "created_at"}
+
+var dummyStock = 25
+
+var dummyPointExchanges = []*model.PointExchange{
+	{
+		PE_ID:           1,
+		Reward:          "10k Pulsa",
+		Price:           100,
+		Category:        "Pulsa",
+		ImageURL:        "https://cdn.example.com/pulsa-10k.png",
+		FulfillmentType: "Provider",
+		Active:          true,
+		CreatedAt:       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		PE_ID:           2,
+		Reward:          "Voucher Kopi",
+		Price:           200,
+		Category:        "Voucher",
+		Stock:           &dummyStock,
+		FulfillmentType: "Voucher Code",
+		Active:          true,
+		CreatedAt:       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+type PointExchangeRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func pointExchangeRows(pointExchanges ...*model.PointExchange) *sqlmock.Rows {
+	rows := sqlmock.NewRows(pointExchangeTestColumns)
+	for _, pe := range pointExchanges {
+		var stock interface{}
+		if pe.Stock != nil {
+			stock = *pe.Stock
+		}
+		rows.AddRow(pe.PE_ID, pe.Reward, pe.Price, pe.Category, pe.Description, pe.ImageURL, stock, pe.FulfillmentType, pe.StartsAt, pe.EndsAt, pe.Active, pe.CreatedAt)
+	}
+	return rows
+}
+
+func (suite *PointExchangeRepositoryTestSuite) TestGetCatalog_Success() {
+	at := time.Now()
+	suite.mockSql.ExpectQuery("SELECT pe_id, reward, price(.+)FROM mst_point_exchange WHERE active = TRUE").WithArgs(at, "").
+		WillReturnRows(pointExchangeRows(dummyPointExchanges...))
+	repo := NewPointExchangeRepository(suite.mockDB)
+	res, err := repo.GetCatalog("", at)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), dummyPointExchanges, res)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *PointExchangeRepositoryTestSuite) TestGetCatalog_Error() {
+	at := time.Now()
+	suite.mockSql.ExpectQuery("SELECT pe_id, reward, price(.+)FROM mst_point_exchange").WithArgs(at, "Pulsa").WillReturnError(errors.New("database error"))
+	repo := NewPointExchangeRepository(suite.mockDB)
+	_, err := repo.GetCatalog("Pulsa", at)
+	assert.Equal(suite.T(), fmt.Errorf("failed to get point exchanges: database error"), err)
+}
+
+func (suite *PointExchangeRepositoryTestSuite) TestGetCategories_Success() {
+	at := time.Now()
+	rows := sqlmock.NewRows([]string{"category", "count"}).AddRow("Pulsa", 3).AddRow("Voucher", 1)
+	suite.mockSql.ExpectQuery("SELECT category, COUNT\\(\\*\\) FROM mst_point_exchange").WithArgs(at).WillReturnRows(rows)
+	repo := NewPointExchangeRepository(suite.mockDB)
+	res, err := repo.GetCategories(at)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.PointExchangeCategory{{Category: "Pulsa", Rewards: 3}, {Category: "Voucher", Rewards: 1}}, res)
+}
+
+func (suite *PointExchangeRepositoryTestSuite) TestGetByID_Success() {
+	suite.mockSql.ExpectQuery("SELECT pe_id, reward, price(.+)WHERE pe_id = \\$1").WithArgs(2).WillReturnRows(pointExchangeRows(dummyPointExchanges[1]))
+	repo := NewPointExchangeRepository(suite.mockDB)
+	res, err := repo.GetByID(2)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), dummyPointExchanges[1], res)
+}
+
+func (suite *PointExchangeRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT pe_id, reward, price(.+)WHERE pe_id = \\$1").WithArgs(9).WillReturnError(sql.ErrNoRows)
+	repo := NewPointExchangeRepository(suite.mockDB)
+	res, err := repo.GetByID(9)
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "point exchange not found")
+}
+
+func (suite *PointExchangeRepositoryTestSuite) TestCreate_Success() {
+	pe := *dummyPointExchanges[1]
+	pe.PE_ID = 0
+	suite.mockSql.ExpectQuery("INSERT INTO mst_point_exchange").
+		WithArgs(pe.Reward, pe.Price, pe.Category, pe.Description, pe.ImageURL, *pe.Stock, pe.FulfillmentType, nil, nil, pe.Active, pe.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"pe_id"}).AddRow(7))
+	repo := NewPointExchangeRepository(suite.mockDB)
+	err := repo.Create(&pe)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 7, pe.PE_ID)
+}
+
+func (suite *PointExchangeRepositoryTestSuite) TestUpdate_NotFound() {
+	pe := *dummyPointExchanges[0]
+	suite.mockSql.ExpectExec("UPDATE mst_point_exchange SET reward").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPointExchangeRepository(suite.mockDB)
+	err := repo.Update(&pe)
+	assert.EqualError(suite.T(), err, "point exchange not found")
+}
+
+func (suite *PointExchangeRepositoryTestSuite) TestDeactivate_Success() {
+	suite.mockSql.ExpectExec("UPDATE mst_point_exchange SET active = FALSE").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPointExchangeRepository(suite.mockDB)
+	err := repo.Deactivate(1)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *PointExchangeRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *PointExchangeRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestPointExchangeRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PointExchangeRepositoryTestSuite))
+}
@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+// ErrPointsUsed is returned by Expire when the lot changed after it was loaded.
+var ErrPointsUsed = errors.New("points were used in the meantime")
+
+// ErrInsufficientPoints is returned by Burn when the user does not have the
+// points to burn.
+var ErrInsufficientPoints = errors.New("insufficient points")
+
+// ErrDailyCapReached is returned by ReserveDailyCap when the points would
+// take the user past the daily cap.
+var ErrDailyCapReached = errors.New("daily cap reached")
+
+type PointLedgerRepository interface {
+	Earn(entry *model.PointLedger) error
+	Credit(entry *model.PointLedger) error
+	Burn(entry *model.PointLedger) error
+	ReserveDailyCap(userID string, entryType string, day time.Time, points int, limit int) error
+	ReleaseDailyCap(userID string, entryType string, day time.Time, points int) error
+	RecordMovement(movement *model.PointMovement) error
+	GetEntries(userID string, limit int) ([]*model.PointLedger, error)
+	GetUpcomingExpiries(userID string) ([]*model.PointExpiry, error)
+	GetExpiredLots(now time.Time, limit int) ([]*model.PointLedger, error)
+	Expire(lot *model.PointLedger, now time.Time) error
+	GetExpiringSoon(before time.Time) ([]*model.PointExpiry, error)
+	MarkExpiryNotified(userID string, before time.Time) error
+}
+
+type pointLedgerRepository struct {
+	db *sql.DB
+}
+
+const pointLedgerColumns = "ledger_id, user_id, COALESCE(transaction_id, 0), entry_type, points, remaining, description, expires_at, created_at"
+
+func scanPointLedgers(rows *sql.Rows) ([]*model.PointLedger, error) {
+	defer rows.Close()
+
+	var entries []*model.PointLedger
+	for rows.Next() {
+		entry := &model.PointLedger{}
+		err := rows.Scan(&entry.LedgerID, &entry.UserID, &entry.TransactionID, &entry.EntryType, &entry.Points, &entry.Remaining,
+			&entry.Description, &entry.ExpiresAt, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan point ledger: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get point ledger: %v", err)
+	}
+	return entries, nil
+}
+
+func (r *pointLedgerRepository) addPoints(userID string, points int) error {
+	_, err := r.db.Exec("UPDATE mst_users SET point = point + $1 WHERE user_id = $2", points, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user point: %v", err)
+	}
+	return nil
+}
+
+// Earn records points earned from a transaction as a new lot and adds them
+// to the user's point balance. A transaction earns points for a user only
+// once.
+func (r *pointLedgerRepository) Earn(entry *model.PointLedger) error {
+	query := `INSERT INTO tx_point_ledger (user_id, transaction_id, entry_type, points, remaining, description, expires_at, created_at)
+	SELECT $1, $2, 'Earn', $3, $3, $4, $5, $6
+	WHERE NOT EXISTS (SELECT 1 FROM tx_point_ledger WHERE user_id = $1 AND transaction_id = $2 AND entry_type = 'Earn')
+	RETURNING ledger_id`
+	err := r.db.QueryRow(query, entry.UserID, entry.TransactionID, entry.Points, entry.Description, entry.ExpiresAt, entry.CreatedAt).Scan(&entry.LedgerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("points already awarded")
+		}
+		return fmt.Errorf("failed to insert point ledger: %v", err)
+	}
+	if err := r.addPoints(entry.UserID, entry.Points); err != nil {
+		return err
+	}
+	entry.EntryType = "Earn"
+	entry.Remaining = entry.Points
+	return nil
+}
+
+// Credit records points given to a user outside of earning, such as a manual
+// adjustment or a transfer from another user, as a new lot.
+func (r *pointLedgerRepository) Credit(entry *model.PointLedger) error {
+	query := `INSERT INTO tx_point_ledger (user_id, transaction_id, entry_type, points, remaining, description, expires_at, created_at)
+	VALUES ($1, NULLIF($2, 0), $3, $4, $4, $5, $6, $7) RETURNING ledger_id`
+	err := r.db.QueryRow(query, entry.UserID, entry.TransactionID, entry.EntryType, entry.Points, entry.Description, entry.ExpiresAt, entry.CreatedAt).Scan(&entry.LedgerID)
+	if err != nil {
+		return fmt.Errorf("failed to insert point ledger: %v", err)
+	}
+	if err := r.addPoints(entry.UserID, entry.Points); err != nil {
+		return err
+	}
+	entry.Remaining = entry.Points
+	return nil
+}
+
+// consumeLots takes points from the user's lots, the ones expiring first
+// before the others, and returns the earliest expiry among the lots used.
+// Points from before the ledger existed have no lot and are used last, so
+// running out of lots is not an error.
+func (r *pointLedgerRepository) consumeLots(userID string, points int) (*time.Time, error) {
+	query := "SELECT ledger_id, remaining, expires_at FROM tx_point_ledger WHERE user_id = $1 AND remaining > 0 ORDER BY expires_at, ledger_id"
+	var earliest *time.Time
+	for points > 0 {
+		rows, err := r.db.Query(query, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get point lots: %v", err)
+		}
+		var lots []*model.PointLedger
+		for rows.Next() {
+			lot := &model.PointLedger{}
+			if err := rows.Scan(&lot.LedgerID, &lot.Remaining, &lot.ExpiresAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan point lot: %v", err)
+			}
+			lots = append(lots, lot)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to get point lots: %v", err)
+		}
+		if len(lots) == 0 {
+			return earliest, nil
+		}
+
+		for _, lot := range lots {
+			take := lot.Remaining
+			if take > points {
+				take = points
+			}
+			res, err := r.db.Exec("UPDATE tx_point_ledger SET remaining = remaining - $1 WHERE ledger_id = $2 AND remaining >= $1", take, lot.LedgerID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to consume point lot: %v", err)
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return nil, fmt.Errorf("failed to consume point lot: %v", err)
+			}
+			if affected == 0 {
+				// Used in the meantime; reload the lots
+				break
+			}
+			if lot.ExpiresAt != nil && (earliest == nil || lot.ExpiresAt.Before(*earliest)) {
+				earliest = lot.ExpiresAt
+			}
+			points -= take
+			if points == 0 {
+				return earliest, nil
+			}
+		}
+	}
+	return earliest, nil
+}
+
+// Burn takes points from the user's balance, consuming lots first in first
+// out, and records the movement with negative points. ExpiresAt is set to
+// the earliest expiry of the lots used, so points passed on to someone else
+// keep their expiry.
+func (r *pointLedgerRepository) Burn(entry *model.PointLedger) error {
+	res, err := r.db.Exec("UPDATE mst_users SET point = point - $1 WHERE user_id = $2 AND point >= $1", entry.Points, entry.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update user point: %v", err)
+	}
+	if err := affectedOr(res, "update user point", ErrInsufficientPoints); err != nil {
+		return err
+	}
+	expiresAt, err := r.consumeLots(entry.UserID, entry.Points)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO tx_point_ledger (user_id, transaction_id, entry_type, points, remaining, description, created_at)
+	VALUES ($1, NULLIF($2, 0), $3, $4, 0, $5, $6) RETURNING ledger_id`
+	err = r.db.QueryRow(query, entry.UserID, entry.TransactionID, entry.EntryType, -entry.Points, entry.Description, entry.CreatedAt).Scan(&entry.LedgerID)
+	if err != nil {
+		return fmt.Errorf("failed to insert point ledger: %v", err)
+	}
+	entry.Points = -entry.Points
+	entry.ExpiresAt = expiresAt
+	return nil
+}
+
+// ReserveDailyCap counts points a user gives away through one entry type
+// against the cap of that day. The update is guarded, so concurrent debits can
+// never pass the cap together.
+func (r *pointLedgerRepository) ReserveDailyCap(userID string, entryType string, day time.Time, points int, limit int) error {
+	query := `INSERT INTO tx_point_daily_usage (user_id, entry_type, usage_date, used) SELECT $1, $2, $3, $4 WHERE $4 <= $5
+	ON CONFLICT (user_id, entry_type, usage_date) DO UPDATE SET used = tx_point_daily_usage.used + EXCLUDED.used
+	WHERE tx_point_daily_usage.used + EXCLUDED.used <= $5`
+	res, err := r.db.Exec(query, userID, entryType, day.Format("2006-01-02"), points, limit)
+	if err != nil {
+		return fmt.Errorf("failed to reserve daily point cap: %v", err)
+	}
+	return affectedOr(res, "reserve daily point cap", ErrDailyCapReached)
+}
+
+// ReleaseDailyCap gives back points reserved for a debit that did not happen.
+func (r *pointLedgerRepository) ReleaseDailyCap(userID string, entryType string, day time.Time, points int) error {
+	query := "UPDATE tx_point_daily_usage SET used = GREATEST(used - $1, 0) WHERE user_id = $2 AND entry_type = $3 AND usage_date = $4"
+	_, err := r.db.Exec(query, points, userID, entryType, day.Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("failed to release daily point cap: %v", err)
+	}
+	return nil
+}
+
+// RecordMovement records a point conversion or transfer as a transaction so
+// it shows in the transaction history of both sides.
+func (r *pointLedgerRepository) RecordMovement(movement *model.PointMovement) error {
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id, recipient_id) VALUES ($1, $2, $3, NULLIF($4, ''))"
+	_, err := r.db.Exec(query, movement.TransactionType, today(), movement.SenderID, movement.RecipientID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_point (transaction_id, points, amount, sender_name, recipient_name, note) VALUES ($1, $2, $3, $4, $5, $6)"
+	_, err = r.db.Exec(query, txID, movement.Points, movement.Amount, movement.SenderName, movement.RecipientName, movement.Note)
+	if err != nil {
+		return fmt.Errorf("failed to insert point movement: %v", err)
+	}
+
+	movement.TransactionID = txID
+	movement.TransactionDate = today()
+	return nil
+}
+
+func (r *pointLedgerRepository) GetEntries(userID string, limit int) ([]*model.PointLedger, error) {
+	rows, err := r.db.Query("SELECT "+pointLedgerColumns+" FROM tx_point_ledger WHERE user_id = $1 ORDER BY ledger_id DESC LIMIT $2", userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point ledger: %v", err)
+	}
+	return scanPointLedgers(rows)
+}
+
+// GetUpcomingExpiries sums the unused points of a user per expiry date.
+func (r *pointLedgerRepository) GetUpcomingExpiries(userID string) ([]*model.PointExpiry, error) {
+	query := `SELECT SUM(remaining), CAST(CAST(expires_at AS DATE) AS VARCHAR) FROM tx_point_ledger
+	WHERE user_id = $1 AND remaining > 0 GROUP BY CAST(expires_at AS DATE) ORDER BY CAST(expires_at AS DATE)`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point expiries: %v", err)
+	}
+	defer rows.Close()
+
+	var expiries []*model.PointExpiry
+	for rows.Next() {
+		expiry := &model.PointExpiry{UserID: userID}
+		if err := rows.Scan(&expiry.Points, &expiry.ExpiresOn); err != nil {
+			return nil, fmt.Errorf("failed to scan point expiry: %v", err)
+		}
+		expiries = append(expiries, expiry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get point expiries: %v", err)
+	}
+	return expiries, nil
+}
+
+func (r *pointLedgerRepository) GetExpiredLots(now time.Time, limit int) ([]*model.PointLedger, error) {
+	query := "SELECT " + pointLedgerColumns + " FROM tx_point_ledger WHERE remaining > 0 AND expires_at <= $1 ORDER BY expires_at LIMIT $2"
+	rows, err := r.db.Query(query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired points: %v", err)
+	}
+	return scanPointLedgers(rows)
+}
+
+// Expire closes what is left of a lot and removes it from the user's
+// balance. It fails when the lot was used since it was loaded.
+func (r *pointLedgerRepository) Expire(lot *model.PointLedger, now time.Time) error {
+	res, err := r.db.Exec("UPDATE tx_point_ledger SET remaining = 0 WHERE ledger_id = $1 AND remaining = $2", lot.LedgerID, lot.Remaining)
+	if err != nil {
+		return fmt.Errorf("failed to expire points: %v", err)
+	}
+	if err := affectedOr(res, "expire points", ErrPointsUsed); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO tx_point_ledger (user_id, entry_type, points, remaining, description, created_at)
+	VALUES ($1, 'Expire', $2, 0, $3, $4)`
+	_, err = r.db.Exec(query, lot.UserID, -lot.Remaining, lot.Description+" expired", now)
+	if err != nil {
+		return fmt.Errorf("failed to insert point ledger: %v", err)
+	}
+
+	_, err = r.db.Exec("UPDATE mst_users SET point = GREATEST(point - $1, 0) WHERE user_id = $2", lot.Remaining, lot.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update user point: %v", err)
+	}
+	return nil
+}
+
+// GetExpiringSoon sums per user the unused points expiring before the given
+// time that the user was not told about yet.
+func (r *pointLedgerRepository) GetExpiringSoon(before time.Time) ([]*model.PointExpiry, error) {
+	query := `SELECT user_id, SUM(remaining), CAST(CAST(MIN(expires_at) AS DATE) AS VARCHAR) FROM tx_point_ledger
+	WHERE remaining > 0 AND expires_at <= $1 AND expiry_notified = FALSE GROUP BY user_id`
+	rows, err := r.db.Query(query, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring points: %v", err)
+	}
+	defer rows.Close()
+
+	var expiries []*model.PointExpiry
+	for rows.Next() {
+		expiry := &model.PointExpiry{}
+		if err := rows.Scan(&expiry.UserID, &expiry.Points, &expiry.ExpiresOn); err != nil {
+			return nil, fmt.Errorf("failed to scan point expiry: %v", err)
+		}
+		expiries = append(expiries, expiry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get expiring points: %v", err)
+	}
+	return expiries, nil
+}
+
+func (r *pointLedgerRepository) MarkExpiryNotified(userID string, before time.Time) error {
+	query := "UPDATE tx_point_ledger SET expiry_notified = TRUE WHERE user_id = $1 AND remaining > 0 AND expires_at <= $2"
+	_, err := r.db.Exec(query, userID, before)
+	if err != nil {
+		return fmt.Errorf("failed to update point ledger: %v", err)
+	}
+	return nil
+}
+
+func NewPointLedgerRepository(db *sql.DB) PointLedgerRepository {
+	return &pointLedgerRepository{db: db}
+}
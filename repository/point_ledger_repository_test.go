@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PointLedgerRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestEarn_Success() {
+	createdAt := time.Now()
+	expiresAt := createdAt.AddDate(1, 0, 0)
+	entry := model.PointLedger{UserID: "1", TransactionID: 12, Points: 30, Description: "Transfer reward", ExpiresAt: &expiresAt, CreatedAt: createdAt}
+	suite.mockSql.ExpectQuery("INSERT INTO tx_point_ledger").WithArgs("1", 12, 30, "Transfer reward", expiresAt, createdAt).
+		WillReturnRows(sqlmock.NewRows([]string{"ledger_id"}).AddRow(5))
+	suite.mockSql.ExpectExec("UPDATE mst_users SET point = point \\+ \\$1").WithArgs(30, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.Earn(&entry)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 5, entry.LedgerID)
+	assert.Equal(suite.T(), "Earn", entry.EntryType)
+	assert.Equal(suite.T(), 30, entry.Remaining)
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestEarn_AlreadyAwarded() {
+	createdAt := time.Now()
+	expiresAt := createdAt.AddDate(1, 0, 0)
+	entry := model.PointLedger{UserID: "1", TransactionID: 12, Points: 30, Description: "Transfer reward", ExpiresAt: &expiresAt, CreatedAt: createdAt}
+	suite.mockSql.ExpectQuery("INSERT INTO tx_point_ledger").WithArgs("1", 12, 30, "Transfer reward", expiresAt, createdAt).WillReturnError(sql.ErrNoRows)
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.Earn(&entry)
+	assert.EqualError(suite.T(), err, "points already awarded")
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestBurn_ConsumesOldestLotsFirst() {
+	entry := model.PointLedger{UserID: "1", EntryType: "Burn", Points: 50, Description: "Redeem Voucher", CreatedAt: time.Now()}
+	suite.mockSql.ExpectExec("UPDATE mst_users SET point = point - \\$1 WHERE user_id = \\$2 AND point >= \\$1").WithArgs(50, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	firstExpiry := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	secondExpiry := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.mockSql.ExpectQuery("SELECT ledger_id, remaining, expires_at FROM tx_point_ledger(.+)ORDER BY expires_at, ledger_id").WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"ledger_id", "remaining", "expires_at"}).AddRow(3, 30, firstExpiry).AddRow(4, 80, secondExpiry))
+	suite.mockSql.ExpectExec("UPDATE tx_point_ledger SET remaining = remaining - \\$1").WithArgs(30, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE tx_point_ledger SET remaining = remaining - \\$1").WithArgs(20, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("INSERT INTO tx_point_ledger").WithArgs("1", 0, "Burn", -50, "Redeem Voucher", entry.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"ledger_id"}).AddRow(9))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.Burn(&entry)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 9, entry.LedgerID)
+	assert.Equal(suite.T(), -50, entry.Points)
+	assert.Equal(suite.T(), firstExpiry, *entry.ExpiresAt)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestBurn_InsufficientPoints() {
+	entry := model.PointLedger{UserID: "1", EntryType: "Burn", Points: 50, Description: "Redeem Voucher", CreatedAt: time.Now()}
+	suite.mockSql.ExpectExec("UPDATE mst_users SET point = point - \\$1").WithArgs(50, "1").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.Burn(&entry)
+	assert.EqualError(suite.T(), err, "insufficient points")
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestCredit_Success() {
+	createdAt := time.Now()
+	expiresAt := createdAt.AddDate(0, 2, 0)
+	entry := model.PointLedger{UserID: "2", TransactionID: 7, EntryType: "Transfer In", Points: 40, Description: "Transfer from Budi", ExpiresAt: &expiresAt, CreatedAt: createdAt}
+	suite.mockSql.ExpectQuery("INSERT INTO tx_point_ledger").WithArgs("2", 7, "Transfer In", 40, "Transfer from Budi", &expiresAt, createdAt).
+		WillReturnRows(sqlmock.NewRows([]string{"ledger_id"}).AddRow(11))
+	suite.mockSql.ExpectExec("UPDATE mst_users SET point = point \\+ \\$1").WithArgs(40, "2").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.Credit(&entry)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 11, entry.LedgerID)
+	assert.Equal(suite.T(), 40, entry.Remaining)
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestReserveDailyCap_Success() {
+	day := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	suite.mockSql.ExpectExec("INSERT INTO tx_point_daily_usage").WithArgs("1", "Convert", "2026-10-19", 250, 1000).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.ReserveDailyCap("1", "Convert", day, 250, 1000)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestReserveDailyCap_Reached() {
+	day := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	suite.mockSql.ExpectExec("INSERT INTO tx_point_daily_usage").WithArgs("1", "Convert", "2026-10-19", 250, 1000).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.ReserveDailyCap("1", "Convert", day, 250, 1000)
+	assert.ErrorIs(suite.T(), err, ErrDailyCapReached)
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestRecordMovement_Success() {
+	movement := model.PointMovement{TransactionType: "Point Transfer", SenderID: "1", RecipientID: "2", SenderName: "Budi", RecipientName: "Sari", Points: 40, Note: "thanks"}
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Point Transfer", today(), "1", "2").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval\\(\\)").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(7))
+	suite.mockSql.ExpectExec("INSERT INTO tx_point").WithArgs(7, 40, 0, "Budi", "Sari", "thanks").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.RecordMovement(&movement)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 7, movement.TransactionID)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestRecordMovement_Failed() {
+	movement := model.PointMovement{TransactionType: "Point Conversion", SenderID: "1", Points: 40, Amount: 400}
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WillReturnError(sql.ErrConnDone)
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.RecordMovement(&movement)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestExpire_Success() {
+	now := time.Now()
+	lot := &model.PointLedger{LedgerID: 3, UserID: "1", Remaining: 30, Description: "Transfer reward"}
+	suite.mockSql.ExpectExec("UPDATE tx_point_ledger SET remaining = 0").WithArgs(3, 30).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_point_ledger").WithArgs("1", -30, "Transfer reward expired", now).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_users SET point = GREATEST").WithArgs(30, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.Expire(lot, now)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestExpire_UsedInTheMeantime() {
+	lot := &model.PointLedger{LedgerID: 3, UserID: "1", Remaining: 30}
+	suite.mockSql.ExpectExec("UPDATE tx_point_ledger SET remaining = 0").WithArgs(3, 30).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPointLedgerRepository(suite.mockDB)
+	err := repo.Expire(lot, time.Now())
+	assert.EqualError(suite.T(), err, "points were used in the meantime")
+}
+
+func (suite *PointLedgerRepositoryTestSuite) TestGetExpiringSoon_Success() {
+	before := time.Now()
+	rows := sqlmock.NewRows([]string{"user_id", "sum", "expires_on"}).AddRow("1", 30, "2026-10-22")
+	suite.mockSql.ExpectQuery("SELECT user_id, SUM\\(remaining\\)(.+)expiry_notified = FALSE").WithArgs(before).WillReturnRows(rows)
+	repo := NewPointLedgerRepository(suite.mockDB)
+	res, err := repo.GetExpiringSoon(before)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.PointExpiry{{UserID: "1", Points: 30, ExpiresOn: "2026-10-22"}}, res)
+}
+
+func (suite *PointLedgerRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *PointLedgerRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestPointLedgerRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PointLedgerRepositoryTestSuite))
+}
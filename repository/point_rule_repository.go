@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type PointRuleRepository interface {
+	GetAll() ([]*model.PointRule, error)
+	GetByID(ruleID int) (*model.PointRule, error)
+	GetActive(transactionType string, at time.Time) ([]*model.PointRule, error)
+	Create(rule *model.PointRule) error
+	Update(rule *model.PointRule) error
+	Deactivate(ruleID int) error
+	GetBadgeMultiplier(badgeID int) (int, error)
+}
+
+type pointRuleRepository struct {
+	db *sql.DB
+}
+
+const pointRuleColumns = "rule_id, name, transaction_type, min_amount, points, percent_bps, max_points, starts_at, ends_at, active, created_at"
+
+func scanPointRule(scanner interface{ Scan(...interface{}) error }, rule *model.PointRule) error {
+	return scanner.Scan(&rule.RuleID, &rule.Name, &rule.TransactionType, &rule.MinAmount, &rule.Points, &rule.PercentBps, &rule.MaxPoints,
+		&rule.StartsAt, &rule.EndsAt, &rule.Active, &rule.CreatedAt)
+}
+
+func (r *pointRuleRepository) queryRules(query string, args ...interface{}) ([]*model.PointRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point rules: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []*model.PointRule
+	for rows.Next() {
+		rule := &model.PointRule{}
+		if err := scanPointRule(rows, rule); err != nil {
+			return nil, fmt.Errorf("failed to scan point rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get point rules: %v", err)
+	}
+	return rules, nil
+}
+
+func (r *pointRuleRepository) GetAll() ([]*model.PointRule, error) {
+	return r.queryRules("SELECT " + pointRuleColumns + " FROM mst_point_rule ORDER BY transaction_type, name, min_amount")
+}
+
+func (r *pointRuleRepository) GetByID(ruleID int) (*model.PointRule, error) {
+	var rule model.PointRule
+	if err := scanPointRule(r.db.QueryRow("SELECT "+pointRuleColumns+" FROM mst_point_rule WHERE rule_id = $1", ruleID), &rule); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("point rule not found")
+		}
+		return nil, fmt.Errorf("failed to get point rule: %v", err)
+	}
+	return &rule, nil
+}
+
+// GetActive returns the active rules of a transaction type whose campaign
+// window, if any, contains the given time.
+func (r *pointRuleRepository) GetActive(transactionType string, at time.Time) ([]*model.PointRule, error) {
+	query := "SELECT " + pointRuleColumns + ` FROM mst_point_rule
+	WHERE transaction_type = $1 AND active = TRUE AND (starts_at IS NULL OR starts_at <= $2) AND (ends_at IS NULL OR ends_at > $2)
+	ORDER BY name, min_amount`
+	return r.queryRules(query, transactionType, at)
+}
+
+func (r *pointRuleRepository) Create(rule *model.PointRule) error {
+	query := `INSERT INTO mst_point_rule (name, transaction_type, min_amount, points, percent_bps, max_points, starts_at, ends_at, active, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING rule_id`
+	err := r.db.QueryRow(query, rule.Name, rule.TransactionType, rule.MinAmount, rule.Points, rule.PercentBps, rule.MaxPoints,
+		rule.StartsAt, rule.EndsAt, rule.Active, rule.CreatedAt).Scan(&rule.RuleID)
+	if err != nil {
+		return fmt.Errorf("failed to create point rule: %v", err)
+	}
+	return nil
+}
+
+func (r *pointRuleRepository) Update(rule *model.PointRule) error {
+	query := `UPDATE mst_point_rule SET name = $1, transaction_type = $2, min_amount = $3, points = $4, percent_bps = $5, max_points = $6,
+	starts_at = $7, ends_at = $8, active = $9 WHERE rule_id = $10`
+	res, err := r.db.Exec(query, rule.Name, rule.TransactionType, rule.MinAmount, rule.Points, rule.PercentBps, rule.MaxPoints,
+		rule.StartsAt, rule.EndsAt, rule.Active, rule.RuleID)
+	if err != nil {
+		return fmt.Errorf("failed to update point rule: %v", err)
+	}
+	return affectedOrError(res, "update point rule", "point rule not found")
+}
+
+func (r *pointRuleRepository) Deactivate(ruleID int) error {
+	res, err := r.db.Exec("UPDATE mst_point_rule SET active = FALSE WHERE rule_id = $1", ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate point rule: %v", err)
+	}
+	return affectedOrError(res, "deactivate point rule", "point rule not found")
+}
+
+// GetBadgeMultiplier returns the earning multiplier of a badge in percent.
+// Users without a known badge earn at 100%.
+func (r *pointRuleRepository) GetBadgeMultiplier(badgeID int) (int, error) {
+	var multiplier int
+	err := r.db.QueryRow("SELECT point_multiplier FROM mst_badges WHERE badge_id = $1", badgeID).Scan(&multiplier)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 100, nil
+		}
+		return 0, fmt.Errorf("failed to get badge multiplier: %v", err)
+	}
+	return multiplier, nil
+}
+
+func NewPointRuleRepository(db *sql.DB) PointRuleRepository {
+	return &pointRuleRepository{db: db}
+}
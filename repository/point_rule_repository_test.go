@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyPointRule = model.PointRule{
+	RuleID:          1,
+	Name:            "Transfer bonus",
+	TransactionType: "Transfer",
+	MinAmount:       50000,
+	Points:          20,
+	Active:          true,
+	CreatedAt:       time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var pointRuleColumnNames = []string{"rule_id", "name", "transaction_type", "min_amount", "points", "percent_bps", "max_points", "starts_at", "ends_at", "active", "created_at"}
+
+type PointRuleRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *PointRuleRepositoryTestSuite) TestGetActive_Success() {
+	at := time.Now()
+	p := dummyPointRule
+	rows := sqlmock.NewRows(pointRuleColumnNames).
+		AddRow(p.RuleID, p.Name, p.TransactionType, p.MinAmount, p.Points, p.PercentBps, p.MaxPoints, p.StartsAt, p.EndsAt, p.Active, p.CreatedAt)
+	suite.mockSql.ExpectQuery("SELECT rule_id(.+)FROM mst_point_rule WHERE transaction_type = \\$1 AND active = TRUE").WithArgs("Transfer", at).WillReturnRows(rows)
+	repo := NewPointRuleRepository(suite.mockDB)
+	res, err := repo.GetActive("Transfer", at)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.PointRule{&p}, res)
+}
+
+func (suite *PointRuleRepositoryTestSuite) TestGetByID_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT rule_id").WithArgs(9).WillReturnError(sql.ErrNoRows)
+	repo := NewPointRuleRepository(suite.mockDB)
+	res, err := repo.GetByID(9)
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "point rule not found")
+}
+
+func (suite *PointRuleRepositoryTestSuite) TestCreate_Success() {
+	rule := dummyPointRule
+	rule.RuleID = 0
+	suite.mockSql.ExpectQuery("INSERT INTO mst_point_rule").
+		WithArgs(rule.Name, rule.TransactionType, 50000, 20, 0, 0, rule.StartsAt, rule.EndsAt, true, rule.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"rule_id"}).AddRow(3))
+	repo := NewPointRuleRepository(suite.mockDB)
+	err := repo.Create(&rule)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, rule.RuleID)
+}
+
+func (suite *PointRuleRepositoryTestSuite) TestDeactivate_NotFound() {
+	suite.mockSql.ExpectExec("UPDATE mst_point_rule SET active = FALSE").WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPointRuleRepository(suite.mockDB)
+	err := repo.Deactivate(9)
+	assert.EqualError(suite.T(), err, "point rule not found")
+}
+
+func (suite *PointRuleRepositoryTestSuite) TestGetBadgeMultiplier_Success() {
+	suite.mockSql.ExpectQuery("SELECT point_multiplier FROM mst_badges").WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"point_multiplier"}).AddRow(150))
+	repo := NewPointRuleRepository(suite.mockDB)
+	res, err := repo.GetBadgeMultiplier(3)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 150, res)
+}
+
+func (suite *PointRuleRepositoryTestSuite) TestGetBadgeMultiplier_UnknownBadge() {
+	suite.mockSql.ExpectQuery("SELECT point_multiplier FROM mst_badges").WithArgs(0).WillReturnError(sql.ErrNoRows)
+	repo := NewPointRuleRepository(suite.mockDB)
+	res, err := repo.GetBadgeMultiplier(0)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 100, res)
+}
+
+func (suite *PointRuleRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *PointRuleRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestPointRuleRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PointRuleRepositoryTestSuite))
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrPromoRedemptionNotFound is returned when no reserved redemption
+	// matches.
+	ErrPromoRedemptionNotFound = errors.New("promo redemption not found")
+	// ErrPromoRedemptionProcessed is returned by Apply and Release when the
+	// redemption was applied or released by someone else first.
+	ErrPromoRedemptionProcessed = errors.New("promo redemption already processed")
+)
+
+type PromoRepository interface {
+	GetAll() ([]*model.PromoCampaign, error)
+	GetByID(campaignID int) (*model.PromoCampaign, error)
+	GetByCode(code string) (*model.PromoCampaign, error)
+	Create(campaign *model.PromoCampaign) error
+	Update(campaign *model.PromoCampaign) error
+	Deactivate(campaignID int) error
+	Reserve(redemption *model.PromoRedemption, perUserLimit int) error
+	SetTransaction(redemptionID int, transactionID int) error
+	GetReservedByTransaction(transactionID int) (*model.PromoRedemption, error)
+	GetStaleReservations(before time.Time) ([]*model.PromoRedemption, error)
+	Apply(redemption *model.PromoRedemption) error
+	Release(redemption *model.PromoRedemption) error
+	RecordCashback(redemption *model.PromoRedemption) (int, error)
+}
+
+type promoRepository struct {
+	db *sql.DB
+}
+
+const promoCampaignColumns = `c.campaign_id, c.code, c.name, c.transaction_type, c.benefit_type, c.cashback_amount, c.cashback_bps, c.max_cashback, c.min_amount,
+	c.per_user_limit, c.budget, c.budget_used, (SELECT COUNT(*) FROM tx_promo_redemption r WHERE r.campaign_id = c.campaign_id AND r.status = 'Applied'),
+	c.eligible_badge_ids, c.starts_at, c.ends_at, c.active, c.created_at`
+
+const promoRedemptionColumns = `r.redemption_id, r.campaign_id, c.code, r.user_id, r.transaction_type, COALESCE(r.transaction_id, 0), r.amount, r.benefit_type, r.benefit,
+	r.status, r.created_at`
+
+func scanPromoCampaign(scanner interface{ Scan(...interface{}) error }, campaign *model.PromoCampaign) error {
+	var badges pq.Int64Array
+	err := scanner.Scan(&campaign.CampaignID, &campaign.Code, &campaign.Name, &campaign.TransactionType, &campaign.BenefitType, &campaign.CashbackAmount,
+		&campaign.CashbackBps, &campaign.MaxCashback, &campaign.MinAmount, &campaign.PerUserLimit, &campaign.Budget, &campaign.BudgetUsed,
+		&campaign.RedemptionCount, &badges, &campaign.StartsAt, &campaign.EndsAt, &campaign.Active, &campaign.CreatedAt)
+	if err != nil {
+		return err
+	}
+	campaign.EligibleBadgeIDs = make([]int, len(badges))
+	for i, badgeID := range badges {
+		campaign.EligibleBadgeIDs[i] = int(badgeID)
+	}
+	return nil
+}
+
+func scanPromoRedemption(scanner interface{ Scan(...interface{}) error }, redemption *model.PromoRedemption) error {
+	return scanner.Scan(&redemption.RedemptionID, &redemption.CampaignID, &redemption.Code, &redemption.UserID, &redemption.TransactionType,
+		&redemption.TransactionID, &redemption.Amount, &redemption.BenefitType, &redemption.Benefit, &redemption.Status, &redemption.CreatedAt)
+}
+
+func badgeArray(badgeIDs []int) pq.Int64Array {
+	badges := make(pq.Int64Array, len(badgeIDs))
+	for i, badgeID := range badgeIDs {
+		badges[i] = int64(badgeID)
+	}
+	return badges
+}
+
+func (r *promoRepository) GetAll() ([]*model.PromoCampaign, error) {
+	rows, err := r.db.Query("SELECT " + promoCampaignColumns + " FROM mst_promo_campaign c ORDER BY c.starts_at DESC, c.campaign_id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo campaigns: %v", err)
+	}
+	defer rows.Close()
+
+	var campaigns []*model.PromoCampaign
+	for rows.Next() {
+		campaign := &model.PromoCampaign{}
+		if err := scanPromoCampaign(rows, campaign); err != nil {
+			return nil, fmt.Errorf("failed to scan promo campaign: %v", err)
+		}
+		campaigns = append(campaigns, campaign)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get promo campaigns: %v", err)
+	}
+	return campaigns, nil
+}
+
+func (r *promoRepository) getCampaign(where string, arg interface{}) (*model.PromoCampaign, error) {
+	var campaign model.PromoCampaign
+	if err := scanPromoCampaign(r.db.QueryRow("SELECT "+promoCampaignColumns+" FROM mst_promo_campaign c WHERE "+where, arg), &campaign); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("promo code not found")
+		}
+		return nil, fmt.Errorf("failed to get promo campaign: %v", err)
+	}
+	return &campaign, nil
+}
+
+func (r *promoRepository) GetByID(campaignID int) (*model.PromoCampaign, error) {
+	return r.getCampaign("c.campaign_id = $1", campaignID)
+}
+
+func (r *promoRepository) GetByCode(code string) (*model.PromoCampaign, error) {
+	return r.getCampaign("c.code = $1", code)
+}
+
+func (r *promoRepository) Create(campaign *model.PromoCampaign) error {
+	query := `INSERT INTO mst_promo_campaign (code, name, transaction_type, benefit_type, cashback_amount, cashback_bps, max_cashback, min_amount,
+	per_user_limit, budget, budget_used, eligible_badge_ids, starts_at, ends_at, active, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 0, $11, $12, $13, $14, $15) RETURNING campaign_id`
+	err := r.db.QueryRow(query, campaign.Code, campaign.Name, campaign.TransactionType, campaign.BenefitType, campaign.CashbackAmount, campaign.CashbackBps,
+		campaign.MaxCashback, campaign.MinAmount, campaign.PerUserLimit, campaign.Budget, badgeArray(campaign.EligibleBadgeIDs), campaign.StartsAt,
+		campaign.EndsAt, campaign.Active, campaign.CreatedAt).Scan(&campaign.CampaignID)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return errors.New("promo code already exists")
+		}
+		return fmt.Errorf("failed to create promo campaign: %v", err)
+	}
+	return nil
+}
+
+// Update changes a campaign but never its code or consumed budget.
+func (r *promoRepository) Update(campaign *model.PromoCampaign) error {
+	query := `UPDATE mst_promo_campaign SET name = $1, transaction_type = $2, benefit_type = $3, cashback_amount = $4, cashback_bps = $5, max_cashback = $6,
+	min_amount = $7, per_user_limit = $8, budget = $9, eligible_badge_ids = $10, starts_at = $11, ends_at = $12, active = $13 WHERE campaign_id = $14`
+	res, err := r.db.Exec(query, campaign.Name, campaign.TransactionType, campaign.BenefitType, campaign.CashbackAmount, campaign.CashbackBps,
+		campaign.MaxCashback, campaign.MinAmount, campaign.PerUserLimit, campaign.Budget, badgeArray(campaign.EligibleBadgeIDs), campaign.StartsAt,
+		campaign.EndsAt, campaign.Active, campaign.CampaignID)
+	if err != nil {
+		return fmt.Errorf("failed to update promo campaign: %v", err)
+	}
+	return affectedOrError(res, "update promo campaign", "promo code not found")
+}
+
+func (r *promoRepository) Deactivate(campaignID int) error {
+	res, err := r.db.Exec("UPDATE mst_promo_campaign SET active = FALSE WHERE campaign_id = $1", campaignID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate promo campaign: %v", err)
+	}
+	return affectedOrError(res, "deactivate promo campaign", "promo code not found")
+}
+
+// Reserve counts a use of the campaign by the user, takes the benefit out of
+// the campaign budget and records the redemption. Both counters are guarded
+// updates, so concurrent redemptions can never pass the per-user limit or
+// overspend the budget. A perUserLimit of 0 means unlimited.
+func (r *promoRepository) Reserve(redemption *model.PromoRedemption, perUserLimit int) error {
+	query := `INSERT INTO tx_promo_usage (campaign_id, user_id, used) VALUES ($1, $2, 1)
+	ON CONFLICT (campaign_id, user_id) DO UPDATE SET used = tx_promo_usage.used + 1 WHERE $3 = 0 OR tx_promo_usage.used < $3`
+	res, err := r.db.Exec(query, redemption.CampaignID, redemption.UserID, perUserLimit)
+	if err != nil {
+		return fmt.Errorf("failed to reserve promo usage: %v", err)
+	}
+	if err := affectedOrError(res, "reserve promo usage", "promo code usage limit reached"); err != nil {
+		return err
+	}
+
+	query = "UPDATE mst_promo_campaign SET budget_used = budget_used + $1 WHERE campaign_id = $2 AND (budget = 0 OR budget_used + $1 <= budget)"
+	res, err = r.db.Exec(query, redemption.Benefit, redemption.CampaignID)
+	if err != nil {
+		r.giveUsageBack(redemption)
+		return fmt.Errorf("failed to reserve promo budget: %v", err)
+	}
+	if err := affectedOrError(res, "reserve promo budget", "promo budget exhausted"); err != nil {
+		r.giveUsageBack(redemption)
+		return err
+	}
+
+	query = `INSERT INTO tx_promo_redemption (campaign_id, user_id, transaction_type, transaction_id, amount, benefit_type, benefit, status, created_at)
+	VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7, 'Reserved', $8) RETURNING redemption_id`
+	err = r.db.QueryRow(query, redemption.CampaignID, redemption.UserID, redemption.TransactionType, redemption.TransactionID, redemption.Amount,
+		redemption.BenefitType, redemption.Benefit, redemption.CreatedAt).Scan(&redemption.RedemptionID)
+	if err != nil {
+		// Give the budget and the use back so a failed insert does not leak them.
+		_, _ = r.db.Exec("UPDATE mst_promo_campaign SET budget_used = budget_used - $1 WHERE campaign_id = $2", redemption.Benefit, redemption.CampaignID)
+		r.giveUsageBack(redemption)
+		return fmt.Errorf("failed to create promo redemption: %v", err)
+	}
+	redemption.Status = "Reserved"
+	return nil
+}
+
+func (r *promoRepository) giveUsageBack(redemption *model.PromoRedemption) {
+	_, _ = r.db.Exec("UPDATE tx_promo_usage SET used = used - 1 WHERE campaign_id = $1 AND user_id = $2 AND used > 0", redemption.CampaignID, redemption.UserID)
+}
+
+func (r *promoRepository) SetTransaction(redemptionID int, transactionID int) error {
+	res, err := r.db.Exec("UPDATE tx_promo_redemption SET transaction_id = $1 WHERE redemption_id = $2 AND status = 'Reserved'", transactionID, redemptionID)
+	if err != nil {
+		return fmt.Errorf("failed to update promo redemption: %v", err)
+	}
+	return affectedOr(res, "update promo redemption", ErrPromoRedemptionNotFound)
+}
+
+func (r *promoRepository) GetReservedByTransaction(transactionID int) (*model.PromoRedemption, error) {
+	redemption := &model.PromoRedemption{}
+	query := "SELECT " + promoRedemptionColumns + ` FROM tx_promo_redemption r JOIN mst_promo_campaign c ON r.campaign_id = c.campaign_id
+	WHERE r.transaction_id = $1 AND r.status = 'Reserved'`
+	if err := scanPromoRedemption(r.db.QueryRow(query, transactionID), redemption); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPromoRedemptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get promo redemption: %v", err)
+	}
+	return redemption, nil
+}
+
+// GetStaleReservations returns reservations made before the given time
+// whose transaction never completed.
+func (r *promoRepository) GetStaleReservations(before time.Time) ([]*model.PromoRedemption, error) {
+	query := "SELECT " + promoRedemptionColumns + ` FROM tx_promo_redemption r JOIN mst_promo_campaign c ON r.campaign_id = c.campaign_id
+	WHERE r.status = 'Reserved' AND r.created_at < $1 ORDER BY r.redemption_id`
+	rows, err := r.db.Query(query, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo reservations: %v", err)
+	}
+	defer rows.Close()
+
+	var redemptions []*model.PromoRedemption
+	for rows.Next() {
+		redemption := &model.PromoRedemption{}
+		if err := scanPromoRedemption(rows, redemption); err != nil {
+			return nil, fmt.Errorf("failed to scan promo redemption: %v", err)
+		}
+		redemptions = append(redemptions, redemption)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get promo reservations: %v", err)
+	}
+	return redemptions, nil
+}
+
+// Apply marks a reserved redemption as used by its transaction. Only one
+// caller can apply it, so cashback is never paid twice.
+func (r *promoRepository) Apply(redemption *model.PromoRedemption) error {
+	query := "UPDATE tx_promo_redemption SET status = 'Applied', transaction_id = $1 WHERE redemption_id = $2 AND status = 'Reserved'"
+	res, err := r.db.Exec(query, redemption.TransactionID, redemption.RedemptionID)
+	if err != nil {
+		return fmt.Errorf("failed to apply promo redemption: %v", err)
+	}
+	if err := affectedOr(res, "apply promo redemption", ErrPromoRedemptionProcessed); err != nil {
+		return err
+	}
+	redemption.Status = "Applied"
+	return nil
+}
+
+// Release cancels a reserved redemption and returns its benefit to the
+// campaign budget and its use to the user.
+func (r *promoRepository) Release(redemption *model.PromoRedemption) error {
+	res, err := r.db.Exec("UPDATE tx_promo_redemption SET status = 'Released' WHERE redemption_id = $1 AND status = 'Reserved'", redemption.RedemptionID)
+	if err != nil {
+		return fmt.Errorf("failed to release promo redemption: %v", err)
+	}
+	if err := affectedOr(res, "release promo redemption", ErrPromoRedemptionProcessed); err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec("UPDATE mst_promo_campaign SET budget_used = budget_used - $1 WHERE campaign_id = $2", redemption.Benefit, redemption.CampaignID)
+	if err != nil {
+		return fmt.Errorf("failed to release promo budget: %v", err)
+	}
+	_, err = r.db.Exec("UPDATE tx_promo_usage SET used = used - 1 WHERE campaign_id = $1 AND user_id = $2 AND used > 0", redemption.CampaignID, redemption.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to release promo usage: %v", err)
+	}
+	redemption.Status = "Released"
+	return nil
+}
+
+// RecordCashback records the cashback of a redemption as its own
+// transaction and returns the transaction ID.
+func (r *promoRepository) RecordCashback(redemption *model.PromoRedemption) (int, error) {
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
+	_, err := r.db.Exec(query, "Cashback", today(), redemption.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_cashback (transaction_id, redemption_id, amount) VALUES ($1, $2, $3)"
+	_, err = r.db.Exec(query, txID, redemption.RedemptionID, redemption.Benefit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert cashback: %v", err)
+	}
+	return txID, nil
+}
+
+func NewPromoRepository(db *sql.DB) PromoRepository {
+	return &promoRepository{db: db}
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var promoCampaignTestColumns = []string{"campaign_id", "code", "name", "transaction_type", "benefit_type", "cashback_amount", "cashback_bps", "max_cashback",
+	"min_amount", "per_user_limit", "budget", "budget_used", "redemption_count", "eligible_badge_ids", "starts_at", "ends_at", "active", "created_at"}
+
+type PromoRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *PromoRepositoryTestSuite) TestGetByCode_Success() {
+	now := time.Now()
+	rows := sqlmock.NewRows(promoCampaignTestColumns).
+		AddRow(1, "HEMAT10", "Hemat 10%", "Merchant Payment", "Cashback", 0, 1000, 10000, 0, 1, 1000000, 25000, 3, "{2,3}", now, now.AddDate(0, 1, 0), true, now)
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM mst_promo_campaign c WHERE c.code = \\$1").WithArgs("HEMAT10").WillReturnRows(rows)
+	repo := NewPromoRepository(suite.mockDB)
+	res, err := repo.GetByCode("HEMAT10")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int{2, 3}, res.EligibleBadgeIDs)
+	assert.Equal(suite.T(), 25000, res.BudgetUsed)
+	assert.Equal(suite.T(), 3, res.RedemptionCount)
+}
+
+func (suite *PromoRepositoryTestSuite) TestGetByCode_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM mst_promo_campaign c WHERE c.code = \\$1").WithArgs("NOPE").WillReturnError(sql.ErrNoRows)
+	repo := NewPromoRepository(suite.mockDB)
+	res, err := repo.GetByCode("NOPE")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "promo code not found")
+}
+
+func (suite *PromoRepositoryTestSuite) TestCreate_DuplicateCode() {
+	now := time.Now()
+	campaign := model.PromoCampaign{Code: "HEMAT10", Name: "Hemat", TransactionType: "Deposit", BenefitType: "Cashback", CashbackAmount: 5000, StartsAt: now, EndsAt: now, CreatedAt: now}
+	suite.mockSql.ExpectQuery("INSERT INTO mst_promo_campaign").WillReturnError(errors.New("pq: duplicate key value violates unique constraint"))
+	repo := NewPromoRepository(suite.mockDB)
+	err := repo.Create(&campaign)
+	assert.EqualError(suite.T(), err, "promo code already exists")
+}
+
+func (suite *PromoRepositoryTestSuite) TestReserve_Success() {
+	redemption := model.PromoRedemption{CampaignID: 1, UserID: "1", TransactionType: "Deposit", Amount: 100000, BenefitType: "Cashback", Benefit: 5000, CreatedAt: time.Now()}
+	suite.mockSql.ExpectExec("INSERT INTO tx_promo_usage").WithArgs(1, "1", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_promo_campaign SET budget_used = budget_used \\+ \\$1").WithArgs(5000, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("INSERT INTO tx_promo_redemption").WithArgs(1, "1", "Deposit", 0, 100000, "Cashback", 5000, redemption.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"redemption_id"}).AddRow(7))
+	repo := NewPromoRepository(suite.mockDB)
+	err := repo.Reserve(&redemption, 2)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 7, redemption.RedemptionID)
+	assert.Equal(suite.T(), "Reserved", redemption.Status)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *PromoRepositoryTestSuite) TestReserve_UsageLimitReached() {
+	redemption := model.PromoRedemption{CampaignID: 1, UserID: "1", Benefit: 5000}
+	suite.mockSql.ExpectExec("INSERT INTO tx_promo_usage").WithArgs(1, "1", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPromoRepository(suite.mockDB)
+	err := repo.Reserve(&redemption, 2)
+	assert.EqualError(suite.T(), err, "promo code usage limit reached")
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *PromoRepositoryTestSuite) TestReserve_BudgetExhausted() {
+	redemption := model.PromoRedemption{CampaignID: 1, UserID: "1", Benefit: 5000}
+	suite.mockSql.ExpectExec("INSERT INTO tx_promo_usage").WithArgs(1, "1", 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_promo_campaign SET budget_used = budget_used \\+ \\$1").WithArgs(5000, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mockSql.ExpectExec("UPDATE tx_promo_usage SET used = used - 1").WithArgs(1, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPromoRepository(suite.mockDB)
+	err := repo.Reserve(&redemption, 0)
+	assert.EqualError(suite.T(), err, "promo budget exhausted")
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *PromoRepositoryTestSuite) TestReserve_InsertFailsGivesBudgetBack() {
+	redemption := model.PromoRedemption{CampaignID: 1, UserID: "1", Benefit: 5000}
+	suite.mockSql.ExpectExec("INSERT INTO tx_promo_usage").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_promo_campaign SET budget_used = budget_used \\+ \\$1").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("INSERT INTO tx_promo_redemption").WillReturnError(errors.New("dummy error"))
+	suite.mockSql.ExpectExec("UPDATE mst_promo_campaign SET budget_used = budget_used - \\$1").WithArgs(5000, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE tx_promo_usage SET used = used - 1").WithArgs(1, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPromoRepository(suite.mockDB)
+	err := repo.Reserve(&redemption, 0)
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *PromoRepositoryTestSuite) TestApply_AlreadyProcessed() {
+	redemption := model.PromoRedemption{RedemptionID: 7, TransactionID: 20}
+	suite.mockSql.ExpectExec("UPDATE tx_promo_redemption SET status = 'Applied'").WithArgs(20, 7).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewPromoRepository(suite.mockDB)
+	err := repo.Apply(&redemption)
+	assert.EqualError(suite.T(), err, "promo redemption already processed")
+}
+
+func (suite *PromoRepositoryTestSuite) TestRelease_Success() {
+	redemption := model.PromoRedemption{RedemptionID: 7, CampaignID: 1, UserID: "1", Benefit: 5000}
+	suite.mockSql.ExpectExec("UPDATE tx_promo_redemption SET status = 'Released'").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_promo_campaign SET budget_used = budget_used - \\$1").WithArgs(5000, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE tx_promo_usage SET used = used - 1").WithArgs(1, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewPromoRepository(suite.mockDB)
+	err := repo.Release(&redemption)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Released", redemption.Status)
+}
+
+func (suite *PromoRepositoryTestSuite) TestRecordCashback_Success() {
+	redemption := model.PromoRedemption{RedemptionID: 7, UserID: "1", Benefit: 5000}
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Cashback", today(), "1").WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval\\(\\)").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(21))
+	suite.mockSql.ExpectExec("INSERT INTO tx_cashback").WithArgs(21, 7, 5000).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewPromoRepository(suite.mockDB)
+	res, err := repo.RecordCashback(&redemption)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 21, res)
+}
+
+func (suite *PromoRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *PromoRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestPromoRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PromoRepositoryTestSuite))
+}
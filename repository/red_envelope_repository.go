@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type RedEnvelopeRepository interface {
+	Create(envelope *model.RedEnvelope) error
+	GetByCode(code string) (*model.RedEnvelope, error)
+	GetByCreator(userID string) ([]*model.RedEnvelope, error)
+	GetClaims(envelopeID int) ([]*model.RedEnvelopeClaim, error)
+	HasClaimed(envelopeID int, userID string) (bool, error)
+	Claim(envelope *model.RedEnvelope, transfer *model.Transfer) error
+	GetExpired(now time.Time) ([]*model.RedEnvelope, error)
+	Refund(envelope *model.RedEnvelope) error
+}
+
+type redEnvelopeRepository struct {
+	db *sql.DB
+}
+
+const redEnvelopeColumns = `e.envelope_id, e.claim_code, e.creator_id, u.name, e.total_amount, e.remaining_amount, e.slots, e.remaining_slots,
+	e.split_type, e.message, e.status, e.expired_at, e.transaction_id`
+
+func scanRedEnvelope(scanner interface{ Scan(...interface{}) error }, envelope *model.RedEnvelope) error {
+	return scanner.Scan(&envelope.EnvelopeID, &envelope.ClaimCode, &envelope.CreatorID, &envelope.CreatorName, &envelope.TotalAmount, &envelope.RemainingAmount,
+		&envelope.Slots, &envelope.RemainingSlots, &envelope.SplitType, &envelope.Message, &envelope.Status, &envelope.ExpiredAt, &envelope.TransactionID)
+}
+
+func (r *redEnvelopeRepository) queryEnvelopes(query string, args ...interface{}) ([]*model.RedEnvelope, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get red envelopes: %v", err)
+	}
+	defer rows.Close()
+
+	var envelopes []*model.RedEnvelope
+	for rows.Next() {
+		envelope := &model.RedEnvelope{}
+		if err := scanRedEnvelope(rows, envelope); err != nil {
+			return nil, fmt.Errorf("failed to scan red envelope: %v", err)
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get red envelopes: %v", err)
+	}
+	return envelopes, nil
+}
+
+// recordMovement stores the envelope funding or refund as a transaction of
+// the creator.
+func (r *redEnvelopeRepository) recordMovement(txType string, envelopeID int, userID string, amount int) (int, error) {
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
+	_, err := r.db.Exec(query, txType, today(), userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_red_envelope (transaction_id, envelope_id, amount) VALUES ($1, $2, $3)"
+	_, err = r.db.Exec(query, txID, envelopeID, amount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert red envelope transaction: %v", err)
+	}
+	return txID, nil
+}
+
+func (r *redEnvelopeRepository) Create(envelope *model.RedEnvelope) error {
+	query := `INSERT INTO mst_red_envelope (claim_code, creator_id, total_amount, remaining_amount, slots, remaining_slots, split_type, message, status, expired_at)
+	VALUES ($1, $2, $3, $3, $4, $4, $5, $6, $7, $8) RETURNING envelope_id`
+	err := r.db.QueryRow(query, envelope.ClaimCode, envelope.CreatorID, envelope.TotalAmount, envelope.Slots, envelope.SplitType, envelope.Message, envelope.Status, envelope.ExpiredAt).Scan(&envelope.EnvelopeID)
+	if err != nil {
+		return fmt.Errorf("failed to create red envelope: %v", err)
+	}
+
+	txID, err := r.recordMovement("Red Envelope", envelope.EnvelopeID, envelope.CreatorID, envelope.TotalAmount)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("UPDATE mst_red_envelope SET transaction_id = $1 WHERE envelope_id = $2", txID, envelope.EnvelopeID)
+	if err != nil {
+		return fmt.Errorf("failed to update red envelope: %v", err)
+	}
+	envelope.TransactionID = txID
+	return nil
+}
+
+func (r *redEnvelopeRepository) GetByCode(code string) (*model.RedEnvelope, error) {
+	var envelope model.RedEnvelope
+	query := "SELECT " + redEnvelopeColumns + " FROM mst_red_envelope e JOIN mst_users u ON e.creator_id = u.user_id WHERE e.claim_code = $1"
+	if err := scanRedEnvelope(r.db.QueryRow(query, code), &envelope); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("envelope not found")
+		}
+		return nil, fmt.Errorf("failed to get red envelope: %v", err)
+	}
+	return &envelope, nil
+}
+
+func (r *redEnvelopeRepository) GetByCreator(userID string) ([]*model.RedEnvelope, error) {
+	query := "SELECT " + redEnvelopeColumns + " FROM mst_red_envelope e JOIN mst_users u ON e.creator_id = u.user_id WHERE e.creator_id = $1 ORDER BY e.envelope_id DESC"
+	return r.queryEnvelopes(query, userID)
+}
+
+func (r *redEnvelopeRepository) GetClaims(envelopeID int) ([]*model.RedEnvelopeClaim, error) {
+	query := `SELECT c.envelope_id, c.user_id, u.name, c.amount, c.transaction_id, CAST(c.claimed_at AS VARCHAR)
+	FROM tx_red_envelope_claim c
+	JOIN mst_users u ON c.user_id = u.user_id
+	WHERE c.envelope_id = $1
+	ORDER BY c.transaction_id`
+	rows, err := r.db.Query(query, envelopeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get red envelope claims: %v", err)
+	}
+	defer rows.Close()
+
+	var claims []*model.RedEnvelopeClaim
+	for rows.Next() {
+		claim := &model.RedEnvelopeClaim{}
+		err := rows.Scan(&claim.EnvelopeID, &claim.UserID, &claim.UserName, &claim.Amount, &claim.TransactionID, &claim.ClaimedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan red envelope claim: %v", err)
+		}
+		claims = append(claims, claim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get red envelope claims: %v", err)
+	}
+	return claims, nil
+}
+
+func (r *redEnvelopeRepository) HasClaimed(envelopeID int, userID string) (bool, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM tx_red_envelope_claim WHERE envelope_id = $1 AND user_id = $2", envelopeID, userID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check red envelope claim: %v", err)
+	}
+	return count > 0, nil
+}
+
+// Claim takes one slot of the envelope and records it as a transfer from the
+// creator. The remaining_slots condition rejects a share computed from a
+// stale envelope when claims race.
+func (r *redEnvelopeRepository) Claim(envelope *model.RedEnvelope, transfer *model.Transfer) error {
+	query := `UPDATE mst_red_envelope
+	SET remaining_amount = remaining_amount - $1, remaining_slots = remaining_slots - 1,
+		status = CASE WHEN remaining_slots = 1 THEN 'Completed' ELSE status END
+	WHERE envelope_id = $2 AND status = 'Active' AND remaining_slots = $3 AND expired_at > $4`
+	res, err := r.db.Exec(query, transfer.Amount, envelope.EnvelopeID, envelope.RemainingSlots, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to claim red envelope: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to claim red envelope: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("envelope is no longer available")
+	}
+
+	query = "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id, recipient_id) VALUES ($1, $2, $3, $4)"
+	_, err = r.db.Exec(query, "Red Envelope Claim", today(), transfer.SenderID, transfer.RecipientID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_transfer (transaction_id, sender_name, recipient_name, amount, sender_phone_number, recipient_phone_number, sender_id, recipient_id, status, note) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	_, err = r.db.Exec(query, txID, transfer.SenderName, transfer.RecipientName, transfer.Amount, transfer.SenderPhoneNumber, transfer.RecipientPhoneNumber, transfer.SenderID, transfer.RecipientID, "Success", transfer.Note)
+	if err != nil {
+		return fmt.Errorf("failed to insert transfer: %v", err)
+	}
+
+	query = "INSERT INTO tx_red_envelope_claim (envelope_id, user_id, amount, transaction_id, claimed_at) VALUES ($1, $2, $3, $4, $5)"
+	_, err = r.db.Exec(query, envelope.EnvelopeID, transfer.RecipientID, transfer.Amount, txID, today())
+	if err != nil {
+		return fmt.Errorf("failed to insert red envelope claim: %v", err)
+	}
+
+	transfer.TransactionID = txID
+	transfer.TxID = txID
+	transfer.TransactionType = "Red Envelope Claim"
+	transfer.TransactionDate = today()
+	transfer.Status = "Success"
+	return nil
+}
+
+func (r *redEnvelopeRepository) GetExpired(now time.Time) ([]*model.RedEnvelope, error) {
+	query := "SELECT " + redEnvelopeColumns + " FROM mst_red_envelope e JOIN mst_users u ON e.creator_id = u.user_id WHERE e.status = 'Active' AND e.expired_at <= $1"
+	return r.queryEnvelopes(query, now)
+}
+
+// Refund closes an expired envelope and records the unclaimed amount going
+// back to the creator.
+func (r *redEnvelopeRepository) Refund(envelope *model.RedEnvelope) error {
+	query := "UPDATE mst_red_envelope SET status = 'Expired', remaining_slots = 0, remaining_amount = 0 WHERE envelope_id = $1 AND status = 'Active' AND remaining_amount = $2"
+	res, err := r.db.Exec(query, envelope.EnvelopeID, envelope.RemainingAmount)
+	if err != nil {
+		return fmt.Errorf("failed to refund red envelope: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to refund red envelope: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("envelope is no longer available")
+	}
+
+	if envelope.RemainingAmount == 0 {
+		return nil
+	}
+	_, err = r.recordMovement("Red Envelope Refund", envelope.EnvelopeID, envelope.CreatorID, envelope.RemainingAmount)
+	return err
+}
+
+func NewRedEnvelopeRepository(db *sql.DB) RedEnvelopeRepository {
+	return &redEnvelopeRepository{db: db}
+}
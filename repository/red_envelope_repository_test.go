@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyRedEnvelope = model.RedEnvelope{
+	EnvelopeID:      1,
+	ClaimCode:       "ABCD2345",
+	CreatorID:       "1",
+	CreatorName:     "name1",
+	TotalAmount:     10000,
+	RemainingAmount: 10000,
+	Slots:           3,
+	RemainingSlots:  3,
+	SplitType:       "Equal",
+	Message:         "Selamat Lebaran",
+	Status:          "Active",
+	ExpiredAt:       time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	TransactionID:   5,
+}
+
+type RedEnvelopeRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *RedEnvelopeRepositoryTestSuite) TestCreate_Success() {
+	envelope := dummyRedEnvelope
+	envelope.EnvelopeID = 0
+	envelope.TransactionID = 0
+	suite.mockSql.ExpectQuery("INSERT INTO mst_red_envelope").WithArgs(envelope.ClaimCode, envelope.CreatorID, envelope.TotalAmount, envelope.Slots, envelope.SplitType, envelope.Message, envelope.Status, envelope.ExpiredAt).WillReturnRows(sqlmock.NewRows([]string{"envelope_id"}).AddRow(2))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Red Envelope", today(), envelope.CreatorID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(9))
+	suite.mockSql.ExpectExec("INSERT INTO tx_red_envelope").WithArgs(9, 2, envelope.TotalAmount).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_red_envelope SET transaction_id").WithArgs(9, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewRedEnvelopeRepository(suite.mockDB)
+	err := repo.Create(&envelope)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, envelope.EnvelopeID)
+	assert.Equal(suite.T(), 9, envelope.TransactionID)
+}
+
+func (suite *RedEnvelopeRepositoryTestSuite) TestGetByCode_Success() {
+	e := dummyRedEnvelope
+	rows := sqlmock.NewRows([]string{"envelope_id", "claim_code", "creator_id", "name", "total_amount", "remaining_amount", "slots", "remaining_slots", "split_type", "message", "status", "expired_at", "transaction_id"}).
+		AddRow(e.EnvelopeID, e.ClaimCode, e.CreatorID, e.CreatorName, e.TotalAmount, e.RemainingAmount, e.Slots, e.RemainingSlots, e.SplitType, e.Message, e.Status, e.ExpiredAt, e.TransactionID)
+	suite.mockSql.ExpectQuery("SELECT e.envelope_id").WithArgs(e.ClaimCode).WillReturnRows(rows)
+	repo := NewRedEnvelopeRepository(suite.mockDB)
+	res, err := repo.GetByCode(e.ClaimCode)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &e, res)
+}
+
+func (suite *RedEnvelopeRepositoryTestSuite) TestGetByCode_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT e.envelope_id").WithArgs("XXXX").WillReturnError(sql.ErrNoRows)
+	repo := NewRedEnvelopeRepository(suite.mockDB)
+	res, err := repo.GetByCode("XXXX")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "envelope not found")
+}
+
+func (suite *RedEnvelopeRepositoryTestSuite) TestClaim_Success() {
+	envelope := dummyRedEnvelope
+	transfer := model.Transfer{SenderID: "1", RecipientID: "2", SenderName: "name1", RecipientName: "name2", SenderPhoneNumber: "0811", RecipientPhoneNumber: "0812", Amount: 3333, Note: "Selamat Lebaran"}
+	suite.mockSql.ExpectExec("UPDATE mst_red_envelope").WithArgs(transfer.Amount, envelope.EnvelopeID, envelope.RemainingSlots, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Red Envelope Claim", today(), transfer.SenderID, transfer.RecipientID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(10))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transfer").WithArgs(10, transfer.SenderName, transfer.RecipientName, transfer.Amount, transfer.SenderPhoneNumber, transfer.RecipientPhoneNumber, transfer.SenderID, transfer.RecipientID, "Success", transfer.Note).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_red_envelope_claim").WithArgs(envelope.EnvelopeID, transfer.RecipientID, transfer.Amount, 10, today()).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewRedEnvelopeRepository(suite.mockDB)
+	err := repo.Claim(&envelope, &transfer)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 10, transfer.TransactionID)
+}
+
+func (suite *RedEnvelopeRepositoryTestSuite) TestClaim_NoLongerAvailable() {
+	envelope := dummyRedEnvelope
+	transfer := model.Transfer{Amount: 3333}
+	suite.mockSql.ExpectExec("UPDATE mst_red_envelope").WithArgs(transfer.Amount, envelope.EnvelopeID, envelope.RemainingSlots, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewRedEnvelopeRepository(suite.mockDB)
+	err := repo.Claim(&envelope, &transfer)
+	assert.EqualError(suite.T(), err, "envelope is no longer available")
+}
+
+func (suite *RedEnvelopeRepositoryTestSuite) TestRefund_Success() {
+	envelope := dummyRedEnvelope
+	envelope.RemainingAmount = 6000
+	suite.mockSql.ExpectExec("UPDATE mst_red_envelope SET status").WithArgs(envelope.EnvelopeID, envelope.RemainingAmount).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Red Envelope Refund", today(), envelope.CreatorID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval()").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(11))
+	suite.mockSql.ExpectExec("INSERT INTO tx_red_envelope").WithArgs(11, envelope.EnvelopeID, envelope.RemainingAmount).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewRedEnvelopeRepository(suite.mockDB)
+	err := repo.Refund(&envelope)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *RedEnvelopeRepositoryTestSuite) TestHasClaimed_True() {
+	suite.mockSql.ExpectQuery("SELECT COUNT").WithArgs(1, "2").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	repo := NewRedEnvelopeRepository(suite.mockDB)
+	claimed, err := repo.HasClaimed(1, "2")
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), claimed)
+}
+
+func (suite *RedEnvelopeRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *RedEnvelopeRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestRedEnvelopeRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RedEnvelopeRepositoryTestSuite))
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+var (
+	// ErrReferralNotFound is returned by GetPendingByReferee when the user
+	// has no pending referral.
+	ErrReferralNotFound = errors.New("referral not found")
+	// ErrReferralCodeUsed and ErrReferralCodeSet are returned by SetCode when
+	// the code belongs to someone else or the user already has one.
+	ErrReferralCodeUsed = errors.New("referral code already used")
+	ErrReferralCodeSet  = errors.New("referral code already set")
+)
+
+type ReferralRepository interface {
+	GetCode(userID string) (string, error)
+	SetCode(userID string, code string) error
+	GetReferrerByCode(code string) (*model.User, error)
+	Create(referral *model.Referral) error
+	GetPendingByReferee(refereeID string) (*model.Referral, error)
+	Complete(referral *model.Referral) error
+	GetByReferrer(referrerID string) ([]*model.Referral, error)
+	RecordBalanceReward(reward *model.ReferralReward) error
+}
+
+type referralRepository struct {
+	db *sql.DB
+}
+
+const referralColumns = `r.referral_id, r.referrer_id, r.referee_id, u.name, r.status, COALESCE(r.reject_reason, ''), COALESCE(r.reward_type, ''),
+	r.referrer_reward, r.referee_reward, COALESCE(r.transaction_id, 0), r.created_at, r.completed_at`
+
+func scanReferral(scanner interface{ Scan(...interface{}) error }, referral *model.Referral) error {
+	return scanner.Scan(&referral.ReferralID, &referral.ReferrerID, &referral.RefereeID, &referral.RefereeName, &referral.Status, &referral.RejectReason, &referral.RewardType,
+		&referral.ReferrerReward, &referral.RefereeReward, &referral.TransactionID, &referral.CreatedAt, &referral.CompletedAt)
+}
+
+// GetCode returns the referral code of a user, or an empty string when the
+// user has none yet.
+func (r *referralRepository) GetCode(userID string) (string, error) {
+	var code sql.NullString
+	err := r.db.QueryRow("SELECT referral_code FROM mst_users WHERE user_id = $1", userID).Scan(&code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.New("id not found")
+		}
+		return "", fmt.Errorf("failed to get referral code: %v", err)
+	}
+	return code.String, nil
+}
+
+// SetCode gives a user their referral code. A code that is already taken
+// fails with ErrReferralCodeUsed so the caller can draw another.
+func (r *referralRepository) SetCode(userID string, code string) error {
+	res, err := r.db.Exec("UPDATE mst_users SET referral_code = $1 WHERE user_id = $2 AND referral_code IS NULL", code, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return ErrReferralCodeUsed
+		}
+		return fmt.Errorf("failed to set referral code: %v", err)
+	}
+	return affectedOr(res, "set referral code", ErrReferralCodeSet)
+}
+
+func (r *referralRepository) GetReferrerByCode(code string) (*model.User, error) {
+	var user model.User
+	err := r.db.QueryRow("SELECT user_id, name, phone_number, COALESCE(token, '') FROM mst_users WHERE referral_code = $1", code).
+		Scan(&user.ID, &user.Name, &user.Phone_Number, &user.Token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("referral code not found")
+		}
+		return nil, fmt.Errorf("failed to get referrer: %v", err)
+	}
+	return &user, nil
+}
+
+// Create records a pending referral. A user can only be referred once.
+func (r *referralRepository) Create(referral *model.Referral) error {
+	query := `INSERT INTO tx_referral (referrer_id, referee_id, status, referrer_reward, referee_reward, created_at)
+	VALUES ($1, $2, 'Pending', 0, 0, $3) ON CONFLICT (referee_id) DO NOTHING RETURNING referral_id`
+	err := r.db.QueryRow(query, referral.ReferrerID, referral.RefereeID, referral.CreatedAt).Scan(&referral.ReferralID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("user already referred")
+		}
+		return fmt.Errorf("failed to create referral: %v", err)
+	}
+	referral.Status = "Pending"
+	return nil
+}
+
+func (r *referralRepository) GetPendingByReferee(refereeID string) (*model.Referral, error) {
+	referral := &model.Referral{}
+	query := "SELECT " + referralColumns + " FROM tx_referral r JOIN mst_users u ON r.referee_id = u.user_id WHERE r.referee_id = $1 AND r.status = 'Pending'"
+	err := scanReferral(r.db.QueryRow(query, refereeID), referral)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReferralNotFound
+		}
+		return nil, fmt.Errorf("failed to get referral: %v", err)
+	}
+	return referral, nil
+}
+
+// Complete closes a pending referral as Rewarded or Rejected. Only one
+// caller can close it, so rewards are never paid twice.
+func (r *referralRepository) Complete(referral *model.Referral) error {
+	query := `UPDATE tx_referral SET status = $1, reject_reason = NULLIF($2, ''), reward_type = NULLIF($3, ''), referrer_reward = $4, referee_reward = $5,
+	transaction_id = NULLIF($6, 0), completed_at = $7 WHERE referral_id = $8 AND status = 'Pending'`
+	res, err := r.db.Exec(query, referral.Status, referral.RejectReason, referral.RewardType, referral.ReferrerReward, referral.RefereeReward,
+		referral.TransactionID, referral.CompletedAt, referral.ReferralID)
+	if err != nil {
+		return fmt.Errorf("failed to complete referral: %v", err)
+	}
+	return affectedOrError(res, "complete referral", "referral already completed")
+}
+
+func (r *referralRepository) GetByReferrer(referrerID string) ([]*model.Referral, error) {
+	query := "SELECT " + referralColumns + " FROM tx_referral r JOIN mst_users u ON r.referee_id = u.user_id WHERE r.referrer_id = $1 ORDER BY r.referral_id DESC"
+	rows, err := r.db.Query(query, referrerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referrals: %v", err)
+	}
+	defer rows.Close()
+
+	var referrals []*model.Referral
+	for rows.Next() {
+		referral := &model.Referral{}
+		if err := scanReferral(rows, referral); err != nil {
+			return nil, fmt.Errorf("failed to scan referral: %v", err)
+		}
+		referrals = append(referrals, referral)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get referrals: %v", err)
+	}
+	return referrals, nil
+}
+
+// RecordBalanceReward records a referral bonus paid into the balance as a
+// transaction, so it shows in the transaction history.
+func (r *referralRepository) RecordBalanceReward(reward *model.ReferralReward) error {
+	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
+	_, err := r.db.Exec(query, "Referral Reward", today(), reward.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to insert transaction: %v", err)
+	}
+
+	var txID int
+	err = r.db.QueryRow("SELECT lastval()").Scan(&txID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
+	}
+
+	query = "INSERT INTO tx_referral_reward (transaction_id, referral_id, amount) VALUES ($1, $2, $3)"
+	_, err = r.db.Exec(query, txID, reward.ReferralID, reward.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to insert referral reward: %v", err)
+	}
+
+	reward.TransactionID = txID
+	reward.TransactionDate = today()
+	return nil
+}
+
+func NewReferralRepository(db *sql.DB) ReferralRepository {
+	return &referralRepository{db: db}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var referralTestColumns = []string{"referral_id", "referrer_id", "referee_id", "name", "status", "reject_reason", "reward_type", "referrer_reward", "referee_reward", "transaction_id", "created_at", "completed_at"}
+
+type ReferralRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *ReferralRepositoryTestSuite) TestGetCode_NotSet() {
+	suite.mockSql.ExpectQuery("SELECT referral_code FROM mst_users").WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"referral_code"}).AddRow(nil))
+	repo := NewReferralRepository(suite.mockDB)
+	res, err := repo.GetCode("1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "", res)
+}
+
+func (suite *ReferralRepositoryTestSuite) TestSetCode_Taken() {
+	suite.mockSql.ExpectExec("UPDATE mst_users SET referral_code = \\$1").WithArgs("ABCD2345", "1").
+		WillReturnError(errors.New("pq: duplicate key value violates unique constraint"))
+	repo := NewReferralRepository(suite.mockDB)
+	err := repo.SetCode("1", "ABCD2345")
+	assert.EqualError(suite.T(), err, "referral code already used")
+}
+
+func (suite *ReferralRepositoryTestSuite) TestGetReferrerByCode_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM mst_users WHERE referral_code = \\$1").WithArgs("ABCD2345").WillReturnError(sql.ErrNoRows)
+	repo := NewReferralRepository(suite.mockDB)
+	res, err := repo.GetReferrerByCode("ABCD2345")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "referral code not found")
+}
+
+func (suite *ReferralRepositoryTestSuite) TestCreate_AlreadyReferred() {
+	referral := model.Referral{ReferrerID: "1", RefereeID: "2", CreatedAt: time.Now()}
+	suite.mockSql.ExpectQuery("INSERT INTO tx_referral").WithArgs("1", "2", referral.CreatedAt).WillReturnError(sql.ErrNoRows)
+	repo := NewReferralRepository(suite.mockDB)
+	err := repo.Create(&referral)
+	assert.EqualError(suite.T(), err, "user already referred")
+}
+
+func (suite *ReferralRepositoryTestSuite) TestGetPendingByReferee_Success() {
+	rows := sqlmock.NewRows(referralTestColumns).AddRow(4, "1", "2", "name2", "Pending", "", "", 0, 0, 0, time.Now(), nil)
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM tx_referral r").WithArgs("2").WillReturnRows(rows)
+	repo := NewReferralRepository(suite.mockDB)
+	res, err := repo.GetPendingByReferee("2")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, res.ReferralID)
+	assert.Equal(suite.T(), "name2", res.RefereeName)
+	assert.Nil(suite.T(), res.CompletedAt)
+}
+
+func (suite *ReferralRepositoryTestSuite) TestComplete_AlreadyCompleted() {
+	completedAt := time.Now()
+	referral := model.Referral{ReferralID: 4, Status: "Rewarded", RewardType: "Point", ReferrerReward: 100, RefereeReward: 50, TransactionID: 10, CompletedAt: &completedAt}
+	suite.mockSql.ExpectExec("UPDATE tx_referral SET status = \\$1").WithArgs("Rewarded", "", "Point", 100, 50, 10, &completedAt, 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewReferralRepository(suite.mockDB)
+	err := repo.Complete(&referral)
+	assert.EqualError(suite.T(), err, "referral already completed")
+}
+
+func (suite *ReferralRepositoryTestSuite) TestRecordBalanceReward_Success() {
+	reward := model.ReferralReward{ReferralID: 4, UserID: "1", Amount: 20000}
+	suite.mockSql.ExpectExec("INSERT INTO tx_transaction").WithArgs("Referral Reward", today(), "1").WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectQuery("SELECT lastval\\(\\)").WillReturnRows(sqlmock.NewRows([]string{"lastval"}).AddRow(12))
+	suite.mockSql.ExpectExec("INSERT INTO tx_referral_reward").WithArgs(12, 4, 20000).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewReferralRepository(suite.mockDB)
+	err := repo.RecordBalanceReward(&reward)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 12, reward.TransactionID)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *ReferralRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *ReferralRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestReferralRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ReferralRepositoryTestSuite))
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,7 +10,16 @@ import (
 )
 
 var now = time.Now().Local()
-var date = now.Format("2006-01-02")
+
+// today is the transaction_date stamped on new transactions. It is read per
+// call so long-running processes do not keep stamping the day they started.
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// ErrDepositNotPending is returned when a deposit was already settled, e.g.
+// because the payment gateway sent its notification twice.
+var ErrDepositNotPending = errors.New("deposit not found or already settled")
 
 type TransactionRepository interface {
 	CreateDepositBank(tx *model.Deposit) error
@@ -17,50 +27,21 @@ type TransactionRepository interface {
 	CreateWithdrawal(tx *model.Withdraw) error
 	CreateTransfer(tx *model.Transfer) error
 	CreateRedeem(tx *model.Redeem) error
-	GetAllPoint() ([]*model.PointExchange, error)
 	GetTransactions(userID string) ([]*model.Transaction, error)
 	GetByPeId(id int) (*model.PointExchange, error)
-	AssignBadge(user *model.User) error
-	UpdateDepositStatus(vaNumber, token string) error
+	TakeRewardStock(peID int) error
+	ReturnRewardStock(peID int) error
+	UpdateDepositStatus(vaNumber, orderID string) error
+	GetDepositByOrderID(orderID string) (*model.Deposit, error)
+	IsTransferSender(txID int, senderID string) (bool, error)
+	UpdateTransferAttachment(txID int, senderID string, url string) error
+	SetCategory(category *model.TransactionCategory) error
 }
 
 type transactionRepository struct {
 	db *sql.DB
 }
 
-func (ur *transactionRepository) AssignBadge(user *model.User) error {
-	queryCount := "SELECT COUNT(*) FROM tx_transfer WHERE sender_id = $1"
-	row := ur.db.QueryRow(queryCount, user.ID)
-
-	var txCount int
-	err := row.Scan(&txCount)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve transaction count: %v", err)
-	}
-
-	// Mengambil badge berdasarkan jumlah transaksi
-	queryBadge := "SELECT badge_id FROM mst_badges WHERE threshold <= $1 ORDER BY threshold DESC LIMIT 1"
-	row = ur.db.QueryRow(queryBadge, txCount)
-
-	var badgeID int
-	err = row.Scan(&badgeID)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve badge ID: %v", err)
-	}
-
-	// Update field tx_count dan badge_id pada tabel pengguna
-	queryUpdate := "UPDATE mst_users SET tx_count = $1, badge_id = $2 WHERE user_id = $3"
-	_, err = ur.db.Exec(queryUpdate, txCount, badgeID, user.ID)
-	if err != nil {
-		return fmt.Errorf("failed to update user: %v", err)
-	}
-
-	user.TxCount = txCount
-	user.BadgeID = badgeID
-
-	return nil
-}
-
 func (r *transactionRepository) GetTransactions(userID string) ([]*model.Transaction, error) {
 	query := `
 	SELECT 
@@ -69,13 +50,39 @@ func (r *transactionRepository) GetTransactions(userID string) ([]*model.Transac
     w.bank_name, w.account_number, w.account_holder_name, w.amount,w.status,
     tr.sender_name, tr.sender_phone_number, tr.recipient_name, tr.recipient_phone_number, tr.amount,tr.status,
     CAST(rp.pe_id AS VARCHAR), rp.amount,rp.status,
-    pe.reward
+    pe.reward,
+    tr.note, tr.attachment_url, c.category,
+    ib.bank_name, ib.account_number, ib.account_holder_name, ib.amount, ib.fee,
+    pm.pocket_name, pm.amount,
+    gw.name, ga.amount,
+    re.amount,
+    h.reference, h.captured_amount,
+    mc.business_name, mp.amount, mp.mdr_amount,
+    rf.amount,
+    pl.description, plp.amount,
+    ptx.points, ptx.amount, ptx.sender_name, ptx.recipient_name, ptx.note,
+    rr.amount, cb.amount
 FROM tx_transaction t
 LEFT JOIN tx_deposit d ON t.tx_id = d.transaction_id
 LEFT JOIN tx_withdraw w ON t.tx_id = w.transaction_id
 LEFT JOIN tx_transfer tr ON t.tx_id = tr.transaction_id
 LEFT JOIN tx_redeem rp ON t.tx_id = rp.transaction_id
 LEFT JOIN mst_point_exchange pe ON rp.pe_id = pe.pe_id
+LEFT JOIN tx_interbank ib ON t.tx_id = ib.transaction_id
+LEFT JOIN tx_pocket pm ON t.tx_id = pm.transaction_id
+LEFT JOIN tx_group_wallet ga ON t.tx_id = ga.transaction_id
+LEFT JOIN mst_group_wallet gw ON ga.group_id = gw.group_id
+LEFT JOIN tx_red_envelope re ON t.tx_id = re.transaction_id
+LEFT JOIN tx_hold h ON t.tx_id = h.transaction_id
+LEFT JOIN tx_merchant_payment mp ON t.tx_id = mp.transaction_id
+LEFT JOIN mst_merchant mc ON mp.merchant_id = mc.merchant_id
+LEFT JOIN tx_merchant_refund rf ON t.tx_id = rf.transaction_id
+LEFT JOIN tx_payment_link_payment plp ON t.tx_id = plp.transaction_id
+LEFT JOIN mst_payment_link pl ON plp.link_id = pl.link_id
+LEFT JOIN tx_point ptx ON t.tx_id = ptx.transaction_id
+LEFT JOIN tx_referral_reward rr ON t.tx_id = rr.transaction_id
+LEFT JOIN tx_cashback cb ON t.tx_id = cb.transaction_id
+LEFT JOIN tx_category c ON t.tx_id = c.transaction_id AND c.user_id = $1
 WHERE (t.sender_id = $1 OR t.recipient_id = $1)
    
 ORDER BY t.tx_id DESC
@@ -116,9 +123,37 @@ ORDER BY t.tx_id DESC
 			redeemAmount               sql.NullInt64
 			redeemReward               sql.NullString
 			redeem_status              string
+			transfer_note              sql.NullString
+			transfer_attachment_url    sql.NullString
+			category                   sql.NullString
+			interbank_bank_name        sql.NullString
+			interbank_account_number   sql.NullString
+			interbank_account_name     sql.NullString
+			interbank_amount           sql.NullInt64
+			interbank_fee              sql.NullInt64
+			pocket_name                sql.NullString
+			pocket_amount              sql.NullInt64
+			group_name                 sql.NullString
+			group_amount               sql.NullInt64
+			red_envelope_amount        sql.NullInt64
+			hold_reference             sql.NullString
+			hold_amount                sql.NullInt64
+			merchant_name              sql.NullString
+			merchant_amount            sql.NullInt64
+			merchant_mdr               sql.NullInt64
+			merchant_refund_amount     sql.NullInt64
+			payment_link_description   sql.NullString
+			payment_link_amount        sql.NullInt64
+			point_points               sql.NullInt64
+			point_amount               sql.NullInt64
+			point_sender_name          sql.NullString
+			point_recipient_name       sql.NullString
+			point_note                 sql.NullString
+			referral_reward_amount     sql.NullInt64
+			cashback_amount            sql.NullInt64
 		)
 
-		err := rows.Scan(&txID, &transactionType, &transactionDate, &depositBankName, &deposit_bank_number, &deposit_account_bank_name, &deposit_amount, &deposit_status, &withdrawBankName, &withdraw_bank_number, &withdraw_account_bank_name, &withdraw_amount, &withdraw_status, &transfer_sender_name, &transfer_sender_phone, &transfer_recipient_name, &transfer_recipient_phone, &transfer_amount, &transfer_status, &redeemPEID, &redeemAmount, &redeem_status, &redeemReward)
+		err := rows.Scan(&txID, &transactionType, &transactionDate, &depositBankName, &deposit_bank_number, &deposit_account_bank_name, &deposit_amount, &deposit_status, &withdrawBankName, &withdraw_bank_number, &withdraw_account_bank_name, &withdraw_amount, &withdraw_status, &transfer_sender_name, &transfer_sender_phone, &transfer_recipient_name, &transfer_recipient_phone, &transfer_amount, &transfer_status, &redeemPEID, &redeemAmount, &redeem_status, &redeemReward, &transfer_note, &transfer_attachment_url, &category, &interbank_bank_name, &interbank_account_number, &interbank_account_name, &interbank_amount, &interbank_fee, &pocket_name, &pocket_amount, &group_name, &group_amount, &red_envelope_amount, &hold_reference, &hold_amount, &merchant_name, &merchant_amount, &merchant_mdr, &merchant_refund_amount, &payment_link_description, &payment_link_amount, &point_points, &point_amount, &point_sender_name, &point_recipient_name, &point_note, &referral_reward_amount, &cashback_amount)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction row: %v", err)
 		}
@@ -179,6 +214,90 @@ ORDER BY t.tx_id DESC
 		if redeemReward.Valid {
 			transaction.RedeemReward = redeemReward.String
 		}
+		if transfer_note.Valid {
+			transaction.TransferNote = transfer_note.String
+		}
+		if transfer_attachment_url.Valid {
+			transaction.TransferAttachmentUrl = transfer_attachment_url.String
+		}
+		if category.Valid {
+			transaction.Category = category.String
+		}
+		if interbank_bank_name.Valid {
+			transaction.InterbankBankName = interbank_bank_name.String
+		}
+		if interbank_account_number.Valid {
+			transaction.InterbankAccountNumber = interbank_account_number.String
+		}
+		if interbank_account_name.Valid {
+			transaction.InterbankAccountHolderName = interbank_account_name.String
+		}
+		if interbank_amount.Valid {
+			transaction.InterbankAmount = int(interbank_amount.Int64)
+		}
+		if interbank_fee.Valid {
+			transaction.InterbankFee = int(interbank_fee.Int64)
+		}
+		if pocket_name.Valid {
+			transaction.PocketName = pocket_name.String
+		}
+		if pocket_amount.Valid {
+			transaction.PocketAmount = int(pocket_amount.Int64)
+		}
+		if group_name.Valid {
+			transaction.GroupName = group_name.String
+		}
+		if group_amount.Valid {
+			transaction.GroupAmount = int(group_amount.Int64)
+		}
+		if red_envelope_amount.Valid {
+			transaction.RedEnvelopeAmount = int(red_envelope_amount.Int64)
+		}
+		if hold_reference.Valid {
+			transaction.HoldReference = hold_reference.String
+		}
+		if hold_amount.Valid {
+			transaction.HoldAmount = int(hold_amount.Int64)
+		}
+		if merchant_name.Valid {
+			transaction.MerchantName = merchant_name.String
+		}
+		if merchant_amount.Valid {
+			transaction.MerchantAmount = int(merchant_amount.Int64)
+		}
+		if merchant_mdr.Valid {
+			transaction.MerchantMDR = int(merchant_mdr.Int64)
+		}
+		if merchant_refund_amount.Valid {
+			transaction.MerchantRefundAmount = int(merchant_refund_amount.Int64)
+		}
+		if payment_link_description.Valid {
+			transaction.PaymentLinkDescription = payment_link_description.String
+		}
+		if payment_link_amount.Valid {
+			transaction.PaymentLinkAmount = int(payment_link_amount.Int64)
+		}
+		if point_points.Valid {
+			transaction.PointPoints = int(point_points.Int64)
+		}
+		if point_amount.Valid {
+			transaction.PointAmount = int(point_amount.Int64)
+		}
+		if point_sender_name.Valid {
+			transaction.PointSenderName = point_sender_name.String
+		}
+		if point_recipient_name.Valid {
+			transaction.PointRecipientName = point_recipient_name.String
+		}
+		if point_note.Valid {
+			transaction.PointNote = point_note.String
+		}
+		if referral_reward_amount.Valid {
+			transaction.ReferralRewardAmount = int(referral_reward_amount.Int64)
+		}
+		if cashback_amount.Valid {
+			transaction.CashbackAmount = int(cashback_amount.Int64)
+		}
 
 		transactions = append(transactions, transaction)
 	}
@@ -192,7 +311,7 @@ ORDER BY t.tx_id DESC
 
 func (r *transactionRepository) CreateDepositBank(tx *model.Deposit) error {
 	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
-	_, err := r.db.Exec(query, "Deposit", date, tx.UserID)
+	_, err := r.db.Exec(query, "Deposit", today(), tx.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to insert transaction: %v", err)
 	}
@@ -203,17 +322,19 @@ func (r *transactionRepository) CreateDepositBank(tx *model.Deposit) error {
 		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
 	}
 
-	query = "INSERT INTO tx_deposit (transaction_id, amount, bank_name, account_number, account_holder_name,status,va_number,token) VALUES ($1, $2, $3, $4, $5,$6,$7,$8)"
-	_, err = r.db.Exec(query, txID, tx.Amount, tx.BankName, tx.AccountNumber, tx.AccountHolderName, "Pending", tx.VaNumber, tx.Token)
+	query = "INSERT INTO tx_deposit (transaction_id, amount, bank_name, account_number, account_holder_name,status,va_number,token,order_id) VALUES ($1, $2, $3, $4, $5,$6,$7,$8,$9)"
+	_, err = r.db.Exec(query, txID, tx.Amount, tx.BankName, tx.AccountNumber, tx.AccountHolderName, "Pending", tx.VaNumber, tx.Token, tx.OrderID)
 	if err != nil {
 		return fmt.Errorf("failed to insert deposit: %v", err)
 	}
+	tx.TransactionID = txID
+	tx.TxID = txID
 
 	return nil
 }
 func (r *transactionRepository) CreateWithdrawal(tx *model.Withdraw) error {
 	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id) VALUES ($1, $2, $3)"
-	_, err := r.db.Exec(query, "Withdraw", date, tx.UserID)
+	_, err := r.db.Exec(query, "Withdraw", today(), tx.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to insert transaction: %v", err)
 	}
@@ -235,7 +356,7 @@ func (r *transactionRepository) CreateWithdrawal(tx *model.Withdraw) error {
 
 func (r *transactionRepository) CreateTransfer(tx *model.Transfer) error {
 	query := "INSERT INTO tx_transaction (transaction_type, transaction_date, sender_id,recipient_id) VALUES ($1, $2, $3,$4)"
-	_, err := r.db.Exec(query, "Transfer", date, tx.SenderID, tx.RecipientID)
+	_, err := r.db.Exec(query, "Transfer", today(), tx.SenderID, tx.RecipientID)
 	if err != nil {
 		return fmt.Errorf("failed to insert transaction: %v", err)
 	}
@@ -246,27 +367,93 @@ func (r *transactionRepository) CreateTransfer(tx *model.Transfer) error {
 		return fmt.Errorf("failed to retrieve transaction ID: %v", err)
 	}
 
-	query = "INSERT INTO tx_transfer (transaction_id, sender_name, recipient_name, amount, sender_phone_number, recipient_phone_number,sender_id,recipient_id,status) VALUES ($1, $2, $3, $4, $5, $6,$7,$8,$9)"
-	_, err = r.db.Exec(query, txID, tx.SenderName, tx.RecipientName, tx.Amount, tx.SenderPhoneNumber, tx.RecipientPhoneNumber, tx.SenderID, tx.RecipientID, "Success")
+	query = "INSERT INTO tx_transfer (transaction_id, sender_name, recipient_name, amount, sender_phone_number, recipient_phone_number,sender_id,recipient_id,status,note) VALUES ($1, $2, $3, $4, $5, $6,$7,$8,$9,$10)"
+	_, err = r.db.Exec(query, txID, tx.SenderName, tx.RecipientName, tx.Amount, tx.SenderPhoneNumber, tx.RecipientPhoneNumber, tx.SenderID, tx.RecipientID, "Success", tx.Note)
 	if err != nil {
 		return fmt.Errorf("failed to insert transfer: %v", err)
 	}
+	tx.TransactionID = txID
+	tx.TxID = txID
 
 	return nil
 }
-func (r *transactionRepository) UpdateDepositStatus(vaNumber, token string) error {
-	query := "UPDATE tx_deposit SET status = $1, va_number = $2 WHERE token = $3"
-	_, err := r.db.Exec(query, "Success", vaNumber, token)
+
+func (r *transactionRepository) IsTransferSender(txID int, senderID string) (bool, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM tx_transfer WHERE transaction_id = $1 AND sender_id = $2"
+	err := r.db.QueryRow(query, txID, senderID).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("failed to update deposit status: %v", err)
+		return false, fmt.Errorf("failed to retrieve transfer: %v", err)
 	}
+	return count > 0, nil
+}
 
+func (r *transactionRepository) UpdateTransferAttachment(txID int, senderID string, url string) error {
+	query := "UPDATE tx_transfer SET attachment_url = $1 WHERE transaction_id = $2 AND sender_id = $3"
+	res, err := r.db.Exec(query, url, txID, senderID)
+	if err != nil {
+		return fmt.Errorf("failed to update transfer attachment: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update transfer attachment: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("transfer not found")
+	}
 	return nil
 }
 
+func (r *transactionRepository) SetCategory(category *model.TransactionCategory) error {
+	var count int
+	query := "SELECT COUNT(*) FROM tx_transaction WHERE tx_id = $1 AND (sender_id = $2 OR recipient_id = $2)"
+	err := r.db.QueryRow(query, category.TransactionID, category.UserID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve transaction: %v", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("transaction not found")
+	}
+
+	query = "INSERT INTO tx_category (transaction_id, user_id, category) VALUES ($1, $2, $3) ON CONFLICT (transaction_id, user_id) DO UPDATE SET category = EXCLUDED.category"
+	_, err = r.db.Exec(query, category.TransactionID, category.UserID, category.Category)
+	if err != nil {
+		return fmt.Errorf("failed to set category: %v", err)
+	}
+	return nil
+}
+
+// UpdateDepositStatus settles a pending deposit. Only one notification can
+// settle it, so the balance is never credited twice.
+func (r *transactionRepository) UpdateDepositStatus(vaNumber, orderID string) error {
+	query := "UPDATE tx_deposit SET status = $1, va_number = $2 WHERE order_id = $3 AND status = $4"
+	res, err := r.db.Exec(query, "Success", vaNumber, orderID, "Pending")
+	if err != nil {
+		return fmt.Errorf("failed to update deposit status: %v", err)
+	}
+
+	return affectedOr(res, "update deposit status", ErrDepositNotPending)
+}
+
+func (r *transactionRepository) GetDepositByOrderID(orderID string) (*model.Deposit, error) {
+	var deposit model.Deposit
+	query := `SELECT d.transaction_id, t.sender_id, d.amount, d.bank_name, d.account_number, d.account_holder_name, d.status, d.va_number, d.token, d.order_id
+	FROM tx_deposit d JOIN tx_transaction t ON d.transaction_id = t.tx_id WHERE d.order_id = $1`
+	err := r.db.QueryRow(query, orderID).Scan(&deposit.TransactionID, &deposit.UserID, &deposit.Amount, &deposit.BankName, &deposit.AccountNumber,
+		&deposit.AccountHolderName, &deposit.Status, &deposit.VaNumber, &deposit.Token, &deposit.OrderID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deposit not found")
+		}
+		return nil, fmt.Errorf("failed to get deposit: %v", err)
+	}
+	deposit.TxID = deposit.TransactionID
+	return &deposit, nil
+}
+
 func (r *transactionRepository) CreateRedeem(tx *model.Redeem) error {
 	query := "INSERT INTO tx_transaction (transaction_type, transaction_date,sender_id) VALUES ($1, $2,$3)"
-	_, err := r.db.Exec(query, "Redeem", date, tx.UserID)
+	_, err := r.db.Exec(query, "Redeem", today(), tx.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to insert transaction: %v", err)
 	}
@@ -280,40 +467,42 @@ func (r *transactionRepository) CreateRedeem(tx *model.Redeem) error {
 	if err != nil {
 		return fmt.Errorf("failed to insert redeem: %v", err)
 	}
+	tx.TransactionID = txID
+	tx.Status = "Success"
 
 	return nil
 }
 
-// Get all point exchanges
-func (r *transactionRepository) GetAllPoint() ([]*model.PointExchange, error) {
-	query := "SELECT pe_id, reward, price FROM mst_point_exchange"
-	rows, err := r.db.Query(query)
+func (r *transactionRepository) GetByPeId(id int) (*model.PointExchange, error) {
+	var peAcc model.PointExchange
+	query := "SELECT " + pointExchangeColumns + " FROM mst_point_exchange WHERE pe_id = $1"
+	err := scanPointExchange(r.db.QueryRow(query, id), &peAcc)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get data: %v", err)
-	}
-	defer rows.Close()
-
-	var pointExchanges []*model.PointExchange
-	for rows.Next() {
-		pe := &model.PointExchange{}
-		err := rows.Scan(&pe.PE_ID, &pe.Reward, &pe.Price)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan store: %v", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("point exchange not found")
 		}
-		pointExchanges = append(pointExchanges, pe)
+		return nil, fmt.Errorf("failed to scan pe_id: %v", err)
 	}
+	return &peAcc, nil
+}
 
-	return pointExchanges, nil
+// TakeRewardStock reserves one unit of a reward for a redemption. Rewards
+// without stock never run out.
+func (r *transactionRepository) TakeRewardStock(peID int) error {
+	res, err := r.db.Exec("UPDATE mst_point_exchange SET stock = stock - 1 WHERE pe_id = $1 AND (stock IS NULL OR stock > 0)", peID)
+	if err != nil {
+		return fmt.Errorf("failed to update reward stock: %v", err)
+	}
+	return affectedOrError(res, "update reward stock", "reward out of stock")
 }
 
-func (r *transactionRepository) GetByPeId(id int) (*model.PointExchange, error) {
-	var peAcc model.PointExchange
-	query := "SELECT pe_id, reward, price FROM mst_point_exchange WHERE pe_id = $1"
-	err := r.db.QueryRow(query, id).Scan(&peAcc.PE_ID, &peAcc.Reward, &peAcc.Price)
+// ReturnRewardStock puts back a unit reserved for a redemption that failed.
+func (r *transactionRepository) ReturnRewardStock(peID int) error {
+	_, err := r.db.Exec("UPDATE mst_point_exchange SET stock = stock + 1 WHERE pe_id = $1 AND stock IS NOT NULL", peID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan pe_id: %v", err)
+		return fmt.Errorf("failed to update reward stock: %v", err)
 	}
-	return &peAcc, nil
+	return nil
 }
 
 func NewTxRepository(db *sql.DB) TransactionRepository {
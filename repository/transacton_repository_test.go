@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ReygaFitra/inc-final-project.git/model"
@@ -266,55 +267,6 @@ func (suite *TransactionRepositoryTestSuite) TestCreateTransfer_Error() {
 
 	assert.Equal(suite.T(), expectedErr, err)
 }
-func (suite *TransactionRepositoryTestSuite) TestGetAllPoint_Success() {
-	// Create some test data
-	pointExchanges := []*model.PointExchange{
-		{
-			PE_ID:  1,
-			Reward: "10k Pulsa",
-			Price:  100,
-		},
-		{
-			PE_ID:  2,
-			Reward: "20k Pulsa",
-			Price:  200,
-		},
-	}
-
-	// Create a mock database connection and repository
-
-	repo := NewTxRepository(suite.mockDB)
-
-	// Expect the query to be executed and return some test data
-	rows := sqlmock.NewRows([]string{"pe_id", "reward", "price"}).
-		AddRow(pointExchanges[0].PE_ID, pointExchanges[0].Reward, pointExchanges[0].Price).
-		AddRow(pointExchanges[1].PE_ID, pointExchanges[1].Reward, pointExchanges[1].Price)
-
-	suite.mockSql.ExpectQuery("SELECT pe_id, reward, price FROM mst_point_exchange").WillReturnRows(rows)
-
-	// Call the GetAllPoint method
-	results, err := repo.GetAllPoint()
-
-	// Assert that no errors occurred and all expectations were met
-	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), pointExchanges, results)
-	assert.NotNil(suite.T(), results)
-	assert.NotNil(suite.T(), rows)
-
-	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
-}
-func (suite *TransactionRepositoryTestSuite) TestGetAllPoint_Error() {
-	expectedErr := fmt.Errorf("failed to get data: database error")
-
-	suite.mockSql.ExpectQuery("SELECT pe_id, reward, price FROM mst_point_exchange").WillReturnError(errors.New("database error"))
-
-	repository := NewTxRepository(suite.mockDB)
-
-	_, err := repository.GetAllPoint()
-
-	assert.Equal(suite.T(), expectedErr, err)
-}
-
 func (suite *TransactionRepositoryTestSuite) TestGetByPeId_Success() {
 	// Create a mock database connection and repository
 
@@ -326,8 +278,8 @@ func (suite *TransactionRepositoryTestSuite) TestGetByPeId_Success() {
 	price := 100
 
 	// Expect the query to be executed with the correct arguments
-	suite.mockSql.ExpectQuery("SELECT pe_id, reward, price FROM mst_point_exchange WHERE pe_id = ?").WithArgs(peID).WillReturnRows(
-		sqlmock.NewRows([]string{"pe_id", "reward", "price"}).AddRow(peID, reward, price))
+	suite.mockSql.ExpectQuery("SELECT pe_id, reward, price(.+)FROM mst_point_exchange WHERE pe_id = ?").WithArgs(peID).WillReturnRows(
+		sqlmock.NewRows(pointExchangeTestColumns).AddRow(peID, reward, price, "Pulsa", "", "", nil, "None", nil, nil, true, time.Now()))
 
 	// Call the GetByPeId method
 	pointExchanges, err := repo.GetByPeId(peID)
@@ -338,6 +290,20 @@ func (suite *TransactionRepositoryTestSuite) TestGetByPeId_Success() {
 
 	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
 }
+func (suite *TransactionRepositoryTestSuite) TestTakeRewardStock_Success() {
+	suite.mockSql.ExpectExec("UPDATE mst_point_exchange SET stock = stock - 1").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewTxRepository(suite.mockDB)
+	err := repo.TakeRewardStock(1)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *TransactionRepositoryTestSuite) TestTakeRewardStock_OutOfStock() {
+	suite.mockSql.ExpectExec("UPDATE mst_point_exchange SET stock = stock - 1").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewTxRepository(suite.mockDB)
+	err := repo.TakeRewardStock(1)
+	assert.EqualError(suite.T(), err, "reward out of stock")
+}
+
 func (suite *TransactionRepositoryTestSuite) TestGetByPeId_Error() {
 	expectedErr := errors.New("database error")
 
@@ -352,6 +318,43 @@ func (suite *TransactionRepositoryTestSuite) TestGetByPeId_Error() {
 
 }
 
+func (suite *TransactionRepositoryTestSuite) TestIsTransferSender_Success() {
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tx_transfer").WithArgs(1, "1").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	repository := NewTxRepository(suite.mockDB)
+	res, err := repository.IsTransferSender(1, "1")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), res)
+}
+func (suite *TransactionRepositoryTestSuite) TestUpdateTransferAttachment_Success() {
+	suite.mockSql.ExpectExec("UPDATE tx_transfer SET attachment_url").WithArgs("file/tx-1.png", 1, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	repository := NewTxRepository(suite.mockDB)
+	err := repository.UpdateTransferAttachment(1, "1", "file/tx-1.png")
+	assert.NoError(suite.T(), err)
+}
+func (suite *TransactionRepositoryTestSuite) TestUpdateTransferAttachment_NotSender() {
+	suite.mockSql.ExpectExec("UPDATE tx_transfer SET attachment_url").WithArgs("file/tx-1.png", 1, "2").WillReturnResult(sqlmock.NewResult(0, 0))
+	repository := NewTxRepository(suite.mockDB)
+	err := repository.UpdateTransferAttachment(1, "2", "file/tx-1.png")
+	assert.EqualError(suite.T(), err, "transfer not found")
+}
+
+func (suite *TransactionRepositoryTestSuite) TestSetCategory_Success() {
+	category := &model.TransactionCategory{TransactionID: 1, UserID: "1", Category: "Food"}
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tx_transaction").WithArgs(1, "1").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mockSql.ExpectExec("INSERT INTO tx_category").WithArgs(1, "1", "Food").WillReturnResult(sqlmock.NewResult(0, 1))
+	repository := NewTxRepository(suite.mockDB)
+	err := repository.SetCategory(category)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+func (suite *TransactionRepositoryTestSuite) TestSetCategory_NotParticipant() {
+	category := &model.TransactionCategory{TransactionID: 1, UserID: "3", Category: "Food"}
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tx_transaction").WithArgs(1, "3").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	repository := NewTxRepository(suite.mockDB)
+	err := repository.SetCategory(category)
+	assert.EqualError(suite.T(), err, "transaction not found")
+}
+
 func (suite *TransactionRepositoryTestSuite) SetupTest() {
 	mockDb, mockSql, err := sqlmock.New()
 	if err != nil {
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type TransferInquiryRepository interface {
+	GetTransferFee(amount int) (*model.TransferFee, error)
+	Create(inquiry *model.TransferInquiry) error
+	GetByToken(token string) (*model.TransferInquiry, error)
+	Claim(token string, senderID string) error
+}
+
+type transferInquiryRepository struct {
+	db *sql.DB
+}
+
+func (r *transferInquiryRepository) GetTransferFee(amount int) (*model.TransferFee, error) {
+	var fee model.TransferFee
+	var maxAmount sql.NullInt64
+	query := "SELECT fee_id, min_amount, max_amount, fee FROM mst_transfer_fee WHERE min_amount <= $1 AND (max_amount IS NULL OR max_amount >= $1) ORDER BY min_amount DESC LIMIT 1"
+	err := r.db.QueryRow(query, amount).Scan(&fee.FeeID, &fee.MinAmount, &maxAmount, &fee.Fee)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("transfer fee not found")
+		}
+		return nil, fmt.Errorf("failed to get transfer fee: %v", err)
+	}
+	if maxAmount.Valid {
+		fee.MaxAmount = int(maxAmount.Int64)
+	}
+	return &fee, nil
+}
+
+func (r *transferInquiryRepository) Create(inquiry *model.TransferInquiry) error {
+	query := "INSERT INTO tx_transfer_inquiry (inquiry_token, sender_id, recipient_id, recipient_phone_number, recipient_name, amount, fee, status, expired_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	_, err := r.db.Exec(query, inquiry.InquiryToken, inquiry.SenderID, inquiry.RecipientID, inquiry.RecipientPhoneNumber, inquiry.RecipientName, inquiry.Amount, inquiry.Fee, inquiry.Status, inquiry.ExpiredAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert transfer inquiry: %v", err)
+	}
+	return nil
+}
+
+func (r *transferInquiryRepository) GetByToken(token string) (*model.TransferInquiry, error) {
+	var inquiry model.TransferInquiry
+	query := "SELECT inquiry_token, sender_id, recipient_id, recipient_phone_number, recipient_name, amount, fee, status, expired_at FROM tx_transfer_inquiry WHERE inquiry_token = $1"
+	err := r.db.QueryRow(query, token).Scan(&inquiry.InquiryToken, &inquiry.SenderID, &inquiry.RecipientID, &inquiry.RecipientPhoneNumber, &inquiry.RecipientName, &inquiry.Amount, &inquiry.Fee, &inquiry.Status, &inquiry.ExpiredAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("inquiry not found")
+		}
+		return nil, fmt.Errorf("failed to get transfer inquiry: %v", err)
+	}
+	inquiry.TotalAmount = inquiry.Amount + inquiry.Fee
+	return &inquiry, nil
+}
+
+// Claim marks a pending inquiry as used in a single statement so the same
+// token can never be executed twice.
+func (r *transferInquiryRepository) Claim(token string, senderID string) error {
+	query := "UPDATE tx_transfer_inquiry SET status = $1 WHERE inquiry_token = $2 AND sender_id = $3 AND status = $4 AND expired_at > $5"
+	res, err := r.db.Exec(query, "Used", token, senderID, "Pending", time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to claim transfer inquiry: %v", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to claim transfer inquiry: %v", err)
+	}
+	if affected == 0 {
+		return errors.New("inquiry expired or already used")
+	}
+	return nil
+}
+
+func NewTransferInquiryRepository(db *sql.DB) TransferInquiryRepository {
+	return &transferInquiryRepository{db: db}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyInquiry = model.TransferInquiry{
+	InquiryToken:         "token-1",
+	SenderID:             "1",
+	RecipientID:          "2",
+	RecipientPhoneNumber: "08111111112",
+	RecipientName:        "R**** F****",
+	Amount:               50000,
+	Fee:                  2500,
+	Status:               "Pending",
+	ExpiredAt:            time.Now().Add(5 * time.Minute),
+}
+
+type TransferInquiryRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) TestGetTransferFee_Success() {
+	suite.mockSql.ExpectQuery("SELECT fee_id, min_amount, max_amount, fee FROM mst_transfer_fee").WithArgs(50000).WillReturnRows(sqlmock.NewRows([]string{"fee_id", "min_amount", "max_amount", "fee"}).AddRow(1, 10000, nil, 1500))
+	repo := NewTransferInquiryRepository(suite.mockDB)
+	fee, err := repo.GetTransferFee(50000)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1500, fee.Fee)
+	assert.Equal(suite.T(), 0, fee.MaxAmount)
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) TestGetTransferFee_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT fee_id, min_amount, max_amount, fee FROM mst_transfer_fee").WithArgs(50000).WillReturnError(sql.ErrNoRows)
+	repo := NewTransferInquiryRepository(suite.mockDB)
+	fee, err := repo.GetTransferFee(50000)
+	assert.Nil(suite.T(), fee)
+	assert.EqualError(suite.T(), err, "transfer fee not found")
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) TestCreate_Success() {
+	inquiry := dummyInquiry
+	suite.mockSql.ExpectExec("INSERT INTO tx_transfer_inquiry").WithArgs(inquiry.InquiryToken, inquiry.SenderID, inquiry.RecipientID, inquiry.RecipientPhoneNumber, inquiry.RecipientName, inquiry.Amount, inquiry.Fee, inquiry.Status, inquiry.ExpiredAt).WillReturnResult(sqlmock.NewResult(1, 1))
+	repo := NewTransferInquiryRepository(suite.mockDB)
+	err := repo.Create(&inquiry)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) TestCreate_Failed() {
+	inquiry := dummyInquiry
+	suite.mockSql.ExpectExec("INSERT INTO tx_transfer_inquiry").WillReturnError(errors.New("failed"))
+	repo := NewTransferInquiryRepository(suite.mockDB)
+	err := repo.Create(&inquiry)
+	assert.EqualError(suite.T(), err, "failed to insert transfer inquiry: failed")
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) TestGetByToken_Success() {
+	inquiry := dummyInquiry
+	rows := sqlmock.NewRows([]string{"inquiry_token", "sender_id", "recipient_id", "recipient_phone_number", "recipient_name", "amount", "fee", "status", "expired_at"}).
+		AddRow(inquiry.InquiryToken, inquiry.SenderID, inquiry.RecipientID, inquiry.RecipientPhoneNumber, inquiry.RecipientName, inquiry.Amount, inquiry.Fee, inquiry.Status, inquiry.ExpiredAt)
+	suite.mockSql.ExpectQuery("SELECT inquiry_token, sender_id").WithArgs(inquiry.InquiryToken).WillReturnRows(rows)
+	repo := NewTransferInquiryRepository(suite.mockDB)
+	res, err := repo.GetByToken(inquiry.InquiryToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 52500, res.TotalAmount)
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) TestGetByToken_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT inquiry_token, sender_id").WithArgs("unknown").WillReturnError(sql.ErrNoRows)
+	repo := NewTransferInquiryRepository(suite.mockDB)
+	res, err := repo.GetByToken("unknown")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "inquiry not found")
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) TestClaim_Success() {
+	suite.mockSql.ExpectExec("UPDATE tx_transfer_inquiry SET status").WithArgs("Used", "token-1", "1", "Pending", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewTransferInquiryRepository(suite.mockDB)
+	err := repo.Claim("token-1", "1")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) TestClaim_AlreadyUsed() {
+	suite.mockSql.ExpectExec("UPDATE tx_transfer_inquiry SET status").WithArgs("Used", "token-1", "1", "Pending", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewTransferInquiryRepository(suite.mockDB)
+	err := repo.Claim("token-1", "1")
+	assert.EqualError(suite.T(), err, "inquiry expired or already used")
+}
+
+func (suite *TransferInquiryRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *TransferInquiryRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestTransferInquiryRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TransferInquiryRepositoryTestSuite))
+}
@@ -9,9 +9,14 @@ import (
 	"github.com/ReygaFitra/inc-final-project.git/model"
 	"github.com/ReygaFitra/inc-final-project.git/utils"
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// ErrPaymentHandleTaken is returned by UpdatePaymentHandle when another user
+// holds the handle. The unique index on payment_handle enforces it, so two
+// users claiming the same handle at once cannot both get it.
+var ErrPaymentHandleTaken = errors.New("handle already taken")
+
 var newUUID = uuid.New()
 
 type UserRepository interface {
@@ -28,6 +33,9 @@ type UserRepository interface {
 	GetByPhone(phoneNumber string) (*model.User, error)
 	SaveDeviceToken(userID string, token string) error
 	GetByIDToken(id string) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
+	GetByPaymentHandle(handle string) (*model.User, error)
+	UpdatePaymentHandle(userID string, handle string) error
 }
 
 type userRepository struct {
@@ -143,6 +151,43 @@ func (r *userRepository) GetByPhone(phoneNumber string) (*model.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) GetByEmail(email string) (*model.User, error) {
+	var user model.User
+	err := r.db.QueryRow("SELECT user_id, name, username, email, phone_number, address, balance, point, badge_id, tx_count, token FROM mst_users WHERE email = $1", email).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Phone_Number, &user.Address, &user.Balance, &user.Point, &user.BadgeID, &user.TxCount, &user.Token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("email not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByPaymentHandle(handle string) (*model.User, error) {
+	var user model.User
+	err := r.db.QueryRow("SELECT user_id, name, username, email, phone_number, address, balance, point, badge_id, tx_count, token, payment_handle FROM mst_users WHERE payment_handle = $1", handle).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Phone_Number, &user.Address, &user.Balance, &user.Point, &user.BadgeID, &user.TxCount, &user.Token, &user.PaymentHandle)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("payment handle not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdatePaymentHandle(userID string, handle string) error {
+	query := "UPDATE mst_users SET payment_handle = $1 WHERE user_id = $2"
+	_, err := r.db.Exec(query, handle, userID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return ErrPaymentHandleTaken
+		}
+		return fmt.Errorf("failed to update payment handle: %v", err)
+	}
+	return nil
+}
+
 func (r *userRepository) GetByiD(id string) (*model.User, error) {
 	var user model.User
 
@@ -10,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ReygaFitra/inc-final-project.git/model"
 	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -263,6 +264,60 @@ func (suite *UserRepositoryTestSuite) TestGetByUsername_UserNotFound() {
 	assert.EqualValues(suite.T(), "user not found", err.Error())
 }
 
+// Test GetByEmail
+func (suite *UserRepositoryTestSuite) TestGetByEmail_Success() {
+	user := dummyUser[0]
+	suite.mockSql.ExpectQuery("SELECT user_id, name, username, email, phone_number, address, balance, point, badge_id, tx_count, token FROM mst_users WHERE email = \\$1").WithArgs(user.Email).WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "username", "email", "phone_number", "address", "balance", "point", "badge_id", "tx_count", "token"}).AddRow(user.ID, user.Name, user.Username, user.Email, user.Phone_Number, user.Address, user.Balance, user.Point, 1, 0, ""))
+	userRepository := NewUserRepository(suite.mockDB)
+	res, err := userRepository.GetByEmail(user.Email)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), user.ID, res.ID)
+}
+func (suite *UserRepositoryTestSuite) TestGetByEmail_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT user_id, name, username, email").WithArgs("unknown@gmail.com").WillReturnError(sql.ErrNoRows)
+	userRepository := NewUserRepository(suite.mockDB)
+	res, err := userRepository.GetByEmail("unknown@gmail.com")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "email not found")
+}
+
+// Test GetByPaymentHandle
+func (suite *UserRepositoryTestSuite) TestGetByPaymentHandle_Success() {
+	user := dummyUser[0]
+	suite.mockSql.ExpectQuery("SELECT user_id, name, username, email, phone_number, address, balance, point, badge_id, tx_count, token, payment_handle FROM mst_users WHERE payment_handle = \\$1").WithArgs("name1").WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "username", "email", "phone_number", "address", "balance", "point", "badge_id", "tx_count", "token", "payment_handle"}).AddRow(user.ID, user.Name, user.Username, user.Email, user.Phone_Number, user.Address, user.Balance, user.Point, 1, 0, "", "name1"))
+	userRepository := NewUserRepository(suite.mockDB)
+	res, err := userRepository.GetByPaymentHandle("name1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "name1", res.PaymentHandle)
+}
+func (suite *UserRepositoryTestSuite) TestGetByPaymentHandle_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT user_id, name, username, email").WithArgs("nobody").WillReturnError(sql.ErrNoRows)
+	userRepository := NewUserRepository(suite.mockDB)
+	res, err := userRepository.GetByPaymentHandle("nobody")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "payment handle not found")
+}
+
+// Test UpdatePaymentHandle
+func (suite *UserRepositoryTestSuite) TestUpdatePaymentHandle_Success() {
+	suite.mockSql.ExpectExec("UPDATE mst_users SET payment_handle").WithArgs("name1", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	userRepository := NewUserRepository(suite.mockDB)
+	err := userRepository.UpdatePaymentHandle("1", "name1")
+	assert.Nil(suite.T(), err)
+}
+func (suite *UserRepositoryTestSuite) TestUpdatePaymentHandle_Taken() {
+	suite.mockSql.ExpectExec("UPDATE mst_users SET payment_handle").WithArgs("name1", "1").WillReturnError(&pq.Error{Code: "23505"})
+	userRepository := NewUserRepository(suite.mockDB)
+	err := userRepository.UpdatePaymentHandle("1", "name1")
+	assert.ErrorIs(suite.T(), err, ErrPaymentHandleTaken)
+}
+func (suite *UserRepositoryTestSuite) TestUpdatePaymentHandle_Failed() {
+	suite.mockSql.ExpectExec("UPDATE mst_users SET payment_handle").WithArgs("name1", "1").WillReturnError(errors.New("duplicate key"))
+	userRepository := NewUserRepository(suite.mockDB)
+	err := userRepository.UpdatePaymentHandle("1", "name1")
+	assert.EqualError(suite.T(), err, "failed to update payment handle: duplicate key")
+}
+
 // Test GetByID
 func (suite *UserRepositoryTestSuite) TestGetByiD_Success() {
 	user := dummyUser[0]
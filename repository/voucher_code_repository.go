@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type VoucherCodeRepository interface {
+	AddCodes(peID int, codes []*model.VoucherCode) (int, error)
+	Assign(peID int, userID string, transactionID int, at time.Time) (*model.VoucherCode, error)
+	SaveIssued(code *model.VoucherCode) error
+	GetByUser(userID string) ([]*model.VoucherCode, error)
+	FailRedeem(transactionID int, peID int) error
+}
+
+type voucherCodeRepository struct {
+	db *sql.DB
+}
+
+// AddCodes loads codes into the pool of a reward, skipping codes the pool
+// already has, and raises the reward stock by the number of codes added.
+func (r *voucherCodeRepository) AddCodes(peID int, codes []*model.VoucherCode) (int, error) {
+	query := "INSERT INTO mst_voucher_code (pe_id, code, valid_until) VALUES ($1, $2, $3) ON CONFLICT (pe_id, code) DO NOTHING"
+	added := 0
+	for _, code := range codes {
+		res, err := r.db.Exec(query, peID, code.Code, code.ValidUntil)
+		if err != nil {
+			return added, fmt.Errorf("failed to insert voucher code: %v", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return added, fmt.Errorf("failed to insert voucher code: %v", err)
+		}
+		added += int(affected)
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	_, err := r.db.Exec("UPDATE mst_point_exchange SET stock = COALESCE(stock, 0) + $1 WHERE pe_id = $2", added, peID)
+	if err != nil {
+		return added, fmt.Errorf("failed to update reward stock: %v", err)
+	}
+	return added, nil
+}
+
+// Assign hands the pool code that expires first to a redemption. The code is
+// claimed in a single statement, so two redemptions never get the same code.
+func (r *voucherCodeRepository) Assign(peID int, userID string, transactionID int, at time.Time) (*model.VoucherCode, error) {
+	query := `UPDATE mst_voucher_code SET user_id = $1, transaction_id = $2, assigned_at = $3
+	WHERE code_id = (
+		SELECT code_id FROM mst_voucher_code WHERE pe_id = $4 AND user_id IS NULL AND (valid_until IS NULL OR valid_until > $3)
+		ORDER BY valid_until NULLS LAST, code_id LIMIT 1 FOR UPDATE SKIP LOCKED
+	)
+	RETURNING code_id, code, valid_until`
+	code := &model.VoucherCode{PEID: peID, UserID: userID, TransactionID: transactionID, AssignedAt: &at}
+	err := r.db.QueryRow(query, userID, transactionID, at, peID).Scan(&code.CodeID, &code.Code, &code.ValidUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("no voucher code available")
+		}
+		return nil, fmt.Errorf("failed to assign voucher code: %v", err)
+	}
+	return code, nil
+}
+
+// SaveIssued stores a code issued by a fulfillment provider.
+func (r *voucherCodeRepository) SaveIssued(code *model.VoucherCode) error {
+	query := `INSERT INTO mst_voucher_code (pe_id, code, valid_until, user_id, transaction_id, assigned_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING code_id`
+	err := r.db.QueryRow(query, code.PEID, code.Code, code.ValidUntil, code.UserID, code.TransactionID, code.AssignedAt).Scan(&code.CodeID)
+	if err != nil {
+		return fmt.Errorf("failed to insert voucher code: %v", err)
+	}
+	return nil
+}
+
+func (r *voucherCodeRepository) GetByUser(userID string) ([]*model.VoucherCode, error) {
+	query := `SELECT v.code_id, v.pe_id, pe.reward, v.code, v.valid_until, v.user_id, v.transaction_id, v.assigned_at
+	FROM mst_voucher_code v JOIN mst_point_exchange pe ON v.pe_id = pe.pe_id
+	WHERE v.user_id = $1 ORDER BY v.assigned_at DESC, v.code_id DESC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher codes: %v", err)
+	}
+	defer rows.Close()
+
+	var codes []*model.VoucherCode
+	for rows.Next() {
+		code := &model.VoucherCode{}
+		err := rows.Scan(&code.CodeID, &code.PEID, &code.Reward, &code.Code, &code.ValidUntil, &code.UserID, &code.TransactionID, &code.AssignedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan voucher code: %v", err)
+		}
+		codes = append(codes, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get voucher codes: %v", err)
+	}
+	return codes, nil
+}
+
+// FailRedeem marks a redemption that could not be fulfilled and gives its
+// reserved unit back to the reward stock.
+func (r *voucherCodeRepository) FailRedeem(transactionID int, peID int) error {
+	res, err := r.db.Exec("UPDATE tx_redeem SET status = 'Failed' WHERE transaction_id = $1 AND status = 'Success'", transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to update redeem status: %v", err)
+	}
+	if err := affectedOrError(res, "update redeem status", "redeem not found"); err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec("UPDATE mst_point_exchange SET stock = stock + 1 WHERE pe_id = $1 AND stock IS NOT NULL", peID)
+	if err != nil {
+		return fmt.Errorf("failed to update reward stock: %v", err)
+	}
+	return nil
+}
+
+func NewVoucherCodeRepository(db *sql.DB) VoucherCodeRepository {
+	return &voucherCodeRepository{db: db}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type VoucherCodeRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *VoucherCodeRepositoryTestSuite) TestAddCodes_SkipsExisting() {
+	codes := []*model.VoucherCode{{Code: "KOPI-001"}, {Code: "KOPI-002"}}
+	suite.mockSql.ExpectExec("INSERT INTO mst_voucher_code(.+)ON CONFLICT").WithArgs(2, "KOPI-001", nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO mst_voucher_code(.+)ON CONFLICT").WithArgs(2, "KOPI-002", nil).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mockSql.ExpectExec("UPDATE mst_point_exchange SET stock = COALESCE\\(stock, 0\\) \\+ \\$1").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewVoucherCodeRepository(suite.mockDB)
+	added, err := repo.AddCodes(2, codes)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, added)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *VoucherCodeRepositoryTestSuite) TestAssign_Success() {
+	at := time.Now()
+	validUntil := at.AddDate(0, 1, 0)
+	suite.mockSql.ExpectQuery("UPDATE mst_voucher_code SET user_id = \\$1(.+)FOR UPDATE SKIP LOCKED").WithArgs("1", 15, at, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"code_id", "code", "valid_until"}).AddRow(4, "KOPI-001", validUntil))
+	repo := NewVoucherCodeRepository(suite.mockDB)
+	code, err := repo.Assign(2, "1", 15, at)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "KOPI-001", code.Code)
+	assert.Equal(suite.T(), 15, code.TransactionID)
+	assert.Equal(suite.T(), validUntil, *code.ValidUntil)
+}
+
+func (suite *VoucherCodeRepositoryTestSuite) TestAssign_NoneAvailable() {
+	at := time.Now()
+	suite.mockSql.ExpectQuery("UPDATE mst_voucher_code SET user_id").WithArgs("1", 15, at, 2).WillReturnError(sql.ErrNoRows)
+	repo := NewVoucherCodeRepository(suite.mockDB)
+	code, err := repo.Assign(2, "1", 15, at)
+	assert.Nil(suite.T(), code)
+	assert.EqualError(suite.T(), err, "no voucher code available")
+}
+
+func (suite *VoucherCodeRepositoryTestSuite) TestGetByUser_Success() {
+	assignedAt := time.Now()
+	rows := sqlmock.NewRows([]string{"code_id", "pe_id", "reward", "code", "valid_until", "user_id", "transaction_id", "assigned_at"}).
+		AddRow(4, 2, "Voucher Kopi", "KOPI-001", nil, "1", 15, assignedAt)
+	suite.mockSql.ExpectQuery("SELECT v.code_id(.+)WHERE v.user_id = \\$1").WithArgs("1").WillReturnRows(rows)
+	repo := NewVoucherCodeRepository(suite.mockDB)
+	res, err := repo.GetByUser("1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.VoucherCode{{CodeID: 4, PEID: 2, Reward: "Voucher Kopi", Code: "KOPI-001", UserID: "1", TransactionID: 15, AssignedAt: &assignedAt}}, res)
+}
+
+func (suite *VoucherCodeRepositoryTestSuite) TestFailRedeem_ReturnsStock() {
+	suite.mockSql.ExpectExec("UPDATE tx_redeem SET status = 'Failed'").WithArgs(15).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE mst_point_exchange SET stock = stock \\+ 1").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewVoucherCodeRepository(suite.mockDB)
+	err := repo.FailRedeem(15, 2)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *VoucherCodeRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *VoucherCodeRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestVoucherCodeRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(VoucherCodeRepositoryTestSuite))
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(subscription *model.WebhookSubscription) error
+	GetSubscriptions(userID string) ([]*model.WebhookSubscription, error)
+	GetSubscription(subscriptionID int, userID string) (*model.WebhookSubscription, error)
+	DeleteSubscription(subscriptionID int, userID string) error
+	GetSubscribers(userID string, eventType string) ([]*model.WebhookSubscription, error)
+
+	CreateDelivery(delivery *model.WebhookDelivery) error
+	GetDeliveries(subscriptionID int) ([]*model.WebhookDelivery, error)
+	GetDelivery(deliveryID int, userID string) (*model.WebhookDelivery, error)
+	GetDueDeliveries(now time.Time, limit int) ([]*model.WebhookDelivery, error)
+	UpdateDelivery(delivery *model.WebhookDelivery) error
+	Redeliver(deliveryID int, now time.Time) error
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+const webhookDeliveryColumns = `d.delivery_id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.last_status_code, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at`
+
+func scanSubscriptions(rows *sql.Rows) ([]*model.WebhookSubscription, error) {
+	defer rows.Close()
+
+	var subscriptions []*model.WebhookSubscription
+	for rows.Next() {
+		subscription := &model.WebhookSubscription{}
+		var eventTypes string
+		err := rows.Scan(&subscription.SubscriptionID, &subscription.UserID, &subscription.URL, &eventTypes, &subscription.Active, &subscription.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %v", err)
+		}
+		subscription.EventTypes = strings.Split(eventTypes, ",")
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %v", err)
+	}
+	return subscriptions, nil
+}
+
+func scanDelivery(scanner interface{ Scan(...interface{}) error }, delivery *model.WebhookDelivery, extra ...interface{}) error {
+	dest := []interface{}{&delivery.DeliveryID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.Attempts,
+		&delivery.LastStatusCode, &delivery.LastError, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.DeliveredAt}
+	return scanner.Scan(append(dest, extra...)...)
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *model.WebhookSubscription) error {
+	query := "INSERT INTO mst_webhook_subscription (user_id, url, event_types, secret, active, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING subscription_id"
+	err := r.db.QueryRow(query, subscription.UserID, subscription.URL, strings.Join(subscription.EventTypes, ","), subscription.Secret, subscription.Active, subscription.CreatedAt).
+		Scan(&subscription.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %v", err)
+	}
+	return nil
+}
+
+// GetSubscriptions lists a user's subscriptions without their secrets.
+func (r *webhookRepository) GetSubscriptions(userID string) ([]*model.WebhookSubscription, error) {
+	query := "SELECT subscription_id, user_id, url, event_types, active, created_at FROM mst_webhook_subscription WHERE user_id = $1 ORDER BY subscription_id"
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %v", err)
+	}
+	return scanSubscriptions(rows)
+}
+
+func (r *webhookRepository) GetSubscription(subscriptionID int, userID string) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	var eventTypes string
+	query := "SELECT subscription_id, user_id, url, event_types, active, created_at FROM mst_webhook_subscription WHERE subscription_id = $1 AND user_id = $2"
+	err := r.db.QueryRow(query, subscriptionID, userID).
+		Scan(&subscription.SubscriptionID, &subscription.UserID, &subscription.URL, &eventTypes, &subscription.Active, &subscription.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("webhook subscription not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %v", err)
+	}
+	subscription.EventTypes = strings.Split(eventTypes, ",")
+	return &subscription, nil
+}
+
+// DeleteSubscription deactivates a subscription. Its delivery log is kept and
+// pending deliveries are no longer picked up.
+func (r *webhookRepository) DeleteSubscription(subscriptionID int, userID string) error {
+	query := "UPDATE mst_webhook_subscription SET active = FALSE WHERE subscription_id = $1 AND user_id = $2 AND active"
+	res, err := r.db.Exec(query, subscriptionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %v", err)
+	}
+	return affectedOrError(res, "delete webhook subscription", "webhook subscription not found")
+}
+
+// GetSubscribers returns the active subscriptions of a user listening to eventType.
+func (r *webhookRepository) GetSubscribers(userID string, eventType string) ([]*model.WebhookSubscription, error) {
+	query := `SELECT subscription_id, user_id, url, event_types, active, created_at FROM mst_webhook_subscription
+	WHERE user_id = $1 AND active AND ',' || event_types || ',' LIKE '%,' || $2 || ',%' ORDER BY subscription_id`
+	rows, err := r.db.Query(query, userID, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %v", err)
+	}
+	return scanSubscriptions(rows)
+}
+
+func (r *webhookRepository) CreateDelivery(delivery *model.WebhookDelivery) error {
+	query := `INSERT INTO tx_webhook_delivery (subscription_id, event_id, event_type, payload, status, attempts, last_status_code, last_error, next_attempt_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, 0, 0, '', $6, $7) RETURNING delivery_id`
+	err := r.db.QueryRow(query, delivery.SubscriptionID, delivery.EventID, delivery.EventType, delivery.Payload, delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt).
+		Scan(&delivery.DeliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %v", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDeliveries(subscriptionID int) ([]*model.WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM tx_webhook_delivery d WHERE d.subscription_id = $1 ORDER BY d.delivery_id DESC"
+	rows, err := r.db.Query(query, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery := &model.WebhookDelivery{}
+		if err := scanDelivery(rows, delivery); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %v", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %v", err)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) GetDelivery(deliveryID int, userID string) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	query := "SELECT " + webhookDeliveryColumns + ` FROM tx_webhook_delivery d
+	JOIN mst_webhook_subscription s ON d.subscription_id = s.subscription_id
+	WHERE d.delivery_id = $1 AND s.user_id = $2`
+	if err := scanDelivery(r.db.QueryRow(query, deliveryID, userID), &delivery); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("webhook delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %v", err)
+	}
+	return &delivery, nil
+}
+
+// GetDueDeliveries returns pending deliveries whose next attempt is due,
+// together with the URL and secret of their subscription.
+func (r *webhookRepository) GetDueDeliveries(now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + `, s.url, s.secret FROM tx_webhook_delivery d
+	JOIN mst_webhook_subscription s ON d.subscription_id = s.subscription_id
+	WHERE d.status = 'Pending' AND d.next_attempt_at <= $1 AND s.active
+	ORDER BY d.next_attempt_at LIMIT $2`
+	rows, err := r.db.Query(query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery := &model.WebhookDelivery{}
+		if err := scanDelivery(rows, delivery, &delivery.URL, &delivery.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %v", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %v", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt.
+func (r *webhookRepository) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	query := `UPDATE tx_webhook_delivery SET status = $1, attempts = $2, last_status_code = $3, last_error = $4, next_attempt_at = $5, delivered_at = $6
+	WHERE delivery_id = $7`
+	res, err := r.db.Exec(query, delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.LastError, delivery.NextAttemptAt, delivery.DeliveredAt, delivery.DeliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %v", err)
+	}
+	return affectedOrError(res, "update webhook delivery", "webhook delivery not found")
+}
+
+// Redeliver queues a delivery again with a fresh retry budget.
+func (r *webhookRepository) Redeliver(deliveryID int, now time.Time) error {
+	query := "UPDATE tx_webhook_delivery SET status = 'Pending', attempts = 0, next_attempt_at = $1, delivered_at = NULL WHERE delivery_id = $2 AND status <> 'Pending'"
+	res, err := r.db.Exec(query, now, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to redeliver webhook: %v", err)
+	}
+	return affectedOrError(res, "redeliver webhook", "webhook delivery is already pending")
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
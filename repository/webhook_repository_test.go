@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyWebhookDelivery = model.WebhookDelivery{
+	DeliveryID:     5,
+	SubscriptionID: 1,
+	EventID:        "evt_1",
+	EventType:      "payment.succeeded",
+	Payload:        `{"event_id":"evt_1"}`,
+	Status:         "Pending",
+	NextAttemptAt:  time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	CreatedAt:      time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	URL:            "https://example.com/hook",
+	Secret:         "whsec_1",
+}
+
+type WebhookRepositoryTestSuite struct {
+	suite.Suite
+	mockDB  *sql.DB
+	mockSql sqlmock.Sqlmock
+}
+
+func (suite *WebhookRepositoryTestSuite) TestCreateSubscription_Success() {
+	subscription := model.WebhookSubscription{
+		UserID:     "1",
+		URL:        "https://example.com/hook",
+		EventTypes: []string{"payment.succeeded", "refund.created"},
+		Secret:     "whsec_1",
+		Active:     true,
+	}
+	suite.mockSql.ExpectQuery("INSERT INTO mst_webhook_subscription").
+		WithArgs("1", subscription.URL, "payment.succeeded,refund.created", "whsec_1", true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"subscription_id"}).AddRow(3))
+	repo := NewWebhookRepository(suite.mockDB)
+	err := repo.CreateSubscription(&subscription)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, subscription.SubscriptionID)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestGetSubscribers_Success() {
+	createdAt := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"subscription_id", "user_id", "url", "event_types", "active", "created_at"}).
+		AddRow(1, "1", "https://example.com/hook", "payment.succeeded,refund.created", true, createdAt)
+	suite.mockSql.ExpectQuery("SELECT subscription_id, user_id, url, event_types").WithArgs("1", "refund.created").WillReturnRows(rows)
+	repo := NewWebhookRepository(suite.mockDB)
+	res, err := repo.GetSubscribers("1", "refund.created")
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), []string{"payment.succeeded", "refund.created"}, res[0].EventTypes)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestGetSubscription_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT subscription_id, user_id, url, event_types").WithArgs(9, "1").WillReturnError(sql.ErrNoRows)
+	repo := NewWebhookRepository(suite.mockDB)
+	res, err := repo.GetSubscription(9, "1")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "webhook subscription not found")
+}
+
+func (suite *WebhookRepositoryTestSuite) TestDeleteSubscription_NotFound() {
+	suite.mockSql.ExpectExec("UPDATE mst_webhook_subscription SET active = FALSE").WithArgs(9, "1").WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewWebhookRepository(suite.mockDB)
+	err := repo.DeleteSubscription(9, "1")
+	assert.EqualError(suite.T(), err, "webhook subscription not found")
+}
+
+func (suite *WebhookRepositoryTestSuite) TestGetDueDeliveries_Success() {
+	d := dummyWebhookDelivery
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"delivery_id", "subscription_id", "event_id", "event_type", "payload", "status", "attempts",
+		"last_status_code", "last_error", "next_attempt_at", "created_at", "delivered_at", "url", "secret"}).
+		AddRow(d.DeliveryID, d.SubscriptionID, d.EventID, d.EventType, d.Payload, d.Status, d.Attempts,
+			d.LastStatusCode, d.LastError, d.NextAttemptAt, d.CreatedAt, nil, d.URL, d.Secret)
+	suite.mockSql.ExpectQuery("SELECT d.delivery_id").WithArgs(now, 50).WillReturnRows(rows)
+	repo := NewWebhookRepository(suite.mockDB)
+	res, err := repo.GetDueDeliveries(now, 50)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []*model.WebhookDelivery{&d}, res)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestUpdateDelivery_Success() {
+	d := dummyWebhookDelivery
+	d.Attempts = 1
+	d.LastStatusCode = 500
+	d.LastError = "unexpected status code 500"
+	suite.mockSql.ExpectExec("UPDATE tx_webhook_delivery SET status").
+		WithArgs(d.Status, 1, 500, d.LastError, d.NextAttemptAt, d.DeliveredAt, d.DeliveryID).WillReturnResult(sqlmock.NewResult(0, 1))
+	repo := NewWebhookRepository(suite.mockDB)
+	err := repo.UpdateDelivery(&d)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestRedeliver_AlreadyPending() {
+	now := time.Now()
+	suite.mockSql.ExpectExec("UPDATE tx_webhook_delivery SET status = 'Pending'").WithArgs(now, 5).WillReturnResult(sqlmock.NewResult(0, 0))
+	repo := NewWebhookRepository(suite.mockDB)
+	err := repo.Redeliver(5, now)
+	assert.EqualError(suite.T(), err, "webhook delivery is already pending")
+}
+
+func (suite *WebhookRepositoryTestSuite) SetupTest() {
+	mockDb, mockSql, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln("Error database", err)
+	}
+	suite.mockDB = mockDb
+	suite.mockSql = mockSql
+}
+func (suite *WebhookRepositoryTestSuite) TearDownTest() {
+	suite.mockDB.Close()
+}
+
+func TestWebhookRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookRepositoryTestSuite))
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	badgeWindowDays  = 90
+	badgeGracePeriod = 30 * 24 * time.Hour
+)
+
+type BadgeUsecase interface {
+	FindBadges() ([]*model.Badge, error)
+	FindHistory(userID string) ([]*model.BadgeChange, error)
+	FindProgress(user *model.User) (*model.BadgeProgress, error)
+	Evaluate(user *model.User) (*model.BadgeChange, error)
+	ReviewBadges() (int, error)
+}
+
+type badgeUsecase struct {
+	badgeRepo repository.BadgeRepository
+	notify    func(token string, title string, body string) error
+}
+
+// badgeBenefits returns the badge of the given id for its benefits. Benefits
+// never block a payment, so an unknown badge simply has none.
+func badgeBenefits(badgeRepo repository.BadgeRepository, badgeID int) *model.Badge {
+	badge, err := badgeRepo.GetByID(badgeID)
+	if err != nil {
+		return &model.Badge{BadgeID: badgeID}
+	}
+	return badge
+}
+
+// discountedFee applies the fee discount of a badge, rounding down.
+func discountedFee(fee int, badge *model.Badge) int {
+	if badge.FeeDiscountPercent <= 0 {
+		return fee
+	}
+	if badge.FeeDiscountPercent >= 100 {
+		return 0
+	}
+	return fee * (100 - badge.FeeDiscountPercent) / 100
+}
+
+// qualifiedBadge returns the index of the highest badge the activity meets
+// both thresholds of. Badges are ordered from the lowest tier up.
+func qualifiedBadge(badges []*model.Badge, activity *model.BadgeActivity) int {
+	qualified := 0
+	for i, badge := range badges {
+		if activity.TxCount >= badge.MinTxCount && activity.TxVolume >= badge.MinTxVolume {
+			qualified = i
+		}
+	}
+	return qualified
+}
+
+func badgeIndex(badges []*model.Badge, badgeID int) int {
+	for i, badge := range badges {
+		if badge.BadgeID == badgeID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (u *badgeUsecase) FindBadges() ([]*model.Badge, error) {
+	return u.badgeRepo.GetAll()
+}
+
+func (u *badgeUsecase) FindHistory(userID string) ([]*model.BadgeChange, error) {
+	return u.badgeRepo.GetHistory(userID)
+}
+
+// thresholdProgress is how far value is towards threshold, in percent.
+func thresholdProgress(value int, threshold int) int {
+	if threshold <= 0 || value >= threshold {
+		return 100
+	}
+	return value * 100 / threshold
+}
+
+// FindProgress compares the user's activity over the rolling window with
+// the thresholds of the badge above the one they hold.
+func (u *badgeUsecase) FindProgress(user *model.User) (*model.BadgeProgress, error) {
+	badges, err := u.badgeRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	since := time.Now().AddDate(0, 0, -badgeWindowDays).Format("2006-01-02")
+	activity, err := u.badgeRepo.GetActivity(user.ID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &model.BadgeProgress{
+		Activity:   *activity,
+		WindowDays: badgeWindowDays,
+		Progress:   100,
+		Tiers:      badges,
+	}
+	current := badgeIndex(badges, user.BadgeID)
+	if current >= 0 {
+		progress.CurrentBadge = badges[current]
+	}
+	if current+1 >= len(badges) {
+		return progress, nil
+	}
+
+	next := badges[current+1]
+	progress.NextBadge = next
+	if activity.TxCount < next.MinTxCount {
+		progress.RemainingTxCount = next.MinTxCount - activity.TxCount
+	}
+	if activity.TxVolume < next.MinTxVolume {
+		progress.RemainingTxVolume = next.MinTxVolume - activity.TxVolume
+	}
+	// Both thresholds must be met, so the one furthest behind sets the pace
+	progress.Progress = thresholdProgress(activity.TxCount, next.MinTxCount)
+	if volume := thresholdProgress(activity.TxVolume, next.MinTxVolume); volume < progress.Progress {
+		progress.Progress = volume
+	}
+	return progress, nil
+}
+
+// announce tells the user about a badge change. Upgrades use the message
+// of the new badge; badges without one are reached silently. The change
+// stands even when the message cannot be sent.
+func (u *badgeUsecase) announce(badges []*model.Badge, token string, change *model.BadgeChange) {
+	if token == "" {
+		return
+	}
+	if change.Direction == "Downgrade" {
+		_ = u.notify(token, "Level Berubah", "Level Anda sekarang "+change.ToBadge+". Tingkatkan transaksi untuk kembali naik level")
+		return
+	}
+	if i := badgeIndex(badges, change.ToBadgeID); i >= 0 && badges[i].UpgradeMessage != "" {
+		_ = u.notify(token, "Selamat", badges[i].UpgradeMessage)
+	}
+}
+
+// evaluate compares the badge a user holds with the one their activity over
+// the rolling window earns. Downgrades are only applied when allowed.
+func (u *badgeUsecase) evaluate(badges []*model.Badge, userID string, badgeID int, allowDowngrade bool, now time.Time) (*model.BadgeChange, error) {
+	if len(badges) == 0 {
+		return nil, nil
+	}
+
+	since := now.AddDate(0, 0, -badgeWindowDays).Format("2006-01-02")
+	activity, err := u.badgeRepo.GetActivity(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	current := badgeIndex(badges, badgeID)
+	qualified := qualifiedBadge(badges, activity)
+	if qualified == current || (qualified < current && !allowDowngrade) {
+		return nil, u.badgeRepo.UpdateTxCount(userID, activity.TxCount)
+	}
+
+	change := &model.BadgeChange{
+		UserID:      userID,
+		FromBadgeID: badgeID,
+		ToBadgeID:   badges[qualified].BadgeID,
+		ToBadge:     badges[qualified].BadgeName,
+		Direction:   "Upgrade",
+		TxCount:     activity.TxCount,
+		TxVolume:    activity.TxVolume,
+		ChangedAt:   now,
+	}
+	if current >= 0 {
+		change.FromBadge = badges[current].BadgeName
+	}
+	if qualified < current {
+		change.Direction = "Downgrade"
+	}
+	if err := u.badgeRepo.ChangeBadge(change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Evaluate runs after a transaction and moves the user up when their recent
+// activity earns a higher badge, letting them know. Downgrades are left to
+// ReviewBadges. It returns nil when the badge stays the same.
+func (u *badgeUsecase) Evaluate(user *model.User) (*model.BadgeChange, error) {
+	badges, err := u.badgeRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	change, err := u.evaluate(badges, user.ID, user.BadgeID, false, time.Now())
+	if err != nil || change == nil {
+		return nil, err
+	}
+	user.BadgeID = change.ToBadgeID
+	user.Badge = change.ToBadge
+	user.TxCount = change.TxCount
+	u.announce(badges, user.Token, change)
+	return change, nil
+}
+
+// ReviewBadges re-evaluates every user against the rolling window. Users
+// move down once their badge has been held for the grace period, and up
+// whenever they qualify. It reports how many badges changed.
+func (u *badgeUsecase) ReviewBadges() (int, error) {
+	badges, err := u.badgeRepo.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	holders, err := u.badgeRepo.GetHolders()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	changed := 0
+	for _, holder := range holders {
+		allowDowngrade := holder.BadgeChangedAt == nil || now.Sub(*holder.BadgeChangedAt) >= badgeGracePeriod
+		change, err := u.evaluate(badges, holder.UserID, holder.BadgeID, allowDowngrade, now)
+		if err != nil {
+			if errors.Is(err, repository.ErrBadgeChanged) {
+				continue
+			}
+			return changed, err
+		}
+		if change == nil {
+			continue
+		}
+		changed++
+		u.announce(badges, holder.Token, change)
+	}
+	return changed, nil
+}
+
+func NewBadgeUsecase(badgeRepo repository.BadgeRepository) BadgeUsecase {
+	return &badgeUsecase{
+		badgeRepo: badgeRepo,
+		notify:    model.SendFCMNotification,
+	}
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyBadges = []*model.Badge{
+	{BadgeID: 1, BadgeName: "Bronze"},
+	{BadgeID: 2, BadgeName: "Silver", MinTxCount: 5, MinTxVolume: 1000000, UpgradeMessage: "Anda telah naik level menjadi Silver"},
+	{BadgeID: 3, BadgeName: "Gold", MinTxCount: 20, MinTxVolume: 10000000, UpgradeMessage: "Anda telah naik level menjadi Gold"},
+}
+
+type badgeRepoMock struct {
+	mock.Mock
+}
+
+func (r *badgeRepoMock) GetAll() ([]*model.Badge, error) {
+	args := r.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Badge), args.Error(1)
+}
+
+func (r *badgeRepoMock) GetByID(badgeID int) (*model.Badge, error) {
+	args := r.Called(badgeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Badge), args.Error(1)
+}
+
+func (r *badgeRepoMock) GetActivity(userID string, since string) (*model.BadgeActivity, error) {
+	args := r.Called(userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BadgeActivity), args.Error(1)
+}
+
+func (r *badgeRepoMock) GetHolders() ([]*model.BadgeHolder, error) {
+	args := r.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.BadgeHolder), args.Error(1)
+}
+
+func (r *badgeRepoMock) UpdateTxCount(userID string, txCount int) error {
+	args := r.Called(userID, txCount)
+	return args.Error(0)
+}
+
+func (r *badgeRepoMock) ChangeBadge(change *model.BadgeChange) error {
+	args := r.Called(change)
+	return args.Error(0)
+}
+
+func (r *badgeRepoMock) GetHistory(userID string) ([]*model.BadgeChange, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.BadgeChange), args.Error(1)
+}
+
+type BadgeUsecaseTestSuite struct {
+	badgeRepoMock *badgeRepoMock
+	suite.Suite
+}
+
+func (suite *BadgeUsecaseTestSuite) TestQualifiedBadge_NeedsCountAndVolume() {
+	assert.Equal(suite.T(), 0, qualifiedBadge(dummyBadges, &model.BadgeActivity{TxCount: 50, TxVolume: 500000}))
+	assert.Equal(suite.T(), 1, qualifiedBadge(dummyBadges, &model.BadgeActivity{TxCount: 5, TxVolume: 50000000}))
+	assert.Equal(suite.T(), 2, qualifiedBadge(dummyBadges, &model.BadgeActivity{TxCount: 20, TxVolume: 10000000}))
+}
+
+func (suite *BadgeUsecaseTestSuite) TestDiscountedFee() {
+	assert.Equal(suite.T(), 2500, discountedFee(2500, &model.Badge{}))
+	assert.Equal(suite.T(), 1875, discountedFee(2500, &model.Badge{FeeDiscountPercent: 25}))
+	assert.Equal(suite.T(), 0, discountedFee(2500, &model.Badge{FeeDiscountPercent: 120}))
+}
+
+func (suite *BadgeUsecaseTestSuite) TestEvaluate_Upgrade() {
+	user := &model.User{ID: "1", BadgeID: 1, Token: "token-1"}
+	suite.badgeRepoMock.On("GetAll").Return(dummyBadges, nil)
+	suite.badgeRepoMock.On("GetActivity", "1", mock.AnythingOfType("string")).Return(&model.BadgeActivity{TxCount: 6, TxVolume: 2000000}, nil)
+	suite.badgeRepoMock.On("ChangeBadge", mock.AnythingOfType("*model.BadgeChange")).Return(nil)
+
+	uc := NewBadgeUsecase(suite.badgeRepoMock)
+	var sentBody string
+	uc.(*badgeUsecase).notify = func(token string, title string, body string) error {
+		sentBody = body
+		return nil
+	}
+
+	res, err := uc.Evaluate(user)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Upgrade", res.Direction)
+	assert.Equal(suite.T(), "Bronze", res.FromBadge)
+	assert.Equal(suite.T(), "Silver", res.ToBadge)
+	assert.Equal(suite.T(), 2, user.BadgeID)
+	assert.Equal(suite.T(), 6, user.TxCount)
+	assert.Equal(suite.T(), "Anda telah naik level menjadi Silver", sentBody)
+}
+
+func (suite *BadgeUsecaseTestSuite) TestEvaluate_BadgeWithoutMessage() {
+	badges := []*model.Badge{{BadgeID: 1, BadgeName: "Bronze", MinTxCount: 5}, {BadgeID: 2, BadgeName: "Silver", MinTxCount: 10}}
+	user := &model.User{ID: "1", BadgeID: 0, Token: "token-1"}
+	suite.badgeRepoMock.On("GetAll").Return(badges, nil)
+	suite.badgeRepoMock.On("GetActivity", "1", mock.AnythingOfType("string")).Return(&model.BadgeActivity{TxCount: 6}, nil)
+	suite.badgeRepoMock.On("ChangeBadge", mock.AnythingOfType("*model.BadgeChange")).Return(nil)
+
+	uc := NewBadgeUsecase(suite.badgeRepoMock)
+	uc.(*badgeUsecase).notify = func(token string, title string, body string) error {
+		suite.T().Errorf("unexpected notification: %s", body)
+		return nil
+	}
+
+	res, err := uc.Evaluate(user)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Bronze", res.ToBadge)
+}
+
+func (suite *BadgeUsecaseTestSuite) TestFindProgress_TowardsNextBadge() {
+	suite.badgeRepoMock.On("GetAll").Return(dummyBadges, nil)
+	suite.badgeRepoMock.On("GetActivity", "1", mock.AnythingOfType("string")).Return(&model.BadgeActivity{TxCount: 15, TxVolume: 2500000}, nil)
+
+	res, err := NewBadgeUsecase(suite.badgeRepoMock).FindProgress(&model.User{ID: "1", BadgeID: 2})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Silver", res.CurrentBadge.BadgeName)
+	assert.Equal(suite.T(), "Gold", res.NextBadge.BadgeName)
+	assert.Equal(suite.T(), 5, res.RemainingTxCount)
+	assert.Equal(suite.T(), 7500000, res.RemainingTxVolume)
+	assert.Equal(suite.T(), 25, res.Progress)
+	assert.Equal(suite.T(), badgeWindowDays, res.WindowDays)
+	assert.Len(suite.T(), res.Tiers, 3)
+}
+
+func (suite *BadgeUsecaseTestSuite) TestFindProgress_TopTier() {
+	suite.badgeRepoMock.On("GetAll").Return(dummyBadges, nil)
+	suite.badgeRepoMock.On("GetActivity", "1", mock.AnythingOfType("string")).Return(&model.BadgeActivity{TxCount: 3, TxVolume: 50000}, nil)
+
+	res, err := NewBadgeUsecase(suite.badgeRepoMock).FindProgress(&model.User{ID: "1", BadgeID: 3})
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), res.NextBadge)
+	assert.Equal(suite.T(), 100, res.Progress)
+	assert.Equal(suite.T(), 0, res.RemainingTxCount)
+}
+
+func (suite *BadgeUsecaseTestSuite) TestEvaluate_NeverDowngrades() {
+	user := &model.User{ID: "1", BadgeID: 3}
+	suite.badgeRepoMock.On("GetAll").Return(dummyBadges, nil)
+	suite.badgeRepoMock.On("GetActivity", "1", mock.AnythingOfType("string")).Return(&model.BadgeActivity{TxCount: 1, TxVolume: 10000}, nil)
+	suite.badgeRepoMock.On("UpdateTxCount", "1", 1).Return(nil)
+
+	res, err := NewBadgeUsecase(suite.badgeRepoMock).Evaluate(user)
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), res)
+	assert.Equal(suite.T(), 3, user.BadgeID)
+	suite.badgeRepoMock.AssertNotCalled(suite.T(), "ChangeBadge", mock.Anything)
+}
+
+func (suite *BadgeUsecaseTestSuite) TestReviewBadges_DowngradesAfterGracePeriod() {
+	longAgo := time.Now().AddDate(0, -2, 0)
+	recently := time.Now().AddDate(0, 0, -3)
+	holders := []*model.BadgeHolder{
+		{UserID: "1", BadgeID: 3, Token: "token-1", BadgeChangedAt: &longAgo},
+		{UserID: "2", BadgeID: 3, Token: "token-2", BadgeChangedAt: &recently},
+	}
+	suite.badgeRepoMock.On("GetAll").Return(dummyBadges, nil)
+	suite.badgeRepoMock.On("GetHolders").Return(holders, nil)
+	suite.badgeRepoMock.On("GetActivity", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(&model.BadgeActivity{TxCount: 6, TxVolume: 2000000}, nil)
+	suite.badgeRepoMock.On("ChangeBadge", mock.AnythingOfType("*model.BadgeChange")).Return(nil).Run(func(args mock.Arguments) {
+		change := args.Get(0).(*model.BadgeChange)
+		assert.Equal(suite.T(), "1", change.UserID)
+		assert.Equal(suite.T(), "Downgrade", change.Direction)
+		assert.Equal(suite.T(), 2, change.ToBadgeID)
+	})
+	suite.badgeRepoMock.On("UpdateTxCount", "2", 6).Return(nil)
+
+	uc := NewBadgeUsecase(suite.badgeRepoMock)
+	var notified []string
+	uc.(*badgeUsecase).notify = func(token string, title string, body string) error {
+		notified = append(notified, token)
+		return nil
+	}
+
+	res, err := uc.ReviewBadges()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, res)
+	assert.Equal(suite.T(), []string{"token-1"}, notified)
+}
+
+func (suite *BadgeUsecaseTestSuite) TestReviewBadges_SkipsConcurrentChange() {
+	holders := []*model.BadgeHolder{{UserID: "1", BadgeID: 1}}
+	suite.badgeRepoMock.On("GetAll").Return(dummyBadges, nil)
+	suite.badgeRepoMock.On("GetHolders").Return(holders, nil)
+	suite.badgeRepoMock.On("GetActivity", "1", mock.AnythingOfType("string")).Return(&model.BadgeActivity{TxCount: 6, TxVolume: 2000000}, nil)
+	suite.badgeRepoMock.On("ChangeBadge", mock.AnythingOfType("*model.BadgeChange")).Return(repository.ErrBadgeChanged)
+
+	res, err := NewBadgeUsecase(suite.badgeRepoMock).ReviewBadges()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, res)
+}
+
+func (suite *BadgeUsecaseTestSuite) SetupTest() {
+	suite.badgeRepoMock = new(badgeRepoMock)
+}
+
+func TestBadgeUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(BadgeUsecaseTestSuite))
+}
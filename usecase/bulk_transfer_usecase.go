@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxBulkTransferRows   = 500
+	maxBulkTransferAmount = 25000000
+)
+
+type BulkTransferUsecase interface {
+	Preview(user *model.User, fileName string, file io.Reader) (*model.BulkTransfer, error)
+	Execute(user *model.User, batchID int) (*model.BulkTransfer, error)
+	FindBatch(userID string, batchID int) (*model.BulkTransfer, error)
+	FindBatches(userID string) ([]*model.BulkTransfer, error)
+}
+
+type bulkTransferUsecase struct {
+	bulkRepo       repository.BulkTransferRepository
+	userRepo       repository.UserRepository
+	txUsecase      TransactionUseCase
+	inquiryUsecase TransferInquiryUseCase
+}
+
+// Preview parses a CSV of phone_number, amount and note, validates every row
+// and stores the batch so it can be executed later.
+func (u *bulkTransferUsecase) Preview(user *model.User, fileName string, file io.Reader) (*model.BulkTransfer, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv file")
+	}
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "phone_number") {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("csv file is empty")
+	}
+	if len(records) > maxBulkTransferRows {
+		return nil, fmt.Errorf("maximum 500 rows per bulk transfer")
+	}
+
+	batch := &model.BulkTransfer{
+		UserID:    user.ID,
+		FileName:  fileName,
+		Status:    "Preview",
+		TotalRows: len(records),
+	}
+	seen := make(map[string]bool)
+	for i, record := range records {
+		item := u.validateRow(user, record, seen)
+		item.RowNumber = i + 1
+		if item.Status == "Valid" {
+			batch.ValidRows++
+			batch.TotalAmount += item.Amount
+			batch.TotalFee += item.Fee
+		}
+		batch.Items = append(batch.Items, item)
+	}
+
+	if batch.ValidRows == 0 {
+		return nil, fmt.Errorf("no valid rows")
+	}
+	available, err := u.txUsecase.AvailableBalance(user)
+	if err != nil {
+		return nil, err
+	}
+	if available < batch.TotalAmount+batch.TotalFee {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+
+	if err := u.bulkRepo.Create(batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (u *bulkTransferUsecase) validateRow(user *model.User, record []string, seen map[string]bool) *model.BulkTransferItem {
+	item := &model.BulkTransferItem{Status: "Invalid"}
+	if len(record) < 2 || len(record) > 3 {
+		item.Message = "row must contain phone_number, amount and optional note"
+		return item
+	}
+
+	item.PhoneNumber = strings.TrimSpace(record[0])
+	if len(record) == 3 {
+		item.Note = strings.TrimSpace(record[2])
+	}
+
+	amount, err := strconv.Atoi(strings.TrimSpace(record[1]))
+	if err != nil {
+		item.Message = "invalid amount"
+		return item
+	}
+	item.Amount = amount
+
+	switch {
+	case !isPhoneNumber(item.PhoneNumber):
+		item.Message = "invalid phone number"
+		return item
+	case amount < minTransferAmount:
+		item.Message = "minimum transfer amount is 10,000"
+		return item
+	case amount > maxBulkTransferAmount:
+		item.Message = "maximum transfer amount is 25,000,000"
+		return item
+	case len(item.Note) > maxTransferNoteLength:
+		item.Message = fmt.Sprintf("note must be at most %d characters", maxTransferNoteLength)
+		return item
+	case seen[item.PhoneNumber]:
+		item.Message = "duplicate recipient"
+		return item
+	}
+
+	recipient, err := u.userRepo.GetByPhone(item.PhoneNumber)
+	if err != nil || recipient == nil {
+		item.Message = "recipient not found"
+		return item
+	}
+	if recipient.ID == user.ID {
+		item.Message = "cannot transfer to yourself"
+		return item
+	}
+
+	seen[item.PhoneNumber] = true
+	item.RecipientID = recipient.ID
+	item.RecipientName = recipient.Name
+	item.Fee = u.inquiryUsecase.QuoteFee(user, amount)
+	item.Status = "Valid"
+	return item
+}
+
+// Execute runs every valid row of a previewed batch as a regular transfer.
+// A failing row does not stop the batch; its reason is kept on the row.
+func (u *bulkTransferUsecase) Execute(user *model.User, batchID int) (*model.BulkTransfer, error) {
+	batch, err := u.bulkRepo.GetByID(batchID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != "Preview" {
+		return nil, fmt.Errorf("bulk transfer already executed")
+	}
+	available, err := u.txUsecase.AvailableBalance(user)
+	if err != nil {
+		return nil, err
+	}
+	if available < batch.TotalAmount+batch.TotalFee {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+
+	if err := u.bulkRepo.Claim(batchID, user.ID); err != nil {
+		return nil, err
+	}
+
+	for _, item := range batch.Items {
+		if item.Status != "Valid" {
+			continue
+		}
+
+		transfer := &model.Transfer{Amount: item.Amount, Fee: item.Fee, Note: item.Note}
+		err := u.transferItem(user.ID, item.RecipientID, transfer)
+		if err != nil {
+			item.Status = "Failed"
+			item.Message = err.Error()
+			batch.FailedCount++
+		} else {
+			item.Status = "Success"
+			item.TransactionID = transfer.TransactionID
+			batch.SuccessCount++
+		}
+		_ = u.bulkRepo.UpdateItem(item)
+	}
+
+	switch {
+	case batch.FailedCount == 0:
+		batch.Status = "Completed"
+	case batch.SuccessCount == 0:
+		batch.Status = "Failed"
+	default:
+		batch.Status = "Partially Completed"
+	}
+	if err := u.bulkRepo.Complete(batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (u *bulkTransferUsecase) transferItem(senderID string, recipientID string, transfer *model.Transfer) error {
+	// Balances change with every row, so both sides are reloaded
+	sender, err := u.userRepo.GetByiD(senderID)
+	if err != nil {
+		return fmt.Errorf("sender not found")
+	}
+	recipient, err := u.userRepo.GetByiD(recipientID)
+	if err != nil {
+		return fmt.Errorf("recipient not found")
+	}
+	return u.txUsecase.CreateTransfer(sender, recipient, transfer)
+}
+
+func (u *bulkTransferUsecase) FindBatch(userID string, batchID int) (*model.BulkTransfer, error) {
+	return u.bulkRepo.GetByID(batchID, userID)
+}
+
+func (u *bulkTransferUsecase) FindBatches(userID string) ([]*model.BulkTransfer, error) {
+	return u.bulkRepo.GetByUserID(userID)
+}
+
+func NewBulkTransferUsecase(bulkRepo repository.BulkTransferRepository, userRepo repository.UserRepository, txUsecase TransactionUseCase, inquiryUsecase TransferInquiryUseCase) BulkTransferUsecase {
+	return &bulkTransferUsecase{
+		bulkRepo:       bulkRepo,
+		userRepo:       userRepo,
+		txUsecase:      txUsecase,
+		inquiryUsecase: inquiryUsecase,
+	}
+}
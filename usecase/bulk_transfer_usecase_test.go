@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type bulkTransferRepoMock struct {
+	mock.Mock
+}
+
+func (r *bulkTransferRepoMock) Create(batch *model.BulkTransfer) error {
+	args := r.Called(batch)
+	return args.Error(0)
+}
+
+func (r *bulkTransferRepoMock) GetByID(batchID int, userID string) (*model.BulkTransfer, error) {
+	args := r.Called(batchID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BulkTransfer), args.Error(1)
+}
+
+func (r *bulkTransferRepoMock) GetByUserID(userID string) ([]*model.BulkTransfer, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.BulkTransfer), args.Error(1)
+}
+
+func (r *bulkTransferRepoMock) Claim(batchID int, userID string) error {
+	args := r.Called(batchID, userID)
+	return args.Error(0)
+}
+
+func (r *bulkTransferRepoMock) UpdateItem(item *model.BulkTransferItem) error {
+	args := r.Called(item)
+	return args.Error(0)
+}
+
+func (r *bulkTransferRepoMock) Complete(batch *model.BulkTransfer) error {
+	args := r.Called(batch)
+	return args.Error(0)
+}
+
+type BulkTransferUsecaseTestSuite struct {
+	bulkRepoMock    *bulkTransferRepoMock
+	userRepoMock    *userRepoMock
+	txRepoMock      *transactionRepoMock
+	inquiryRepoMock *transferInquiryRepoMock
+	holdRepoMock    *holdRepoMock
+	badgeRepoMock   *badgeRepoMock
+	suite.Suite
+}
+
+func (suite *BulkTransferUsecaseTestSuite) usecase() BulkTransferUsecase {
+	txUsecase := NewTransactionUseCase(suite.txRepoMock, suite.userRepoMock, suite.holdRepoMock, new(pointLedgerRepoMock))
+	inquiryUsecase := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	return NewBulkTransferUsecase(suite.bulkRepoMock, suite.userRepoMock, txUsecase, inquiryUsecase)
+}
+
+func (suite *BulkTransferUsecaseTestSuite) TestPreview_Success() {
+	user := dummySender
+	csvFile := "phone_number,amount,note\n08111111,20000,gaji juni\n08111111,5000,\n0899,20000\nabc,20000\n"
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil).Once()
+	suite.userRepoMock.On("GetByPhone", "0899").Return("", errors.New("phone not found"))
+	suite.inquiryRepoMock.On("GetTransferFee", 20000).Return(&model.TransferFee{Fee: 1000}, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
+	suite.bulkRepoMock.On("Create", mock.AnythingOfType("*model.BulkTransfer")).Return(nil)
+
+	res, err := suite.usecase().Preview(&user, "payroll.csv", strings.NewReader(csvFile))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 4, res.TotalRows)
+	assert.Equal(suite.T(), 1, res.ValidRows)
+	assert.Equal(suite.T(), 20000, res.TotalAmount)
+	assert.Equal(suite.T(), 1000, res.TotalFee)
+	assert.Equal(suite.T(), "Valid", res.Items[0].Status)
+	assert.Equal(suite.T(), "minimum transfer amount is 10,000", res.Items[1].Message)
+	assert.Equal(suite.T(), "recipient not found", res.Items[2].Message)
+	assert.Equal(suite.T(), "invalid phone number", res.Items[3].Message)
+}
+
+func (suite *BulkTransferUsecaseTestSuite) TestPreview_InsufficientBalance() {
+	user := dummySender
+	user.Balance = 30000
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.userRepoMock.On("GetByPhone", "08111112").Return(nil, nil)
+	suite.inquiryRepoMock.On("GetTransferFee", 20000).Return(&model.TransferFee{Fee: 1000}, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
+
+	res, err := suite.usecase().Preview(&user, "payroll.csv", strings.NewReader("08111111,20000\n08111112,20000\n"))
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.bulkRepoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *BulkTransferUsecaseTestSuite) TestPreview_NoValidRows() {
+	user := dummySender
+	res, err := suite.usecase().Preview(&user, "payroll.csv", strings.NewReader("phone_number,amount\nabc,1\n"))
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "no valid rows")
+}
+
+func (suite *BulkTransferUsecaseTestSuite) TestExecute_PartiallyCompleted() {
+	user := dummySender
+	recipient := &model.User{ID: "1", Balance: 0}
+	batch := &model.BulkTransfer{
+		BatchID:     1,
+		UserID:      user.ID,
+		Status:      "Preview",
+		TotalAmount: 40000,
+		TotalFee:    2000,
+		Items: []*model.BulkTransferItem{
+			{ItemID: 1, RecipientID: "1", Amount: 20000, Fee: 1000, Status: "Valid"},
+			{ItemID: 2, RecipientID: "9", Amount: 20000, Fee: 1000, Status: "Valid"},
+			{ItemID: 3, Status: "Invalid", Message: "recipient not found"},
+		},
+	}
+	suite.bulkRepoMock.On("GetByID", 1, user.ID).Return(batch, nil)
+	suite.bulkRepoMock.On("Claim", 1, user.ID).Return(nil)
+	suite.userRepoMock.On("GetByiD", user.ID).Return(&user, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(recipient, nil)
+	suite.userRepoMock.On("GetByiD", "9").Return(nil, errors.New("id not found"))
+	suite.userRepoMock.On("UpdateBalance", mock.Anything, mock.Anything).Return(nil)
+	suite.userRepoMock.On("UpdatePoint", mock.Anything, mock.Anything).Return(nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
+	suite.txRepoMock.On("CreateTransfer", mock.AnythingOfType("*model.Transfer")).Return(nil)
+	suite.bulkRepoMock.On("UpdateItem", mock.AnythingOfType("*model.BulkTransferItem")).Return(nil)
+	suite.bulkRepoMock.On("Complete", batch).Return(nil)
+
+	res, err := suite.usecase().Execute(&user, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Partially Completed", res.Status)
+	assert.Equal(suite.T(), 1, res.SuccessCount)
+	assert.Equal(suite.T(), 1, res.FailedCount)
+	assert.Equal(suite.T(), "Success", res.Items[0].Status)
+	assert.Equal(suite.T(), "recipient not found", res.Items[1].Message)
+	assert.Equal(suite.T(), "Invalid", res.Items[2].Status)
+}
+
+func (suite *BulkTransferUsecaseTestSuite) TestExecute_AlreadyExecuted() {
+	user := dummySender
+	batch := &model.BulkTransfer{BatchID: 1, UserID: user.ID, Status: "Completed"}
+	suite.bulkRepoMock.On("GetByID", 1, user.ID).Return(batch, nil)
+
+	res, err := suite.usecase().Execute(&user, 1)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "bulk transfer already executed")
+	suite.bulkRepoMock.AssertNotCalled(suite.T(), "Claim", 1, user.ID)
+}
+
+func (suite *BulkTransferUsecaseTestSuite) TestExecute_InsufficientBalance() {
+	user := dummySender
+	user.Balance = 1000
+	batch := &model.BulkTransfer{BatchID: 1, UserID: user.ID, Status: "Preview", TotalAmount: 20000, TotalFee: 1000}
+	suite.bulkRepoMock.On("GetByID", 1, user.ID).Return(batch, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
+
+	res, err := suite.usecase().Execute(&user, 1)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+}
+
+func (suite *BulkTransferUsecaseTestSuite) TestExecute_BalanceHeld() {
+	user := dummySender
+	user.Balance = 30000
+	batch := &model.BulkTransfer{BatchID: 1, UserID: user.ID, Status: "Preview", TotalAmount: 20000, TotalFee: 1000}
+	suite.bulkRepoMock.On("GetByID", 1, user.ID).Return(batch, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(10000, nil)
+
+	res, err := suite.usecase().Execute(&user, 1)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.bulkRepoMock.AssertNotCalled(suite.T(), "Claim", 1, user.ID)
+}
+
+func (suite *BulkTransferUsecaseTestSuite) SetupTest() {
+	suite.bulkRepoMock = new(bulkTransferRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.txRepoMock = new(transactionRepoMock)
+	suite.inquiryRepoMock = new(transferInquiryRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.badgeRepoMock = new(badgeRepoMock)
+	suite.badgeRepoMock.On("GetByID", mock.Anything).Return(nil, errors.New("badge not found"))
+}
+
+func TestBulkTransferUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(BulkTransferUsecaseTestSuite))
+}
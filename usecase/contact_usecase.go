@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	defaultRecentRecipients = 5
+	maxRecentRecipients     = 20
+	maxContactAliasLength   = 30
+)
+
+type ContactUsecase interface {
+	FindContacts(userID string) ([]*model.Contact, error)
+	AddContact(contact *model.Contact) error
+	RenameContact(contactID int, userID string, alias string) error
+	RemoveContact(contactID int, userID string) error
+	FindRecentRecipients(userID string, limit int) ([]*model.RecentRecipient, error)
+}
+
+type contactUsecase struct {
+	contactRepo repository.ContactRepository
+	userRepo    repository.UserRepository
+}
+
+func (u *contactUsecase) FindContacts(userID string) ([]*model.Contact, error) {
+	return u.contactRepo.GetByUserID(userID)
+}
+
+func (u *contactUsecase) AddContact(contact *model.Contact) error {
+	recipient, err := u.userRepo.GetByPhone(contact.PhoneNumber)
+	if err != nil {
+		return fmt.Errorf("recipient not found")
+	}
+	if recipient.ID == contact.UserID {
+		return fmt.Errorf("cannot add yourself as a contact")
+	}
+
+	contacts, err := u.contactRepo.GetByUserID(contact.UserID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range contacts {
+		if existing.RecipientID == recipient.ID {
+			return fmt.Errorf("contact already exists")
+		}
+	}
+
+	contact.Alias = strings.TrimSpace(contact.Alias)
+	if contact.Alias == "" {
+		contact.Alias = recipient.Name
+	}
+	if len(contact.Alias) > maxContactAliasLength {
+		return fmt.Errorf("alias must be at most 30 characters")
+	}
+	contact.RecipientID = recipient.ID
+	contact.RecipientName = recipient.Name
+	return u.contactRepo.Create(contact)
+}
+
+func (u *contactUsecase) RenameContact(contactID int, userID string, alias string) error {
+	alias = strings.TrimSpace(alias)
+	if alias == "" || len(alias) > maxContactAliasLength {
+		return fmt.Errorf("alias must be 1 - 30 characters")
+	}
+	return u.contactRepo.UpdateAlias(contactID, userID, alias)
+}
+
+func (u *contactUsecase) RemoveContact(contactID int, userID string) error {
+	return u.contactRepo.Delete(contactID, userID)
+}
+
+func (u *contactUsecase) FindRecentRecipients(userID string, limit int) ([]*model.RecentRecipient, error) {
+	if limit <= 0 {
+		limit = defaultRecentRecipients
+	}
+	if limit > maxRecentRecipients {
+		limit = maxRecentRecipients
+	}
+	return u.contactRepo.GetRecentRecipients(userID, limit)
+}
+
+func NewContactUsecase(contactRepo repository.ContactRepository, userRepo repository.UserRepository) ContactUsecase {
+	return &contactUsecase{
+		contactRepo: contactRepo,
+		userRepo:    userRepo,
+	}
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type contactRepoMock struct {
+	mock.Mock
+}
+
+func (r *contactRepoMock) GetByUserID(userID string) ([]*model.Contact, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Contact), args.Error(1)
+}
+
+func (r *contactRepoMock) Create(contact *model.Contact) error {
+	args := r.Called(contact)
+	return args.Error(0)
+}
+
+func (r *contactRepoMock) UpdateAlias(contactID int, userID string, alias string) error {
+	args := r.Called(contactID, userID, alias)
+	return args.Error(0)
+}
+
+func (r *contactRepoMock) Delete(contactID int, userID string) error {
+	args := r.Called(contactID, userID)
+	return args.Error(0)
+}
+
+func (r *contactRepoMock) GetRecentRecipients(userID string, limit int) ([]*model.RecentRecipient, error) {
+	args := r.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RecentRecipient), args.Error(1)
+}
+
+type ContactUsecaseTestSuite struct {
+	contactRepoMock *contactRepoMock
+	userRepoMock    *userRepoMock
+	suite.Suite
+}
+
+func (suite *ContactUsecaseTestSuite) TestAddContact_Success() {
+	contact := &model.Contact{UserID: "2", PhoneNumber: "08111111"}
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.contactRepoMock.On("GetByUserID", "2").Return([]*model.Contact{}, nil)
+	suite.contactRepoMock.On("Create", contact).Return(nil)
+
+	err := NewContactUsecase(suite.contactRepoMock, suite.userRepoMock).AddContact(contact)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "1", contact.RecipientID)
+	assert.Equal(suite.T(), "name1", contact.Alias)
+}
+
+func (suite *ContactUsecaseTestSuite) TestAddContact_Duplicate() {
+	contact := &model.Contact{UserID: "2", PhoneNumber: "08111111"}
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.contactRepoMock.On("GetByUserID", "2").Return([]*model.Contact{{RecipientID: "1"}}, nil)
+
+	err := NewContactUsecase(suite.contactRepoMock, suite.userRepoMock).AddContact(contact)
+
+	assert.EqualError(suite.T(), err, "contact already exists")
+}
+
+func (suite *ContactUsecaseTestSuite) TestAddContact_Self() {
+	contact := &model.Contact{UserID: "1", PhoneNumber: "08111111"}
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+
+	err := NewContactUsecase(suite.contactRepoMock, suite.userRepoMock).AddContact(contact)
+
+	assert.EqualError(suite.T(), err, "cannot add yourself as a contact")
+}
+
+func (suite *ContactUsecaseTestSuite) TestAddContact_RecipientNotFound() {
+	contact := &model.Contact{UserID: "1", PhoneNumber: "0899"}
+	suite.userRepoMock.On("GetByPhone", "0899").Return("", errors.New("phone not found"))
+
+	err := NewContactUsecase(suite.contactRepoMock, suite.userRepoMock).AddContact(contact)
+
+	assert.EqualError(suite.T(), err, "recipient not found")
+}
+
+func (suite *ContactUsecaseTestSuite) TestRenameContact_Invalid() {
+	err := NewContactUsecase(suite.contactRepoMock, suite.userRepoMock).RenameContact(1, "1", " ")
+	assert.EqualError(suite.T(), err, "alias must be 1 - 30 characters")
+}
+
+func (suite *ContactUsecaseTestSuite) TestRenameContact_Success() {
+	suite.contactRepoMock.On("UpdateAlias", 1, "1", "Ayah").Return(nil)
+	err := NewContactUsecase(suite.contactRepoMock, suite.userRepoMock).RenameContact(1, "1", " Ayah ")
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *ContactUsecaseTestSuite) TestFindRecentRecipients_Limit() {
+	recipients := []*model.RecentRecipient{{RecipientID: "2"}}
+	suite.contactRepoMock.On("GetRecentRecipients", "1", defaultRecentRecipients).Return(recipients, nil)
+	suite.contactRepoMock.On("GetRecentRecipients", "1", maxRecentRecipients).Return(recipients, nil)
+
+	uc := NewContactUsecase(suite.contactRepoMock, suite.userRepoMock)
+	res, err := uc.FindRecentRecipients("1", 0)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), recipients, res)
+
+	_, err = uc.FindRecentRecipients("1", 100)
+	assert.Nil(suite.T(), err)
+	suite.contactRepoMock.AssertCalled(suite.T(), "GetRecentRecipients", "1", maxRecentRecipients)
+}
+
+func (suite *ContactUsecaseTestSuite) SetupTest() {
+	suite.contactRepoMock = new(contactRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+}
+
+func TestContactUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ContactUsecaseTestSuite))
+}
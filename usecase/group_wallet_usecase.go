@@ -0,0 +1,343 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxGroupNameLength   = 50
+	minGroupContribution = 10000
+	maxGroupMembers      = 50
+	groupPeriodWeekly    = "Weekly"
+	groupPeriodMonthly   = "Monthly"
+	groupRoleOwner       = "Owner"
+	groupRoleAdmin       = "Admin"
+	groupRoleMember      = "Member"
+	groupActivityPayout  = "Payout"
+	groupActivityContrib = "Contribution"
+)
+
+type GroupWalletUsecase interface {
+	CreateGroup(group *model.GroupWallet) error
+	FindGroups(userID string) ([]*model.GroupWallet, error)
+	FindGroup(userID string, groupID int) (*model.GroupWallet, error)
+	AddMember(actorID string, groupID int, phoneNumber string, role string) (*model.GroupMember, error)
+	RemoveMember(actorID string, groupID int, memberID string) error
+	UpdateSchedule(actorID string, groupID int, order []string) (*model.GroupWallet, error)
+	Contribute(user *model.User, groupID int, amount int) (*model.GroupActivity, error)
+	Payout(actorID string, groupID int) (*model.GroupActivity, error)
+	FindActivities(userID string, groupID int) ([]*model.GroupActivity, error)
+}
+
+type groupWalletUsecase struct {
+	groupRepo repository.GroupWalletRepository
+	userRepo  repository.UserRepository
+	holdRepo  repository.HoldRepository
+}
+
+// payoutDate returns the scheduled date of the given rotation round.
+func payoutDate(group *model.GroupWallet, round int) string {
+	start, err := time.Parse("2006-01-02", group.StartDate)
+	if err != nil {
+		return ""
+	}
+	if group.Period == groupPeriodWeekly {
+		return start.AddDate(0, 0, 7*(round-1)).Format("2006-01-02")
+	}
+	return start.AddDate(0, round-1, 0).Format("2006-01-02")
+}
+
+func (u *groupWalletUsecase) requireAdmin(groupID int, actorID string) error {
+	actor, err := u.groupRepo.GetMember(groupID, actorID)
+	if err != nil {
+		return fmt.Errorf("group not found")
+	}
+	if actor.Role != groupRoleOwner && actor.Role != groupRoleAdmin {
+		return fmt.Errorf("only group owner or admin can do this")
+	}
+	return nil
+}
+
+func (u *groupWalletUsecase) CreateGroup(group *model.GroupWallet) error {
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" || len(group.Name) > maxGroupNameLength {
+		return fmt.Errorf("group name must be 1 - 50 characters")
+	}
+	if group.ContributionAmount < minGroupContribution {
+		return fmt.Errorf("minimum contribution is 10,000")
+	}
+	if group.Period != groupPeriodWeekly && group.Period != groupPeriodMonthly {
+		return fmt.Errorf("period must be Weekly or Monthly")
+	}
+	if group.StartDate == "" {
+		group.StartDate = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", group.StartDate); err != nil {
+		return fmt.Errorf("start date must use YYYY-MM-DD format")
+	}
+
+	group.Balance = 0
+	return u.groupRepo.Create(group)
+}
+
+func (u *groupWalletUsecase) FindGroups(userID string) ([]*model.GroupWallet, error) {
+	return u.groupRepo.GetByUserID(userID)
+}
+
+// FindGroup returns the group with its members in payout order, each with
+// the date of their scheduled payout.
+func (u *groupWalletUsecase) FindGroup(userID string, groupID int) (*model.GroupWallet, error) {
+	if _, err := u.groupRepo.GetMember(groupID, userID); err != nil {
+		return nil, fmt.Errorf("group not found")
+	}
+
+	group, err := u.groupRepo.GetByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := u.groupRepo.GetMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		member.PayoutDate = payoutDate(group, member.PayoutOrder)
+	}
+	group.Members = members
+	return group, nil
+}
+
+func (u *groupWalletUsecase) AddMember(actorID string, groupID int, phoneNumber string, role string) (*model.GroupMember, error) {
+	if err := u.requireAdmin(groupID, actorID); err != nil {
+		return nil, err
+	}
+	if role == "" {
+		role = groupRoleMember
+	}
+	if role != groupRoleAdmin && role != groupRoleMember {
+		return nil, fmt.Errorf("role must be Admin or Member")
+	}
+
+	user, err := u.userRepo.GetByPhone(phoneNumber)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if _, err := u.groupRepo.GetMember(groupID, user.ID); err == nil {
+		return nil, fmt.Errorf("user is already a member")
+	}
+
+	members, err := u.groupRepo.GetMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) >= maxGroupMembers {
+		return nil, fmt.Errorf("maximum 50 members per group")
+	}
+
+	// New members join at the end of the rotation
+	member := &model.GroupMember{
+		GroupID:     groupID,
+		UserID:      user.ID,
+		Name:        user.Name,
+		PhoneNumber: user.Phone_Number,
+		Role:        role,
+		PayoutOrder: len(members) + 1,
+	}
+	if err := u.groupRepo.AddMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (u *groupWalletUsecase) RemoveMember(actorID string, groupID int, memberID string) error {
+	if err := u.requireAdmin(groupID, actorID); err != nil {
+		return err
+	}
+	member, err := u.groupRepo.GetMember(groupID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == groupRoleOwner {
+		return fmt.Errorf("group owner cannot be removed")
+	}
+	// Renumbering the rotation mid-way would skip members still waiting
+	group, err := u.groupRepo.GetByID(groupID)
+	if err != nil {
+		return err
+	}
+	if group.CurrentRound > 1 {
+		return fmt.Errorf("members cannot be removed after the first payout")
+	}
+	if err := u.groupRepo.RemoveMember(groupID, memberID); err != nil {
+		return err
+	}
+
+	// Close the gap the member left in the rotation
+	members, err := u.groupRepo.GetMembers(groupID)
+	if err != nil {
+		return err
+	}
+	for i, m := range members {
+		if m.PayoutOrder != i+1 {
+			if err := u.groupRepo.UpdatePayoutOrder(groupID, m.UserID, i+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateSchedule sets the payout rotation; order must list every member
+// exactly once.
+func (u *groupWalletUsecase) UpdateSchedule(actorID string, groupID int, order []string) (*model.GroupWallet, error) {
+	if err := u.requireAdmin(groupID, actorID); err != nil {
+		return nil, err
+	}
+	group, err := u.groupRepo.GetByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.CurrentRound > 1 {
+		return nil, fmt.Errorf("schedule cannot be changed after the first payout")
+	}
+
+	members, err := u.groupRepo.GetMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) != len(members) {
+		return nil, fmt.Errorf("schedule must list every member once")
+	}
+	listed := make(map[string]bool)
+	for _, member := range members {
+		listed[member.UserID] = false
+	}
+	for _, userID := range order {
+		seen, ok := listed[userID]
+		if !ok || seen {
+			return nil, fmt.Errorf("schedule must list every member once")
+		}
+		listed[userID] = true
+	}
+
+	for i, userID := range order {
+		if err := u.groupRepo.UpdatePayoutOrder(groupID, userID, i+1); err != nil {
+			return nil, err
+		}
+	}
+	return u.FindGroup(actorID, groupID)
+}
+
+func (u *groupWalletUsecase) Contribute(user *model.User, groupID int, amount int) (*model.GroupActivity, error) {
+	if _, err := u.groupRepo.GetMember(groupID, user.ID); err != nil {
+		return nil, fmt.Errorf("group not found")
+	}
+	group, err := u.groupRepo.GetByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if amount == 0 {
+		amount = group.ContributionAmount
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	available, err := availableBalance(u.holdRepo, user)
+	if err != nil {
+		return nil, err
+	}
+	if available < amount {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+
+	err = u.userRepo.UpdateBalance(user.ID, user.Balance-amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user balance: %v", err)
+	}
+
+	activity := &model.GroupActivity{
+		GroupID:  groupID,
+		UserID:   user.ID,
+		UserName: user.Name,
+		Activity: groupActivityContrib,
+		Amount:   amount,
+		Round:    group.CurrentRound,
+	}
+	if err := u.groupRepo.Contribute(activity); err != nil {
+		return nil, fmt.Errorf("failed to record contribution: %v", err)
+	}
+	return activity, nil
+}
+
+// Payout pays the whole pot to the member whose turn it is in the rotation.
+func (u *groupWalletUsecase) Payout(actorID string, groupID int) (*model.GroupActivity, error) {
+	if err := u.requireAdmin(groupID, actorID); err != nil {
+		return nil, err
+	}
+	group, err := u.groupRepo.GetByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := u.groupRepo.GetMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.CurrentRound > len(members) {
+		return nil, fmt.Errorf("all payouts completed")
+	}
+	if group.Balance <= 0 {
+		return nil, fmt.Errorf("group balance is empty")
+	}
+
+	var recipient *model.GroupMember
+	for _, member := range members {
+		if member.PayoutOrder == group.CurrentRound {
+			recipient = member
+			break
+		}
+	}
+	if recipient == nil {
+		return nil, fmt.Errorf("no member scheduled for this round")
+	}
+
+	activity := &model.GroupActivity{
+		GroupID:  groupID,
+		UserID:   recipient.UserID,
+		UserName: recipient.Name,
+		Activity: groupActivityPayout,
+		Amount:   group.Balance,
+		Round:    group.CurrentRound,
+	}
+	if err := u.groupRepo.Payout(activity); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetByiD(recipient.UserID)
+	if err != nil {
+		return nil, err
+	}
+	err = u.userRepo.UpdateBalance(user.ID, user.Balance+activity.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user balance: %v", err)
+	}
+	return activity, nil
+}
+
+func (u *groupWalletUsecase) FindActivities(userID string, groupID int) ([]*model.GroupActivity, error) {
+	if _, err := u.groupRepo.GetMember(groupID, userID); err != nil {
+		return nil, fmt.Errorf("group not found")
+	}
+	return u.groupRepo.GetActivities(groupID)
+}
+
+func NewGroupWalletUsecase(groupRepo repository.GroupWalletRepository, userRepo repository.UserRepository, holdRepo repository.HoldRepository) GroupWalletUsecase {
+	return &groupWalletUsecase{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+		holdRepo:  holdRepo,
+	}
+}
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type groupWalletRepoMock struct {
+	mock.Mock
+}
+
+func (r *groupWalletRepoMock) Create(group *model.GroupWallet) error {
+	args := r.Called(group)
+	return args.Error(0)
+}
+
+func (r *groupWalletRepoMock) GetByID(groupID int) (*model.GroupWallet, error) {
+	args := r.Called(groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.GroupWallet), args.Error(1)
+}
+
+func (r *groupWalletRepoMock) GetByUserID(userID string) ([]*model.GroupWallet, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GroupWallet), args.Error(1)
+}
+
+func (r *groupWalletRepoMock) GetMembers(groupID int) ([]*model.GroupMember, error) {
+	args := r.Called(groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GroupMember), args.Error(1)
+}
+
+func (r *groupWalletRepoMock) GetMember(groupID int, userID string) (*model.GroupMember, error) {
+	args := r.Called(groupID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.GroupMember), args.Error(1)
+}
+
+func (r *groupWalletRepoMock) AddMember(member *model.GroupMember) error {
+	args := r.Called(member)
+	return args.Error(0)
+}
+
+func (r *groupWalletRepoMock) RemoveMember(groupID int, userID string) error {
+	args := r.Called(groupID, userID)
+	return args.Error(0)
+}
+
+func (r *groupWalletRepoMock) UpdatePayoutOrder(groupID int, userID string, order int) error {
+	args := r.Called(groupID, userID, order)
+	return args.Error(0)
+}
+
+func (r *groupWalletRepoMock) Contribute(activity *model.GroupActivity) error {
+	args := r.Called(activity)
+	return args.Error(0)
+}
+
+func (r *groupWalletRepoMock) Payout(activity *model.GroupActivity) error {
+	args := r.Called(activity)
+	return args.Error(0)
+}
+
+func (r *groupWalletRepoMock) GetActivities(groupID int) ([]*model.GroupActivity, error) {
+	args := r.Called(groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GroupActivity), args.Error(1)
+}
+
+var dummyGroup = model.GroupWallet{
+	GroupID:            1,
+	Name:               "Arisan RT",
+	OwnerID:            "2",
+	Balance:            0,
+	ContributionAmount: 100000,
+	Period:             "Monthly",
+	StartDate:          "2026-01-10",
+	CurrentRound:       1,
+}
+
+var dummyGroupMembers = []*model.GroupMember{
+	{GroupID: 1, UserID: "2", Name: "sender", Role: "Owner", PayoutOrder: 1},
+	{GroupID: 1, UserID: "1", Name: "name1", Role: "Member", PayoutOrder: 2},
+}
+
+type GroupWalletUsecaseTestSuite struct {
+	groupRepoMock *groupWalletRepoMock
+	userRepoMock  *userRepoMock
+	holdRepoMock  *holdRepoMock
+	suite.Suite
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestCreateGroup_Success() {
+	group := &model.GroupWallet{Name: "Arisan RT", OwnerID: "2", ContributionAmount: 100000, Period: "Weekly"}
+	suite.groupRepoMock.On("Create", group).Return(nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.CreateGroup(group)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), date, group.StartDate)
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestCreateGroup_InvalidPeriod() {
+	group := &model.GroupWallet{Name: "Arisan RT", OwnerID: "2", ContributionAmount: 100000, Period: "Daily"}
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.CreateGroup(group)
+
+	assert.EqualError(suite.T(), err, "period must be Weekly or Monthly")
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestFindGroup_Schedule() {
+	group := dummyGroup
+	members := []*model.GroupMember{{UserID: "2", PayoutOrder: 1}, {UserID: "1", PayoutOrder: 2}}
+	suite.groupRepoMock.On("GetMember", 1, "2").Return(dummyGroupMembers[0], nil)
+	suite.groupRepoMock.On("GetByID", 1).Return(&group, nil)
+	suite.groupRepoMock.On("GetMembers", 1).Return(members, nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.FindGroup("2", 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "2026-01-10", res.Members[0].PayoutDate)
+	assert.Equal(suite.T(), "2026-02-10", res.Members[1].PayoutDate)
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestFindGroup_NotMember() {
+	suite.groupRepoMock.On("GetMember", 1, "3").Return(nil, errors.New("member not found"))
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.FindGroup("3", 1)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "group not found")
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestAddMember_Success() {
+	suite.groupRepoMock.On("GetMember", 1, "2").Return(dummyGroupMembers[0], nil)
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.groupRepoMock.On("GetMember", 1, "1").Return(nil, errors.New("member not found"))
+	suite.groupRepoMock.On("GetMembers", 1).Return(dummyGroupMembers[:1], nil)
+	suite.groupRepoMock.On("AddMember", mock.AnythingOfType("*model.GroupMember")).Return(nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.AddMember("2", 1, "08111111", "")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Member", res.Role)
+	assert.Equal(suite.T(), 2, res.PayoutOrder)
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestAddMember_NotAdmin() {
+	suite.groupRepoMock.On("GetMember", 1, "1").Return(dummyGroupMembers[1], nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.AddMember("1", 1, "08222222222", "")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "only group owner or admin can do this")
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestRemoveMember_AfterFirstPayout() {
+	group := dummyGroup
+	group.CurrentRound = 3
+	suite.groupRepoMock.On("GetMember", 1, "2").Return(dummyGroupMembers[0], nil)
+	suite.groupRepoMock.On("GetMember", 1, "1").Return(dummyGroupMembers[1], nil)
+	suite.groupRepoMock.On("GetByID", 1).Return(&group, nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.RemoveMember("2", 1, "1")
+
+	assert.EqualError(suite.T(), err, "members cannot be removed after the first payout")
+	suite.groupRepoMock.AssertNotCalled(suite.T(), "RemoveMember", mock.Anything, mock.Anything)
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestUpdateSchedule_MissingMember() {
+	group := dummyGroup
+	suite.groupRepoMock.On("GetMember", 1, "2").Return(dummyGroupMembers[0], nil)
+	suite.groupRepoMock.On("GetByID", 1).Return(&group, nil)
+	suite.groupRepoMock.On("GetMembers", 1).Return(dummyGroupMembers, nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.UpdateSchedule("2", 1, []string{"1", "1"})
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "schedule must list every member once")
+	suite.groupRepoMock.AssertNotCalled(suite.T(), "UpdatePayoutOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestContribute_Success() {
+	user := dummySender
+	group := dummyGroup
+	suite.groupRepoMock.On("GetMember", 1, user.ID).Return(dummyGroupMembers[0], nil)
+	suite.groupRepoMock.On("GetByID", 1).Return(&group, nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, 0).Return(nil)
+	suite.groupRepoMock.On("Contribute", mock.AnythingOfType("*model.GroupActivity")).Return(nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.Contribute(&user, 1, 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 100000, res.Amount)
+	assert.Equal(suite.T(), "Contribution", res.Activity)
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestContribute_InsufficientBalance() {
+	user := dummySender
+	user.Balance = 5000
+	group := dummyGroup
+	suite.groupRepoMock.On("GetMember", 1, user.ID).Return(dummyGroupMembers[0], nil)
+	suite.groupRepoMock.On("GetByID", 1).Return(&group, nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.Contribute(&user, 1, 0)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestPayout_Success() {
+	group := dummyGroup
+	group.Balance = 200000
+	group.CurrentRound = 2
+	recipient := model.User{ID: "1", Balance: 1000}
+	suite.groupRepoMock.On("GetMember", 1, "2").Return(dummyGroupMembers[0], nil)
+	suite.groupRepoMock.On("GetByID", 1).Return(&group, nil)
+	suite.groupRepoMock.On("GetMembers", 1).Return(dummyGroupMembers, nil)
+	suite.groupRepoMock.On("Payout", mock.AnythingOfType("*model.GroupActivity")).Return(nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&recipient, nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 201000).Return(nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.Payout("2", 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", res.UserID)
+	assert.Equal(suite.T(), 200000, res.Amount)
+	assert.Equal(suite.T(), 2, res.Round)
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestPayout_AllCompleted() {
+	group := dummyGroup
+	group.Balance = 200000
+	group.CurrentRound = 3
+	suite.groupRepoMock.On("GetMember", 1, "2").Return(dummyGroupMembers[0], nil)
+	suite.groupRepoMock.On("GetByID", 1).Return(&group, nil)
+	suite.groupRepoMock.On("GetMembers", 1).Return(dummyGroupMembers, nil)
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.Payout("2", 1)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "all payouts completed")
+}
+
+func (suite *GroupWalletUsecaseTestSuite) TestPayout_AlreadyPaid() {
+	group := dummyGroup
+	group.Balance = 200000
+	suite.groupRepoMock.On("GetMember", 1, "2").Return(dummyGroupMembers[0], nil)
+	suite.groupRepoMock.On("GetByID", 1).Return(&group, nil)
+	suite.groupRepoMock.On("GetMembers", 1).Return(dummyGroupMembers, nil)
+	suite.groupRepoMock.On("Payout", mock.AnythingOfType("*model.GroupActivity")).Return(errors.New("round already paid out"))
+
+	uc := NewGroupWalletUsecase(suite.groupRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.Payout("2", 1)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "round already paid out")
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", mock.Anything, mock.Anything)
+}
+
+func (suite *GroupWalletUsecaseTestSuite) SetupTest() {
+	suite.groupRepoMock = new(groupWalletRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.holdRepoMock.On("GetHeldAmount", mock.Anything).Return(0, nil)
+}
+
+func TestGroupWalletUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(GroupWalletUsecaseTestSuite))
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	defaultHoldMinutes = 24 * 60
+	maxHoldMinutes     = 30 * 24 * 60
+	maxHoldReference   = 50
+	maxHoldDescription = 100
+)
+
+type HoldUsecase interface {
+	FindBalance(userID string) (*model.WalletBalance, error)
+	FindHolds(userID string) ([]*model.Hold, error)
+	FindHold(holdID int, userID string) (*model.Hold, error)
+	CreateHold(user *model.User, hold *model.Hold) error
+	Capture(user *model.User, holdID int, amount int) (*model.Hold, error)
+	Release(holdID int, userID string) error
+	ExpireHolds() (int, error)
+}
+
+type holdUsecase struct {
+	holdRepo repository.HoldRepository
+	userRepo repository.UserRepository
+}
+
+// availableBalance is the part of the balance not reserved by active holds.
+// Every debit checks against it so held funds cannot be spent.
+func availableBalance(holdRepo repository.HoldRepository, user *model.User) (int, error) {
+	held, err := holdRepo.GetHeldAmount(user.ID)
+	if err != nil {
+		return 0, err
+	}
+	return user.Balance - held, nil
+}
+
+func (u *holdUsecase) FindBalance(userID string) (*model.WalletBalance, error) {
+	user, err := u.userRepo.GetByiD(userID)
+	if err != nil {
+		return nil, err
+	}
+	available, err := availableBalance(u.holdRepo, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.WalletBalance{
+		UserID:           userID,
+		LedgerBalance:    user.Balance,
+		HeldAmount:       user.Balance - available,
+		AvailableBalance: available,
+	}, nil
+}
+
+func (u *holdUsecase) FindHolds(userID string) ([]*model.Hold, error) {
+	return u.holdRepo.GetByUserID(userID)
+}
+
+func (u *holdUsecase) FindHold(holdID int, userID string) (*model.Hold, error) {
+	return u.holdRepo.GetByID(holdID, userID)
+}
+
+func (u *holdUsecase) CreateHold(user *model.User, hold *model.Hold) error {
+	hold.Reference = strings.TrimSpace(hold.Reference)
+	if hold.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if len(hold.Reference) > maxHoldReference {
+		return fmt.Errorf("reference must be at most 50 characters")
+	}
+	if len(hold.Description) > maxHoldDescription {
+		return fmt.Errorf("description must be at most 100 characters")
+	}
+	if hold.ExpiresInMinutes == 0 {
+		hold.ExpiresInMinutes = defaultHoldMinutes
+	}
+	if hold.ExpiresInMinutes < 1 || hold.ExpiresInMinutes > maxHoldMinutes {
+		return fmt.Errorf("expiry must be 1 minute - 30 days")
+	}
+
+	available, err := availableBalance(u.holdRepo, user)
+	if err != nil {
+		return err
+	}
+	if available < hold.Amount {
+		return fmt.Errorf("insufficient balance")
+	}
+
+	now := time.Now()
+	hold.UserID = user.ID
+	hold.Status = "Active"
+	hold.CreatedAt = now
+	hold.ExpiredAt = now.Add(time.Duration(hold.ExpiresInMinutes) * time.Minute)
+	return u.holdRepo.Create(hold)
+}
+
+// Capture debits the wallet for a held amount. An amount of 0 captures the
+// whole hold; a smaller amount releases the rest.
+func (u *holdUsecase) Capture(user *model.User, holdID int, amount int) (*model.Hold, error) {
+	hold, err := u.holdRepo.GetByID(holdID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != "Active" || !time.Now().Before(hold.ExpiredAt) {
+		return nil, fmt.Errorf("hold is no longer active")
+	}
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	if amount > hold.Amount {
+		return nil, fmt.Errorf("capture amount exceeds held amount")
+	}
+
+	if err := u.holdRepo.Capture(hold, amount); err != nil {
+		return nil, err
+	}
+	if err := u.userRepo.UpdateBalance(user.ID, user.Balance-amount); err != nil {
+		return nil, fmt.Errorf("failed to update user balance: %v", err)
+	}
+	return hold, nil
+}
+
+func (u *holdUsecase) Release(holdID int, userID string) error {
+	if _, err := u.holdRepo.GetByID(holdID, userID); err != nil {
+		return err
+	}
+	return u.holdRepo.Release(holdID, userID)
+}
+
+func (u *holdUsecase) ExpireHolds() (int, error) {
+	return u.holdRepo.ExpireAll(time.Now())
+}
+
+func NewHoldUsecase(holdRepo repository.HoldRepository, userRepo repository.UserRepository) HoldUsecase {
+	return &holdUsecase{
+		holdRepo: holdRepo,
+		userRepo: userRepo,
+	}
+}
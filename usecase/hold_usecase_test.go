@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type holdRepoMock struct {
+	mock.Mock
+}
+
+func (r *holdRepoMock) Create(hold *model.Hold) error {
+	args := r.Called(hold)
+	return args.Error(0)
+}
+
+func (r *holdRepoMock) GetByID(holdID int, userID string) (*model.Hold, error) {
+	args := r.Called(holdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Hold), args.Error(1)
+}
+
+func (r *holdRepoMock) GetByUserID(userID string) ([]*model.Hold, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Hold), args.Error(1)
+}
+
+func (r *holdRepoMock) GetHeldAmount(userID string) (int, error) {
+	args := r.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *holdRepoMock) Capture(hold *model.Hold, amount int) error {
+	args := r.Called(hold, amount)
+	return args.Error(0)
+}
+
+func (r *holdRepoMock) Release(holdID int, userID string) error {
+	args := r.Called(holdID, userID)
+	return args.Error(0)
+}
+
+func (r *holdRepoMock) ExpireAll(now time.Time) (int, error) {
+	args := r.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+type HoldUsecaseTestSuite struct {
+	holdRepoMock *holdRepoMock
+	userRepoMock *userRepoMock
+	suite.Suite
+}
+
+func (suite *HoldUsecaseTestSuite) activeHold() *model.Hold {
+	return &model.Hold{HoldID: 1, UserID: dummySender.ID, Amount: 30000, Status: "Active", ExpiredAt: time.Now().Add(time.Hour)}
+}
+
+func (suite *HoldUsecaseTestSuite) TestFindBalance_Success() {
+	user := dummySender
+	suite.userRepoMock.On("GetByiD", user.ID).Return(&user, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(30000, nil)
+
+	res, err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).FindBalance(user.ID)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 100000, res.LedgerBalance)
+	assert.Equal(suite.T(), 30000, res.HeldAmount)
+	assert.Equal(suite.T(), 70000, res.AvailableBalance)
+}
+
+func (suite *HoldUsecaseTestSuite) TestCreateHold_Success() {
+	user := dummySender
+	hold := &model.Hold{Amount: 50000, Reference: " ORDER-1 "}
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(30000, nil)
+	suite.holdRepoMock.On("Create", hold).Return(nil)
+
+	err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).CreateHold(&user, hold)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "ORDER-1", hold.Reference)
+	assert.Equal(suite.T(), "Active", hold.Status)
+	assert.Equal(suite.T(), defaultHoldMinutes, hold.ExpiresInMinutes)
+	assert.True(suite.T(), hold.ExpiredAt.After(hold.CreatedAt))
+}
+
+func (suite *HoldUsecaseTestSuite) TestCreateHold_InsufficientAvailableBalance() {
+	user := dummySender
+	hold := &model.Hold{Amount: 80000}
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(30000, nil)
+
+	err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).CreateHold(&user, hold)
+
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.holdRepoMock.AssertNotCalled(suite.T(), "Create", hold)
+}
+
+func (suite *HoldUsecaseTestSuite) TestCreateHold_InvalidExpiry() {
+	user := dummySender
+	hold := &model.Hold{Amount: 1000, ExpiresInMinutes: maxHoldMinutes + 1}
+
+	err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).CreateHold(&user, hold)
+
+	assert.EqualError(suite.T(), err, "expiry must be 1 minute - 30 days")
+}
+
+func (suite *HoldUsecaseTestSuite) TestCapture_Full() {
+	user := dummySender
+	hold := suite.activeHold()
+	suite.holdRepoMock.On("GetByID", 1, user.ID).Return(hold, nil)
+	suite.holdRepoMock.On("Capture", hold, 30000).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, 70000).Return(nil)
+
+	res, err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).Capture(&user, 1, 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), hold, res)
+}
+
+func (suite *HoldUsecaseTestSuite) TestCapture_Partial() {
+	user := dummySender
+	hold := suite.activeHold()
+	suite.holdRepoMock.On("GetByID", 1, user.ID).Return(hold, nil)
+	suite.holdRepoMock.On("Capture", hold, 12000).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, 88000).Return(nil)
+
+	_, err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).Capture(&user, 1, 12000)
+
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *HoldUsecaseTestSuite) TestCapture_ExceedsHold() {
+	user := dummySender
+	suite.holdRepoMock.On("GetByID", 1, user.ID).Return(suite.activeHold(), nil)
+
+	res, err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).Capture(&user, 1, 30001)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "capture amount exceeds held amount")
+}
+
+func (suite *HoldUsecaseTestSuite) TestCapture_Expired() {
+	user := dummySender
+	hold := suite.activeHold()
+	hold.ExpiredAt = time.Now().Add(-time.Minute)
+	suite.holdRepoMock.On("GetByID", 1, user.ID).Return(hold, nil)
+
+	res, err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).Capture(&user, 1, 0)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "hold is no longer active")
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", mock.Anything, mock.Anything)
+}
+
+func (suite *HoldUsecaseTestSuite) TestRelease_NotFound() {
+	suite.holdRepoMock.On("GetByID", 9, "2").Return(nil, errors.New("hold not found"))
+
+	err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).Release(9, "2")
+
+	assert.EqualError(suite.T(), err, "hold not found")
+	suite.holdRepoMock.AssertNotCalled(suite.T(), "Release", 9, "2")
+}
+
+func (suite *HoldUsecaseTestSuite) TestExpireHolds_Success() {
+	suite.holdRepoMock.On("ExpireAll", mock.AnythingOfType("time.Time")).Return(3, nil)
+
+	expired, err := NewHoldUsecase(suite.holdRepoMock, suite.userRepoMock).ExpireHolds()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, expired)
+}
+
+func (suite *HoldUsecaseTestSuite) SetupTest() {
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+}
+
+func TestHoldUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(HoldUsecaseTestSuite))
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	interbankFee             = 6500
+	minInterbankAmount       = 20000
+	maxInterbankAmount       = 25000000
+	dailyInterbankLimit      = 50000000
+	interbankInquiryLifetime = 5 * time.Minute
+)
+
+type InterbankUsecase interface {
+	Inquiry(user *model.User, bankCode string, accountNumber string, amount int) (*model.InterbankInquiry, error)
+	Transfer(user *model.User, token string) (*model.InterbankTransfer, error)
+}
+
+type interbankUsecase struct {
+	interbankRepo repository.InterbankRepository
+	userRepo      repository.UserRepository
+	holdRepo      repository.HoldRepository
+	badgeRepo     repository.BadgeRepository
+	provider      model.BankInquiryProvider
+}
+
+// dailyLimit is the interbank amount a user may send per day, which higher
+// badges may raise.
+func dailyLimit(badge *model.Badge) int {
+	if badge.DailyInterbankLimit > dailyInterbankLimit {
+		return badge.DailyInterbankLimit
+	}
+	return dailyInterbankLimit
+}
+
+// checkLimits applies the per-transfer limits and the daily limit.
+func (u *interbankUsecase) checkLimits(userID string, badge *model.Badge, amount int) error {
+	if amount < minInterbankAmount {
+		return fmt.Errorf("minimum interbank transfer is 20,000")
+	}
+	if amount > maxInterbankAmount {
+		return fmt.Errorf("maximum interbank transfer is 25,000,000")
+	}
+
+	total, err := u.interbankRepo.GetDailyTotal(userID)
+	if err != nil {
+		return err
+	}
+	if total+amount > dailyLimit(badge) {
+		return fmt.Errorf("daily interbank limit exceeded")
+	}
+	return nil
+}
+
+func (u *interbankUsecase) Inquiry(user *model.User, bankCode string, accountNumber string, amount int) (*model.InterbankInquiry, error) {
+	badge := badgeBenefits(u.badgeRepo, user.BadgeID)
+	if err := u.checkLimits(user.ID, badge, amount); err != nil {
+		return nil, err
+	}
+	fee := discountedFee(interbankFee, badge)
+	available, err := availableBalance(u.holdRepo, user)
+	if err != nil {
+		return nil, err
+	}
+	if available < amount+fee {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+
+	account, err := u.provider.InquiryAccount(bankCode, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	inquiry := &model.InterbankInquiry{
+		InquiryToken:      uuid.New().String(),
+		UserID:            user.ID,
+		BankCode:          bankCode,
+		BankName:          account.BankName,
+		AccountNumber:     accountNumber,
+		AccountHolderName: account.AccountHolderName,
+		Amount:            amount,
+		Fee:               fee,
+		TotalAmount:       amount + fee,
+		Status:            "Pending",
+		ExpiredAt:         time.Now().Add(interbankInquiryLifetime),
+	}
+	if err := u.interbankRepo.CreateInquiry(inquiry); err != nil {
+		return nil, err
+	}
+	return inquiry, nil
+}
+
+func (u *interbankUsecase) Transfer(user *model.User, token string) (*model.InterbankTransfer, error) {
+	inquiry, err := u.interbankRepo.GetInquiryByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if inquiry.UserID != user.ID {
+		return nil, fmt.Errorf("inquiry not found")
+	}
+
+	// Limits and balance may have changed since the inquiry was made
+	badge := badgeBenefits(u.badgeRepo, user.BadgeID)
+	if err := u.checkLimits(user.ID, badge, inquiry.Amount); err != nil {
+		return nil, err
+	}
+	available, err := availableBalance(u.holdRepo, user)
+	if err != nil {
+		return nil, err
+	}
+	if available < inquiry.TotalAmount {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+
+	if err := u.interbankRepo.ReserveDailyAmount(user.ID, inquiry.Amount, dailyLimit(badge)); err != nil {
+		return nil, err
+	}
+	if err := u.interbankRepo.ClaimInquiry(token, user.ID); err != nil {
+		return nil, u.releaseDailyAmount(user.ID, inquiry.Amount, err)
+	}
+
+	err = u.userRepo.UpdateBalance(user.ID, user.Balance-inquiry.TotalAmount)
+	if err != nil {
+		return nil, u.releaseDailyAmount(user.ID, inquiry.Amount, fmt.Errorf("failed to update user balance: %v", err))
+	}
+
+	transfer := &model.InterbankTransfer{
+		UserID:            user.ID,
+		InquiryToken:      token,
+		BankCode:          inquiry.BankCode,
+		BankName:          inquiry.BankName,
+		AccountNumber:     inquiry.AccountNumber,
+		AccountHolderName: inquiry.AccountHolderName,
+		Amount:            inquiry.Amount,
+		Fee:               inquiry.Fee,
+	}
+	if err := u.interbankRepo.Create(transfer); err != nil {
+		return nil, fmt.Errorf("failed to create interbank transaction: %v", err)
+	}
+	return transfer, nil
+}
+
+// releaseDailyAmount gives the reserved amount back after the transfer failed
+// with err.
+func (u *interbankUsecase) releaseDailyAmount(userID string, amount int, err error) error {
+	if releaseErr := u.interbankRepo.ReleaseDailyAmount(userID, amount); releaseErr != nil {
+		return fmt.Errorf("%v; %v", err, releaseErr)
+	}
+	return err
+}
+
+func NewInterbankUsecase(interbankRepo repository.InterbankRepository, userRepo repository.UserRepository, holdRepo repository.HoldRepository, badgeRepo repository.BadgeRepository, provider model.BankInquiryProvider) InterbankUsecase {
+	return &interbankUsecase{
+		interbankRepo: interbankRepo,
+		userRepo:      userRepo,
+		holdRepo:      holdRepo,
+		badgeRepo:     badgeRepo,
+		provider:      provider,
+	}
+}
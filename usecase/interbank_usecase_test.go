@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type interbankRepoMock struct {
+	mock.Mock
+}
+
+func (r *interbankRepoMock) CreateInquiry(inquiry *model.InterbankInquiry) error {
+	args := r.Called(inquiry)
+	return args.Error(0)
+}
+
+func (r *interbankRepoMock) GetInquiryByToken(token string) (*model.InterbankInquiry, error) {
+	args := r.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.InterbankInquiry), args.Error(1)
+}
+
+func (r *interbankRepoMock) ClaimInquiry(token string, userID string) error {
+	args := r.Called(token, userID)
+	return args.Error(0)
+}
+
+func (r *interbankRepoMock) Create(tx *model.InterbankTransfer) error {
+	args := r.Called(tx)
+	return args.Error(0)
+}
+
+func (r *interbankRepoMock) GetDailyTotal(userID string) (int, error) {
+	args := r.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *interbankRepoMock) ReserveDailyAmount(userID string, amount int, limit int) error {
+	args := r.Called(userID, amount, limit)
+	return args.Error(0)
+}
+
+func (r *interbankRepoMock) ReleaseDailyAmount(userID string, amount int) error {
+	args := r.Called(userID, amount)
+	return args.Error(0)
+}
+
+// fakeBankInquiryProvider answers account inquiries from a fixed set of accounts.
+type fakeBankInquiryProvider struct {
+	accounts map[string]model.BankAccountInquiry
+}
+
+func (p *fakeBankInquiryProvider) InquiryAccount(bankCode, accountNumber string) (*model.BankAccountInquiry, error) {
+	account, ok := p.accounts[bankCode+"/"+accountNumber]
+	if !ok {
+		return nil, errors.New("bank account not found")
+	}
+	return &account, nil
+}
+
+type InterbankUsecaseTestSuite struct {
+	interbankRepoMock *interbankRepoMock
+	userRepoMock      *userRepoMock
+	holdRepoMock      *holdRepoMock
+	badgeRepoMock     *badgeRepoMock
+	provider          *fakeBankInquiryProvider
+	suite.Suite
+}
+
+func (suite *InterbankUsecaseTestSuite) TestInquiry_Success() {
+	user := dummySender
+	suite.interbankRepoMock.On("GetDailyTotal", user.ID).Return(0, nil)
+	suite.interbankRepoMock.On("CreateInquiry", mock.AnythingOfType("*model.InterbankInquiry")).Return(nil)
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Inquiry(&user, "014", "1234567890", 50000)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "BCA", res.BankName)
+	assert.Equal(suite.T(), "name2", res.AccountHolderName)
+	assert.Equal(suite.T(), 56500, res.TotalAmount)
+	assert.NotEmpty(suite.T(), res.InquiryToken)
+}
+
+func (suite *InterbankUsecaseTestSuite) TestInquiry_AccountNotFound() {
+	user := dummySender
+	suite.interbankRepoMock.On("GetDailyTotal", user.ID).Return(0, nil)
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Inquiry(&user, "014", "000", 50000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "bank account not found")
+}
+
+func (suite *InterbankUsecaseTestSuite) TestInquiry_BelowMinimum() {
+	user := dummySender
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Inquiry(&user, "014", "1234567890", 10000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "minimum interbank transfer is 20,000")
+}
+
+func (suite *InterbankUsecaseTestSuite) TestInquiry_DailyLimitExceeded() {
+	user := dummySender
+	suite.interbankRepoMock.On("GetDailyTotal", user.ID).Return(49990000, nil)
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Inquiry(&user, "014", "1234567890", 50000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "daily interbank limit exceeded")
+}
+
+func (suite *InterbankUsecaseTestSuite) TestInquiry_BadgeRaisesDailyLimit() {
+	user := dummySender
+	user.BadgeID = 5
+	suite.badgeRepoMock = new(badgeRepoMock)
+	suite.badgeRepoMock.On("GetByID", 5).Return(&model.Badge{BadgeID: 5, FeeDiscountPercent: 100, DailyInterbankLimit: 100000000}, nil)
+	suite.interbankRepoMock.On("GetDailyTotal", user.ID).Return(49990000, nil)
+	suite.interbankRepoMock.On("CreateInquiry", mock.AnythingOfType("*model.InterbankInquiry")).Return(nil)
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Inquiry(&user, "014", "1234567890", 50000)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, res.Fee)
+	assert.Equal(suite.T(), 50000, res.TotalAmount)
+}
+
+func (suite *InterbankUsecaseTestSuite) TestInquiry_InsufficientBalance() {
+	user := dummySender
+	user.Balance = 50000
+	suite.interbankRepoMock.On("GetDailyTotal", user.ID).Return(0, nil)
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Inquiry(&user, "014", "1234567890", 50000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+}
+
+func (suite *InterbankUsecaseTestSuite) TestTransfer_Success() {
+	user := dummySender
+	inquiry := &model.InterbankInquiry{InquiryToken: "token-1", UserID: user.ID, BankCode: "014", BankName: "BCA", AccountNumber: "1234567890", AccountHolderName: "name2", Amount: 50000, Fee: 6500, TotalAmount: 56500, Status: "Pending", ExpiredAt: time.Now().Add(time.Minute)}
+	suite.interbankRepoMock.On("GetInquiryByToken", "token-1").Return(inquiry, nil)
+	suite.interbankRepoMock.On("GetDailyTotal", user.ID).Return(0, nil)
+	suite.interbankRepoMock.On("ReserveDailyAmount", user.ID, 50000, 50000000).Return(nil)
+	suite.interbankRepoMock.On("ClaimInquiry", "token-1", user.ID).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, user.Balance-56500).Return(nil)
+	suite.interbankRepoMock.On("Create", mock.AnythingOfType("*model.InterbankTransfer")).Return(nil)
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Transfer(&user, "token-1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 50000, res.Amount)
+	assert.Equal(suite.T(), "1234567890", res.AccountNumber)
+}
+
+func (suite *InterbankUsecaseTestSuite) TestTransfer_OtherUser() {
+	user := dummySender
+	inquiry := &model.InterbankInquiry{InquiryToken: "token-1", UserID: "3"}
+	suite.interbankRepoMock.On("GetInquiryByToken", "token-1").Return(inquiry, nil)
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Transfer(&user, "token-1")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "inquiry not found")
+}
+
+func (suite *InterbankUsecaseTestSuite) TestTransfer_AlreadyUsed() {
+	user := dummySender
+	inquiry := &model.InterbankInquiry{InquiryToken: "token-1", UserID: user.ID, Amount: 50000, Fee: 6500, TotalAmount: 56500}
+	suite.interbankRepoMock.On("GetInquiryByToken", "token-1").Return(inquiry, nil)
+	suite.interbankRepoMock.On("GetDailyTotal", user.ID).Return(0, nil)
+	suite.interbankRepoMock.On("ReserveDailyAmount", user.ID, 50000, 50000000).Return(nil)
+	suite.interbankRepoMock.On("ClaimInquiry", "token-1", user.ID).Return(errors.New("inquiry expired or already used"))
+	suite.interbankRepoMock.On("ReleaseDailyAmount", user.ID, 50000).Return(nil)
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Transfer(&user, "token-1")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "inquiry expired or already used")
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", user.ID, mock.Anything)
+	suite.interbankRepoMock.AssertCalled(suite.T(), "ReleaseDailyAmount", user.ID, 50000)
+}
+
+func (suite *InterbankUsecaseTestSuite) TestTransfer_DailyLimitReached() {
+	user := dummySender
+	inquiry := &model.InterbankInquiry{InquiryToken: "token-1", UserID: user.ID, Amount: 50000, Fee: 6500, TotalAmount: 56500}
+	suite.interbankRepoMock.On("GetInquiryByToken", "token-1").Return(inquiry, nil)
+	suite.interbankRepoMock.On("GetDailyTotal", user.ID).Return(0, nil)
+	suite.interbankRepoMock.On("ReserveDailyAmount", user.ID, 50000, 50000000).Return(errors.New("daily interbank limit exceeded"))
+
+	uc := NewInterbankUsecase(suite.interbankRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.badgeRepoMock, suite.provider)
+	res, err := uc.Transfer(&user, "token-1")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "daily interbank limit exceeded")
+	suite.interbankRepoMock.AssertNotCalled(suite.T(), "ClaimInquiry", "token-1", user.ID)
+}
+
+func (suite *InterbankUsecaseTestSuite) SetupTest() {
+	suite.interbankRepoMock = new(interbankRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.holdRepoMock.On("GetHeldAmount", mock.Anything).Return(0, nil)
+	suite.badgeRepoMock = new(badgeRepoMock)
+	suite.badgeRepoMock.On("GetByID", mock.Anything).Return(nil, errors.New("badge not found"))
+	suite.provider = &fakeBankInquiryProvider{
+		accounts: map[string]model.BankAccountInquiry{
+			"014/1234567890": {BankCode: "014", BankName: "BCA", AccountNumber: "1234567890", AccountHolderName: "name2"},
+		},
+	}
+}
+
+func TestInterbankUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(InterbankUsecaseTestSuite))
+}
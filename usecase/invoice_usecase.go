@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+)
+
+const (
+	maxInvoiceItems           = 50
+	maxInvoiceItemDescription = 100
+	maxInvoiceNotes           = 255
+	maxInvoiceTaxBps          = 5000
+	invoiceIDSize             = 10
+	invoiceReminderInterval   = 72 * time.Hour
+)
+
+type InvoiceUsecase interface {
+	Create(userID string, invoice *model.Invoice) error
+	Update(userID string, invoice *model.Invoice) error
+	FindByMerchant(userID string) ([]*model.Invoice, error)
+	FindReceived(userID string) ([]*model.Invoice, error)
+	FindInvoice(userID string, invoiceID string) (*model.Invoice, error)
+	Send(userID string, invoiceID string) (*model.Invoice, error)
+	Void(userID string, invoiceID string) error
+	Pay(user *model.User, invoiceID string) (*model.Invoice, error)
+	RenderPDF(userID string, invoiceID string) ([]byte, error)
+	RemindOverdue() (int, error)
+}
+
+type invoiceUsecase struct {
+	invoiceRepo     repository.InvoiceRepository
+	merchantRepo    repository.MerchantRepository
+	userRepo        repository.UserRepository
+	merchantUsecase MerchantUsecase
+	notify          func(token string, title string, body string) error
+}
+
+func invoiceRupiah(amount int) string {
+	return "Rp " + strconv.FormatFloat(float64(amount)/1000, 'f', 3, 64)
+}
+
+// prepare validates an invoice, resolves its customer from the phone number
+// and computes the line and total amounts.
+func (u *invoiceUsecase) prepare(merchant *model.Merchant, invoice *model.Invoice) error {
+	if len(invoice.Items) == 0 || len(invoice.Items) > maxInvoiceItems {
+		return fmt.Errorf("invoice must have 1 - 50 items")
+	}
+	invoice.Subtotal = 0
+	for _, item := range invoice.Items {
+		item.Description = strings.TrimSpace(item.Description)
+		if item.Description == "" || len(item.Description) > maxInvoiceItemDescription {
+			return fmt.Errorf("item description must be 1 - 100 characters")
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("item quantity must be greater than 0")
+		}
+		if item.UnitPrice <= 0 {
+			return fmt.Errorf("item unit price must be greater than 0")
+		}
+		item.Amount = item.Quantity * item.UnitPrice
+		invoice.Subtotal += item.Amount
+	}
+	if invoice.TaxBps < 0 || invoice.TaxBps > maxInvoiceTaxBps {
+		return fmt.Errorf("tax must be 0 - 5000 bps")
+	}
+	invoice.Notes = strings.TrimSpace(invoice.Notes)
+	if len(invoice.Notes) > maxInvoiceNotes {
+		return fmt.Errorf("notes must be at most 255 characters")
+	}
+	dueDate, err := time.Parse("2006-01-02", invoice.DueDate)
+	if err != nil {
+		return fmt.Errorf("due date must use YYYY-MM-DD format")
+	}
+	if dueDate.Format("2006-01-02") < time.Now().Format("2006-01-02") {
+		return fmt.Errorf("due date must not be in the past")
+	}
+
+	customer, err := u.userRepo.GetByPhone(strings.TrimSpace(invoice.PhoneNumber))
+	if err != nil {
+		return fmt.Errorf("customer not found")
+	}
+	if customer.ID == merchant.UserID {
+		return fmt.Errorf("cannot invoice your own account")
+	}
+
+	invoice.MerchantID = merchant.MerchantID
+	invoice.MerchantName = merchant.BusinessName
+	invoice.MerchantUserID = merchant.UserID
+	invoice.CustomerID = customer.ID
+	invoice.CustomerName = customer.Name
+	invoice.PhoneNumber = customer.Phone_Number
+	invoice.TaxAmount = (invoice.Subtotal*invoice.TaxBps + 5000) / 10000
+	invoice.Total = invoice.Subtotal + invoice.TaxAmount
+	return nil
+}
+
+// merchantInvoice loads an invoice that belongs to the merchant of userID.
+func (u *invoiceUsecase) merchantInvoice(userID string, invoiceID string) (*model.Merchant, *model.Invoice, error) {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	invoice, err := u.invoiceRepo.GetByID(strings.ToUpper(invoiceID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if invoice.MerchantID != merchant.MerchantID {
+		return nil, nil, fmt.Errorf("invoice not found")
+	}
+	return merchant, invoice, nil
+}
+
+func (u *invoiceUsecase) Create(userID string, invoice *model.Invoice) error {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if err := u.prepare(merchant, invoice); err != nil {
+		return err
+	}
+
+	code, err := randomCode(envelopeClaimCodeChars, invoiceIDSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate invoice id: %v", err)
+	}
+	invoice.InvoiceID = "INV" + code
+	invoice.Status = "Draft"
+	invoice.CreatedAt = time.Now()
+	return u.invoiceRepo.Create(invoice)
+}
+
+// Update replaces the customer, items and terms of a draft invoice.
+func (u *invoiceUsecase) Update(userID string, invoice *model.Invoice) error {
+	merchant, existing, err := u.merchantInvoice(userID, invoice.InvoiceID)
+	if err != nil {
+		return err
+	}
+	if existing.Status != "Draft" {
+		return fmt.Errorf("invoice is no longer a draft")
+	}
+	if err := u.prepare(merchant, invoice); err != nil {
+		return err
+	}
+
+	invoice.InvoiceID = existing.InvoiceID
+	invoice.Status = existing.Status
+	invoice.CreatedAt = existing.CreatedAt
+	return u.invoiceRepo.Update(invoice)
+}
+
+func (u *invoiceUsecase) FindByMerchant(userID string) ([]*model.Invoice, error) {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.invoiceRepo.GetByMerchant(merchant.MerchantID)
+}
+
+func (u *invoiceUsecase) FindReceived(userID string) ([]*model.Invoice, error) {
+	return u.invoiceRepo.GetByCustomer(userID)
+}
+
+// FindInvoice returns an invoice to its merchant or, once sent, its customer.
+func (u *invoiceUsecase) FindInvoice(userID string, invoiceID string) (*model.Invoice, error) {
+	invoice, err := u.invoiceRepo.GetByID(strings.ToUpper(invoiceID))
+	if err != nil {
+		return nil, err
+	}
+	if invoice.MerchantUserID == userID {
+		return invoice, nil
+	}
+	if invoice.CustomerID == userID && invoice.Status != "Draft" {
+		return invoice, nil
+	}
+	return nil, fmt.Errorf("invoice not found")
+}
+
+func (u *invoiceUsecase) Send(userID string, invoiceID string) (*model.Invoice, error) {
+	merchant, invoice, err := u.merchantInvoice(userID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := u.invoiceRepo.Send(invoice.InvoiceID, merchant.MerchantID, now); err != nil {
+		return nil, err
+	}
+	invoice.Status = "Sent"
+	invoice.SentAt = &now
+	return invoice, nil
+}
+
+func (u *invoiceUsecase) Void(userID string, invoiceID string) error {
+	merchant, invoice, err := u.merchantInvoice(userID, invoiceID)
+	if err != nil {
+		return err
+	}
+	return u.invoiceRepo.Void(invoice.InvoiceID, merchant.MerchantID)
+}
+
+// Pay settles an invoice from the customer's wallet through the regular
+// merchant payment flow, so MDR and settlement apply as for a QR payment.
+func (u *invoiceUsecase) Pay(user *model.User, invoiceID string) (*model.Invoice, error) {
+	invoice, err := u.invoiceRepo.GetByID(strings.ToUpper(invoiceID))
+	if err != nil {
+		return nil, err
+	}
+	if invoice.CustomerID != user.ID || invoice.Status == "Draft" {
+		return nil, fmt.Errorf("invoice not found")
+	}
+	if invoice.Status != "Sent" && invoice.Status != "Overdue" {
+		return nil, fmt.Errorf("invoice is not payable")
+	}
+	if err := u.invoiceRepo.Claim(invoice.InvoiceID, user.ID); err != nil {
+		return nil, err
+	}
+
+	payment := &model.MerchantPayment{
+		MerchantID: invoice.MerchantID,
+		Amount:     invoice.Total,
+		Note:       "Invoice " + invoice.InvoiceID,
+	}
+	if err := u.merchantUsecase.Pay(user, payment); err != nil {
+		if statusErr := u.invoiceRepo.SetStatus(invoice.InvoiceID, invoice.Status); statusErr != nil {
+			return nil, statusErr
+		}
+		return nil, err
+	}
+
+	if err := u.invoiceRepo.SetPaid(invoice.InvoiceID, payment.TransactionID, payment.PaidAt); err != nil {
+		return nil, err
+	}
+	invoice.Status = "Paid"
+	invoice.TransactionID = payment.TransactionID
+	invoice.PaidAt = &payment.PaidAt
+	return invoice, nil
+}
+
+func (u *invoiceUsecase) RenderPDF(userID string, invoiceID string) ([]byte, error) {
+	invoice, err := u.FindInvoice(userID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	line := strings.Repeat("-", 72)
+	lines := []string{
+		"# INVOICE " + invoice.InvoiceID,
+		"",
+		"Merchant : " + invoice.MerchantName,
+		"Customer : " + invoice.CustomerName + " (" + invoice.PhoneNumber + ")",
+		"Issued   : " + invoice.CreatedAt.Format("2006-01-02"),
+		"Due date : " + invoice.DueDate,
+		"Status   : " + invoice.Status,
+		"",
+		fmt.Sprintf("# %-34s %5s %15s %15s", "Description", "Qty", "Unit price", "Amount"),
+		line,
+	}
+	for _, item := range invoice.Items {
+		description := item.Description
+		if len(description) > 34 {
+			description = description[:31] + "..."
+		}
+		lines = append(lines, fmt.Sprintf("%-34s %5d %15s %15s", description, item.Quantity, invoiceRupiah(item.UnitPrice), invoiceRupiah(item.Amount)))
+	}
+	lines = append(lines,
+		line,
+		fmt.Sprintf("%56s %15s", "Subtotal", invoiceRupiah(invoice.Subtotal)),
+		fmt.Sprintf("%56s %15s", fmt.Sprintf("Tax (%.2f%%)", float64(invoice.TaxBps)/100), invoiceRupiah(invoice.TaxAmount)),
+		fmt.Sprintf("# %56s %15s", "Total", invoiceRupiah(invoice.Total)),
+	)
+	if invoice.Notes != "" {
+		lines = append(lines, "", "Notes: "+invoice.Notes)
+	}
+	if invoice.PaidAt != nil {
+		lines = append(lines, "", fmt.Sprintf("Paid on %s, transaction %d", invoice.PaidAt.Format("2006-01-02 15:04"), invoice.TransactionID))
+	}
+	return utils.RenderTextPDF(lines), nil
+}
+
+// RemindOverdue marks sent invoices past their due date as overdue and
+// reminds customers of overdue invoices at most once per reminder interval.
+// It reports how many reminders were sent.
+func (u *invoiceUsecase) RemindOverdue() (int, error) {
+	if _, err := u.invoiceRepo.MarkOverdue(time.Now().Format("2006-01-02")); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	invoices, err := u.invoiceRepo.GetReminderDue(now.Add(-invoiceReminderInterval))
+	if err != nil {
+		return 0, err
+	}
+
+	reminded := 0
+	for _, invoice := range invoices {
+		customer, err := u.userRepo.GetByIDToken(invoice.CustomerID)
+		if err != nil {
+			return reminded, err
+		}
+		body := "Tagihan " + invoice.InvoiceID + " dari " + invoice.MerchantName + " sebesar " + invoiceRupiah(invoice.Total) + " telah jatuh tempo"
+		if err := u.notify(customer.Token, "Tagihan Jatuh Tempo", body); err != nil {
+			// Left unmarked so the next run tries again
+			continue
+		}
+		if err := u.invoiceRepo.SetReminded(invoice.InvoiceID, now); err != nil {
+			return reminded, err
+		}
+		reminded++
+	}
+	return reminded, nil
+}
+
+func NewInvoiceUsecase(invoiceRepo repository.InvoiceRepository, merchantRepo repository.MerchantRepository, userRepo repository.UserRepository, merchantUsecase MerchantUsecase) InvoiceUsecase {
+	return &invoiceUsecase{
+		invoiceRepo:     invoiceRepo,
+		merchantRepo:    merchantRepo,
+		userRepo:        userRepo,
+		merchantUsecase: merchantUsecase,
+		notify:          model.SendFCMNotification,
+	}
+}
@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type invoiceRepoMock struct {
+	mock.Mock
+}
+
+func (r *invoiceRepoMock) Create(invoice *model.Invoice) error {
+	args := r.Called(invoice)
+	return args.Error(0)
+}
+
+func (r *invoiceRepoMock) Update(invoice *model.Invoice) error {
+	args := r.Called(invoice)
+	return args.Error(0)
+}
+
+func (r *invoiceRepoMock) GetByID(invoiceID string) (*model.Invoice, error) {
+	args := r.Called(invoiceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Invoice), args.Error(1)
+}
+
+func (r *invoiceRepoMock) GetByMerchant(merchantID string) ([]*model.Invoice, error) {
+	args := r.Called(merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Invoice), args.Error(1)
+}
+
+func (r *invoiceRepoMock) GetByCustomer(customerID string) ([]*model.Invoice, error) {
+	args := r.Called(customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Invoice), args.Error(1)
+}
+
+func (r *invoiceRepoMock) Send(invoiceID string, merchantID string, sentAt time.Time) error {
+	args := r.Called(invoiceID, merchantID, sentAt)
+	return args.Error(0)
+}
+
+func (r *invoiceRepoMock) Void(invoiceID string, merchantID string) error {
+	args := r.Called(invoiceID, merchantID)
+	return args.Error(0)
+}
+
+func (r *invoiceRepoMock) Claim(invoiceID string, customerID string) error {
+	args := r.Called(invoiceID, customerID)
+	return args.Error(0)
+}
+
+func (r *invoiceRepoMock) SetStatus(invoiceID string, status string) error {
+	args := r.Called(invoiceID, status)
+	return args.Error(0)
+}
+
+func (r *invoiceRepoMock) SetPaid(invoiceID string, transactionID int, paidAt time.Time) error {
+	args := r.Called(invoiceID, transactionID, paidAt)
+	return args.Error(0)
+}
+
+func (r *invoiceRepoMock) MarkOverdue(today string) (int, error) {
+	args := r.Called(today)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *invoiceRepoMock) GetReminderDue(before time.Time) ([]*model.Invoice, error) {
+	args := r.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Invoice), args.Error(1)
+}
+
+func (r *invoiceRepoMock) SetReminded(invoiceID string, remindedAt time.Time) error {
+	args := r.Called(invoiceID, remindedAt)
+	return args.Error(0)
+}
+
+type InvoiceUsecaseTestSuite struct {
+	invoiceRepoMock  *invoiceRepoMock
+	merchantRepoMock *merchantRepoMock
+	userRepoMock     *userRepoMock
+	bankaccRepoMock  *bankaccRepoMock
+	holdRepoMock     *holdRepoMock
+	suite.Suite
+}
+
+func (suite *InvoiceUsecaseTestSuite) usecase() InvoiceUsecase {
+	merchantUsecase := NewMerchantUsecase(suite.merchantRepoMock, suite.userRepoMock, suite.bankaccRepoMock, suite.holdRepoMock)
+	return NewInvoiceUsecase(suite.invoiceRepoMock, suite.merchantRepoMock, suite.userRepoMock, merchantUsecase)
+}
+
+func sentInvoice() *model.Invoice {
+	return &model.Invoice{
+		InvoiceID:      "INVABCDEFGH23",
+		MerchantID:     dummyMerchant.MerchantID,
+		MerchantName:   dummyMerchant.BusinessName,
+		MerchantUserID: dummyMerchant.UserID,
+		CustomerID:     "2",
+		Total:          55500,
+		DueDate:        "2026-05-01",
+		Status:         "Sent",
+	}
+}
+
+func newInvoiceRequest() *model.Invoice {
+	return &model.Invoice{
+		PhoneNumber: "08111111",
+		Items: []*model.InvoiceItem{
+			{Description: " Nasi goreng ", Quantity: 2, UnitPrice: 25000},
+			{Description: "Es teh", Quantity: 3, UnitPrice: 5000},
+		},
+		TaxBps:  1100,
+		DueDate: time.Now().AddDate(0, 0, 14).Format("2006-01-02"),
+	}
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestCreate_Success() {
+	merchant := dummyMerchant
+	merchant.UserID = "2"
+	invoice := newInvoiceRequest()
+	suite.merchantRepoMock.On("GetByUserID", "2").Return(&merchant, nil)
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.invoiceRepoMock.On("Create", invoice).Return(nil)
+
+	err := suite.usecase().Create("2", invoice)
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(invoice.InvoiceID, "INV"))
+	assert.Equal(suite.T(), "Draft", invoice.Status)
+	assert.Equal(suite.T(), "Nasi goreng", invoice.Items[0].Description)
+	assert.Equal(suite.T(), 50000, invoice.Items[0].Amount)
+	assert.Equal(suite.T(), 65000, invoice.Subtotal)
+	assert.Equal(suite.T(), 7150, invoice.TaxAmount)
+	assert.Equal(suite.T(), 72150, invoice.Total)
+	assert.Equal(suite.T(), "1", invoice.CustomerID)
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestCreate_OwnAccount() {
+	merchant := dummyMerchant
+	suite.merchantRepoMock.On("GetByUserID", "1").Return(&merchant, nil)
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+
+	err := suite.usecase().Create("1", newInvoiceRequest())
+
+	assert.EqualError(suite.T(), err, "cannot invoice your own account")
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestCreate_PastDueDate() {
+	merchant := dummyMerchant
+	invoice := newInvoiceRequest()
+	invoice.DueDate = time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	suite.merchantRepoMock.On("GetByUserID", "1").Return(&merchant, nil)
+
+	err := suite.usecase().Create("1", invoice)
+
+	assert.EqualError(suite.T(), err, "due date must not be in the past")
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestUpdate_NotDraft() {
+	merchant := dummyMerchant
+	suite.merchantRepoMock.On("GetByUserID", "1").Return(&merchant, nil)
+	suite.invoiceRepoMock.On("GetByID", "INVABCDEFGH23").Return(sentInvoice(), nil)
+	invoice := newInvoiceRequest()
+	invoice.InvoiceID = "invabcdefgh23"
+
+	err := suite.usecase().Update("1", invoice)
+
+	assert.EqualError(suite.T(), err, "invoice is no longer a draft")
+	suite.invoiceRepoMock.AssertNotCalled(suite.T(), "Update", invoice)
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestFindInvoice_DraftHiddenFromCustomer() {
+	invoice := sentInvoice()
+	invoice.Status = "Draft"
+	suite.invoiceRepoMock.On("GetByID", "INVABCDEFGH23").Return(invoice, nil)
+
+	res, err := suite.usecase().FindInvoice("2", "INVABCDEFGH23")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "invoice not found")
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestPay_Success() {
+	payer := dummySender
+	merchant := dummyMerchant
+	merchantUser := &model.User{ID: "1", Balance: 5000}
+	suite.invoiceRepoMock.On("GetByID", "INVABCDEFGH23").Return(sentInvoice(), nil)
+	suite.invoiceRepoMock.On("Claim", "INVABCDEFGH23", payer.ID).Return(nil)
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+	suite.holdRepoMock.On("GetHeldAmount", payer.ID).Return(0, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(merchantUser, nil)
+	suite.userRepoMock.On("UpdateBalance", payer.ID, 100000-55500).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 5000+55500-389).Return(nil)
+	suite.merchantRepoMock.On("CreatePayment", mock.AnythingOfType("*model.MerchantPayment")).Return(nil).Run(func(args mock.Arguments) {
+		payment := args.Get(0).(*model.MerchantPayment)
+		assert.Equal(suite.T(), "Invoice INVABCDEFGH23", payment.Note)
+		payment.TransactionID = 41
+	})
+	suite.invoiceRepoMock.On("SetPaid", "INVABCDEFGH23", 41, mock.AnythingOfType("time.Time")).Return(nil)
+
+	res, err := suite.usecase().Pay(&payer, "invabcdefgh23")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Paid", res.Status)
+	assert.Equal(suite.T(), 41, res.TransactionID)
+	assert.NotNil(suite.T(), res.PaidAt)
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestPay_FailureRestoresStatus() {
+	payer := dummySender
+	invoice := sentInvoice()
+	invoice.Status = "Overdue"
+	suite.invoiceRepoMock.On("GetByID", "INVABCDEFGH23").Return(invoice, nil)
+	suite.invoiceRepoMock.On("Claim", "INVABCDEFGH23", payer.ID).Return(nil)
+	suite.merchantRepoMock.On("GetByID", dummyMerchant.MerchantID).Return(&dummyMerchant, nil)
+	suite.holdRepoMock.On("GetHeldAmount", payer.ID).Return(60000, nil)
+	suite.invoiceRepoMock.On("SetStatus", "INVABCDEFGH23", "Overdue").Return(nil)
+
+	res, err := suite.usecase().Pay(&payer, "INVABCDEFGH23")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.invoiceRepoMock.AssertCalled(suite.T(), "SetStatus", "INVABCDEFGH23", "Overdue")
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestPay_NotPayable() {
+	payer := dummySender
+	invoice := sentInvoice()
+	invoice.Status = "Paid"
+	suite.invoiceRepoMock.On("GetByID", "INVABCDEFGH23").Return(invoice, nil)
+
+	res, err := suite.usecase().Pay(&payer, "INVABCDEFGH23")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "invoice is not payable")
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestRenderPDF_Success() {
+	invoice := sentInvoice()
+	invoice.Items = []*model.InvoiceItem{{Description: "Nasi goreng", Quantity: 2, UnitPrice: 25000, Amount: 50000}}
+	suite.invoiceRepoMock.On("GetByID", "INVABCDEFGH23").Return(invoice, nil)
+
+	res, err := suite.usecase().RenderPDF("1", "INVABCDEFGH23")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(string(res), "%PDF-"))
+	assert.Contains(suite.T(), string(res), "INVOICE INVABCDEFGH23")
+}
+
+func (suite *InvoiceUsecaseTestSuite) TestRemindOverdue_SkipsFailedNotification() {
+	first := sentInvoice()
+	first.Status = "Overdue"
+	second := sentInvoice()
+	second.InvoiceID = "INVZZZZZZZZ23"
+	second.CustomerID = "3"
+	second.Status = "Overdue"
+	suite.invoiceRepoMock.On("MarkOverdue", time.Now().Format("2006-01-02")).Return(2, nil)
+	suite.invoiceRepoMock.On("GetReminderDue", mock.AnythingOfType("time.Time")).Return([]*model.Invoice{first, second}, nil)
+	suite.userRepoMock.On("GetByIDToken", "2").Return(&model.User{ID: "2", Token: "token2"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "3").Return(&model.User{ID: "3", Token: "token3"}, nil)
+	suite.invoiceRepoMock.On("SetReminded", "INVABCDEFGH23", mock.AnythingOfType("time.Time")).Return(nil)
+
+	uc := suite.usecase()
+	uc.(*invoiceUsecase).notify = func(token string, title string, body string) error {
+		if token == "token3" {
+			return errors.New("fcm unavailable")
+		}
+		return nil
+	}
+	res, err := uc.RemindOverdue()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, res)
+	suite.invoiceRepoMock.AssertNotCalled(suite.T(), "SetReminded", "INVZZZZZZZZ23", mock.Anything)
+}
+
+func (suite *InvoiceUsecaseTestSuite) SetupTest() {
+	suite.invoiceRepoMock = new(invoiceRepoMock)
+	suite.merchantRepoMock = new(merchantRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.bankaccRepoMock = new(bankaccRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+}
+
+func TestInvoiceUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(InvoiceUsecaseTestSuite))
+}
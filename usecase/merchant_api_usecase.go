@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxActiveAPIKeys     = 5
+	apiKeyChars          = "abcdefghijklmnopqrstuvwxyz0123456789"
+	apiKeyIDSize         = 24
+	apiKeySecretSize     = 40
+	apiSignatureWindow   = 5 * time.Minute
+	maxAPINonce          = 64
+	chargeLifetime       = 15 * time.Minute
+	chargeIDSize         = 20
+	maxChargeReference   = 50
+	maxChargeDescription = 100
+	chargeStatusPending  = "Pending"
+	chargeStatusSuccess  = "Success"
+	chargeStatusFailed   = "Failed"
+)
+
+type MerchantAPIUsecase interface {
+	IssueKey(userID string) (*model.MerchantAPIKey, error)
+	FindKeys(userID string) ([]*model.MerchantAPIKey, error)
+	RotateKey(userID string, keyID string) (*model.MerchantAPIKey, error)
+	RevokeKey(userID string, keyID string) error
+	Authenticate(keyID string, timestamp string, nonce string, signature string, method string, path string, body []byte) (*model.MerchantAPIKey, error)
+	PurgeNonces() (int, error)
+
+	CreateCharge(merchantID string, charge *model.MerchantCharge) error
+	FindCharge(merchantID string, chargeID string) (*model.MerchantCharge, error)
+	RefundCharge(merchantID string, chargeID string, amount int) (*model.ChargeRefund, error)
+
+	FindPendingCharges(userID string) ([]*model.MerchantCharge, error)
+	ApproveCharge(user *model.User, chargeID string) (*model.MerchantCharge, error)
+	DeclineCharge(userID string, chargeID string) error
+	ExpireCharges() (int, error)
+}
+
+type merchantAPIUsecase struct {
+	apiRepo         repository.MerchantAPIRepository
+	merchantRepo    repository.MerchantRepository
+	userRepo        repository.UserRepository
+	holdRepo        repository.HoldRepository
+	merchantUsecase MerchantUsecase
+}
+
+// merchantSignature is what a merchant sends in X-Signature: the hex HMAC-SHA256
+// of "timestamp\nnonce\nMETHOD\npath\nbody" keyed with the API key secret.
+func merchantSignature(secret string, timestamp string, nonce string, method string, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + nonce + "\n" + method + "\n" + path + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (u *merchantAPIUsecase) newKey(merchantID string) (*model.MerchantAPIKey, error) {
+	id, err := randomCode(apiKeyChars, apiKeyIDSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %v", err)
+	}
+	secret, err := randomCode(apiKeyChars, apiKeySecretSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %v", err)
+	}
+
+	key := &model.MerchantAPIKey{
+		KeyID:      "pk_" + id,
+		MerchantID: merchantID,
+		Secret:     "sk_" + secret,
+		Status:     "Active",
+		CreatedAt:  time.Now(),
+	}
+	if err := u.apiRepo.CreateKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// IssueKey returns the new key with its secret; the secret is not shown again.
+func (u *merchantAPIUsecase) IssueKey(userID string) (*model.MerchantAPIKey, error) {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	active, err := u.apiRepo.CountActiveKeys(merchant.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if active >= maxActiveAPIKeys {
+		return nil, fmt.Errorf("too many active api keys")
+	}
+	return u.newKey(merchant.MerchantID)
+}
+
+func (u *merchantAPIUsecase) FindKeys(userID string) ([]*model.MerchantAPIKey, error) {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.apiRepo.GetKeys(merchant.MerchantID)
+}
+
+// RotateKey revokes the given key and issues its replacement.
+func (u *merchantAPIUsecase) RotateKey(userID string, keyID string) (*model.MerchantAPIKey, error) {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.apiRepo.RevokeKey(keyID, merchant.MerchantID); err != nil {
+		return nil, err
+	}
+	return u.newKey(merchant.MerchantID)
+}
+
+func (u *merchantAPIUsecase) RevokeKey(userID string, keyID string) error {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	return u.apiRepo.RevokeKey(keyID, merchant.MerchantID)
+}
+
+// Authenticate verifies a signed request. Each request carries a nonce that
+// is part of the signature and is rejected when it was used before.
+func (u *merchantAPIUsecase) Authenticate(keyID string, timestamp string, nonce string, signature string, method string, path string, body []byte) (*model.MerchantAPIKey, error) {
+	if keyID == "" || timestamp == "" || nonce == "" || signature == "" {
+		return nil, fmt.Errorf("missing authentication headers")
+	}
+	if len(nonce) > maxAPINonce {
+		return nil, fmt.Errorf("nonce must be at most 64 characters")
+	}
+	key, err := u.apiRepo.GetKey(keyID)
+	if err != nil || key.Status != "Active" {
+		return nil, fmt.Errorf("invalid api key")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request timestamp")
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > apiSignatureWindow || age < -apiSignatureWindow {
+		return nil, fmt.Errorf("request timestamp expired")
+	}
+
+	expected := merchantSignature(key.Secret, timestamp, nonce, method, path, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if err := u.apiRepo.UseNonce(key.KeyID, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PurgeNonces forgets nonces whose requests can no longer pass the timestamp
+// check. Timestamps may be off by the window either way, hence twice the window.
+func (u *merchantAPIUsecase) PurgeNonces() (int, error) {
+	return u.apiRepo.PurgeNonces(time.Now().Add(-2 * apiSignatureWindow))
+}
+
+func (u *merchantAPIUsecase) CreateCharge(merchantID string, charge *model.MerchantCharge) error {
+	charge.Reference = strings.TrimSpace(charge.Reference)
+	if charge.Reference == "" || len(charge.Reference) > maxChargeReference {
+		return fmt.Errorf("reference must be 1 - 50 characters")
+	}
+	if charge.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if len(charge.Description) > maxChargeDescription {
+		return fmt.Errorf("description must be at most 100 characters")
+	}
+	if _, err := u.apiRepo.GetChargeByReference(merchantID, charge.Reference); err == nil {
+		return fmt.Errorf("duplicate reference")
+	}
+
+	merchant, err := u.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		return err
+	}
+	user, err := u.userRepo.GetByPhone(charge.PhoneNumber)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if user.ID == merchant.UserID {
+		return fmt.Errorf("cannot charge your own account")
+	}
+
+	id, err := randomCode(apiKeyChars, chargeIDSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate charge id: %v", err)
+	}
+	now := time.Now()
+	charge.ChargeID = "ch_" + id
+	charge.MerchantID = merchant.MerchantID
+	charge.MerchantName = merchant.BusinessName
+	charge.UserID = user.ID
+	charge.Status = chargeStatusPending
+	charge.CreatedAt = now
+	charge.ExpiredAt = now.Add(chargeLifetime)
+	return u.apiRepo.CreateCharge(charge)
+}
+
+func (u *merchantAPIUsecase) FindCharge(merchantID string, chargeID string) (*model.MerchantCharge, error) {
+	charge, err := u.apiRepo.GetCharge(chargeID)
+	if err != nil {
+		return nil, err
+	}
+	if charge.MerchantID != merchantID {
+		return nil, fmt.Errorf("charge not found")
+	}
+	return charge, nil
+}
+
+// RefundCharge sends money back to the user. An amount of 0 refunds whatever
+// has not been refunded yet. The MDR already taken is not returned to the
+// merchant.
+func (u *merchantAPIUsecase) RefundCharge(merchantID string, chargeID string, amount int) (*model.ChargeRefund, error) {
+	charge, err := u.FindCharge(merchantID, chargeID)
+	if err != nil {
+		return nil, err
+	}
+	if charge.Status != chargeStatusSuccess && charge.Status != "Partially Refunded" {
+		return nil, fmt.Errorf("charge is not refundable")
+	}
+	if amount == 0 {
+		amount = charge.Amount - charge.RefundedAmount
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	if charge.RefundedAmount+amount > charge.Amount {
+		return nil, fmt.Errorf("refund exceeds charged amount")
+	}
+
+	merchant, err := u.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	merchantUser, err := u.userRepo.GetByiD(merchant.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant user: %v", err)
+	}
+	available, err := availableBalance(u.holdRepo, merchantUser)
+	if err != nil {
+		return nil, err
+	}
+	if available < amount {
+		return nil, fmt.Errorf("insufficient merchant balance")
+	}
+	user, err := u.userRepo.GetByiD(charge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := &model.ChargeRefund{Amount: amount}
+	if err := u.apiRepo.Refund(charge, refund, merchantUser.ID); err != nil {
+		return nil, err
+	}
+	if err := u.userRepo.UpdateBalance(merchantUser.ID, merchantUser.Balance-amount); err != nil {
+		return nil, fmt.Errorf("failed to update merchant balance: %v", err)
+	}
+	if err := u.userRepo.UpdateBalance(user.ID, user.Balance+amount); err != nil {
+		return nil, fmt.Errorf("failed to update user balance: %v", err)
+	}
+	refund.MerchantUserID = merchantUser.ID
+	return refund, nil
+}
+
+func (u *merchantAPIUsecase) FindPendingCharges(userID string) ([]*model.MerchantCharge, error) {
+	return u.apiRepo.GetPendingCharges(userID)
+}
+
+// ApproveCharge pays a pending charge through the regular merchant payment
+// flow, so MDR and settlement work the same as a QR payment.
+func (u *merchantAPIUsecase) ApproveCharge(user *model.User, chargeID string) (*model.MerchantCharge, error) {
+	charge, err := u.apiRepo.GetCharge(chargeID)
+	if err != nil {
+		return nil, err
+	}
+	if charge.UserID != user.ID {
+		return nil, fmt.Errorf("charge not found")
+	}
+	if err := u.apiRepo.ClaimCharge(chargeID, user.ID); err != nil {
+		return nil, err
+	}
+
+	payment := &model.MerchantPayment{
+		MerchantID: charge.MerchantID,
+		Amount:     charge.Amount,
+		Note:       charge.Description,
+	}
+	if err := u.merchantUsecase.Pay(user, payment); err != nil {
+		if resultErr := u.apiRepo.SetChargeResult(chargeID, chargeStatusFailed, 0); resultErr != nil {
+			return nil, resultErr
+		}
+		return nil, err
+	}
+
+	if err := u.apiRepo.SetChargeResult(chargeID, chargeStatusSuccess, payment.TransactionID); err != nil {
+		return nil, err
+	}
+	charge.Status = chargeStatusSuccess
+	charge.TransactionID = payment.TransactionID
+	charge.MerchantUserID = payment.MerchantUserID
+	return charge, nil
+}
+
+func (u *merchantAPIUsecase) DeclineCharge(userID string, chargeID string) error {
+	return u.apiRepo.DeclineCharge(chargeID, userID)
+}
+
+func (u *merchantAPIUsecase) ExpireCharges() (int, error) {
+	return u.apiRepo.ExpireCharges(time.Now())
+}
+
+func NewMerchantAPIUsecase(apiRepo repository.MerchantAPIRepository, merchantRepo repository.MerchantRepository, userRepo repository.UserRepository, holdRepo repository.HoldRepository, merchantUsecase MerchantUsecase) MerchantAPIUsecase {
+	return &merchantAPIUsecase{
+		apiRepo:         apiRepo,
+		merchantRepo:    merchantRepo,
+		userRepo:        userRepo,
+		holdRepo:        holdRepo,
+		merchantUsecase: merchantUsecase,
+	}
+}
@@ -0,0 +1,373 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type merchantAPIRepoMock struct {
+	mock.Mock
+}
+
+func (r *merchantAPIRepoMock) CreateKey(key *model.MerchantAPIKey) error {
+	args := r.Called(key)
+	return args.Error(0)
+}
+
+func (r *merchantAPIRepoMock) GetKey(keyID string) (*model.MerchantAPIKey, error) {
+	args := r.Called(keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MerchantAPIKey), args.Error(1)
+}
+
+func (r *merchantAPIRepoMock) GetKeys(merchantID string) ([]*model.MerchantAPIKey, error) {
+	args := r.Called(merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.MerchantAPIKey), args.Error(1)
+}
+
+func (r *merchantAPIRepoMock) CountActiveKeys(merchantID string) (int, error) {
+	args := r.Called(merchantID)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *merchantAPIRepoMock) RevokeKey(keyID string, merchantID string) error {
+	args := r.Called(keyID, merchantID)
+	return args.Error(0)
+}
+
+func (r *merchantAPIRepoMock) UseNonce(keyID string, nonce string, now time.Time) error {
+	args := r.Called(keyID, nonce, now)
+	return args.Error(0)
+}
+
+func (r *merchantAPIRepoMock) PurgeNonces(before time.Time) (int, error) {
+	args := r.Called(before)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *merchantAPIRepoMock) CreateCharge(charge *model.MerchantCharge) error {
+	args := r.Called(charge)
+	return args.Error(0)
+}
+
+func (r *merchantAPIRepoMock) GetCharge(chargeID string) (*model.MerchantCharge, error) {
+	args := r.Called(chargeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MerchantCharge), args.Error(1)
+}
+
+func (r *merchantAPIRepoMock) GetChargeByReference(merchantID string, reference string) (*model.MerchantCharge, error) {
+	args := r.Called(merchantID, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MerchantCharge), args.Error(1)
+}
+
+func (r *merchantAPIRepoMock) GetPendingCharges(userID string) ([]*model.MerchantCharge, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.MerchantCharge), args.Error(1)
+}
+
+func (r *merchantAPIRepoMock) ClaimCharge(chargeID string, userID string) error {
+	args := r.Called(chargeID, userID)
+	return args.Error(0)
+}
+
+func (r *merchantAPIRepoMock) SetChargeResult(chargeID string, status string, transactionID int) error {
+	args := r.Called(chargeID, status, transactionID)
+	return args.Error(0)
+}
+
+func (r *merchantAPIRepoMock) DeclineCharge(chargeID string, userID string) error {
+	args := r.Called(chargeID, userID)
+	return args.Error(0)
+}
+
+func (r *merchantAPIRepoMock) Refund(charge *model.MerchantCharge, refund *model.ChargeRefund, merchantUserID string) error {
+	args := r.Called(charge, refund, merchantUserID)
+	return args.Error(0)
+}
+
+func (r *merchantAPIRepoMock) ExpireCharges(now time.Time) (int, error) {
+	args := r.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+type MerchantAPIUsecaseTestSuite struct {
+	apiRepoMock      *merchantAPIRepoMock
+	merchantRepoMock *merchantRepoMock
+	userRepoMock     *userRepoMock
+	holdRepoMock     *holdRepoMock
+	suite.Suite
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) usecase() MerchantAPIUsecase {
+	merchantUsecase := NewMerchantUsecase(suite.merchantRepoMock, suite.userRepoMock, new(bankaccRepoMock), suite.holdRepoMock)
+	return NewMerchantAPIUsecase(suite.apiRepoMock, suite.merchantRepoMock, suite.userRepoMock, suite.holdRepoMock, merchantUsecase)
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) activeKey() *model.MerchantAPIKey {
+	return &model.MerchantAPIKey{KeyID: "pk_test", MerchantID: dummyMerchant.MerchantID, Secret: "sk_secret", Status: "Active"}
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) paidCharge() *model.MerchantCharge {
+	return &model.MerchantCharge{ChargeID: "ch_1", MerchantID: dummyMerchant.MerchantID, UserID: dummySender.ID, Amount: 40000, RefundedAmount: 10000, Status: "Partially Refunded"}
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestIssueKey_Success() {
+	merchant := dummyMerchant
+	suite.merchantRepoMock.On("GetByUserID", "1").Return(&merchant, nil)
+	suite.apiRepoMock.On("CountActiveKeys", merchant.MerchantID).Return(1, nil)
+	suite.apiRepoMock.On("CreateKey", mock.AnythingOfType("*model.MerchantAPIKey")).Return(nil)
+
+	key, err := suite.usecase().IssueKey("1")
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(key.KeyID, "pk_"))
+	assert.True(suite.T(), strings.HasPrefix(key.Secret, "sk_"))
+	assert.Equal(suite.T(), "Active", key.Status)
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestIssueKey_TooMany() {
+	merchant := dummyMerchant
+	suite.merchantRepoMock.On("GetByUserID", "1").Return(&merchant, nil)
+	suite.apiRepoMock.On("CountActiveKeys", merchant.MerchantID).Return(maxActiveAPIKeys, nil)
+
+	key, err := suite.usecase().IssueKey("1")
+
+	assert.Nil(suite.T(), key)
+	assert.EqualError(suite.T(), err, "too many active api keys")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestRotateKey_RevokesOldKey() {
+	merchant := dummyMerchant
+	suite.merchantRepoMock.On("GetByUserID", "1").Return(&merchant, nil)
+	suite.apiRepoMock.On("RevokeKey", "pk_old", merchant.MerchantID).Return(nil)
+	suite.apiRepoMock.On("CreateKey", mock.AnythingOfType("*model.MerchantAPIKey")).Return(nil)
+
+	key, err := suite.usecase().RotateKey("1", "pk_old")
+
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), "pk_old", key.KeyID)
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestAuthenticate_Success() {
+	suite.apiRepoMock.On("GetKey", "pk_test").Return(suite.activeKey(), nil)
+	body := []byte(`{"amount":1000}`)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := merchantSignature("sk_secret", timestamp, "n-1", "POST", "/api/v1/charges", body)
+	suite.apiRepoMock.On("UseNonce", "pk_test", "n-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	key, err := suite.usecase().Authenticate("pk_test", timestamp, "n-1", signature, "POST", "/api/v1/charges", body)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), dummyMerchant.MerchantID, key.MerchantID)
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestAuthenticate_ReplayedNonce() {
+	suite.apiRepoMock.On("GetKey", "pk_test").Return(suite.activeKey(), nil)
+	body := []byte(`{"amount":1000}`)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := merchantSignature("sk_secret", timestamp, "n-1", "POST", "/api/v1/charges", body)
+	suite.apiRepoMock.On("UseNonce", "pk_test", "n-1", mock.AnythingOfType("time.Time")).Return(errors.New("nonce already used"))
+
+	key, err := suite.usecase().Authenticate("pk_test", timestamp, "n-1", signature, "POST", "/api/v1/charges", body)
+
+	assert.Nil(suite.T(), key)
+	assert.EqualError(suite.T(), err, "nonce already used")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestAuthenticate_MissingNonce() {
+	key, err := suite.usecase().Authenticate("pk_test", "1", "", "abc", "GET", "/", nil)
+
+	assert.Nil(suite.T(), key)
+	assert.EqualError(suite.T(), err, "missing authentication headers")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestAuthenticate_TamperedBody() {
+	suite.apiRepoMock.On("GetKey", "pk_test").Return(suite.activeKey(), nil)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := merchantSignature("sk_secret", timestamp, "n-1", "POST", "/api/v1/charges", []byte(`{"amount":1000}`))
+
+	key, err := suite.usecase().Authenticate("pk_test", timestamp, "n-1", signature, "POST", "/api/v1/charges", []byte(`{"amount":9000}`))
+
+	assert.Nil(suite.T(), key)
+	assert.EqualError(suite.T(), err, "invalid signature")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestAuthenticate_StaleTimestamp() {
+	suite.apiRepoMock.On("GetKey", "pk_test").Return(suite.activeKey(), nil)
+	timestamp := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	signature := merchantSignature("sk_secret", timestamp, "n-1", "GET", "/api/v1/charges/ch_1", nil)
+
+	key, err := suite.usecase().Authenticate("pk_test", timestamp, "n-1", signature, "GET", "/api/v1/charges/ch_1", nil)
+
+	assert.Nil(suite.T(), key)
+	assert.EqualError(suite.T(), err, "request timestamp expired")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestAuthenticate_RevokedKey() {
+	key := suite.activeKey()
+	key.Status = "Revoked"
+	suite.apiRepoMock.On("GetKey", "pk_test").Return(key, nil)
+
+	res, err := suite.usecase().Authenticate("pk_test", "1", "n-1", "abc", "GET", "/", nil)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "invalid api key")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestCreateCharge_Success() {
+	merchant := dummyMerchant
+	merchant.UserID = "9"
+	charge := &model.MerchantCharge{Reference: "INV-1", PhoneNumber: "08111111", Amount: 25000}
+	suite.apiRepoMock.On("GetChargeByReference", merchant.MerchantID, "INV-1").Return(nil, errors.New("charge not found"))
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.apiRepoMock.On("CreateCharge", charge).Return(nil)
+
+	err := suite.usecase().CreateCharge(merchant.MerchantID, charge)
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(charge.ChargeID, "ch_"))
+	assert.Equal(suite.T(), "Pending", charge.Status)
+	assert.Equal(suite.T(), dummyUser[0].ID, charge.UserID)
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestCreateCharge_DuplicateReference() {
+	suite.apiRepoMock.On("GetChargeByReference", dummyMerchant.MerchantID, "INV-1").Return(&model.MerchantCharge{}, nil)
+
+	err := suite.usecase().CreateCharge(dummyMerchant.MerchantID, &model.MerchantCharge{Reference: "INV-1", Amount: 1000})
+
+	assert.EqualError(suite.T(), err, "duplicate reference")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestFindCharge_OtherMerchant() {
+	charge := suite.paidCharge()
+	charge.MerchantID = "MRC9"
+	suite.apiRepoMock.On("GetCharge", "ch_1").Return(charge, nil)
+
+	res, err := suite.usecase().FindCharge(dummyMerchant.MerchantID, "ch_1")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "charge not found")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestApproveCharge_Success() {
+	user := dummySender
+	merchant := dummyMerchant
+	charge := &model.MerchantCharge{ChargeID: "ch_1", MerchantID: merchant.MerchantID, UserID: user.ID, Amount: 20000, Status: "Pending"}
+	suite.apiRepoMock.On("GetCharge", "ch_1").Return(charge, nil)
+	suite.apiRepoMock.On("ClaimCharge", "ch_1", user.ID).Return(nil)
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1"}, nil)
+	suite.userRepoMock.On("UpdateBalance", mock.Anything, mock.Anything).Return(nil)
+	suite.merchantRepoMock.On("CreatePayment", mock.AnythingOfType("*model.MerchantPayment")).Return(nil)
+	suite.apiRepoMock.On("SetChargeResult", "ch_1", "Success", 0).Return(nil)
+
+	res, err := suite.usecase().ApproveCharge(&user, "ch_1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Success", res.Status)
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestApproveCharge_PaymentFails() {
+	user := dummySender
+	user.Balance = 1000
+	merchant := dummyMerchant
+	charge := &model.MerchantCharge{ChargeID: "ch_1", MerchantID: merchant.MerchantID, UserID: user.ID, Amount: 20000, Status: "Pending"}
+	suite.apiRepoMock.On("GetCharge", "ch_1").Return(charge, nil)
+	suite.apiRepoMock.On("ClaimCharge", "ch_1", user.ID).Return(nil)
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
+	suite.apiRepoMock.On("SetChargeResult", "ch_1", "Failed", 0).Return(nil)
+
+	res, err := suite.usecase().ApproveCharge(&user, "ch_1")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestRefundCharge_Remaining() {
+	merchant := dummyMerchant
+	charge := suite.paidCharge()
+	suite.apiRepoMock.On("GetCharge", "ch_1").Return(charge, nil)
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1", Balance: 50000}, nil)
+	suite.userRepoMock.On("GetByiD", dummySender.ID).Return(&model.User{ID: dummySender.ID, Balance: 0}, nil)
+	suite.holdRepoMock.On("GetHeldAmount", "1").Return(0, nil)
+	suite.apiRepoMock.On("Refund", charge, &model.ChargeRefund{Amount: 30000}, "1").Return(nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 20000).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", dummySender.ID, 30000).Return(nil)
+
+	refund, err := suite.usecase().RefundCharge(merchant.MerchantID, "ch_1", 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 30000, refund.Amount)
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestRefundCharge_MerchantBalanceHeld() {
+	merchant := dummyMerchant
+	charge := suite.paidCharge()
+	suite.apiRepoMock.On("GetCharge", "ch_1").Return(charge, nil)
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1", Balance: 50000}, nil)
+	suite.holdRepoMock.On("GetHeldAmount", "1").Return(30000, nil)
+
+	refund, err := suite.usecase().RefundCharge(merchant.MerchantID, "ch_1", 0)
+
+	assert.Nil(suite.T(), refund)
+	assert.EqualError(suite.T(), err, "insufficient merchant balance")
+	suite.apiRepoMock.AssertNotCalled(suite.T(), "Refund", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestRefundCharge_Exceeds() {
+	suite.apiRepoMock.On("GetCharge", "ch_1").Return(suite.paidCharge(), nil)
+
+	refund, err := suite.usecase().RefundCharge(dummyMerchant.MerchantID, "ch_1", 30001)
+
+	assert.Nil(suite.T(), refund)
+	assert.EqualError(suite.T(), err, "refund exceeds charged amount")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) TestRefundCharge_NotPaid() {
+	charge := suite.paidCharge()
+	charge.Status = "Pending"
+	suite.apiRepoMock.On("GetCharge", "ch_1").Return(charge, nil)
+
+	refund, err := suite.usecase().RefundCharge(dummyMerchant.MerchantID, "ch_1", 0)
+
+	assert.Nil(suite.T(), refund)
+	assert.EqualError(suite.T(), err, "charge is not refundable")
+}
+
+func (suite *MerchantAPIUsecaseTestSuite) SetupTest() {
+	suite.apiRepoMock = new(merchantAPIRepoMock)
+	suite.merchantRepoMock = new(merchantRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+}
+
+func TestMerchantAPIUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(MerchantAPIUsecaseTestSuite))
+}
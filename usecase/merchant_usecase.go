@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	defaultMerchantMDRBps   = 70
+	maxMerchantMDRBps       = 1000
+	maxBusinessNameLength   = 50
+	maxBusinessCategory     = 30
+	maxBusinessAddress      = 200
+	maxMerchantPaymentNote  = 100
+	merchantIDPrefix        = "MRC"
+	merchantIDChars         = "0123456789"
+	merchantIDSize          = 10
+	defaultSettlementWindow = 7
+)
+
+// merchantQRPrefix shares the payment QR scheme with personal handles; the
+// slash keeps it apart from them since handles cannot contain one.
+const merchantQRPrefix = qrPaymentPrefix + "merchant/"
+
+type MerchantUsecase interface {
+	Register(user *model.User, merchant *model.Merchant) error
+	FindByUserID(userID string) (*model.Merchant, error)
+	FindByID(merchantID string) (*model.Merchant, error)
+	Update(user *model.User, merchant *model.Merchant) error
+	SetMDR(merchantID string, mdrBps int) error
+	Pay(payer *model.User, payment *model.MerchantPayment) error
+	FindSales(userID string) ([]*model.MerchantPayment, error)
+	FindSettlements(userID string, from string, to string) ([]*model.MerchantSettlement, error)
+}
+
+type merchantUsecase struct {
+	merchantRepo repository.MerchantRepository
+	userRepo     repository.UserRepository
+	bankAccRepo  repository.BankAccRepository
+	holdRepo     repository.HoldRepository
+}
+
+func merchantQR(merchantID string) string {
+	return merchantQRPrefix + merchantID
+}
+
+// merchantMDR is the fee kept from a payment, rounded half up to the rupiah.
+func merchantMDR(amount int, mdrBps int) int {
+	return (amount*mdrBps + 5000) / 10000
+}
+
+func (u *merchantUsecase) validateProfile(user *model.User, merchant *model.Merchant) error {
+	merchant.BusinessName = strings.TrimSpace(merchant.BusinessName)
+	merchant.BusinessCategory = strings.TrimSpace(merchant.BusinessCategory)
+	merchant.BusinessAddress = strings.TrimSpace(merchant.BusinessAddress)
+	if merchant.BusinessName == "" || len(merchant.BusinessName) > maxBusinessNameLength {
+		return fmt.Errorf("business name must be 1 - 50 characters")
+	}
+	if merchant.BusinessCategory == "" || len(merchant.BusinessCategory) > maxBusinessCategory {
+		return fmt.Errorf("business category must be 1 - 30 characters")
+	}
+	if len(merchant.BusinessAddress) > maxBusinessAddress {
+		return fmt.Errorf("business address must be at most 200 characters")
+	}
+
+	account, err := u.bankAccRepo.GetByAccountID(merchant.SettlementAccountID)
+	if err != nil || account.UserID != user.ID {
+		return fmt.Errorf("settlement account not found")
+	}
+	merchant.SettlementBankName = account.BankName
+	merchant.SettlementAccountNumber = account.AccountNumber
+	return nil
+}
+
+func (u *merchantUsecase) Register(user *model.User, merchant *model.Merchant) error {
+	if _, err := u.merchantRepo.GetByUserID(user.ID); err == nil {
+		return fmt.Errorf("user is already a merchant")
+	}
+	if err := u.validateProfile(user, merchant); err != nil {
+		return err
+	}
+
+	code, err := randomCode(merchantIDChars, merchantIDSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate merchant id: %v", err)
+	}
+	merchant.MerchantID = merchantIDPrefix + code
+	merchant.UserID = user.ID
+	merchant.MDRBps = defaultMerchantMDRBps
+	merchant.CreatedAt = time.Now()
+	if err := u.merchantRepo.Create(merchant); err != nil {
+		return err
+	}
+	merchant.QRPayload = merchantQR(merchant.MerchantID)
+	return nil
+}
+
+func (u *merchantUsecase) FindByUserID(userID string) (*model.Merchant, error) {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	merchant.QRPayload = merchantQR(merchant.MerchantID)
+	return merchant, nil
+}
+
+// FindByID is the payer-facing lookup, so settlement details are left out.
+func (u *merchantUsecase) FindByID(merchantID string) (*model.Merchant, error) {
+	merchant, err := u.merchantRepo.GetByID(strings.ToUpper(strings.TrimSpace(merchantID)))
+	if err != nil {
+		return nil, err
+	}
+	merchant.SettlementAccountID = 0
+	merchant.SettlementBankName = ""
+	merchant.SettlementAccountNumber = ""
+	merchant.QRPayload = merchantQR(merchant.MerchantID)
+	return merchant, nil
+}
+
+func (u *merchantUsecase) Update(user *model.User, merchant *model.Merchant) error {
+	current, err := u.merchantRepo.GetByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+	if err := u.validateProfile(user, merchant); err != nil {
+		return err
+	}
+	merchant.MerchantID = current.MerchantID
+	merchant.UserID = current.UserID
+	merchant.MDRBps = current.MDRBps
+	merchant.CreatedAt = current.CreatedAt
+	if err := u.merchantRepo.Update(merchant); err != nil {
+		return err
+	}
+	merchant.QRPayload = merchantQR(merchant.MerchantID)
+	return nil
+}
+
+func (u *merchantUsecase) SetMDR(merchantID string, mdrBps int) error {
+	if mdrBps < 0 || mdrBps > maxMerchantMDRBps {
+		return fmt.Errorf("mdr must be 0 - 1000 basis points")
+	}
+	return u.merchantRepo.UpdateMDR(merchantID, mdrBps)
+}
+
+// Pay moves money from the payer to the merchant. The payer can identify the
+// merchant either by merchant ID or by the scanned QR payload.
+func (u *merchantUsecase) Pay(payer *model.User, payment *model.MerchantPayment) error {
+	merchantID := strings.TrimSpace(payment.MerchantID)
+	if payment.QRPayload != "" {
+		if !strings.HasPrefix(payment.QRPayload, merchantQRPrefix) {
+			return fmt.Errorf("invalid QR code")
+		}
+		merchantID = strings.TrimPrefix(payment.QRPayload, merchantQRPrefix)
+	}
+	if merchantID == "" {
+		return fmt.Errorf("merchant id or QR code is required")
+	}
+	if payment.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	if len(payment.Note) > maxMerchantPaymentNote {
+		return fmt.Errorf("note must be at most 100 characters")
+	}
+
+	merchant, err := u.merchantRepo.GetByID(strings.ToUpper(merchantID))
+	if err != nil {
+		return err
+	}
+	if merchant.UserID == payer.ID {
+		return fmt.Errorf("cannot pay your own merchant")
+	}
+
+	available, err := availableBalance(u.holdRepo, payer)
+	if err != nil {
+		return err
+	}
+	if available < payment.Amount {
+		return fmt.Errorf("insufficient balance")
+	}
+
+	merchantUser, err := u.userRepo.GetByiD(merchant.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get merchant user: %v", err)
+	}
+
+	payment.MerchantID = merchant.MerchantID
+	payment.MerchantName = merchant.BusinessName
+	payment.MerchantUserID = merchant.UserID
+	payment.PayerID = payer.ID
+	payment.PayerName = payer.Name
+	payment.MDRAmount = merchantMDR(payment.Amount, merchant.MDRBps)
+	payment.NetAmount = payment.Amount - payment.MDRAmount
+	payment.PaidAt = time.Now()
+
+	if err := u.userRepo.UpdateBalance(payer.ID, payer.Balance-payment.Amount); err != nil {
+		return fmt.Errorf("failed to update user balance: %v", err)
+	}
+	if err := u.userRepo.UpdateBalance(merchantUser.ID, merchantUser.Balance+payment.NetAmount); err != nil {
+		return fmt.Errorf("failed to update merchant balance: %v", err)
+	}
+	return u.merchantRepo.CreatePayment(payment)
+}
+
+func (u *merchantUsecase) FindSales(userID string) ([]*model.MerchantPayment, error) {
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.merchantRepo.GetPayments(merchant.MerchantID)
+}
+
+// FindSettlements defaults to the last seven days when no range is given.
+func (u *merchantUsecase) FindSettlements(userID string, from string, to string) ([]*model.MerchantSettlement, error) {
+	if to == "" {
+		to = time.Now().Format("2006-01-02")
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("date must use YYYY-MM-DD format")
+	}
+	if from == "" {
+		from = toDate.AddDate(0, 0, -(defaultSettlementWindow - 1)).Format("2006-01-02")
+	}
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("date must use YYYY-MM-DD format")
+	}
+	if fromDate.After(toDate) {
+		return nil, fmt.Errorf("from date must not be after to date")
+	}
+
+	merchant, err := u.merchantRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.merchantRepo.GetSettlements(merchant.MerchantID, from, to)
+}
+
+func NewMerchantUsecase(merchantRepo repository.MerchantRepository, userRepo repository.UserRepository, bankAccRepo repository.BankAccRepository, holdRepo repository.HoldRepository) MerchantUsecase {
+	return &merchantUsecase{
+		merchantRepo: merchantRepo,
+		userRepo:     userRepo,
+		bankAccRepo:  bankAccRepo,
+		holdRepo:     holdRepo,
+	}
+}
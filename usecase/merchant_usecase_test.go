@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type merchantRepoMock struct {
+	mock.Mock
+}
+
+func (r *merchantRepoMock) Create(merchant *model.Merchant) error {
+	args := r.Called(merchant)
+	return args.Error(0)
+}
+
+func (r *merchantRepoMock) GetByID(merchantID string) (*model.Merchant, error) {
+	args := r.Called(merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Merchant), args.Error(1)
+}
+
+func (r *merchantRepoMock) GetByUserID(userID string) (*model.Merchant, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Merchant), args.Error(1)
+}
+
+func (r *merchantRepoMock) Update(merchant *model.Merchant) error {
+	args := r.Called(merchant)
+	return args.Error(0)
+}
+
+func (r *merchantRepoMock) UpdateMDR(merchantID string, mdrBps int) error {
+	args := r.Called(merchantID, mdrBps)
+	return args.Error(0)
+}
+
+func (r *merchantRepoMock) CreatePayment(payment *model.MerchantPayment) error {
+	args := r.Called(payment)
+	return args.Error(0)
+}
+
+func (r *merchantRepoMock) GetPayments(merchantID string) ([]*model.MerchantPayment, error) {
+	args := r.Called(merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.MerchantPayment), args.Error(1)
+}
+
+func (r *merchantRepoMock) GetSettlements(merchantID string, from string, to string) ([]*model.MerchantSettlement, error) {
+	args := r.Called(merchantID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.MerchantSettlement), args.Error(1)
+}
+
+var dummyMerchant = model.Merchant{
+	MerchantID:          "MRC0123456789",
+	UserID:              "1",
+	BusinessName:        "Warung Makan",
+	BusinessCategory:    "Food",
+	SettlementAccountID: 3,
+	MDRBps:              70,
+}
+
+type MerchantUsecaseTestSuite struct {
+	merchantRepoMock *merchantRepoMock
+	userRepoMock     *userRepoMock
+	bankaccRepoMock  *bankaccRepoMock
+	holdRepoMock     *holdRepoMock
+	suite.Suite
+}
+
+func (suite *MerchantUsecaseTestSuite) usecase() MerchantUsecase {
+	return NewMerchantUsecase(suite.merchantRepoMock, suite.userRepoMock, suite.bankaccRepoMock, suite.holdRepoMock)
+}
+
+func (suite *MerchantUsecaseTestSuite) TestMerchantMDR_RoundsHalfUp() {
+	assert.Equal(suite.T(), 700, merchantMDR(100000, 70))
+	assert.Equal(suite.T(), 1, merchantMDR(100, 70))
+	assert.Equal(suite.T(), 0, merchantMDR(50000, 0))
+}
+
+func (suite *MerchantUsecaseTestSuite) TestRegister_Success() {
+	user := dummySender
+	merchant := &model.Merchant{BusinessName: " Toko Kopi ", BusinessCategory: "Food", SettlementAccountID: 3}
+	suite.merchantRepoMock.On("GetByUserID", user.ID).Return(nil, errors.New("merchant not found"))
+	suite.bankaccRepoMock.On("GetByAccountID", uint(3)).Return(&model.BankAcc{AccountID: 3, UserID: user.ID, BankName: "BCA", AccountNumber: "123"})
+	suite.merchantRepoMock.On("Create", merchant).Return(nil)
+
+	err := suite.usecase().Register(&user, merchant)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Toko Kopi", merchant.BusinessName)
+	assert.True(suite.T(), strings.HasPrefix(merchant.MerchantID, merchantIDPrefix))
+	assert.Len(suite.T(), merchant.MerchantID, len(merchantIDPrefix)+merchantIDSize)
+	assert.Equal(suite.T(), defaultMerchantMDRBps, merchant.MDRBps)
+	assert.Equal(suite.T(), merchantQRPrefix+merchant.MerchantID, merchant.QRPayload)
+}
+
+func (suite *MerchantUsecaseTestSuite) TestRegister_AlreadyMerchant() {
+	user := dummySender
+	existing := dummyMerchant
+	suite.merchantRepoMock.On("GetByUserID", user.ID).Return(&existing, nil)
+
+	err := suite.usecase().Register(&user, &model.Merchant{BusinessName: "Toko"})
+
+	assert.EqualError(suite.T(), err, "user is already a merchant")
+}
+
+func (suite *MerchantUsecaseTestSuite) TestRegister_ForeignSettlementAccount() {
+	user := dummySender
+	merchant := &model.Merchant{BusinessName: "Toko Kopi", BusinessCategory: "Food", SettlementAccountID: 3}
+	suite.merchantRepoMock.On("GetByUserID", user.ID).Return(nil, errors.New("merchant not found"))
+	suite.bankaccRepoMock.On("GetByAccountID", uint(3)).Return(&model.BankAcc{AccountID: 3, UserID: "someone else"})
+
+	err := suite.usecase().Register(&user, merchant)
+
+	assert.EqualError(suite.T(), err, "settlement account not found")
+	suite.merchantRepoMock.AssertNotCalled(suite.T(), "Create", merchant)
+}
+
+func (suite *MerchantUsecaseTestSuite) TestFindByID_HidesSettlement() {
+	merchant := dummyMerchant
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+
+	res, err := suite.usecase().FindByID(" mrc0123456789 ")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(0), res.SettlementAccountID)
+	assert.Equal(suite.T(), "Warung Makan", res.BusinessName)
+}
+
+func (suite *MerchantUsecaseTestSuite) TestSetMDR_OutOfRange() {
+	err := suite.usecase().SetMDR(dummyMerchant.MerchantID, 1001)
+
+	assert.EqualError(suite.T(), err, "mdr must be 0 - 1000 basis points")
+}
+
+func (suite *MerchantUsecaseTestSuite) TestPay_ByQR() {
+	payer := dummySender
+	merchant := dummyMerchant
+	merchantUser := &model.User{ID: "1", Balance: 5000}
+	payment := &model.MerchantPayment{QRPayload: merchantQRPrefix + merchant.MerchantID, Amount: 50000}
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+	suite.holdRepoMock.On("GetHeldAmount", payer.ID).Return(0, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(merchantUser, nil)
+	suite.userRepoMock.On("UpdateBalance", payer.ID, 50000).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 5000+49650).Return(nil)
+	suite.merchantRepoMock.On("CreatePayment", payment).Return(nil)
+
+	err := suite.usecase().Pay(&payer, payment)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), merchant.MerchantID, payment.MerchantID)
+	assert.Equal(suite.T(), 350, payment.MDRAmount)
+	assert.Equal(suite.T(), 49650, payment.NetAmount)
+	assert.Equal(suite.T(), "1", payment.MerchantUserID)
+}
+
+func (suite *MerchantUsecaseTestSuite) TestPay_InvalidQR() {
+	payer := dummySender
+
+	err := suite.usecase().Pay(&payer, &model.MerchantPayment{QRPayload: "https://example.com", Amount: 1000})
+
+	assert.EqualError(suite.T(), err, "invalid QR code")
+}
+
+func (suite *MerchantUsecaseTestSuite) TestPay_OwnMerchant() {
+	payer := dummySender
+	merchant := dummyMerchant
+	merchant.UserID = payer.ID
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+
+	err := suite.usecase().Pay(&payer, &model.MerchantPayment{MerchantID: merchant.MerchantID, Amount: 1000})
+
+	assert.EqualError(suite.T(), err, "cannot pay your own merchant")
+}
+
+func (suite *MerchantUsecaseTestSuite) TestPay_BalanceHeld() {
+	payer := dummySender
+	merchant := dummyMerchant
+	suite.merchantRepoMock.On("GetByID", merchant.MerchantID).Return(&merchant, nil)
+	suite.holdRepoMock.On("GetHeldAmount", payer.ID).Return(60000, nil)
+
+	err := suite.usecase().Pay(&payer, &model.MerchantPayment{MerchantID: merchant.MerchantID, Amount: 50000})
+
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", mock.Anything, mock.Anything)
+}
+
+func (suite *MerchantUsecaseTestSuite) TestFindSettlements_DefaultWindow() {
+	merchant := dummyMerchant
+	suite.merchantRepoMock.On("GetByUserID", "1").Return(&merchant, nil)
+	suite.merchantRepoMock.On("GetSettlements", merchant.MerchantID, "2026-03-26", "2026-04-01").Return([]*model.MerchantSettlement{}, nil)
+
+	_, err := suite.usecase().FindSettlements("1", "", "2026-04-01")
+
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *MerchantUsecaseTestSuite) TestFindSettlements_InvalidRange() {
+	_, err := suite.usecase().FindSettlements("1", "2026-04-02", "2026-04-01")
+
+	assert.EqualError(suite.T(), err, "from date must not be after to date")
+}
+
+func (suite *MerchantUsecaseTestSuite) SetupTest() {
+	suite.merchantRepoMock = new(merchantRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.bankaccRepoMock = new(bankaccRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+}
+
+func TestMerchantUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(MerchantUsecaseTestSuite))
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxMissionTitle       = 50
+	maxMissionDescription = 200
+)
+
+type MissionUsecase interface {
+	FindMissions() ([]*model.Mission, error)
+	CreateMission(mission *model.Mission) error
+	UpdateMission(mission *model.Mission) error
+	DeactivateMission(missionID int) error
+	FindActive() ([]*model.Mission, error)
+	FindProgress(userID string) ([]*model.MissionProgress, error)
+	Track(userID string, transactionType string, amount int) ([]*model.Mission, error)
+}
+
+type missionUsecase struct {
+	missionRepo  repository.MissionRepository
+	userRepo     repository.UserRepository
+	pointUsecase PointUsecase
+	notify       func(token string, title string, body string) error
+}
+
+func validateMission(mission *model.Mission) error {
+	mission.Title = strings.TrimSpace(mission.Title)
+	mission.Description = strings.TrimSpace(mission.Description)
+	if mission.Title == "" || len(mission.Title) > maxMissionTitle {
+		return fmt.Errorf("title must be 1 - 50 characters")
+	}
+	if len(mission.Description) > maxMissionDescription {
+		return fmt.Errorf("description must be at most 200 characters")
+	}
+	if !pointEarningTypes[mission.TransactionType] {
+		return fmt.Errorf("transaction type must be Deposit, Transfer or Merchant Payment")
+	}
+	if mission.Metric != "Count" && mission.Metric != "Amount" {
+		return fmt.Errorf("metric must be Count or Amount")
+	}
+	if mission.Target <= 0 || mission.RewardPoints <= 0 {
+		return fmt.Errorf("target and reward points must be greater than 0")
+	}
+	if mission.MinAmount < 0 {
+		return fmt.Errorf("min amount must not be negative")
+	}
+	if !mission.EndsAt.After(mission.StartsAt) {
+		return fmt.Errorf("mission must end after it starts")
+	}
+	return nil
+}
+
+// missionStep is how much a transaction moves a mission forward.
+func missionStep(mission *model.Mission, transactionType string, amount int) int {
+	if mission.TransactionType != transactionType || amount < mission.MinAmount {
+		return 0
+	}
+	if mission.Metric == "Amount" {
+		return amount
+	}
+	return 1
+}
+
+func missionPercent(progress int, target int) int {
+	if progress >= target {
+		return 100
+	}
+	return progress * 100 / target
+}
+
+func (u *missionUsecase) FindMissions() ([]*model.Mission, error) {
+	return u.missionRepo.GetAll()
+}
+
+func (u *missionUsecase) CreateMission(mission *model.Mission) error {
+	if err := validateMission(mission); err != nil {
+		return err
+	}
+	mission.Active = true
+	mission.CreatedAt = time.Now()
+	return u.missionRepo.Create(mission)
+}
+
+func (u *missionUsecase) UpdateMission(mission *model.Mission) error {
+	if err := validateMission(mission); err != nil {
+		return err
+	}
+	if err := u.missionRepo.Update(mission); err != nil {
+		return err
+	}
+
+	updated, err := u.missionRepo.GetByID(mission.MissionID)
+	if err != nil {
+		return err
+	}
+	*mission = *updated
+	return nil
+}
+
+func (u *missionUsecase) DeactivateMission(missionID int) error {
+	return u.missionRepo.Deactivate(missionID)
+}
+
+func (u *missionUsecase) FindActive() ([]*model.Mission, error) {
+	return u.missionRepo.GetActive(time.Now())
+}
+
+func (u *missionUsecase) FindProgress(userID string) ([]*model.MissionProgress, error) {
+	if _, err := u.userRepo.GetByiD(userID); err != nil {
+		return nil, err
+	}
+	progress, err := u.missionRepo.GetProgress(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range progress {
+		p.Percent = missionPercent(p.Progress, p.Target)
+	}
+	return progress, nil
+}
+
+// Track moves the user's active missions forward for a successful
+// transaction and grants the reward of every mission it completes. It
+// returns the completed missions.
+func (u *missionUsecase) Track(userID string, transactionType string, amount int) ([]*model.Mission, error) {
+	now := time.Now()
+	missions, err := u.missionRepo.GetActive(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var completed []*model.Mission
+	for _, mission := range missions {
+		step := missionStep(mission, transactionType, amount)
+		if step == 0 {
+			continue
+		}
+		progress, err := u.missionRepo.AddProgress(mission.MissionID, userID, step, now)
+		if err != nil {
+			if errors.Is(err, repository.ErrMissionCompleted) {
+				continue
+			}
+			return completed, err
+		}
+		if progress < mission.Target {
+			continue
+		}
+
+		if err := u.missionRepo.Complete(mission.MissionID, userID, now); err != nil {
+			if errors.Is(err, repository.ErrMissionCompleted) {
+				continue
+			}
+			return completed, err
+		}
+		adjustment := &model.PointAdjustment{Points: mission.RewardPoints, Description: "Mission reward: " + mission.Title}
+		if _, err := u.pointUsecase.Adjust(userID, adjustment); err != nil {
+			return completed, err
+		}
+		completed = append(completed, mission)
+	}
+
+	if len(completed) > 0 {
+		if user, err := u.userRepo.GetByIDToken(userID); err == nil {
+			for _, mission := range completed {
+				_ = u.notify(user.Token, "Misi Selesai", fmt.Sprintf("Misi \"%s\" selesai. Anda mendapatkan %d poin", mission.Title, mission.RewardPoints))
+			}
+		}
+	}
+	return completed, nil
+}
+
+func NewMissionUsecase(missionRepo repository.MissionRepository, userRepo repository.UserRepository, pointUsecase PointUsecase) MissionUsecase {
+	return &missionUsecase{
+		missionRepo:  missionRepo,
+		userRepo:     userRepo,
+		pointUsecase: pointUsecase,
+		notify:       model.SendFCMNotification,
+	}
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummyMissions = []*model.Mission{
+	{MissionID: 1, Title: "5 transfer minggu ini", TransactionType: "Transfer", Metric: "Count", Target: 5, RewardPoints: 50},
+	{MissionID: 2, Title: "Top up 100rb", TransactionType: "Deposit", Metric: "Amount", Target: 100000, MinAmount: 10000, RewardPoints: 100},
+}
+
+type missionRepoMock struct {
+	mock.Mock
+}
+
+func (r *missionRepoMock) GetAll() ([]*model.Mission, error) {
+	args := r.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Mission), args.Error(1)
+}
+
+func (r *missionRepoMock) GetByID(missionID int) (*model.Mission, error) {
+	args := r.Called(missionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Mission), args.Error(1)
+}
+
+func (r *missionRepoMock) GetActive(at time.Time) ([]*model.Mission, error) {
+	args := r.Called(at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Mission), args.Error(1)
+}
+
+func (r *missionRepoMock) Create(mission *model.Mission) error {
+	args := r.Called(mission)
+	return args.Error(0)
+}
+
+func (r *missionRepoMock) Update(mission *model.Mission) error {
+	args := r.Called(mission)
+	return args.Error(0)
+}
+
+func (r *missionRepoMock) Deactivate(missionID int) error {
+	args := r.Called(missionID)
+	return args.Error(0)
+}
+
+func (r *missionRepoMock) GetProgress(userID string, at time.Time) ([]*model.MissionProgress, error) {
+	args := r.Called(userID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.MissionProgress), args.Error(1)
+}
+
+func (r *missionRepoMock) AddProgress(missionID int, userID string, delta int, at time.Time) (int, error) {
+	args := r.Called(missionID, userID, delta, at)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *missionRepoMock) Complete(missionID int, userID string, at time.Time) error {
+	args := r.Called(missionID, userID, at)
+	return args.Error(0)
+}
+
+type MissionUsecaseTestSuite struct {
+	missionRepoMock *missionRepoMock
+	userRepoMock    *userRepoMock
+	ledgerRepoMock  *pointLedgerRepoMock
+	suite.Suite
+}
+
+func (suite *MissionUsecaseTestSuite) newUsecase() MissionUsecase {
+	pointUsecase := NewPointUsecase(new(pointRuleRepoMock), suite.ledgerRepoMock, suite.userRepoMock, PointConfig{ExpiryMonths: 12})
+	uc := NewMissionUsecase(suite.missionRepoMock, suite.userRepoMock, pointUsecase)
+	uc.(*missionUsecase).notify = func(token string, title string, body string) error {
+		return nil
+	}
+	return uc
+}
+
+func (suite *MissionUsecaseTestSuite) TestCreateMission_InvalidWindow() {
+	now := time.Now()
+	mission := &model.Mission{Title: "Top up", TransactionType: "Deposit", Metric: "Amount", Target: 100000, RewardPoints: 10, StartsAt: now, EndsAt: now}
+
+	err := suite.newUsecase().CreateMission(mission)
+
+	assert.EqualError(suite.T(), err, "mission must end after it starts")
+	suite.missionRepoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *MissionUsecaseTestSuite) TestCreateMission_InvalidMetric() {
+	now := time.Now()
+	mission := &model.Mission{Title: "Top up", TransactionType: "Deposit", Metric: "Volume", Target: 100000, RewardPoints: 10, StartsAt: now, EndsAt: now.AddDate(0, 0, 7)}
+
+	err := suite.newUsecase().CreateMission(mission)
+
+	assert.EqualError(suite.T(), err, "metric must be Count or Amount")
+}
+
+func (suite *MissionUsecaseTestSuite) TestCreateMission_Success() {
+	now := time.Now()
+	mission := &model.Mission{Title: " Top up ", TransactionType: "Deposit", Metric: "Amount", Target: 100000, RewardPoints: 10, StartsAt: now, EndsAt: now.AddDate(0, 0, 7)}
+	suite.missionRepoMock.On("Create", mission).Return(nil)
+
+	err := suite.newUsecase().CreateMission(mission)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Top up", mission.Title)
+	assert.True(suite.T(), mission.Active)
+}
+
+func (suite *MissionUsecaseTestSuite) TestMissionStep() {
+	assert.Equal(suite.T(), 1, missionStep(dummyMissions[0], "Transfer", 5000))
+	assert.Equal(suite.T(), 0, missionStep(dummyMissions[0], "Deposit", 5000))
+	assert.Equal(suite.T(), 50000, missionStep(dummyMissions[1], "Deposit", 50000))
+	assert.Equal(suite.T(), 0, missionStep(dummyMissions[1], "Deposit", 5000))
+}
+
+func (suite *MissionUsecaseTestSuite) TestTrack_ProgressOnly() {
+	suite.missionRepoMock.On("GetActive", mock.AnythingOfType("time.Time")).Return(dummyMissions, nil)
+	suite.missionRepoMock.On("AddProgress", 1, "1", 1, mock.AnythingOfType("time.Time")).Return(3, nil)
+
+	res, err := suite.newUsecase().Track("1", "Transfer", 20000)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), res)
+	suite.missionRepoMock.AssertNotCalled(suite.T(), "Complete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *MissionUsecaseTestSuite) TestTrack_CompletesAndRewards() {
+	suite.missionRepoMock.On("GetActive", mock.AnythingOfType("time.Time")).Return(dummyMissions, nil)
+	suite.missionRepoMock.On("AddProgress", 2, "1", 60000, mock.AnythingOfType("time.Time")).Return(120000, nil)
+	suite.missionRepoMock.On("Complete", 2, "1", mock.AnythingOfType("time.Time")).Return(nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "1").Return(&model.User{ID: "1", Token: "token-1"}, nil)
+	suite.ledgerRepoMock.On("Credit", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		entry := args.Get(0).(*model.PointLedger)
+		assert.Equal(suite.T(), 100, entry.Points)
+		assert.Equal(suite.T(), "Mission reward: Top up 100rb", entry.Description)
+	})
+
+	uc := suite.newUsecase()
+	var sentBody string
+	uc.(*missionUsecase).notify = func(token string, title string, body string) error {
+		sentBody = body
+		return nil
+	}
+
+	res, err := uc.Track("1", "Deposit", 60000)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, 1)
+	assert.Equal(suite.T(), 2, res[0].MissionID)
+	assert.Equal(suite.T(), "Misi \"Top up 100rb\" selesai. Anda mendapatkan 100 poin", sentBody)
+}
+
+func (suite *MissionUsecaseTestSuite) TestTrack_AlreadyCompleted() {
+	suite.missionRepoMock.On("GetActive", mock.AnythingOfType("time.Time")).Return(dummyMissions, nil)
+	suite.missionRepoMock.On("AddProgress", 1, "1", 1, mock.AnythingOfType("time.Time")).Return(0, repository.ErrMissionCompleted)
+
+	res, err := suite.newUsecase().Track("1", "Transfer", 20000)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), res)
+	suite.ledgerRepoMock.AssertNotCalled(suite.T(), "Credit", mock.Anything)
+}
+
+func (suite *MissionUsecaseTestSuite) TestFindProgress_Percent() {
+	progress := []*model.MissionProgress{
+		{Mission: *dummyMissions[0], Progress: 2},
+		{Mission: *dummyMissions[1], Progress: 150000},
+	}
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1"}, nil)
+	suite.missionRepoMock.On("GetProgress", "1", mock.AnythingOfType("time.Time")).Return(progress, nil)
+
+	res, err := suite.newUsecase().FindProgress("1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 40, res[0].Percent)
+	assert.Equal(suite.T(), 100, res[1].Percent)
+}
+
+func (suite *MissionUsecaseTestSuite) SetupTest() {
+	suite.missionRepoMock = new(missionRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.ledgerRepoMock = new(pointLedgerRepoMock)
+}
+
+func TestMissionUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(MissionUsecaseTestSuite))
+}
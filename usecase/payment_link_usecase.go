@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	PaymentLinkOrderPrefix = "PAYLINK-"
+
+	paymentLinkIDSize         = 10
+	paymentLinkOrderIDSize    = 12
+	maxPaymentLinkDescription = 100
+	maxPaymentLinkPayerName   = 50
+	minPaymentLinkAmount      = 1000
+	minGatewayPaymentAmount   = 10000
+	defaultPaymentLinkDays    = 7
+	maxPaymentLinkDays        = 90
+
+	// gatewayPaymentExpiry matches the default expiry of a Snap transaction
+	gatewayPaymentExpiry = 24 * time.Hour
+)
+
+type PaymentLinkUsecase interface {
+	Create(owner *model.User, link *model.PaymentLink) error
+	FindByOwner(ownerID string) ([]*model.PaymentLink, error)
+	FindByID(linkID string) (*model.PaymentLink, error)
+	Deactivate(ownerID string, linkID string) error
+	FindPayments(ownerID string, linkID string) ([]*model.PaymentLinkPayment, error)
+
+	PayWithWallet(payer *model.User, linkID string, amount int) (*model.PaymentLinkPayment, error)
+	StartGatewayPayment(linkID string, payment *model.PaymentLinkPayment) error
+	SettleGatewayPayment(orderID string, grossAmount string) (*model.PaymentLinkPayment, error)
+	FailGatewayPayment(orderID string) error
+	ExpireGatewayPayments() (int, error)
+}
+
+type paymentLinkUsecase struct {
+	linkRepo repository.PaymentLinkRepository
+	userRepo repository.UserRepository
+	holdRepo repository.HoldRepository
+}
+
+// paymentLinkStatus reports Expired or Completed for active links that can no
+// longer be paid, so callers do not have to repeat the checks.
+func paymentLinkStatus(link *model.PaymentLink) {
+	if link.Status != "Active" {
+		return
+	}
+	if !time.Now().Before(link.ExpiredAt) {
+		link.Status = "Expired"
+	} else if link.MaxUses > 0 && link.UseCount >= link.MaxUses {
+		link.Status = "Completed"
+	}
+}
+
+// paymentAmount decides what the payer owes: the fixed link amount, or the
+// amount they chose when the link leaves it open.
+func paymentAmount(link *model.PaymentLink, amount int) (int, error) {
+	if link.Amount > 0 {
+		if amount != 0 && amount != link.Amount {
+			return 0, fmt.Errorf("amount must match the payment link")
+		}
+		return link.Amount, nil
+	}
+	if amount < minPaymentLinkAmount {
+		return 0, fmt.Errorf("minimum payment amount is 1,000")
+	}
+	return amount, nil
+}
+
+func (u *paymentLinkUsecase) Create(owner *model.User, link *model.PaymentLink) error {
+	if link.Amount < 0 || (link.Amount > 0 && link.Amount < minPaymentLinkAmount) {
+		return fmt.Errorf("amount must be 0 or at least 1,000")
+	}
+	link.Description = strings.TrimSpace(link.Description)
+	if link.Description == "" || len(link.Description) > maxPaymentLinkDescription {
+		return fmt.Errorf("description must be 1 - 100 characters")
+	}
+	if link.MaxUses < 0 {
+		return fmt.Errorf("max uses must not be negative")
+	}
+	if link.ExpiresInDays == 0 {
+		link.ExpiresInDays = defaultPaymentLinkDays
+	}
+	if link.ExpiresInDays < 1 || link.ExpiresInDays > maxPaymentLinkDays {
+		return fmt.Errorf("expiry must be 1 - 90 days")
+	}
+
+	code, err := randomCode(envelopeClaimCodeChars, paymentLinkIDSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate payment link id: %v", err)
+	}
+
+	now := time.Now()
+	link.LinkID = "PL" + code
+	link.OwnerID = owner.ID
+	link.OwnerName = owner.Name
+	link.UseCount = 0
+	link.CollectedAmount = 0
+	link.Status = "Active"
+	link.ExpiredAt = now.AddDate(0, 0, link.ExpiresInDays)
+	link.CreatedAt = now
+	return u.linkRepo.Create(link)
+}
+
+func (u *paymentLinkUsecase) FindByOwner(ownerID string) ([]*model.PaymentLink, error) {
+	links, err := u.linkRepo.GetByOwner(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		paymentLinkStatus(link)
+	}
+	return links, nil
+}
+
+func (u *paymentLinkUsecase) FindByID(linkID string) (*model.PaymentLink, error) {
+	link, err := u.linkRepo.GetByID(strings.ToUpper(strings.TrimSpace(linkID)))
+	if err != nil {
+		return nil, err
+	}
+	paymentLinkStatus(link)
+	return link, nil
+}
+
+func (u *paymentLinkUsecase) Deactivate(ownerID string, linkID string) error {
+	return u.linkRepo.Deactivate(strings.ToUpper(linkID), ownerID)
+}
+
+func (u *paymentLinkUsecase) FindPayments(ownerID string, linkID string) ([]*model.PaymentLinkPayment, error) {
+	link, err := u.linkRepo.GetByID(strings.ToUpper(linkID))
+	if err != nil {
+		return nil, err
+	}
+	if link.OwnerID != ownerID {
+		return nil, fmt.Errorf("payment link not found")
+	}
+	return u.linkRepo.GetPayments(link.LinkID)
+}
+
+// payable loads a link and checks it can still take a payment.
+func (u *paymentLinkUsecase) payable(linkID string) (*model.PaymentLink, error) {
+	link, err := u.FindByID(linkID)
+	if err != nil {
+		return nil, err
+	}
+	if link.Status != "Active" {
+		return nil, fmt.Errorf("payment link is no longer available")
+	}
+	return link, nil
+}
+
+func (u *paymentLinkUsecase) PayWithWallet(payer *model.User, linkID string, amount int) (*model.PaymentLinkPayment, error) {
+	link, err := u.payable(linkID)
+	if err != nil {
+		return nil, err
+	}
+	if link.OwnerID == payer.ID {
+		return nil, fmt.Errorf("cannot pay your own payment link")
+	}
+	amount, err = paymentAmount(link, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := availableBalance(u.holdRepo, payer)
+	if err != nil {
+		return nil, err
+	}
+	if available < amount {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+	owner, err := u.userRepo.GetByiD(link.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link owner: %v", err)
+	}
+
+	if err := u.linkRepo.ReserveUse(link.LinkID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	payment := &model.PaymentLinkPayment{
+		LinkID:     link.LinkID,
+		PayerID:    payer.ID,
+		PayerName:  payer.Name,
+		PayerEmail: payer.Email,
+		PayerPhone: payer.Phone_Number,
+		Amount:     amount,
+		Method:     "Wallet",
+		Status:     "Success",
+		CreatedAt:  now,
+		PaidAt:     &now,
+		OwnerID:    owner.ID,
+	}
+	if err := u.linkRepo.CreateWalletPayment(payment); err != nil {
+		if releaseErr := u.linkRepo.ReleaseUse(link.LinkID); releaseErr != nil {
+			return nil, releaseErr
+		}
+		return nil, err
+	}
+
+	if err := u.userRepo.UpdateBalance(payer.ID, payer.Balance-amount); err != nil {
+		return nil, fmt.Errorf("failed to update user balance: %v", err)
+	}
+	if err := u.userRepo.UpdateBalance(owner.ID, owner.Balance+amount); err != nil {
+		return nil, fmt.Errorf("failed to update owner balance: %v", err)
+	}
+	return payment, nil
+}
+
+// StartGatewayPayment reserves a use of the link for a guest and stores a
+// pending payment. The caller opens the gateway transaction with its OrderID.
+func (u *paymentLinkUsecase) StartGatewayPayment(linkID string, payment *model.PaymentLinkPayment) error {
+	link, err := u.payable(linkID)
+	if err != nil {
+		return err
+	}
+	payment.PayerName = strings.TrimSpace(payment.PayerName)
+	if payment.PayerName == "" || len(payment.PayerName) > maxPaymentLinkPayerName {
+		return fmt.Errorf("payer name must be 1 - 50 characters")
+	}
+	amount, err := paymentAmount(link, payment.Amount)
+	if err != nil {
+		return err
+	}
+	if amount < minGatewayPaymentAmount {
+		return fmt.Errorf("minimum gateway payment is 10,000")
+	}
+
+	code, err := randomCode(envelopeClaimCodeChars, paymentLinkOrderIDSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate order id: %v", err)
+	}
+	if err := u.linkRepo.ReserveUse(link.LinkID, time.Now()); err != nil {
+		return err
+	}
+
+	payment.LinkID = link.LinkID
+	payment.PayerID = ""
+	payment.Amount = amount
+	payment.Method = "Gateway"
+	payment.Status = "Pending"
+	payment.OrderID = PaymentLinkOrderPrefix + code
+	payment.CreatedAt = time.Now()
+	payment.OwnerID = link.OwnerID
+	if err := u.linkRepo.CreateGatewayPayment(payment); err != nil {
+		if releaseErr := u.linkRepo.ReleaseUse(link.LinkID); releaseErr != nil {
+			return releaseErr
+		}
+		return err
+	}
+	return nil
+}
+
+// SettleGatewayPayment credits the link owner once the gateway reports a
+// guest payment as settled. Repeated notifications are rejected by the
+// repository, so the owner is only credited once. The reported gross amount
+// must match what the guest was asked to pay.
+func (u *paymentLinkUsecase) SettleGatewayPayment(orderID string, grossAmount string) (*model.PaymentLinkPayment, error) {
+	payment, err := u.linkRepo.GetPaymentByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != "Pending" {
+		return nil, fmt.Errorf("payment is no longer pending")
+	}
+	paid, err := strconv.ParseFloat(grossAmount, 64)
+	if err != nil || paid != float64(payment.Amount) {
+		return nil, fmt.Errorf("gross amount does not match payment")
+	}
+	owner, err := u.userRepo.GetByiD(payment.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link owner: %v", err)
+	}
+
+	now := time.Now()
+	payment.PaidAt = &now
+	if err := u.linkRepo.SettleGatewayPayment(payment); err != nil {
+		return nil, err
+	}
+	if err := u.userRepo.UpdateBalance(owner.ID, owner.Balance+payment.Amount); err != nil {
+		return nil, fmt.Errorf("failed to update owner balance: %v", err)
+	}
+	return payment, nil
+}
+
+func (u *paymentLinkUsecase) FailGatewayPayment(orderID string) error {
+	return u.linkRepo.FailGatewayPayment(orderID)
+}
+
+// ExpireGatewayPayments fails guest payments the gateway never reported on
+// once their Snap transaction has expired, so their link uses are given back.
+func (u *paymentLinkUsecase) ExpireGatewayPayments() (int, error) {
+	orderIDs, err := u.linkRepo.GetStaleGatewayOrders(time.Now().Add(-gatewayPaymentExpiry))
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, orderID := range orderIDs {
+		err := u.linkRepo.FailGatewayPayment(orderID)
+		if err != nil && err.Error() != "payment is no longer pending" {
+			return expired, err
+		}
+		if err == nil {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func NewPaymentLinkUsecase(linkRepo repository.PaymentLinkRepository, userRepo repository.UserRepository, holdRepo repository.HoldRepository) PaymentLinkUsecase {
+	return &paymentLinkUsecase{
+		linkRepo: linkRepo,
+		userRepo: userRepo,
+		holdRepo: holdRepo,
+	}
+}
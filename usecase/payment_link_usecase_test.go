@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type paymentLinkRepoMock struct {
+	mock.Mock
+}
+
+func (r *paymentLinkRepoMock) Create(link *model.PaymentLink) error {
+	args := r.Called(link)
+	return args.Error(0)
+}
+
+func (r *paymentLinkRepoMock) GetByID(linkID string) (*model.PaymentLink, error) {
+	args := r.Called(linkID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PaymentLink), args.Error(1)
+}
+
+func (r *paymentLinkRepoMock) GetByOwner(ownerID string) ([]*model.PaymentLink, error) {
+	args := r.Called(ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PaymentLink), args.Error(1)
+}
+
+func (r *paymentLinkRepoMock) Deactivate(linkID string, ownerID string) error {
+	args := r.Called(linkID, ownerID)
+	return args.Error(0)
+}
+
+func (r *paymentLinkRepoMock) ReserveUse(linkID string, now time.Time) error {
+	args := r.Called(linkID, now)
+	return args.Error(0)
+}
+
+func (r *paymentLinkRepoMock) ReleaseUse(linkID string) error {
+	args := r.Called(linkID)
+	return args.Error(0)
+}
+
+func (r *paymentLinkRepoMock) CreateWalletPayment(payment *model.PaymentLinkPayment) error {
+	args := r.Called(payment)
+	return args.Error(0)
+}
+
+func (r *paymentLinkRepoMock) CreateGatewayPayment(payment *model.PaymentLinkPayment) error {
+	args := r.Called(payment)
+	return args.Error(0)
+}
+
+func (r *paymentLinkRepoMock) GetPaymentByOrderID(orderID string) (*model.PaymentLinkPayment, error) {
+	args := r.Called(orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PaymentLinkPayment), args.Error(1)
+}
+
+func (r *paymentLinkRepoMock) SettleGatewayPayment(payment *model.PaymentLinkPayment) error {
+	args := r.Called(payment)
+	return args.Error(0)
+}
+
+func (r *paymentLinkRepoMock) FailGatewayPayment(orderID string) error {
+	args := r.Called(orderID)
+	return args.Error(0)
+}
+
+func (r *paymentLinkRepoMock) GetStaleGatewayOrders(before time.Time) ([]string, error) {
+	args := r.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (r *paymentLinkRepoMock) GetPayments(linkID string) ([]*model.PaymentLinkPayment, error) {
+	args := r.Called(linkID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PaymentLinkPayment), args.Error(1)
+}
+
+type PaymentLinkUsecaseTestSuite struct {
+	linkRepoMock *paymentLinkRepoMock
+	userRepoMock *userRepoMock
+	holdRepoMock *holdRepoMock
+	suite.Suite
+}
+
+func activePaymentLink(amount int) *model.PaymentLink {
+	return &model.PaymentLink{
+		LinkID:      "PLABCDEFGH2",
+		OwnerID:     "1",
+		Amount:      amount,
+		Description: "Iuran futsal",
+		Status:      "Active",
+		ExpiredAt:   time.Now().Add(time.Hour),
+	}
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestCreate_Success() {
+	link := &model.PaymentLink{Amount: 0, Description: "  Donasi  ", MaxUses: 5}
+	suite.linkRepoMock.On("Create", link).Return(nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.Create(&dummyUser[0], link)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(link.LinkID, "PL"))
+	assert.Equal(suite.T(), "Donasi", link.Description)
+	assert.Equal(suite.T(), "Active", link.Status)
+	assert.Equal(suite.T(), defaultPaymentLinkDays, link.ExpiresInDays)
+	assert.WithinDuration(suite.T(), time.Now().AddDate(0, 0, 7), link.ExpiredAt, time.Minute)
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestCreate_InvalidAmount() {
+	link := &model.PaymentLink{Amount: 500, Description: "Donasi"}
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.Create(&dummyUser[0], link)
+	assert.EqualError(suite.T(), err, "amount must be 0 or at least 1,000")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestFindByID_Completed() {
+	link := activePaymentLink(25000)
+	link.MaxUses = 2
+	link.UseCount = 2
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(link, nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.FindByID("plabcdefgh2")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Completed", res.Status)
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestPayWithWallet_Success() {
+	payer := dummySender
+	owner := dummyUser[0]
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(activePaymentLink(25000), nil)
+	suite.holdRepoMock.On("GetHeldAmount", payer.ID).Return(0, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&owner, nil)
+	suite.linkRepoMock.On("ReserveUse", "PLABCDEFGH2", mock.AnythingOfType("time.Time")).Return(nil)
+	suite.linkRepoMock.On("CreateWalletPayment", mock.AnythingOfType("*model.PaymentLinkPayment")).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", payer.ID, payer.Balance-25000).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", owner.ID, owner.Balance+25000).Return(nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	payment, err := uc.PayWithWallet(&payer, "PLABCDEFGH2", 0)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 25000, payment.Amount)
+	assert.Equal(suite.T(), "Wallet", payment.Method)
+	assert.Equal(suite.T(), "1", payment.OwnerID)
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestPayWithWallet_AmountMismatch() {
+	payer := dummySender
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(activePaymentLink(25000), nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	payment, err := uc.PayWithWallet(&payer, "PLABCDEFGH2", 20000)
+	assert.Nil(suite.T(), payment)
+	assert.EqualError(suite.T(), err, "amount must match the payment link")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestPayWithWallet_Expired() {
+	payer := dummySender
+	link := activePaymentLink(0)
+	link.ExpiredAt = time.Now().Add(-time.Minute)
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(link, nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	payment, err := uc.PayWithWallet(&payer, "PLABCDEFGH2", 5000)
+	assert.Nil(suite.T(), payment)
+	assert.EqualError(suite.T(), err, "payment link is no longer available")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestPayWithWallet_InsufficientBalance() {
+	payer := dummySender
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(activePaymentLink(0), nil)
+	suite.holdRepoMock.On("GetHeldAmount", payer.ID).Return(90000, nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	payment, err := uc.PayWithWallet(&payer, "PLABCDEFGH2", 20000)
+	assert.Nil(suite.T(), payment)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestPayWithWallet_OwnLink() {
+	payer := dummyUser[0]
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(activePaymentLink(25000), nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	payment, err := uc.PayWithWallet(&payer, "PLABCDEFGH2", 0)
+	assert.Nil(suite.T(), payment)
+	assert.EqualError(suite.T(), err, "cannot pay your own payment link")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestPayWithWallet_ReleasesUseOnFailure() {
+	payer := dummySender
+	owner := dummyUser[0]
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(activePaymentLink(25000), nil)
+	suite.holdRepoMock.On("GetHeldAmount", payer.ID).Return(0, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&owner, nil)
+	suite.linkRepoMock.On("ReserveUse", "PLABCDEFGH2", mock.AnythingOfType("time.Time")).Return(nil)
+	suite.linkRepoMock.On("CreateWalletPayment", mock.AnythingOfType("*model.PaymentLinkPayment")).Return(errors.New("failed to insert transaction: db down"))
+	suite.linkRepoMock.On("ReleaseUse", "PLABCDEFGH2").Return(nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	payment, err := uc.PayWithWallet(&payer, "PLABCDEFGH2", 0)
+	assert.Nil(suite.T(), payment)
+	assert.EqualError(suite.T(), err, "failed to insert transaction: db down")
+	suite.linkRepoMock.AssertCalled(suite.T(), "ReleaseUse", "PLABCDEFGH2")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestStartGatewayPayment_Success() {
+	payment := &model.PaymentLinkPayment{PayerName: "Guest", PayerEmail: "guest@mail.com", Amount: 15000}
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(activePaymentLink(0), nil)
+	suite.linkRepoMock.On("ReserveUse", "PLABCDEFGH2", mock.AnythingOfType("time.Time")).Return(nil)
+	suite.linkRepoMock.On("CreateGatewayPayment", payment).Return(nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.StartGatewayPayment("PLABCDEFGH2", payment)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(payment.OrderID, PaymentLinkOrderPrefix))
+	assert.Equal(suite.T(), "Pending", payment.Status)
+	assert.Equal(suite.T(), "Gateway", payment.Method)
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestStartGatewayPayment_BelowGatewayMinimum() {
+	payment := &model.PaymentLinkPayment{PayerName: "Guest", Amount: 5000}
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(activePaymentLink(0), nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.StartGatewayPayment("PLABCDEFGH2", payment)
+	assert.EqualError(suite.T(), err, "minimum gateway payment is 10,000")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestSettleGatewayPayment_Success() {
+	owner := dummyUser[0]
+	payment := &model.PaymentLinkPayment{PaymentID: 4, LinkID: "PLABCDEFGH2", Amount: 15000, Status: "Pending", OrderID: "PAYLINK-1", OwnerID: "1"}
+	suite.linkRepoMock.On("GetPaymentByOrderID", "PAYLINK-1").Return(payment, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&owner, nil)
+	suite.linkRepoMock.On("SettleGatewayPayment", payment).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", owner.ID, owner.Balance+15000).Return(nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.SettleGatewayPayment("PAYLINK-1", "15000.00")
+	assert.Nil(suite.T(), err)
+	assert.NotNil(suite.T(), res.PaidAt)
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestSettleGatewayPayment_NotPending() {
+	payment := &model.PaymentLinkPayment{PaymentID: 4, Status: "Success", OrderID: "PAYLINK-1"}
+	suite.linkRepoMock.On("GetPaymentByOrderID", "PAYLINK-1").Return(payment, nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.SettleGatewayPayment("PAYLINK-1", "15000.00")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "payment is no longer pending")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestSettleGatewayPayment_AmountMismatch() {
+	payment := &model.PaymentLinkPayment{PaymentID: 4, Amount: 15000, Status: "Pending", OrderID: "PAYLINK-1", OwnerID: "1"}
+	suite.linkRepoMock.On("GetPaymentByOrderID", "PAYLINK-1").Return(payment, nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.SettleGatewayPayment("PAYLINK-1", "10000.00")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "gross amount does not match payment")
+	suite.linkRepoMock.AssertNotCalled(suite.T(), "SettleGatewayPayment", mock.Anything)
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", mock.Anything, mock.Anything)
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestExpireGatewayPayments_SkipsSettled() {
+	suite.linkRepoMock.On("GetStaleGatewayOrders", mock.AnythingOfType("time.Time")).Return([]string{"PAYLINK-A", "PAYLINK-B"}, nil)
+	suite.linkRepoMock.On("FailGatewayPayment", "PAYLINK-A").Return(nil)
+	suite.linkRepoMock.On("FailGatewayPayment", "PAYLINK-B").Return(errors.New("payment is no longer pending"))
+
+	expired, err := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock).ExpireGatewayPayments()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, expired)
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) TestFindPayments_NotOwner() {
+	suite.linkRepoMock.On("GetByID", "PLABCDEFGH2").Return(activePaymentLink(0), nil)
+	uc := NewPaymentLinkUsecase(suite.linkRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.FindPayments("2", "PLABCDEFGH2")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "payment link not found")
+}
+
+func (suite *PaymentLinkUsecaseTestSuite) SetupTest() {
+	suite.linkRepoMock = new(paymentLinkRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+}
+
+func TestPaymentLinkUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentLinkUsecaseTestSuite))
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxPockets          = 10
+	maxPocketNameLength = 30
+	maxAutoSavePercent  = 50
+)
+
+type PocketUsecase interface {
+	FindPockets(userID string) ([]*model.Pocket, error)
+	CreatePocket(pocket *model.Pocket) error
+	UpdatePocket(pocket *model.Pocket) error
+	DeletePocket(user *model.User, pocketID int) error
+	MoveIn(user *model.User, pocketID int, amount int) (*model.PocketMovement, error)
+	MoveOut(user *model.User, pocketID int, amount int) (*model.PocketMovement, error)
+	AutoSave(userID string, amount int) error
+}
+
+type pocketUsecase struct {
+	pocketRepo repository.PocketRepository
+	userRepo   repository.UserRepository
+	holdRepo   repository.HoldRepository
+}
+
+func pocketProgress(pocket *model.Pocket) {
+	if pocket.TargetAmount <= 0 {
+		return
+	}
+	pocket.Progress = pocket.Balance * 100 / pocket.TargetAmount
+	if pocket.Progress > 100 {
+		pocket.Progress = 100
+	}
+}
+
+func validatePocket(pocket *model.Pocket) error {
+	pocket.Name = strings.TrimSpace(pocket.Name)
+	if pocket.Name == "" || len(pocket.Name) > maxPocketNameLength {
+		return fmt.Errorf("pocket name must be 1 - 30 characters")
+	}
+	if pocket.TargetAmount < 0 {
+		return fmt.Errorf("target amount must not be negative")
+	}
+	if pocket.AutoSavePercent < 0 || pocket.AutoSavePercent > maxAutoSavePercent {
+		return fmt.Errorf("auto save percent must be 0 - 50")
+	}
+	if pocket.Deadline != "" {
+		deadline, err := time.Parse("2006-01-02", pocket.Deadline)
+		if err != nil {
+			return fmt.Errorf("deadline must use YYYY-MM-DD format")
+		}
+		if deadline.Format("2006-01-02") < time.Now().Format("2006-01-02") {
+			return fmt.Errorf("deadline must not be in the past")
+		}
+	}
+	return nil
+}
+
+func (u *pocketUsecase) FindPockets(userID string) ([]*model.Pocket, error) {
+	pockets, err := u.pocketRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, pocket := range pockets {
+		pocketProgress(pocket)
+	}
+	return pockets, nil
+}
+
+func (u *pocketUsecase) CreatePocket(pocket *model.Pocket) error {
+	if err := validatePocket(pocket); err != nil {
+		return err
+	}
+
+	pockets, err := u.pocketRepo.GetByUserID(pocket.UserID)
+	if err != nil {
+		return err
+	}
+	if len(pockets) >= maxPockets {
+		return fmt.Errorf("maximum 10 pockets per user")
+	}
+
+	pocket.Balance = 0
+	return u.pocketRepo.Create(pocket)
+}
+
+func (u *pocketUsecase) UpdatePocket(pocket *model.Pocket) error {
+	if err := validatePocket(pocket); err != nil {
+		return err
+	}
+	if err := u.pocketRepo.Update(pocket); err != nil {
+		return err
+	}
+
+	updated, err := u.pocketRepo.GetByID(pocket.PocketID, pocket.UserID)
+	if err != nil {
+		return err
+	}
+	*pocket = *updated
+	pocketProgress(pocket)
+	return nil
+}
+
+// DeletePocket returns any remaining pocket money to the main balance
+// before removing the pocket.
+func (u *pocketUsecase) DeletePocket(user *model.User, pocketID int) error {
+	pocket, err := u.pocketRepo.GetByID(pocketID, user.ID)
+	if err != nil {
+		return err
+	}
+	if pocket.Balance > 0 {
+		if _, err := u.move(user, pocket, -pocket.Balance, "Pocket Out"); err != nil {
+			return err
+		}
+	}
+	return u.pocketRepo.Delete(pocketID, user.ID)
+}
+
+func (u *pocketUsecase) MoveIn(user *model.User, pocketID int, amount int) (*model.PocketMovement, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	pocket, err := u.pocketRepo.GetByID(pocketID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	available, err := availableBalance(u.holdRepo, user)
+	if err != nil {
+		return nil, err
+	}
+	if available < amount {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+	return u.move(user, pocket, amount, "Pocket In")
+}
+
+func (u *pocketUsecase) MoveOut(user *model.User, pocketID int, amount int) (*model.PocketMovement, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	pocket, err := u.pocketRepo.GetByID(pocketID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pocket.Balance < amount {
+		return nil, fmt.Errorf("insufficient pocket balance")
+	}
+	return u.move(user, pocket, -amount, "Pocket Out")
+}
+
+// AutoSave sweeps the configured percentage of an incoming amount into every
+// pocket with an auto-save rule, as long as the main balance covers it.
+func (u *pocketUsecase) AutoSave(userID string, amount int) error {
+	pockets, err := u.pocketRepo.GetAutoSavePockets(userID)
+	if err != nil {
+		return err
+	}
+	if len(pockets) == 0 {
+		return nil
+	}
+
+	user, err := u.userRepo.GetByiD(userID)
+	if err != nil {
+		return err
+	}
+	available, err := availableBalance(u.holdRepo, user)
+	if err != nil {
+		return err
+	}
+	for _, pocket := range pockets {
+		save := amount * pocket.AutoSavePercent / 100
+		if save <= 0 || available < save {
+			continue
+		}
+		if _, err := u.move(user, pocket, save, "Auto Save"); err != nil {
+			return err
+		}
+		available -= save
+	}
+	return nil
+}
+
+// move shifts a signed amount between the main balance and a pocket.
+// Positive amounts go into the pocket, negative amounts come out of it.
+func (u *pocketUsecase) move(user *model.User, pocket *model.Pocket, amount int, txType string) (*model.PocketMovement, error) {
+	movement := &model.PocketMovement{
+		PocketID:        pocket.PocketID,
+		UserID:          user.ID,
+		PocketName:      pocket.Name,
+		Amount:          amount,
+		TransactionType: txType,
+	}
+	if err := u.pocketRepo.Move(movement); err != nil {
+		return nil, err
+	}
+
+	err := u.userRepo.UpdateBalance(user.ID, user.Balance-amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user balance: %v", err)
+	}
+	user.Balance -= amount
+	pocket.Balance += amount
+	return movement, nil
+}
+
+func NewPocketUsecase(pocketRepo repository.PocketRepository, userRepo repository.UserRepository, holdRepo repository.HoldRepository) PocketUsecase {
+	return &pocketUsecase{
+		pocketRepo: pocketRepo,
+		userRepo:   userRepo,
+		holdRepo:   holdRepo,
+	}
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type pocketRepoMock struct {
+	mock.Mock
+}
+
+func (r *pocketRepoMock) GetByUserID(userID string) ([]*model.Pocket, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Pocket), args.Error(1)
+}
+
+func (r *pocketRepoMock) GetByID(pocketID int, userID string) (*model.Pocket, error) {
+	args := r.Called(pocketID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Pocket), args.Error(1)
+}
+
+func (r *pocketRepoMock) GetAutoSavePockets(userID string) ([]*model.Pocket, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Pocket), args.Error(1)
+}
+
+func (r *pocketRepoMock) Create(pocket *model.Pocket) error {
+	args := r.Called(pocket)
+	return args.Error(0)
+}
+
+func (r *pocketRepoMock) Update(pocket *model.Pocket) error {
+	args := r.Called(pocket)
+	return args.Error(0)
+}
+
+func (r *pocketRepoMock) Delete(pocketID int, userID string) error {
+	args := r.Called(pocketID, userID)
+	return args.Error(0)
+}
+
+func (r *pocketRepoMock) Move(movement *model.PocketMovement) error {
+	args := r.Called(movement)
+	return args.Error(0)
+}
+
+type PocketUsecaseTestSuite struct {
+	pocketRepoMock *pocketRepoMock
+	userRepoMock   *userRepoMock
+	holdRepoMock   *holdRepoMock
+	suite.Suite
+}
+
+func (suite *PocketUsecaseTestSuite) TestFindPockets_Progress() {
+	pockets := []*model.Pocket{{PocketID: 1, UserID: "2", Name: "Liburan", Balance: 250000, TargetAmount: 1000000}}
+	suite.pocketRepoMock.On("GetByUserID", "2").Return(pockets, nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.FindPockets("2")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 25, res[0].Progress)
+}
+
+func (suite *PocketUsecaseTestSuite) TestCreatePocket_Success() {
+	pocket := &model.Pocket{UserID: "2", Name: " Liburan ", TargetAmount: 1000000, AutoSavePercent: 10}
+	suite.pocketRepoMock.On("GetByUserID", "2").Return([]*model.Pocket{}, nil)
+	suite.pocketRepoMock.On("Create", pocket).Return(nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.CreatePocket(pocket)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Liburan", pocket.Name)
+}
+
+func (suite *PocketUsecaseTestSuite) TestCreatePocket_InvalidAutoSave() {
+	pocket := &model.Pocket{UserID: "2", Name: "Liburan", AutoSavePercent: 80}
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.CreatePocket(pocket)
+
+	assert.EqualError(suite.T(), err, "auto save percent must be 0 - 50")
+}
+
+func (suite *PocketUsecaseTestSuite) TestCreatePocket_PastDeadline() {
+	pocket := &model.Pocket{UserID: "2", Name: "Liburan", Deadline: "2000-01-01"}
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.CreatePocket(pocket)
+
+	assert.EqualError(suite.T(), err, "deadline must not be in the past")
+}
+
+func (suite *PocketUsecaseTestSuite) TestCreatePocket_LimitReached() {
+	pockets := make([]*model.Pocket, maxPockets)
+	suite.pocketRepoMock.On("GetByUserID", "2").Return(pockets, nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.CreatePocket(&model.Pocket{UserID: "2", Name: "Liburan"})
+
+	assert.EqualError(suite.T(), err, "maximum 10 pockets per user")
+}
+
+func (suite *PocketUsecaseTestSuite) TestMoveIn_Success() {
+	user := dummySender
+	pocket := &model.Pocket{PocketID: 1, UserID: user.ID, Name: "Liburan"}
+	suite.pocketRepoMock.On("GetByID", 1, user.ID).Return(pocket, nil)
+	suite.pocketRepoMock.On("Move", mock.AnythingOfType("*model.PocketMovement")).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, 70000).Return(nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.MoveIn(&user, 1, 30000)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Pocket In", res.TransactionType)
+	assert.Equal(suite.T(), 30000, res.Amount)
+	assert.Equal(suite.T(), 70000, user.Balance)
+}
+
+func (suite *PocketUsecaseTestSuite) TestMoveIn_InsufficientBalance() {
+	user := dummySender
+	pocket := &model.Pocket{PocketID: 1, UserID: user.ID, Name: "Liburan"}
+	suite.pocketRepoMock.On("GetByID", 1, user.ID).Return(pocket, nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.MoveIn(&user, 1, 200000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+}
+
+func (suite *PocketUsecaseTestSuite) TestMoveIn_BalanceHeld() {
+	user := dummySender
+	pocket := &model.Pocket{PocketID: 1, UserID: user.ID, Name: "Liburan"}
+	suite.pocketRepoMock.On("GetByID", 1, user.ID).Return(pocket, nil)
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(80000, nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.MoveIn(&user, 1, 30000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.pocketRepoMock.AssertNotCalled(suite.T(), "Move", mock.Anything)
+}
+
+func (suite *PocketUsecaseTestSuite) TestMoveOut_Success() {
+	user := dummySender
+	pocket := &model.Pocket{PocketID: 1, UserID: user.ID, Name: "Liburan", Balance: 50000}
+	suite.pocketRepoMock.On("GetByID", 1, user.ID).Return(pocket, nil)
+	suite.pocketRepoMock.On("Move", mock.AnythingOfType("*model.PocketMovement")).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, 120000).Return(nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.MoveOut(&user, 1, 20000)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), -20000, res.Amount)
+	assert.Equal(suite.T(), 30000, pocket.Balance)
+}
+
+func (suite *PocketUsecaseTestSuite) TestMoveOut_InsufficientPocketBalance() {
+	user := dummySender
+	pocket := &model.Pocket{PocketID: 1, UserID: user.ID, Name: "Liburan", Balance: 10000}
+	suite.pocketRepoMock.On("GetByID", 1, user.ID).Return(pocket, nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	res, err := uc.MoveOut(&user, 1, 20000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient pocket balance")
+}
+
+func (suite *PocketUsecaseTestSuite) TestDeletePocket_ReturnsBalance() {
+	user := dummySender
+	pocket := &model.Pocket{PocketID: 1, UserID: user.ID, Name: "Liburan", Balance: 50000}
+	suite.pocketRepoMock.On("GetByID", 1, user.ID).Return(pocket, nil)
+	suite.pocketRepoMock.On("Move", mock.AnythingOfType("*model.PocketMovement")).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, 150000).Return(nil)
+	suite.pocketRepoMock.On("Delete", 1, user.ID).Return(nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.DeletePocket(&user, 1)
+
+	assert.NoError(suite.T(), err)
+	suite.userRepoMock.AssertCalled(suite.T(), "UpdateBalance", user.ID, 150000)
+}
+
+func (suite *PocketUsecaseTestSuite) TestAutoSave_SweepsPercentage() {
+	user := dummySender
+	pockets := []*model.Pocket{{PocketID: 1, UserID: user.ID, Name: "Liburan", AutoSavePercent: 10}}
+	suite.pocketRepoMock.On("GetAutoSavePockets", user.ID).Return(pockets, nil)
+	suite.userRepoMock.On("GetByiD", user.ID).Return(&user, nil)
+	suite.pocketRepoMock.On("Move", mock.AnythingOfType("*model.PocketMovement")).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, 95000).Return(nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.AutoSave(user.ID, 50000)
+
+	assert.NoError(suite.T(), err)
+	suite.userRepoMock.AssertCalled(suite.T(), "UpdateBalance", user.ID, 95000)
+}
+
+func (suite *PocketUsecaseTestSuite) TestAutoSave_NoRules() {
+	suite.pocketRepoMock.On("GetAutoSavePockets", "2").Return([]*model.Pocket{}, nil)
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.AutoSave("2", 50000)
+
+	assert.NoError(suite.T(), err)
+	suite.userRepoMock.AssertNotCalled(suite.T(), "GetByiD", "2")
+}
+
+func (suite *PocketUsecaseTestSuite) TestAutoSave_Failed() {
+	suite.pocketRepoMock.On("GetAutoSavePockets", "2").Return(nil, errors.New("failed to get pockets: failed"))
+
+	uc := NewPocketUsecase(suite.pocketRepoMock, suite.userRepoMock, suite.holdRepoMock)
+	err := uc.AutoSave("2", 50000)
+
+	assert.EqualError(suite.T(), err, "failed to get pockets: failed")
+}
+
+func (suite *PocketUsecaseTestSuite) SetupTest() {
+	suite.pocketRepoMock = new(pocketRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.holdRepoMock.On("GetHeldAmount", mock.Anything).Return(0, nil)
+}
+
+func TestPocketUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(PocketUsecaseTestSuite))
+}
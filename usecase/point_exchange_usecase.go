@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxRewardName        = 100
+	maxRewardCategory    = 30
+	maxRewardDescription = 500
+	maxRewardImageURL    = 500
+)
+
+// rewardFulfillmentTypes are the ways a redeemed reward can reach the user.
+var rewardFulfillmentTypes = map[string]bool{
+	"None":         true,
+	"Voucher Code": true,
+	"Provider":     true,
+}
+
+type PointExchangeUsecase interface {
+	FindCatalog(category string) ([]*model.PointExchange, error)
+	FindCategories() ([]*model.PointExchangeCategory, error)
+	FindReward(peID int) (*model.PointExchange, error)
+	FindAll() ([]*model.PointExchange, error)
+	Create(pe *model.PointExchange) error
+	Update(pe *model.PointExchange) error
+	Deactivate(peID int) error
+}
+
+type pointExchangeUsecase struct {
+	pointExchangeRepo repository.PointExchangeRepository
+	providerEnabled   bool
+}
+
+// rewardAvailable reports whether a reward can be redeemed at the given time.
+// Stock is checked separately when the reward is taken.
+func rewardAvailable(pe *model.PointExchange, at time.Time) bool {
+	if !pe.Active {
+		return false
+	}
+	if pe.StartsAt != nil && pe.StartsAt.After(at) {
+		return false
+	}
+	return pe.EndsAt == nil || pe.EndsAt.After(at)
+}
+
+func validatePointExchange(pe *model.PointExchange, providerEnabled bool) error {
+	pe.Reward = strings.TrimSpace(pe.Reward)
+	if pe.Reward == "" || len(pe.Reward) > maxRewardName {
+		return fmt.Errorf("reward must be 1 - 100 characters")
+	}
+	if pe.Price <= 0 {
+		return fmt.Errorf("price must be greater than 0")
+	}
+	pe.Category = strings.TrimSpace(pe.Category)
+	if pe.Category == "" || len(pe.Category) > maxRewardCategory {
+		return fmt.Errorf("category must be 1 - 30 characters")
+	}
+	pe.Description = strings.TrimSpace(pe.Description)
+	if len(pe.Description) > maxRewardDescription {
+		return fmt.Errorf("description must be at most 500 characters")
+	}
+	pe.ImageURL = strings.TrimSpace(pe.ImageURL)
+	if pe.ImageURL != "" {
+		image, err := url.Parse(pe.ImageURL)
+		if err != nil || (image.Scheme != "http" && image.Scheme != "https") || image.Host == "" || len(pe.ImageURL) > maxRewardImageURL {
+			return fmt.Errorf("image url must be a valid http or https address")
+		}
+	}
+	if pe.FulfillmentType == "" {
+		pe.FulfillmentType = "None"
+	}
+	if !rewardFulfillmentTypes[pe.FulfillmentType] {
+		return fmt.Errorf("fulfillment type must be None, Voucher Code or Provider")
+	}
+	if pe.FulfillmentType == "Provider" && !providerEnabled {
+		return fmt.Errorf("no reward provider is configured")
+	}
+	if pe.Stock != nil && *pe.Stock < 0 {
+		return fmt.Errorf("stock must not be negative")
+	}
+	if pe.StartsAt != nil && pe.EndsAt != nil && !pe.EndsAt.After(*pe.StartsAt) {
+		return fmt.Errorf("reward must end after it starts")
+	}
+	return nil
+}
+
+func (u *pointExchangeUsecase) FindCatalog(category string) ([]*model.PointExchange, error) {
+	return u.pointExchangeRepo.GetCatalog(strings.TrimSpace(category), time.Now())
+}
+
+func (u *pointExchangeUsecase) FindCategories() ([]*model.PointExchangeCategory, error) {
+	return u.pointExchangeRepo.GetCategories(time.Now())
+}
+
+// FindReward returns a reward from the public catalog. Rewards that are
+// inactive or outside their window are reported as not found.
+func (u *pointExchangeUsecase) FindReward(peID int) (*model.PointExchange, error) {
+	pe, err := u.pointExchangeRepo.GetByID(peID)
+	if err != nil {
+		return nil, err
+	}
+	if !rewardAvailable(pe, time.Now()) {
+		return nil, fmt.Errorf("point exchange not found")
+	}
+	return pe, nil
+}
+
+func (u *pointExchangeUsecase) FindAll() ([]*model.PointExchange, error) {
+	return u.pointExchangeRepo.GetAll()
+}
+
+func (u *pointExchangeUsecase) Create(pe *model.PointExchange) error {
+	if err := validatePointExchange(pe, u.providerEnabled); err != nil {
+		return err
+	}
+	pe.Active = true
+	pe.CreatedAt = time.Now()
+	return u.pointExchangeRepo.Create(pe)
+}
+
+func (u *pointExchangeUsecase) Update(pe *model.PointExchange) error {
+	if err := validatePointExchange(pe, u.providerEnabled); err != nil {
+		return err
+	}
+	if err := u.pointExchangeRepo.Update(pe); err != nil {
+		return err
+	}
+
+	updated, err := u.pointExchangeRepo.GetByID(pe.PE_ID)
+	if err != nil {
+		return err
+	}
+	*pe = *updated
+	return nil
+}
+
+func (u *pointExchangeUsecase) Deactivate(peID int) error {
+	return u.pointExchangeRepo.Deactivate(peID)
+}
+
+// NewPointExchangeUsecase takes whether a reward provider is wired, since
+// rewards fulfilled by a provider cannot be delivered without one.
+func NewPointExchangeUsecase(pointExchangeRepo repository.PointExchangeRepository, providerEnabled bool) PointExchangeUsecase {
+	return &pointExchangeUsecase{
+		pointExchangeRepo: pointExchangeRepo,
+		providerEnabled:   providerEnabled,
+	}
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type pointExchangeRepoMock struct {
+	mock.Mock
+}
+
+func (r *pointExchangeRepoMock) GetCatalog(category string, at time.Time) ([]*model.PointExchange, error) {
+	args := r.Called(category, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointExchange), args.Error(1)
+}
+
+func (r *pointExchangeRepoMock) GetCategories(at time.Time) ([]*model.PointExchangeCategory, error) {
+	args := r.Called(at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointExchangeCategory), args.Error(1)
+}
+
+func (r *pointExchangeRepoMock) GetAll() ([]*model.PointExchange, error) {
+	args := r.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointExchange), args.Error(1)
+}
+
+func (r *pointExchangeRepoMock) GetByID(peID int) (*model.PointExchange, error) {
+	args := r.Called(peID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PointExchange), args.Error(1)
+}
+
+func (r *pointExchangeRepoMock) Create(pe *model.PointExchange) error {
+	args := r.Called(pe)
+	return args.Error(0)
+}
+
+func (r *pointExchangeRepoMock) Update(pe *model.PointExchange) error {
+	args := r.Called(pe)
+	return args.Error(0)
+}
+
+func (r *pointExchangeRepoMock) Deactivate(peID int) error {
+	args := r.Called(peID)
+	return args.Error(0)
+}
+
+type PointExchangeUsecaseTestSuite struct {
+	pointExchangeRepoMock *pointExchangeRepoMock
+	suite.Suite
+}
+
+func (suite *PointExchangeUsecaseTestSuite) TestFindCatalog_TrimsCategory() {
+	catalog := []*model.PointExchange{{PE_ID: 1, Reward: "10k Pulsa", Price: 100, Category: "Pulsa", Active: true}}
+	suite.pointExchangeRepoMock.On("GetCatalog", "Pulsa", mock.AnythingOfType("time.Time")).Return(catalog, nil)
+
+	res, err := NewPointExchangeUsecase(suite.pointExchangeRepoMock, true).FindCatalog(" Pulsa ")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), catalog, res)
+}
+
+func (suite *PointExchangeUsecaseTestSuite) TestFindReward_OutsideWindow() {
+	startsAt := time.Now().Add(time.Hour)
+	pe := &model.PointExchange{PE_ID: 1, Reward: "Ramadan Hamper", Price: 500, Category: "Hamper", Active: true, StartsAt: &startsAt}
+	suite.pointExchangeRepoMock.On("GetByID", 1).Return(pe, nil)
+
+	res, err := NewPointExchangeUsecase(suite.pointExchangeRepoMock, true).FindReward(1)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "point exchange not found")
+}
+
+func (suite *PointExchangeUsecaseTestSuite) TestCreate_Success() {
+	stock := 10
+	pe := &model.PointExchange{Reward: " Voucher Kopi ", Price: 200, Category: "Voucher", ImageURL: "https://cdn.example.com/kopi.png", Stock: &stock}
+	suite.pointExchangeRepoMock.On("Create", pe).Return(nil)
+
+	err := NewPointExchangeUsecase(suite.pointExchangeRepoMock, true).Create(pe)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Voucher Kopi", pe.Reward)
+	assert.Equal(suite.T(), "None", pe.FulfillmentType)
+	assert.True(suite.T(), pe.Active)
+}
+
+func (suite *PointExchangeUsecaseTestSuite) TestCreate_Invalid() {
+	stock := -1
+	endsAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	startsAt := endsAt.Add(time.Hour)
+	cases := map[string]*model.PointExchange{
+		"reward must be 1 - 100 characters":                       {Reward: " ", Price: 100, Category: "Pulsa"},
+		"price must be greater than 0":                            {Reward: "10k Pulsa", Category: "Pulsa"},
+		"category must be 1 - 30 characters":                      {Reward: "10k Pulsa", Price: 100},
+		"image url must be a valid http or https address":         {Reward: "10k Pulsa", Price: 100, Category: "Pulsa", ImageURL: "ftp://cdn/pulsa.png"},
+		"fulfillment type must be None, Voucher Code or Provider": {Reward: "10k Pulsa", Price: 100, Category: "Pulsa", FulfillmentType: "Courier"},
+		"stock must not be negative":                              {Reward: "10k Pulsa", Price: 100, Category: "Pulsa", Stock: &stock},
+		"reward must end after it starts":                         {Reward: "10k Pulsa", Price: 100, Category: "Pulsa", StartsAt: &startsAt, EndsAt: &endsAt},
+	}
+	for expected, pe := range cases {
+		err := NewPointExchangeUsecase(suite.pointExchangeRepoMock, true).Create(pe)
+		assert.EqualError(suite.T(), err, expected)
+	}
+	suite.pointExchangeRepoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *PointExchangeUsecaseTestSuite) TestCreate_ProviderNotConfigured() {
+	pe := &model.PointExchange{Reward: "10k Pulsa", Price: 100, Category: "Pulsa", FulfillmentType: "Provider"}
+
+	err := NewPointExchangeUsecase(suite.pointExchangeRepoMock, false).Create(pe)
+
+	assert.EqualError(suite.T(), err, "no reward provider is configured")
+	suite.pointExchangeRepoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *PointExchangeUsecaseTestSuite) TestUpdate_ReloadsReward() {
+	pe := &model.PointExchange{PE_ID: 1, Reward: "10k Pulsa", Price: 120, Category: "Pulsa", Active: true}
+	updated := &model.PointExchange{PE_ID: 1, Reward: "10k Pulsa", Price: 120, Category: "Pulsa", Active: true, CreatedAt: time.Now()}
+	suite.pointExchangeRepoMock.On("Update", pe).Return(nil)
+	suite.pointExchangeRepoMock.On("GetByID", 1).Return(updated, nil)
+
+	err := NewPointExchangeUsecase(suite.pointExchangeRepoMock, true).Update(pe)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), updated, pe)
+}
+
+func (suite *PointExchangeUsecaseTestSuite) SetupTest() {
+	suite.pointExchangeRepoMock = new(pointExchangeRepoMock)
+}
+
+func TestPointExchangeUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(PointExchangeUsecaseTestSuite))
+}
@@ -0,0 +1,524 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxPointRuleName               = 50
+	maxPointPercentBps             = 10000
+	maxPointDescription            = 100
+	maxPointTransferNote           = 100
+	defaultPointExpiryMonths       = 12
+	defaultPointConversionRate     = 1
+	defaultPointConversionDailyCap = 50000
+	defaultPointTransferDailyCap   = 10000
+	pointHistoryLimit              = 100
+	pointExpiryBatch               = 500
+	pointExpiryNotice              = 7 * 24 * time.Hour
+)
+
+// PointConfig holds the tunable parts of the points program. Values that are
+// not positive fall back to the defaults.
+type PointConfig struct {
+	// ExpiryMonths is how long earned points stay usable.
+	ExpiryMonths int
+	// ConversionRate is the balance, in rupiah, one point converts into.
+	ConversionRate int
+	// ConversionDailyCap and TransferDailyCap limit the points a user can
+	// convert or send per day.
+	ConversionDailyCap int
+	TransferDailyCap   int
+}
+
+// pointEarningTypes are the transaction types that can earn loyalty points.
+var pointEarningTypes = map[string]bool{
+	"Deposit":          true,
+	"Transfer":         true,
+	"Merchant Payment": true,
+}
+
+// defaultPointRules keep the flat bonus deposits and transfers earned before
+// rules were configurable: 20 points from Rp 50.000.
+var defaultPointRules = []model.PointRule{
+	{Name: "Default", TransactionType: "Deposit", MinAmount: 50000, Points: 20},
+	{Name: "Default", TransactionType: "Transfer", MinAmount: 50000, Points: 20},
+}
+
+type PointUsecase interface {
+	SeedDefaultRules() error
+	FindRules() ([]*model.PointRule, error)
+	CreateRule(rule *model.PointRule) error
+	UpdateRule(rule *model.PointRule) error
+	DeactivateRule(ruleID int) error
+	Award(userID string, transactionType string, transactionID int, amount int) (int, error)
+	FindHistory(userID string) (*model.PointHistory, error)
+	Adjust(userID string, adjustment *model.PointAdjustment) (*model.PointLedger, error)
+	Refund(userID string, transactionID int, points int, expiresAt *time.Time, description string) (*model.PointLedger, error)
+	ExpirePoints() (int, error)
+	NotifyExpiringSoon() (int, error)
+	Convert(user *model.User, points int) (*model.PointMovement, error)
+	Transfer(sender *model.User, transfer *model.PointTransferRequest) (*model.PointMovement, error)
+}
+
+type pointUsecase struct {
+	ruleRepo   repository.PointRuleRepository
+	ledgerRepo repository.PointLedgerRepository
+	userRepo   repository.UserRepository
+	config     PointConfig
+	notify     func(token string, title string, body string) error
+}
+
+func validatePointRule(rule *model.PointRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || len(rule.Name) > maxPointRuleName {
+		return fmt.Errorf("rule name must be 1 - 50 characters")
+	}
+	if !pointEarningTypes[rule.TransactionType] {
+		return fmt.Errorf("transaction type must be Deposit, Transfer or Merchant Payment")
+	}
+	if rule.MinAmount < 0 || rule.Points < 0 || rule.MaxPoints < 0 {
+		return fmt.Errorf("amounts and points must not be negative")
+	}
+	if rule.PercentBps < 0 || rule.PercentBps > maxPointPercentBps {
+		return fmt.Errorf("percent must be 0 - 10000 bps")
+	}
+	if rule.Points == 0 && rule.PercentBps == 0 {
+		return fmt.Errorf("rule must award fixed or percentage points")
+	}
+	if rule.StartsAt != nil && rule.EndsAt != nil && !rule.EndsAt.After(*rule.StartsAt) {
+		return fmt.Errorf("campaign must end after it starts")
+	}
+	return nil
+}
+
+// earnedPoints applies the rules that an amount qualifies for. Rules sharing
+// a name are tiers and only the highest tier reached counts; rules with
+// different names stack.
+func earnedPoints(rules []*model.PointRule, amount int) int {
+	tiers := map[string]*model.PointRule{}
+	for _, rule := range rules {
+		if amount < rule.MinAmount {
+			continue
+		}
+		if best, ok := tiers[rule.Name]; !ok || rule.MinAmount > best.MinAmount {
+			tiers[rule.Name] = rule
+		}
+	}
+
+	total := 0
+	for _, rule := range tiers {
+		points := rule.Points + amount*rule.PercentBps/10000
+		if rule.MaxPoints > 0 && points > rule.MaxPoints {
+			points = rule.MaxPoints
+		}
+		total += points
+	}
+	return total
+}
+
+// SeedDefaultRules creates the default rules on a database without any rule
+// yet. Once rules exist, deactivated ones included, nothing is changed.
+func (u *pointUsecase) SeedDefaultRules() error {
+	rules, err := u.ruleRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	if len(rules) > 0 {
+		return nil
+	}
+	for _, rule := range defaultPointRules {
+		rule := rule
+		rule.Active = true
+		rule.CreatedAt = time.Now()
+		if err := u.ruleRepo.Create(&rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *pointUsecase) FindRules() ([]*model.PointRule, error) {
+	return u.ruleRepo.GetAll()
+}
+
+func (u *pointUsecase) CreateRule(rule *model.PointRule) error {
+	if err := validatePointRule(rule); err != nil {
+		return err
+	}
+	rule.Active = true
+	rule.CreatedAt = time.Now()
+	return u.ruleRepo.Create(rule)
+}
+
+func (u *pointUsecase) UpdateRule(rule *model.PointRule) error {
+	if err := validatePointRule(rule); err != nil {
+		return err
+	}
+	if err := u.ruleRepo.Update(rule); err != nil {
+		return err
+	}
+
+	updated, err := u.ruleRepo.GetByID(rule.RuleID)
+	if err != nil {
+		return err
+	}
+	*rule = *updated
+	return nil
+}
+
+func (u *pointUsecase) DeactivateRule(ruleID int) error {
+	return u.ruleRepo.Deactivate(ruleID)
+}
+
+// Award evaluates the earning rules for a successful transaction, applies
+// the user's badge multiplier and records the points in the ledger. It
+// returns the number of points awarded.
+func (u *pointUsecase) Award(userID string, transactionType string, transactionID int, amount int) (int, error) {
+	now := time.Now()
+	rules, err := u.ruleRepo.GetActive(transactionType, now)
+	if err != nil {
+		return 0, err
+	}
+	points := earnedPoints(rules, amount)
+	if points == 0 {
+		return 0, nil
+	}
+
+	user, err := u.userRepo.GetByiD(userID)
+	if err != nil {
+		return 0, err
+	}
+	multiplier, err := u.ruleRepo.GetBadgeMultiplier(user.BadgeID)
+	if err != nil {
+		return 0, err
+	}
+	points = points * multiplier / 100
+	if points <= 0 {
+		return 0, nil
+	}
+
+	expiresAt := u.expiresAt(now)
+	entry := &model.PointLedger{
+		UserID:        userID,
+		TransactionID: transactionID,
+		Points:        points,
+		Description:   transactionType + " reward",
+		ExpiresAt:     &expiresAt,
+		CreatedAt:     now,
+	}
+	if err := u.ledgerRepo.Earn(entry); err != nil {
+		return 0, err
+	}
+	return points, nil
+}
+
+// expiresAt is when points earned or credited at the given time expire.
+func (u *pointUsecase) expiresAt(from time.Time) time.Time {
+	return from.AddDate(0, u.config.ExpiryMonths, 0)
+}
+
+func (u *pointUsecase) FindHistory(userID string) (*model.PointHistory, error) {
+	user, err := u.userRepo.GetByiD(userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := u.ledgerRepo.GetEntries(userID, pointHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	expiries, err := u.ledgerRepo.GetUpcomingExpiries(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PointHistory{
+		Balance:          user.Point,
+		Entries:          entries,
+		UpcomingExpiries: expiries,
+	}, nil
+}
+
+// Adjust corrects a user's points by hand. Credits become a new lot that
+// expires like earned points; debits are taken from the oldest lots first.
+func (u *pointUsecase) Adjust(userID string, adjustment *model.PointAdjustment) (*model.PointLedger, error) {
+	if adjustment.Points == 0 {
+		return nil, fmt.Errorf("points must not be 0")
+	}
+	description := strings.TrimSpace(adjustment.Description)
+	if description == "" || len(description) > maxPointDescription {
+		return nil, fmt.Errorf("description must be 1 - 100 characters")
+	}
+	if _, err := u.userRepo.GetByiD(userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := &model.PointLedger{
+		UserID:      userID,
+		EntryType:   "Adjust",
+		Description: description,
+		CreatedAt:   now,
+	}
+	if adjustment.Points > 0 {
+		expiresAt := u.expiresAt(now)
+		entry.Points = adjustment.Points
+		entry.ExpiresAt = &expiresAt
+		if err := u.ledgerRepo.Credit(entry); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+
+	entry.Points = -adjustment.Points
+	if err := u.ledgerRepo.Burn(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Refund gives back points taken by a transaction that did not go through.
+// They expire when the points they replace would have, like in Transfer.
+func (u *pointUsecase) Refund(userID string, transactionID int, points int, expiresAt *time.Time, description string) (*model.PointLedger, error) {
+	now := time.Now()
+	if expiresAt == nil {
+		// Points from before the ledger had no expiry of their own
+		defaultExpiry := u.expiresAt(now)
+		expiresAt = &defaultExpiry
+	}
+	entry := &model.PointLedger{
+		UserID:        userID,
+		TransactionID: transactionID,
+		EntryType:     "Refund",
+		Points:        points,
+		Description:   description,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     now,
+	}
+	if err := u.ledgerRepo.Credit(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ExpirePoints removes the unused points of every expired lot and reports
+// how many lots were closed.
+func (u *pointUsecase) ExpirePoints() (int, error) {
+	now := time.Now()
+	expired := 0
+	for {
+		lots, err := u.ledgerRepo.GetExpiredLots(now, pointExpiryBatch)
+		if err != nil {
+			return expired, err
+		}
+
+		batchExpired := 0
+		for _, lot := range lots {
+			if err := u.ledgerRepo.Expire(lot, now); err != nil {
+				if errors.Is(err, repository.ErrPointsUsed) {
+					// Reloaded with what is left in the next batch
+					continue
+				}
+				return expired, err
+			}
+			batchExpired++
+		}
+		expired += batchExpired
+
+		if len(lots) < pointExpiryBatch || batchExpired == 0 {
+			return expired, nil
+		}
+	}
+}
+
+// NotifyExpiringSoon tells users once about points that expire within the
+// notice period and reports how many users were notified.
+func (u *pointUsecase) NotifyExpiringSoon() (int, error) {
+	before := time.Now().Add(pointExpiryNotice)
+	expiries, err := u.ledgerRepo.GetExpiringSoon(before)
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, expiry := range expiries {
+		user, err := u.userRepo.GetByIDToken(expiry.UserID)
+		if err != nil {
+			return notified, err
+		}
+		body := strconv.Itoa(expiry.Points) + " poin Anda akan kedaluwarsa mulai " + expiry.ExpiresOn + ". Tukarkan sebelum hangus!"
+		if err := u.notify(user.Token, "Poin Akan Kedaluwarsa", body); err != nil {
+			// Left unmarked so the next run tries again
+			continue
+		}
+		if err := u.ledgerRepo.MarkExpiryNotified(expiry.UserID, before); err != nil {
+			return notified, err
+		}
+		notified++
+	}
+	return notified, nil
+}
+
+// reserveDailyCap counts points debited through entryType against today's
+// limit, failing with reachedErr when they do not fit.
+func (u *pointUsecase) reserveDailyCap(userID string, entryType string, points int, limit int, now time.Time, reachedErr string) error {
+	err := u.ledgerRepo.ReserveDailyCap(userID, entryType, now, points, limit)
+	if errors.Is(err, repository.ErrDailyCapReached) {
+		return errors.New(reachedErr)
+	}
+	return err
+}
+
+// releaseDailyCap gives the reserved points back after the debit failed with
+// err.
+func (u *pointUsecase) releaseDailyCap(userID string, entryType string, points int, now time.Time, err error) error {
+	if releaseErr := u.ledgerRepo.ReleaseDailyCap(userID, entryType, now, points); releaseErr != nil {
+		return fmt.Errorf("%v; %v", err, releaseErr)
+	}
+	return err
+}
+
+// Convert turns points into wallet balance at the configured rate.
+func (u *pointUsecase) Convert(user *model.User, points int) (*model.PointMovement, error) {
+	if points <= 0 {
+		return nil, fmt.Errorf("points must be greater than 0")
+	}
+	if user.Point < points {
+		return nil, fmt.Errorf("insufficient points")
+	}
+	now := time.Now()
+	if err := u.reserveDailyCap(user.ID, "Convert", points, u.config.ConversionDailyCap, now, "daily conversion limit reached"); err != nil {
+		return nil, err
+	}
+
+	burn := &model.PointLedger{
+		UserID:      user.ID,
+		EntryType:   "Convert",
+		Points:      points,
+		Description: "Convert to balance",
+		CreatedAt:   now,
+	}
+	if err := u.ledgerRepo.Burn(burn); err != nil {
+		return nil, u.releaseDailyCap(user.ID, "Convert", points, now, err)
+	}
+
+	movement := &model.PointMovement{
+		TransactionType: "Point Conversion",
+		SenderID:        user.ID,
+		SenderName:      user.Name,
+		Points:          points,
+		Amount:          points * u.config.ConversionRate,
+	}
+	if err := u.ledgerRepo.RecordMovement(movement); err != nil {
+		return nil, err
+	}
+
+	err := u.userRepo.UpdateBalance(user.ID, user.Balance+movement.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user balance: %v", err)
+	}
+	user.Balance += movement.Amount
+	user.Point -= points
+	return movement, nil
+}
+
+// Transfer sends points to another user. The points keep the expiry of the
+// lots they came from, so passing points around never extends their life.
+func (u *pointUsecase) Transfer(sender *model.User, transfer *model.PointTransferRequest) (*model.PointMovement, error) {
+	if transfer.Points <= 0 {
+		return nil, fmt.Errorf("points must be greater than 0")
+	}
+	transfer.Note = strings.TrimSpace(transfer.Note)
+	if len(transfer.Note) > maxPointTransferNote {
+		return nil, fmt.Errorf("note must be at most 100 characters")
+	}
+	if sender.Point < transfer.Points {
+		return nil, fmt.Errorf("insufficient points")
+	}
+
+	recipient, err := u.userRepo.GetByPhone(strings.TrimSpace(transfer.RecipientPhoneNumber))
+	if err != nil {
+		return nil, err
+	}
+	if recipient.ID == sender.ID {
+		return nil, fmt.Errorf("cannot transfer points to yourself")
+	}
+	now := time.Now()
+	if err := u.reserveDailyCap(sender.ID, "Transfer Out", transfer.Points, u.config.TransferDailyCap, now, "daily transfer limit reached"); err != nil {
+		return nil, err
+	}
+
+	burn := &model.PointLedger{
+		UserID:      sender.ID,
+		EntryType:   "Transfer Out",
+		Points:      transfer.Points,
+		Description: "Transfer to " + recipient.Name,
+		CreatedAt:   now,
+	}
+	if err := u.ledgerRepo.Burn(burn); err != nil {
+		return nil, u.releaseDailyCap(sender.ID, "Transfer Out", transfer.Points, now, err)
+	}
+
+	movement := &model.PointMovement{
+		TransactionType: "Point Transfer",
+		SenderID:        sender.ID,
+		RecipientID:     recipient.ID,
+		SenderName:      sender.Name,
+		RecipientName:   recipient.Name,
+		Points:          transfer.Points,
+		Note:            transfer.Note,
+	}
+	if err := u.ledgerRepo.RecordMovement(movement); err != nil {
+		return nil, err
+	}
+
+	expiresAt := burn.ExpiresAt
+	if expiresAt == nil {
+		// Points from before the ledger had no expiry of their own
+		defaultExpiry := u.expiresAt(now)
+		expiresAt = &defaultExpiry
+	}
+	credit := &model.PointLedger{
+		UserID:        recipient.ID,
+		TransactionID: movement.TransactionID,
+		EntryType:     "Transfer In",
+		Points:        transfer.Points,
+		Description:   "Transfer from " + sender.Name,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     now,
+	}
+	if err := u.ledgerRepo.Credit(credit); err != nil {
+		return nil, err
+	}
+	sender.Point -= transfer.Points
+	return movement, nil
+}
+
+// NewPointUsecase creates the points usecase.
+func NewPointUsecase(ruleRepo repository.PointRuleRepository, ledgerRepo repository.PointLedgerRepository, userRepo repository.UserRepository, config PointConfig) PointUsecase {
+	if config.ExpiryMonths <= 0 {
+		config.ExpiryMonths = defaultPointExpiryMonths
+	}
+	if config.ConversionRate <= 0 {
+		config.ConversionRate = defaultPointConversionRate
+	}
+	if config.ConversionDailyCap <= 0 {
+		config.ConversionDailyCap = defaultPointConversionDailyCap
+	}
+	if config.TransferDailyCap <= 0 {
+		config.TransferDailyCap = defaultPointTransferDailyCap
+	}
+	return &pointUsecase{
+		ruleRepo:   ruleRepo,
+		ledgerRepo: ledgerRepo,
+		userRepo:   userRepo,
+		config:     config,
+		notify:     model.SendFCMNotification,
+	}
+}
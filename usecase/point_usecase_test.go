@@ -0,0 +1,497 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type pointRuleRepoMock struct {
+	mock.Mock
+}
+
+func (r *pointRuleRepoMock) GetAll() ([]*model.PointRule, error) {
+	args := r.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointRule), args.Error(1)
+}
+
+func (r *pointRuleRepoMock) GetByID(ruleID int) (*model.PointRule, error) {
+	args := r.Called(ruleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PointRule), args.Error(1)
+}
+
+func (r *pointRuleRepoMock) GetActive(transactionType string, at time.Time) ([]*model.PointRule, error) {
+	args := r.Called(transactionType, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointRule), args.Error(1)
+}
+
+func (r *pointRuleRepoMock) Create(rule *model.PointRule) error {
+	args := r.Called(rule)
+	return args.Error(0)
+}
+
+func (r *pointRuleRepoMock) Update(rule *model.PointRule) error {
+	args := r.Called(rule)
+	return args.Error(0)
+}
+
+func (r *pointRuleRepoMock) Deactivate(ruleID int) error {
+	args := r.Called(ruleID)
+	return args.Error(0)
+}
+
+func (r *pointRuleRepoMock) GetBadgeMultiplier(badgeID int) (int, error) {
+	args := r.Called(badgeID)
+	return args.Int(0), args.Error(1)
+}
+
+type pointLedgerRepoMock struct {
+	mock.Mock
+}
+
+func (r *pointLedgerRepoMock) Earn(entry *model.PointLedger) error {
+	args := r.Called(entry)
+	return args.Error(0)
+}
+
+func (r *pointLedgerRepoMock) Credit(entry *model.PointLedger) error {
+	args := r.Called(entry)
+	return args.Error(0)
+}
+
+func (r *pointLedgerRepoMock) Burn(entry *model.PointLedger) error {
+	args := r.Called(entry)
+	return args.Error(0)
+}
+
+func (r *pointLedgerRepoMock) GetEntries(userID string, limit int) ([]*model.PointLedger, error) {
+	args := r.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointLedger), args.Error(1)
+}
+
+func (r *pointLedgerRepoMock) GetUpcomingExpiries(userID string) ([]*model.PointExpiry, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointExpiry), args.Error(1)
+}
+
+func (r *pointLedgerRepoMock) GetExpiredLots(now time.Time, limit int) ([]*model.PointLedger, error) {
+	args := r.Called(now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointLedger), args.Error(1)
+}
+
+func (r *pointLedgerRepoMock) Expire(lot *model.PointLedger, now time.Time) error {
+	args := r.Called(lot, now)
+	return args.Error(0)
+}
+
+func (r *pointLedgerRepoMock) GetExpiringSoon(before time.Time) ([]*model.PointExpiry, error) {
+	args := r.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PointExpiry), args.Error(1)
+}
+
+func (r *pointLedgerRepoMock) MarkExpiryNotified(userID string, before time.Time) error {
+	args := r.Called(userID, before)
+	return args.Error(0)
+}
+
+func (r *pointLedgerRepoMock) ReserveDailyCap(userID string, entryType string, day time.Time, points int, limit int) error {
+	args := r.Called(userID, entryType, day, points, limit)
+	return args.Error(0)
+}
+
+func (r *pointLedgerRepoMock) ReleaseDailyCap(userID string, entryType string, day time.Time, points int) error {
+	args := r.Called(userID, entryType, day, points)
+	return args.Error(0)
+}
+
+func (r *pointLedgerRepoMock) RecordMovement(movement *model.PointMovement) error {
+	args := r.Called(movement)
+	return args.Error(0)
+}
+
+type PointUsecaseTestSuite struct {
+	ruleRepoMock   *pointRuleRepoMock
+	ledgerRepoMock *pointLedgerRepoMock
+	userRepoMock   *userRepoMock
+	suite.Suite
+}
+
+func (suite *PointUsecaseTestSuite) usecase() PointUsecase {
+	return NewPointUsecase(suite.ruleRepoMock, suite.ledgerRepoMock, suite.userRepoMock, PointConfig{ExpiryMonths: 12, ConversionRate: 10, ConversionDailyCap: 1000, TransferDailyCap: 500})
+}
+
+func (suite *PointUsecaseTestSuite) TestEarnedPoints_TiersAndStacking() {
+	rules := []*model.PointRule{
+		{Name: "Transfer tier", MinAmount: 50000, Points: 20},
+		{Name: "Transfer tier", MinAmount: 200000, Points: 60},
+		{Name: "Ramadan", MinAmount: 0, PercentBps: 100, MaxPoints: 1500},
+	}
+	assert.Equal(suite.T(), 100, earnedPoints(rules, 10000))
+	assert.Equal(suite.T(), 20+1000, earnedPoints(rules, 100000))
+	assert.Equal(suite.T(), 60+1500, earnedPoints(rules, 500000))
+}
+
+func (suite *PointUsecaseTestSuite) TestSeedDefaultRules_EmptyTable() {
+	suite.ruleRepoMock.On("GetAll").Return([]*model.PointRule{}, nil)
+	suite.ruleRepoMock.On("Create", mock.Anything).Return(nil)
+
+	err := suite.usecase().SeedDefaultRules()
+
+	assert.NoError(suite.T(), err)
+	suite.ruleRepoMock.AssertNumberOfCalls(suite.T(), "Create", 2)
+	seeded := suite.ruleRepoMock.Calls[1].Arguments.Get(0).(*model.PointRule)
+	assert.Equal(suite.T(), "Deposit", seeded.TransactionType)
+	assert.Equal(suite.T(), 50000, seeded.MinAmount)
+	assert.Equal(suite.T(), 20, seeded.Points)
+	assert.True(suite.T(), seeded.Active)
+	assert.Equal(suite.T(), 20, earnedPoints([]*model.PointRule{seeded}, 50000))
+}
+
+func (suite *PointUsecaseTestSuite) TestSeedDefaultRules_RulesExist() {
+	suite.ruleRepoMock.On("GetAll").Return([]*model.PointRule{{RuleID: 1, Name: "Bonus", TransactionType: "Deposit", Points: 5}}, nil)
+
+	err := suite.usecase().SeedDefaultRules()
+
+	assert.NoError(suite.T(), err)
+	suite.ruleRepoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *PointUsecaseTestSuite) TestCreateRule_Success() {
+	rule := &model.PointRule{Name: " Deposit bonus ", TransactionType: "Deposit", MinAmount: 50000, Points: 20}
+	suite.ruleRepoMock.On("Create", rule).Return(nil)
+
+	err := suite.usecase().CreateRule(rule)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Deposit bonus", rule.Name)
+	assert.True(suite.T(), rule.Active)
+}
+
+func (suite *PointUsecaseTestSuite) TestCreateRule_UnknownType() {
+	err := suite.usecase().CreateRule(&model.PointRule{Name: "Bonus", TransactionType: "Withdraw", Points: 20})
+
+	assert.EqualError(suite.T(), err, "transaction type must be Deposit, Transfer or Merchant Payment")
+}
+
+func (suite *PointUsecaseTestSuite) TestCreateRule_InvalidWindow() {
+	startsAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(-time.Hour)
+	rule := &model.PointRule{Name: "Ramadan", TransactionType: "Transfer", PercentBps: 100, StartsAt: &startsAt, EndsAt: &endsAt}
+
+	err := suite.usecase().CreateRule(rule)
+
+	assert.EqualError(suite.T(), err, "campaign must end after it starts")
+}
+
+func (suite *PointUsecaseTestSuite) TestAward_AppliesBadgeMultiplier() {
+	user := &model.User{ID: "1", BadgeID: 3}
+	rules := []*model.PointRule{{Name: "Transfer bonus", MinAmount: 50000, Points: 20}}
+	suite.ruleRepoMock.On("GetActive", "Transfer", mock.AnythingOfType("time.Time")).Return(rules, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(user, nil)
+	suite.ruleRepoMock.On("GetBadgeMultiplier", 3).Return(150, nil)
+	suite.ledgerRepoMock.On("Earn", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		entry := args.Get(0).(*model.PointLedger)
+		assert.Equal(suite.T(), 12, entry.TransactionID)
+		assert.Equal(suite.T(), 30, entry.Points)
+		assert.Equal(suite.T(), "Transfer reward", entry.Description)
+		assert.Equal(suite.T(), entry.CreatedAt.AddDate(1, 0, 0), *entry.ExpiresAt)
+	})
+
+	res, err := suite.usecase().Award("1", "Transfer", 12, 75000)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 30, res)
+}
+
+func (suite *PointUsecaseTestSuite) TestAward_BelowThreshold() {
+	rules := []*model.PointRule{{Name: "Transfer bonus", MinAmount: 50000, Points: 20}}
+	suite.ruleRepoMock.On("GetActive", "Transfer", mock.AnythingOfType("time.Time")).Return(rules, nil)
+
+	res, err := suite.usecase().Award("1", "Transfer", 12, 49999)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, res)
+	suite.ledgerRepoMock.AssertNotCalled(suite.T(), "Earn", mock.Anything)
+}
+
+func (suite *PointUsecaseTestSuite) TestAward_AlreadyAwarded() {
+	rules := []*model.PointRule{{Name: "Deposit bonus", MinAmount: 50000, Points: 20}}
+	suite.ruleRepoMock.On("GetActive", "Deposit", mock.AnythingOfType("time.Time")).Return(rules, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1", BadgeID: 1}, nil)
+	suite.ruleRepoMock.On("GetBadgeMultiplier", 1).Return(100, nil)
+	suite.ledgerRepoMock.On("Earn", mock.AnythingOfType("*model.PointLedger")).Return(errors.New("points already awarded"))
+
+	res, err := suite.usecase().Award("1", "Deposit", 7, 50000)
+
+	assert.Equal(suite.T(), 0, res)
+	assert.EqualError(suite.T(), err, "points already awarded")
+}
+
+func (suite *PointUsecaseTestSuite) TestFindHistory_Success() {
+	entries := []*model.PointLedger{{LedgerID: 2, UserID: "1", EntryType: "Burn", Points: -50}, {LedgerID: 1, UserID: "1", EntryType: "Earn", Points: 80, Remaining: 30}}
+	expiries := []*model.PointExpiry{{UserID: "1", Points: 30, ExpiresOn: "2027-10-19"}}
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1", Point: 30}, nil)
+	suite.ledgerRepoMock.On("GetEntries", "1", pointHistoryLimit).Return(entries, nil)
+	suite.ledgerRepoMock.On("GetUpcomingExpiries", "1").Return(expiries, nil)
+
+	res, err := suite.usecase().FindHistory("1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 30, res.Balance)
+	assert.Equal(suite.T(), entries, res.Entries)
+	assert.Equal(suite.T(), expiries, res.UpcomingExpiries)
+}
+
+func (suite *PointUsecaseTestSuite) TestAdjust_Credit() {
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1"}, nil)
+	suite.ledgerRepoMock.On("Credit", mock.AnythingOfType("*model.PointLedger")).Return(nil)
+
+	res, err := suite.usecase().Adjust("1", &model.PointAdjustment{Points: 100, Description: " Goodwill "})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 100, res.Points)
+	assert.Equal(suite.T(), "Goodwill", res.Description)
+	assert.NotNil(suite.T(), res.ExpiresAt)
+	suite.ledgerRepoMock.AssertNotCalled(suite.T(), "Burn", mock.Anything)
+}
+
+func (suite *PointUsecaseTestSuite) TestAdjust_Debit() {
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1"}, nil)
+	suite.ledgerRepoMock.On("Burn", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		entry := args.Get(0).(*model.PointLedger)
+		assert.Equal(suite.T(), "Adjust", entry.EntryType)
+		assert.Equal(suite.T(), 40, entry.Points)
+	})
+
+	_, err := suite.usecase().Adjust("1", &model.PointAdjustment{Points: -40, Description: "Duplicate award"})
+
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *PointUsecaseTestSuite) TestAdjust_InsufficientPoints() {
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1"}, nil)
+	suite.ledgerRepoMock.On("Burn", mock.AnythingOfType("*model.PointLedger")).Return(errors.New("insufficient points"))
+
+	res, err := suite.usecase().Adjust("1", &model.PointAdjustment{Points: -40, Description: "Duplicate award"})
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient points")
+}
+
+func (suite *PointUsecaseTestSuite) TestAdjust_ZeroPoints() {
+	res, err := suite.usecase().Adjust("1", &model.PointAdjustment{Description: "Nothing"})
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "points must not be 0")
+}
+
+func (suite *PointUsecaseTestSuite) TestRefund_NoOriginalExpiry() {
+	suite.ledgerRepoMock.On("Credit", mock.AnythingOfType("*model.PointLedger")).Return(nil)
+
+	res, err := suite.usecase().Refund("1", 15, 200, nil, "Refund Voucher Kopi")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Refund", res.EntryType)
+	assert.Equal(suite.T(), 200, res.Points)
+	assert.WithinDuration(suite.T(), time.Now().AddDate(0, 12, 0), *res.ExpiresAt, time.Minute)
+}
+
+func (suite *PointUsecaseTestSuite) TestExpirePoints_SkipsUsedLots() {
+	lots := []*model.PointLedger{{LedgerID: 1, UserID: "1", Remaining: 30}, {LedgerID: 2, UserID: "2", Remaining: 10}}
+	suite.ledgerRepoMock.On("GetExpiredLots", mock.AnythingOfType("time.Time"), pointExpiryBatch).Return(lots, nil)
+	suite.ledgerRepoMock.On("Expire", lots[0], mock.AnythingOfType("time.Time")).Return(repository.ErrPointsUsed)
+	suite.ledgerRepoMock.On("Expire", lots[1], mock.AnythingOfType("time.Time")).Return(nil)
+
+	res, err := suite.usecase().ExpirePoints()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, res)
+}
+
+func (suite *PointUsecaseTestSuite) TestExpirePoints_DrainsFullBatches() {
+	var full []*model.PointLedger
+	for i := 0; i < pointExpiryBatch; i++ {
+		full = append(full, &model.PointLedger{LedgerID: i + 1, UserID: "1", Remaining: 1})
+	}
+	rest := []*model.PointLedger{{LedgerID: pointExpiryBatch + 1, UserID: "2", Remaining: 10}}
+	suite.ledgerRepoMock.On("GetExpiredLots", mock.AnythingOfType("time.Time"), pointExpiryBatch).Return(full, nil).Once()
+	suite.ledgerRepoMock.On("GetExpiredLots", mock.AnythingOfType("time.Time"), pointExpiryBatch).Return(rest, nil).Once()
+	suite.ledgerRepoMock.On("Expire", mock.AnythingOfType("*model.PointLedger"), mock.AnythingOfType("time.Time")).Return(nil)
+
+	res, err := suite.usecase().ExpirePoints()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), pointExpiryBatch+1, res)
+	suite.ledgerRepoMock.AssertNumberOfCalls(suite.T(), "GetExpiredLots", 2)
+}
+
+func (suite *PointUsecaseTestSuite) TestNotifyExpiringSoon_Success() {
+	expiries := []*model.PointExpiry{{UserID: "1", Points: 30, ExpiresOn: "2026-10-22"}}
+	suite.ledgerRepoMock.On("GetExpiringSoon", mock.AnythingOfType("time.Time")).Return(expiries, nil)
+	suite.userRepoMock.On("GetByIDToken", "1").Return(&model.User{ID: "1", Token: "token-1"}, nil)
+	suite.ledgerRepoMock.On("MarkExpiryNotified", "1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	uc := suite.usecase()
+	var sentTo, sentBody string
+	uc.(*pointUsecase).notify = func(token string, title string, body string) error {
+		sentTo, sentBody = token, body
+		return nil
+	}
+
+	res, err := uc.NotifyExpiringSoon()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, res)
+	assert.Equal(suite.T(), "token-1", sentTo)
+	assert.Contains(suite.T(), sentBody, "30 poin")
+}
+
+func (suite *PointUsecaseTestSuite) TestNotifyExpiringSoon_NotifyFailed() {
+	expiries := []*model.PointExpiry{{UserID: "1", Points: 30, ExpiresOn: "2026-10-22"}}
+	suite.ledgerRepoMock.On("GetExpiringSoon", mock.AnythingOfType("time.Time")).Return(expiries, nil)
+	suite.userRepoMock.On("GetByIDToken", "1").Return(&model.User{ID: "1", Token: "token-1"}, nil)
+
+	uc := suite.usecase()
+	uc.(*pointUsecase).notify = func(token string, title string, body string) error {
+		return errors.New("fcm unavailable")
+	}
+
+	res, err := uc.NotifyExpiringSoon()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, res)
+	suite.ledgerRepoMock.AssertNotCalled(suite.T(), "MarkExpiryNotified", mock.Anything, mock.Anything)
+}
+
+func (suite *PointUsecaseTestSuite) TestConvert_Success() {
+	user := &model.User{ID: "1", Name: "Budi", Balance: 5000, Point: 300}
+	suite.ledgerRepoMock.On("ReserveDailyCap", "1", "Convert", mock.AnythingOfType("time.Time"), 200, 1000).Return(nil)
+	suite.ledgerRepoMock.On("Burn", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		assert.Equal(suite.T(), "Convert", args.Get(0).(*model.PointLedger).EntryType)
+	})
+	suite.ledgerRepoMock.On("RecordMovement", mock.AnythingOfType("*model.PointMovement")).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 7000).Return(nil)
+
+	res, err := suite.usecase().Convert(user, 200)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Point Conversion", res.TransactionType)
+	assert.Equal(suite.T(), 2000, res.Amount)
+	assert.Equal(suite.T(), 7000, user.Balance)
+	assert.Equal(suite.T(), 100, user.Point)
+}
+
+func (suite *PointUsecaseTestSuite) TestConvert_DailyLimitReached() {
+	user := &model.User{ID: "1", Point: 300}
+	suite.ledgerRepoMock.On("ReserveDailyCap", "1", "Convert", mock.AnythingOfType("time.Time"), 200, 1000).Return(repository.ErrDailyCapReached)
+
+	res, err := suite.usecase().Convert(user, 200)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "daily conversion limit reached")
+	suite.ledgerRepoMock.AssertNotCalled(suite.T(), "Burn", mock.Anything)
+}
+
+func (suite *PointUsecaseTestSuite) TestConvert_BurnFailedReleasesCap() {
+	user := &model.User{ID: "1", Point: 300}
+	suite.ledgerRepoMock.On("ReserveDailyCap", "1", "Convert", mock.AnythingOfType("time.Time"), 200, 1000).Return(nil)
+	suite.ledgerRepoMock.On("Burn", mock.AnythingOfType("*model.PointLedger")).Return(errors.New("insufficient points"))
+	suite.ledgerRepoMock.On("ReleaseDailyCap", "1", "Convert", mock.AnythingOfType("time.Time"), 200).Return(nil)
+
+	res, err := suite.usecase().Convert(user, 200)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient points")
+	suite.ledgerRepoMock.AssertCalled(suite.T(), "ReleaseDailyCap", "1", "Convert", mock.AnythingOfType("time.Time"), 200)
+}
+
+func (suite *PointUsecaseTestSuite) TestConvert_InsufficientPoints() {
+	res, err := suite.usecase().Convert(&model.User{ID: "1", Point: 50}, 200)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient points")
+}
+
+func (suite *PointUsecaseTestSuite) TestTransfer_InheritsExpiry() {
+	expiresAt := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	sender := &model.User{ID: "2", Name: "Budi", Point: 300}
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.ledgerRepoMock.On("ReserveDailyCap", "2", "Transfer Out", mock.AnythingOfType("time.Time"), 100, 500).Return(nil)
+	suite.ledgerRepoMock.On("Burn", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.PointLedger).ExpiresAt = &expiresAt
+	})
+	suite.ledgerRepoMock.On("RecordMovement", mock.AnythingOfType("*model.PointMovement")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.PointMovement).TransactionID = 9
+	})
+	var credit *model.PointLedger
+	suite.ledgerRepoMock.On("Credit", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		credit = args.Get(0).(*model.PointLedger)
+	})
+
+	res, err := suite.usecase().Transfer(sender, &model.PointTransferRequest{RecipientPhoneNumber: "08111111", Points: 100, Note: " thanks "})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Point Transfer", res.TransactionType)
+	assert.Equal(suite.T(), "thanks", res.Note)
+	assert.Equal(suite.T(), "1", credit.UserID)
+	assert.Equal(suite.T(), "Transfer In", credit.EntryType)
+	assert.Equal(suite.T(), 9, credit.TransactionID)
+	assert.Equal(suite.T(), expiresAt, *credit.ExpiresAt)
+	assert.Equal(suite.T(), 200, sender.Point)
+}
+
+func (suite *PointUsecaseTestSuite) TestTransfer_ToSelf() {
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+
+	res, err := suite.usecase().Transfer(&model.User{ID: "1", Point: 300}, &model.PointTransferRequest{RecipientPhoneNumber: "08111111", Points: 100})
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "cannot transfer points to yourself")
+}
+
+func (suite *PointUsecaseTestSuite) TestTransfer_DailyLimitReached() {
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.ledgerRepoMock.On("ReserveDailyCap", "2", "Transfer Out", mock.AnythingOfType("time.Time"), 100, 500).Return(repository.ErrDailyCapReached)
+
+	res, err := suite.usecase().Transfer(&model.User{ID: "2", Point: 300}, &model.PointTransferRequest{RecipientPhoneNumber: "08111111", Points: 100})
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "daily transfer limit reached")
+}
+
+func (suite *PointUsecaseTestSuite) SetupTest() {
+	suite.ruleRepoMock = new(pointRuleRepoMock)
+	suite.ledgerRepoMock = new(pointLedgerRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+}
+
+func TestPointUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(PointUsecaseTestSuite))
+}
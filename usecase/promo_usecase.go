@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxPromoCodeSize         = 20
+	maxPromoName             = 50
+	promoReservationLifetime = 24 * time.Hour
+)
+
+// promoTransactionTypes are the transactions a promo code can be applied to.
+var promoTransactionTypes = map[string]bool{
+	"Transfer":         true,
+	"Deposit":          true,
+	"Merchant Payment": true,
+}
+
+type PromoUsecase interface {
+	FindCampaigns() ([]*model.PromoCampaign, error)
+	CreateCampaign(campaign *model.PromoCampaign) error
+	UpdateCampaign(campaign *model.PromoCampaign) error
+	DeactivateCampaign(campaignID int) error
+	Reserve(user *model.User, code string, transactionType string, amount int, fee int) (*model.PromoRedemption, error)
+	Link(redemption *model.PromoRedemption, transactionID int) error
+	Apply(redemption *model.PromoRedemption, transactionID int) error
+	ApplyForTransaction(transactionID int) (*model.PromoRedemption, error)
+	Release(redemption *model.PromoRedemption) error
+	ReleaseStale() (int, error)
+}
+
+type promoUsecase struct {
+	promoRepo repository.PromoRepository
+	userRepo  repository.UserRepository
+	notify    func(token string, title string, body string) error
+}
+
+func validatePromoCampaign(campaign *model.PromoCampaign) error {
+	campaign.Code = strings.ToUpper(strings.TrimSpace(campaign.Code))
+	campaign.Name = strings.TrimSpace(campaign.Name)
+	if campaign.Code == "" || len(campaign.Code) > maxPromoCodeSize || strings.ContainsAny(campaign.Code, " \t") {
+		return fmt.Errorf("code must be 1 - 20 characters without spaces")
+	}
+	if campaign.Name == "" || len(campaign.Name) > maxPromoName {
+		return fmt.Errorf("campaign name must be 1 - 50 characters")
+	}
+	if !promoTransactionTypes[campaign.TransactionType] {
+		return fmt.Errorf("transaction type must be Transfer, Deposit or Merchant Payment")
+	}
+	switch campaign.BenefitType {
+	case "Fee Waiver":
+		if campaign.TransactionType != "Transfer" {
+			return fmt.Errorf("fee waiver is only available for transfers")
+		}
+	case "Cashback":
+		if campaign.CashbackAmount == 0 && campaign.CashbackBps == 0 {
+			return fmt.Errorf("cashback must be a fixed amount or a percentage")
+		}
+	default:
+		return fmt.Errorf("benefit type must be Fee Waiver or Cashback")
+	}
+	if campaign.CashbackAmount < 0 || campaign.MaxCashback < 0 || campaign.MinAmount < 0 || campaign.PerUserLimit < 0 || campaign.Budget < 0 {
+		return fmt.Errorf("amounts and limits must not be negative")
+	}
+	if campaign.CashbackBps < 0 || campaign.CashbackBps > maxPointPercentBps {
+		return fmt.Errorf("percent must be 0 - 10000 bps")
+	}
+	if !campaign.EndsAt.After(campaign.StartsAt) {
+		return fmt.Errorf("campaign must end after it starts")
+	}
+	return nil
+}
+
+// promoBenefit is what a campaign gives on a transaction: the waived fee or
+// the capped cashback.
+func promoBenefit(campaign *model.PromoCampaign, amount int, fee int) int {
+	if campaign.BenefitType == "Fee Waiver" {
+		return fee
+	}
+	cashback := campaign.CashbackAmount + amount*campaign.CashbackBps/10000
+	if campaign.MaxCashback > 0 && cashback > campaign.MaxCashback {
+		cashback = campaign.MaxCashback
+	}
+	return cashback
+}
+
+func promoEligibleBadge(campaign *model.PromoCampaign, badgeID int) bool {
+	if len(campaign.EligibleBadgeIDs) == 0 {
+		return true
+	}
+	for _, eligible := range campaign.EligibleBadgeIDs {
+		if eligible == badgeID {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *promoUsecase) FindCampaigns() ([]*model.PromoCampaign, error) {
+	return u.promoRepo.GetAll()
+}
+
+func (u *promoUsecase) CreateCampaign(campaign *model.PromoCampaign) error {
+	if err := validatePromoCampaign(campaign); err != nil {
+		return err
+	}
+	campaign.Active = true
+	campaign.BudgetUsed = 0
+	campaign.CreatedAt = time.Now()
+	return u.promoRepo.Create(campaign)
+}
+
+func (u *promoUsecase) UpdateCampaign(campaign *model.PromoCampaign) error {
+	current, err := u.promoRepo.GetByID(campaign.CampaignID)
+	if err != nil {
+		return err
+	}
+	campaign.Code = current.Code
+	if err := validatePromoCampaign(campaign); err != nil {
+		return err
+	}
+	if err := u.promoRepo.Update(campaign); err != nil {
+		return err
+	}
+
+	updated, err := u.promoRepo.GetByID(campaign.CampaignID)
+	if err != nil {
+		return err
+	}
+	*campaign = *updated
+	return nil
+}
+
+func (u *promoUsecase) DeactivateCampaign(campaignID int) error {
+	return u.promoRepo.Deactivate(campaignID)
+}
+
+// Reserve checks a promo code against a transaction that is about to be made
+// and sets its benefit aside from the campaign budget. The caller must Apply
+// the redemption once the transaction succeeds or Release it otherwise.
+func (u *promoUsecase) Reserve(user *model.User, code string, transactionType string, amount int, fee int) (*model.PromoRedemption, error) {
+	campaign, err := u.promoRepo.GetByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !campaign.Active || now.Before(campaign.StartsAt) || !now.Before(campaign.EndsAt) {
+		return nil, fmt.Errorf("promo code is not active")
+	}
+	if campaign.TransactionType != transactionType {
+		return nil, fmt.Errorf("promo code is not valid for this transaction")
+	}
+	if amount < campaign.MinAmount {
+		return nil, fmt.Errorf("minimum amount for this promo is not reached")
+	}
+	if !promoEligibleBadge(campaign, user.BadgeID) {
+		return nil, fmt.Errorf("promo code is not available for your badge")
+	}
+	benefit := promoBenefit(campaign, amount, fee)
+	if benefit <= 0 {
+		return nil, fmt.Errorf("promo code gives no benefit for this transaction")
+	}
+
+	redemption := &model.PromoRedemption{
+		CampaignID:      campaign.CampaignID,
+		Code:            campaign.Code,
+		UserID:          user.ID,
+		TransactionType: transactionType,
+		Amount:          amount,
+		BenefitType:     campaign.BenefitType,
+		Benefit:         benefit,
+		CreatedAt:       now,
+	}
+	if err := u.promoRepo.Reserve(redemption, campaign.PerUserLimit); err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
+
+// Link ties a reservation to a transaction that completes later, such as a
+// deposit waiting for its payment.
+func (u *promoUsecase) Link(redemption *model.PromoRedemption, transactionID int) error {
+	if err := u.promoRepo.SetTransaction(redemption.RedemptionID, transactionID); err != nil {
+		return err
+	}
+	redemption.TransactionID = transactionID
+	return nil
+}
+
+// Apply uses a reservation for its successful transaction. Cashback is paid
+// into the balance as a separate transaction.
+func (u *promoUsecase) Apply(redemption *model.PromoRedemption, transactionID int) error {
+	redemption.TransactionID = transactionID
+	if err := u.promoRepo.Apply(redemption); err != nil {
+		return err
+	}
+	if redemption.BenefitType != "Cashback" {
+		return nil
+	}
+
+	user, err := u.userRepo.GetByIDToken(redemption.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.promoRepo.RecordCashback(redemption); err != nil {
+		return err
+	}
+	if err := u.userRepo.UpdateBalance(user.ID, user.Balance+redemption.Benefit); err != nil {
+		return fmt.Errorf("failed to update user balance: %v", err)
+	}
+
+	formattedAmount := "Rp " + strconv.FormatFloat(float64(redemption.Benefit)/1000, 'f', 3, 64)
+	_ = u.notify(user.Token, "Cashback Diterima", "Anda mendapatkan cashback "+formattedAmount+" dari promo "+redemption.Code)
+	return nil
+}
+
+// ApplyForTransaction applies the reservation linked to a transaction, if
+// there is one.
+func (u *promoUsecase) ApplyForTransaction(transactionID int) (*model.PromoRedemption, error) {
+	redemption, err := u.promoRepo.GetReservedByTransaction(transactionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPromoRedemptionNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := u.Apply(redemption, transactionID); err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
+
+func (u *promoUsecase) Release(redemption *model.PromoRedemption) error {
+	return u.promoRepo.Release(redemption)
+}
+
+// ReleaseStale gives back the budget of reservations whose transaction never
+// completed, such as deposits that were never paid.
+func (u *promoUsecase) ReleaseStale() (int, error) {
+	redemptions, err := u.promoRepo.GetStaleReservations(time.Now().Add(-promoReservationLifetime))
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, redemption := range redemptions {
+		if err := u.promoRepo.Release(redemption); err != nil {
+			if errors.Is(err, repository.ErrPromoRedemptionProcessed) {
+				continue
+			}
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+func NewPromoUsecase(promoRepo repository.PromoRepository, userRepo repository.UserRepository) PromoUsecase {
+	return &promoUsecase{
+		promoRepo: promoRepo,
+		userRepo:  userRepo,
+		notify:    model.SendFCMNotification,
+	}
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type promoRepoMock struct {
+	mock.Mock
+}
+
+func (r *promoRepoMock) GetAll() ([]*model.PromoCampaign, error) {
+	args := r.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PromoCampaign), args.Error(1)
+}
+
+func (r *promoRepoMock) GetByID(campaignID int) (*model.PromoCampaign, error) {
+	args := r.Called(campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PromoCampaign), args.Error(1)
+}
+
+func (r *promoRepoMock) GetByCode(code string) (*model.PromoCampaign, error) {
+	args := r.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PromoCampaign), args.Error(1)
+}
+
+func (r *promoRepoMock) Create(campaign *model.PromoCampaign) error {
+	args := r.Called(campaign)
+	return args.Error(0)
+}
+
+func (r *promoRepoMock) Update(campaign *model.PromoCampaign) error {
+	args := r.Called(campaign)
+	return args.Error(0)
+}
+
+func (r *promoRepoMock) Deactivate(campaignID int) error {
+	args := r.Called(campaignID)
+	return args.Error(0)
+}
+
+func (r *promoRepoMock) Reserve(redemption *model.PromoRedemption, perUserLimit int) error {
+	args := r.Called(redemption, perUserLimit)
+	return args.Error(0)
+}
+
+func (r *promoRepoMock) SetTransaction(redemptionID int, transactionID int) error {
+	args := r.Called(redemptionID, transactionID)
+	return args.Error(0)
+}
+
+func (r *promoRepoMock) GetReservedByTransaction(transactionID int) (*model.PromoRedemption, error) {
+	args := r.Called(transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PromoRedemption), args.Error(1)
+}
+
+func (r *promoRepoMock) GetStaleReservations(before time.Time) ([]*model.PromoRedemption, error) {
+	args := r.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PromoRedemption), args.Error(1)
+}
+
+func (r *promoRepoMock) Apply(redemption *model.PromoRedemption) error {
+	args := r.Called(redemption)
+	return args.Error(0)
+}
+
+func (r *promoRepoMock) Release(redemption *model.PromoRedemption) error {
+	args := r.Called(redemption)
+	return args.Error(0)
+}
+
+func (r *promoRepoMock) RecordCashback(redemption *model.PromoRedemption) (int, error) {
+	args := r.Called(redemption)
+	return args.Int(0), args.Error(1)
+}
+
+type PromoUsecaseTestSuite struct {
+	promoRepoMock *promoRepoMock
+	userRepoMock  *userRepoMock
+	suite.Suite
+}
+
+func (suite *PromoUsecaseTestSuite) newUsecase() PromoUsecase {
+	uc := NewPromoUsecase(suite.promoRepoMock, suite.userRepoMock)
+	uc.(*promoUsecase).notify = func(token string, title string, body string) error {
+		return nil
+	}
+	return uc
+}
+
+func activeCampaign(campaign model.PromoCampaign) *model.PromoCampaign {
+	campaign.Active = true
+	campaign.StartsAt = time.Now().AddDate(0, 0, -1)
+	campaign.EndsAt = time.Now().AddDate(0, 0, 7)
+	return &campaign
+}
+
+func (suite *PromoUsecaseTestSuite) TestPromoBenefit() {
+	assert.Equal(suite.T(), 2500, promoBenefit(&model.PromoCampaign{BenefitType: "Fee Waiver"}, 100000, 2500))
+	assert.Equal(suite.T(), 5000, promoBenefit(&model.PromoCampaign{BenefitType: "Cashback", CashbackBps: 500}, 100000, 0))
+	assert.Equal(suite.T(), 3000, promoBenefit(&model.PromoCampaign{BenefitType: "Cashback", CashbackBps: 500, MaxCashback: 3000}, 100000, 0))
+	assert.Equal(suite.T(), 6000, promoBenefit(&model.PromoCampaign{BenefitType: "Cashback", CashbackAmount: 1000, CashbackBps: 500}, 100000, 0))
+}
+
+func (suite *PromoUsecaseTestSuite) TestCreateCampaign_FeeWaiverOnlyForTransfer() {
+	now := time.Now()
+	campaign := &model.PromoCampaign{Code: "gratis", Name: "Gratis biaya", TransactionType: "Deposit", BenefitType: "Fee Waiver", StartsAt: now, EndsAt: now.AddDate(0, 1, 0)}
+
+	err := suite.newUsecase().CreateCampaign(campaign)
+
+	assert.EqualError(suite.T(), err, "fee waiver is only available for transfers")
+	suite.promoRepoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *PromoUsecaseTestSuite) TestCreateCampaign_Success() {
+	now := time.Now()
+	campaign := &model.PromoCampaign{Code: " hemat10 ", Name: "Hemat 10%", TransactionType: "Merchant Payment", BenefitType: "Cashback", CashbackBps: 1000,
+		MaxCashback: 10000, Budget: 1000000, StartsAt: now, EndsAt: now.AddDate(0, 1, 0)}
+	suite.promoRepoMock.On("Create", campaign).Return(nil)
+
+	err := suite.newUsecase().CreateCampaign(campaign)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "HEMAT10", campaign.Code)
+	assert.True(suite.T(), campaign.Active)
+}
+
+func (suite *PromoUsecaseTestSuite) TestReserve_WrongTransactionType() {
+	suite.promoRepoMock.On("GetByCode", "HEMAT10").Return(activeCampaign(model.PromoCampaign{CampaignID: 1, TransactionType: "Merchant Payment", BenefitType: "Cashback"}), nil)
+
+	res, err := suite.newUsecase().Reserve(&model.User{ID: "1"}, "hemat10", "Transfer", 100000, 2500)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "promo code is not valid for this transaction")
+}
+
+func (suite *PromoUsecaseTestSuite) TestReserve_Expired() {
+	campaign := activeCampaign(model.PromoCampaign{CampaignID: 1, TransactionType: "Transfer", BenefitType: "Fee Waiver"})
+	campaign.EndsAt = time.Now().Add(-time.Minute)
+	suite.promoRepoMock.On("GetByCode", "GRATIS").Return(campaign, nil)
+
+	res, err := suite.newUsecase().Reserve(&model.User{ID: "1"}, "GRATIS", "Transfer", 100000, 2500)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "promo code is not active")
+}
+
+func (suite *PromoUsecaseTestSuite) TestReserve_BadgeNotEligible() {
+	suite.promoRepoMock.On("GetByCode", "GOLD").Return(activeCampaign(model.PromoCampaign{CampaignID: 1, TransactionType: "Transfer", BenefitType: "Fee Waiver", EligibleBadgeIDs: []int{3}}), nil)
+
+	res, err := suite.newUsecase().Reserve(&model.User{ID: "1", BadgeID: 2}, "GOLD", "Transfer", 100000, 2500)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "promo code is not available for your badge")
+}
+
+func (suite *PromoUsecaseTestSuite) TestReserve_PerUserLimit() {
+	suite.promoRepoMock.On("GetByCode", "GRATIS").Return(activeCampaign(model.PromoCampaign{CampaignID: 1, TransactionType: "Transfer", BenefitType: "Fee Waiver", PerUserLimit: 2}), nil)
+	suite.promoRepoMock.On("Reserve", mock.AnythingOfType("*model.PromoRedemption"), 2).Return(errors.New("promo code usage limit reached"))
+
+	res, err := suite.newUsecase().Reserve(&model.User{ID: "1"}, "GRATIS", "Transfer", 100000, 2500)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "promo code usage limit reached")
+}
+
+func (suite *PromoUsecaseTestSuite) TestReserve_NoFeeToWaive() {
+	suite.promoRepoMock.On("GetByCode", "GRATIS").Return(activeCampaign(model.PromoCampaign{CampaignID: 1, TransactionType: "Transfer", BenefitType: "Fee Waiver"}), nil)
+
+	res, err := suite.newUsecase().Reserve(&model.User{ID: "1"}, "GRATIS", "Transfer", 100000, 0)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "promo code gives no benefit for this transaction")
+}
+
+func (suite *PromoUsecaseTestSuite) TestReserve_BudgetExhausted() {
+	suite.promoRepoMock.On("GetByCode", "HEMAT10").Return(activeCampaign(model.PromoCampaign{CampaignID: 1, Code: "HEMAT10", TransactionType: "Deposit", BenefitType: "Cashback", CashbackAmount: 5000}), nil)
+	suite.promoRepoMock.On("Reserve", mock.AnythingOfType("*model.PromoRedemption"), 0).Return(errors.New("promo budget exhausted"))
+
+	res, err := suite.newUsecase().Reserve(&model.User{ID: "1"}, "HEMAT10", "Deposit", 100000, 0)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "promo budget exhausted")
+}
+
+func (suite *PromoUsecaseTestSuite) TestReserve_Success() {
+	suite.promoRepoMock.On("GetByCode", "HEMAT10").Return(activeCampaign(model.PromoCampaign{CampaignID: 1, Code: "HEMAT10", TransactionType: "Deposit", BenefitType: "Cashback", CashbackBps: 1000, MaxCashback: 7500}), nil)
+	suite.promoRepoMock.On("Reserve", mock.AnythingOfType("*model.PromoRedemption"), 0).Return(nil)
+
+	res, err := suite.newUsecase().Reserve(&model.User{ID: "1"}, "HEMAT10", "Deposit", 100000, 0)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 7500, res.Benefit)
+	assert.Equal(suite.T(), "Cashback", res.BenefitType)
+}
+
+func (suite *PromoUsecaseTestSuite) TestApply_CashbackCreditsBalance() {
+	redemption := &model.PromoRedemption{RedemptionID: 4, Code: "HEMAT10", UserID: "1", BenefitType: "Cashback", Benefit: 7500}
+	suite.promoRepoMock.On("Apply", redemption).Return(nil)
+	suite.promoRepoMock.On("RecordCashback", redemption).Return(21, nil)
+	suite.userRepoMock.On("GetByIDToken", "1").Return(&model.User{ID: "1", Balance: 100000, Token: "token-1"}, nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 107500).Return(nil)
+
+	err := suite.newUsecase().Apply(redemption, 20)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 20, redemption.TransactionID)
+	suite.userRepoMock.AssertExpectations(suite.T())
+}
+
+func (suite *PromoUsecaseTestSuite) TestApply_FeeWaiverPaysNothing() {
+	redemption := &model.PromoRedemption{RedemptionID: 4, UserID: "1", BenefitType: "Fee Waiver", Benefit: 2500}
+	suite.promoRepoMock.On("Apply", redemption).Return(nil)
+
+	err := suite.newUsecase().Apply(redemption, 20)
+
+	assert.NoError(suite.T(), err)
+	suite.promoRepoMock.AssertNotCalled(suite.T(), "RecordCashback", mock.Anything)
+}
+
+func (suite *PromoUsecaseTestSuite) TestApplyForTransaction_NoPromo() {
+	suite.promoRepoMock.On("GetReservedByTransaction", 20).Return(nil, repository.ErrPromoRedemptionNotFound)
+
+	res, err := suite.newUsecase().ApplyForTransaction(20)
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), res)
+}
+
+func (suite *PromoUsecaseTestSuite) TestReleaseStale() {
+	stale := []*model.PromoRedemption{{RedemptionID: 1}, {RedemptionID: 2}}
+	suite.promoRepoMock.On("GetStaleReservations", mock.AnythingOfType("time.Time")).Return(stale, nil)
+	suite.promoRepoMock.On("Release", stale[0]).Return(nil)
+	suite.promoRepoMock.On("Release", stale[1]).Return(repository.ErrPromoRedemptionProcessed)
+
+	res, err := suite.newUsecase().ReleaseStale()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, res)
+}
+
+func (suite *PromoUsecaseTestSuite) SetupTest() {
+	suite.promoRepoMock = new(promoRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+}
+
+func TestPromoUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(PromoUsecaseTestSuite))
+}
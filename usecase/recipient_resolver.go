@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+// qrPaymentPrefix is the payload prefix of the personal payment QR code,
+// followed by the user's payment handle.
+const qrPaymentPrefix = "saku://pay/"
+
+type RecipientResolver interface {
+	Resolve(sender *model.User, recipient string) (*model.User, error)
+}
+
+type recipientResolver struct {
+	userRepo repository.UserRepository
+}
+
+// Resolve finds the transfer recipient addressed by phone number, username,
+// email, payment handle ("@handle") or the content of a payment QR code.
+func (r *recipientResolver) Resolve(sender *model.User, recipient string) (*model.User, error) {
+	recipient = strings.TrimSpace(recipient)
+	if recipient == "" {
+		return nil, fmt.Errorf("recipient not found")
+	}
+
+	var user *model.User
+	var err error
+	switch {
+	case strings.HasPrefix(recipient, qrPaymentPrefix):
+		user, err = r.userRepo.GetByPaymentHandle(strings.ToLower(strings.TrimPrefix(recipient, qrPaymentPrefix)))
+	case strings.HasPrefix(recipient, "@"):
+		user, err = r.userRepo.GetByPaymentHandle(strings.ToLower(strings.TrimPrefix(recipient, "@")))
+	case strings.Contains(recipient, "@"):
+		user, err = r.userRepo.GetByEmail(recipient)
+	case isPhoneNumber(recipient):
+		user, err = r.userRepo.GetByPhone(recipient)
+	default:
+		user, err = r.userRepo.GetByUsername(recipient)
+	}
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("recipient not found")
+	}
+
+	if user.ID == sender.ID {
+		return nil, fmt.Errorf("cannot transfer to yourself")
+	}
+	return user, nil
+}
+
+func isPhoneNumber(s string) bool {
+	s = strings.TrimPrefix(s, "+")
+	for _, char := range s {
+		if !unicode.IsDigit(char) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func NewRecipientResolver(userRepo repository.UserRepository) RecipientResolver {
+	return &recipientResolver{
+		userRepo: userRepo,
+	}
+}
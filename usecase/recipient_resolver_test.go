@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecipientResolverTestSuite struct {
+	userRepoMock *userRepoMock
+	suite.Suite
+}
+
+func (suite *RecipientResolverTestSuite) TestResolve_Phone() {
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	res, err := NewRecipientResolver(suite.userRepoMock).Resolve(&dummySender, "08111111")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", res.ID)
+}
+
+func (suite *RecipientResolverTestSuite) TestResolve_Username() {
+	suite.userRepoMock.On("GetByUsername", "username1").Return(nil, nil)
+	res, err := NewRecipientResolver(suite.userRepoMock).Resolve(&dummySender, "username1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", res.ID)
+}
+
+func (suite *RecipientResolverTestSuite) TestResolve_Email() {
+	suite.userRepoMock.On("GetByEmail", "email1@mail.com").Return(&dummyUser[0], nil)
+	res, err := NewRecipientResolver(suite.userRepoMock).Resolve(&dummySender, "email1@mail.com")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", res.ID)
+}
+
+func (suite *RecipientResolverTestSuite) TestResolve_Handle() {
+	suite.userRepoMock.On("GetByPaymentHandle", "name1").Return(&dummyUser[0], nil)
+	res, err := NewRecipientResolver(suite.userRepoMock).Resolve(&dummySender, "@Name1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", res.ID)
+}
+
+func (suite *RecipientResolverTestSuite) TestResolve_QR() {
+	suite.userRepoMock.On("GetByPaymentHandle", "name1").Return(&dummyUser[0], nil)
+	res, err := NewRecipientResolver(suite.userRepoMock).Resolve(&dummySender, "saku://pay/name1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", res.ID)
+}
+
+func (suite *RecipientResolverTestSuite) TestResolve_NotFound() {
+	suite.userRepoMock.On("GetByEmail", "nobody@gmail.com").Return(nil, errors.New("email not found"))
+	res, err := NewRecipientResolver(suite.userRepoMock).Resolve(&dummySender, "nobody@gmail.com")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "recipient not found")
+}
+
+func (suite *RecipientResolverTestSuite) TestResolve_Self() {
+	sender := model.User{ID: "1"}
+	suite.userRepoMock.On("GetByPaymentHandle", "name1").Return(&dummyUser[0], nil)
+	res, err := NewRecipientResolver(suite.userRepoMock).Resolve(&sender, "@name1")
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "cannot transfer to yourself")
+}
+
+func (suite *RecipientResolverTestSuite) SetupTest() {
+	suite.userRepoMock = new(userRepoMock)
+}
+
+func TestRecipientResolverTestSuite(t *testing.T) {
+	suite.Run(t, new(RecipientResolverTestSuite))
+}
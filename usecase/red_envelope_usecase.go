@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	minEnvelopeShare       = 1000
+	maxEnvelopeSlots       = 100
+	maxEnvelopeMessage     = 100
+	envelopeLifetime       = 24 * time.Hour
+	envelopeClaimCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	envelopeClaimCodeSize  = 8
+)
+
+type RedEnvelopeUsecase interface {
+	Create(user *model.User, envelope *model.RedEnvelope) error
+	Claim(user *model.User, code string) (*model.Transfer, error)
+	FindByCode(code string) (*model.RedEnvelope, error)
+	FindByCreator(userID string) ([]*model.RedEnvelope, error)
+	RefundExpired() (int, error)
+}
+
+type redEnvelopeUsecase struct {
+	envelopeRepo repository.RedEnvelopeRepository
+	userRepo     repository.UserRepository
+	holdRepo     repository.HoldRepository
+	randIntn     func(n int) int
+}
+
+// randomCode draws size characters from chars using crypto/rand.
+func randomCode(chars string, size int) (string, error) {
+	code := make([]byte, size)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = chars[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func newClaimCode() (string, error) {
+	return randomCode(envelopeClaimCodeChars, envelopeClaimCodeSize)
+}
+
+func (u *redEnvelopeUsecase) Create(user *model.User, envelope *model.RedEnvelope) error {
+	if envelope.SplitType == "" {
+		envelope.SplitType = "Equal"
+	}
+	if envelope.SplitType != "Equal" && envelope.SplitType != "Random" {
+		return fmt.Errorf("split type must be Equal or Random")
+	}
+	if envelope.Slots < 1 || envelope.Slots > maxEnvelopeSlots {
+		return fmt.Errorf("slots must be 1 - 100")
+	}
+	if envelope.TotalAmount < envelope.Slots*minEnvelopeShare {
+		return fmt.Errorf("each slot must get at least 1,000")
+	}
+	envelope.Message = strings.TrimSpace(envelope.Message)
+	if len(envelope.Message) > maxEnvelopeMessage {
+		return fmt.Errorf("message must be at most 100 characters")
+	}
+	available, err := availableBalance(u.holdRepo, user)
+	if err != nil {
+		return err
+	}
+	if available < envelope.TotalAmount {
+		return fmt.Errorf("insufficient balance")
+	}
+
+	code, err := newClaimCode()
+	if err != nil {
+		return fmt.Errorf("failed to generate claim code: %v", err)
+	}
+
+	err = u.userRepo.UpdateBalance(user.ID, user.Balance-envelope.TotalAmount)
+	if err != nil {
+		return fmt.Errorf("failed to update user balance: %v", err)
+	}
+
+	envelope.ClaimCode = code
+	envelope.CreatorID = user.ID
+	envelope.CreatorName = user.Name
+	envelope.RemainingAmount = envelope.TotalAmount
+	envelope.RemainingSlots = envelope.Slots
+	envelope.Status = "Active"
+	envelope.ExpiredAt = time.Now().Add(envelopeLifetime)
+	return u.envelopeRepo.Create(envelope)
+}
+
+// share decides the amount of the next claim. The last slot always takes
+// what is left, so rounding never strands money in the envelope.
+func (u *redEnvelopeUsecase) share(envelope *model.RedEnvelope) int {
+	if envelope.RemainingSlots == 1 {
+		return envelope.RemainingAmount
+	}
+	if envelope.SplitType == "Equal" {
+		return envelope.TotalAmount / envelope.Slots
+	}
+
+	// Random shares stay below twice the average and leave the minimum for
+	// every slot after this one
+	upper := envelope.RemainingAmount / envelope.RemainingSlots * 2
+	if limit := envelope.RemainingAmount - (envelope.RemainingSlots-1)*minEnvelopeShare; upper > limit {
+		upper = limit
+	}
+	if upper <= minEnvelopeShare {
+		return minEnvelopeShare
+	}
+	return minEnvelopeShare + u.randIntn(upper-minEnvelopeShare+1)
+}
+
+func (u *redEnvelopeUsecase) Claim(user *model.User, code string) (*model.Transfer, error) {
+	envelope, err := u.envelopeRepo.GetByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if envelope.Status != "Active" || !time.Now().Before(envelope.ExpiredAt) {
+		return nil, fmt.Errorf("envelope is no longer available")
+	}
+	if envelope.CreatorID == user.ID {
+		return nil, fmt.Errorf("cannot claim your own envelope")
+	}
+
+	claimed, err := u.envelopeRepo.HasClaimed(envelope.EnvelopeID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, fmt.Errorf("envelope already claimed")
+	}
+
+	creator, err := u.userRepo.GetByiD(envelope.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &model.Transfer{
+		SenderID:             creator.ID,
+		RecipientID:          user.ID,
+		SenderName:           creator.Name,
+		RecipientName:        user.Name,
+		SenderPhoneNumber:    creator.Phone_Number,
+		RecipientPhoneNumber: user.Phone_Number,
+		Amount:               u.share(envelope),
+		Note:                 envelope.Message,
+	}
+	if err := u.envelopeRepo.Claim(envelope, transfer); err != nil {
+		return nil, err
+	}
+
+	err = u.userRepo.UpdateBalance(user.ID, user.Balance+transfer.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user balance: %v", err)
+	}
+	return transfer, nil
+}
+
+func (u *redEnvelopeUsecase) FindByCode(code string) (*model.RedEnvelope, error) {
+	envelope, err := u.envelopeRepo.GetByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	claims, err := u.envelopeRepo.GetClaims(envelope.EnvelopeID)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Claims = claims
+	return envelope, nil
+}
+
+func (u *redEnvelopeUsecase) FindByCreator(userID string) ([]*model.RedEnvelope, error) {
+	return u.envelopeRepo.GetByCreator(userID)
+}
+
+// RefundExpired returns the unclaimed money of every expired envelope to its
+// creator and reports how many envelopes were closed.
+func (u *redEnvelopeUsecase) RefundExpired() (int, error) {
+	envelopes, err := u.envelopeRepo.GetExpired(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	refunded := 0
+	for _, envelope := range envelopes {
+		if err := u.envelopeRepo.Refund(envelope); err != nil {
+			// Claimed in the meantime; the next run picks it up again
+			continue
+		}
+		refunded++
+		if envelope.RemainingAmount == 0 {
+			continue
+		}
+
+		creator, err := u.userRepo.GetByiD(envelope.CreatorID)
+		if err != nil {
+			return refunded, err
+		}
+		err = u.userRepo.UpdateBalance(creator.ID, creator.Balance+envelope.RemainingAmount)
+		if err != nil {
+			return refunded, fmt.Errorf("failed to update user balance: %v", err)
+		}
+	}
+	return refunded, nil
+}
+
+func NewRedEnvelopeUsecase(envelopeRepo repository.RedEnvelopeRepository, userRepo repository.UserRepository, holdRepo repository.HoldRepository) RedEnvelopeUsecase {
+	return &redEnvelopeUsecase{
+		envelopeRepo: envelopeRepo,
+		userRepo:     userRepo,
+		holdRepo:     holdRepo,
+		randIntn:     mrand.Intn,
+	}
+}
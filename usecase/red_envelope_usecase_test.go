@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type redEnvelopeRepoMock struct {
+	mock.Mock
+}
+
+func (r *redEnvelopeRepoMock) Create(envelope *model.RedEnvelope) error {
+	args := r.Called(envelope)
+	return args.Error(0)
+}
+
+func (r *redEnvelopeRepoMock) GetByCode(code string) (*model.RedEnvelope, error) {
+	args := r.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RedEnvelope), args.Error(1)
+}
+
+func (r *redEnvelopeRepoMock) GetByCreator(userID string) ([]*model.RedEnvelope, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RedEnvelope), args.Error(1)
+}
+
+func (r *redEnvelopeRepoMock) GetClaims(envelopeID int) ([]*model.RedEnvelopeClaim, error) {
+	args := r.Called(envelopeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RedEnvelopeClaim), args.Error(1)
+}
+
+func (r *redEnvelopeRepoMock) HasClaimed(envelopeID int, userID string) (bool, error) {
+	args := r.Called(envelopeID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *redEnvelopeRepoMock) Claim(envelope *model.RedEnvelope, transfer *model.Transfer) error {
+	args := r.Called(envelope, transfer)
+	return args.Error(0)
+}
+
+func (r *redEnvelopeRepoMock) GetExpired(now time.Time) ([]*model.RedEnvelope, error) {
+	args := r.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.RedEnvelope), args.Error(1)
+}
+
+func (r *redEnvelopeRepoMock) Refund(envelope *model.RedEnvelope) error {
+	args := r.Called(envelope)
+	return args.Error(0)
+}
+
+type RedEnvelopeUsecaseTestSuite struct {
+	envelopeRepoMock *redEnvelopeRepoMock
+	userRepoMock     *userRepoMock
+	holdRepoMock     *holdRepoMock
+	suite.Suite
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) usecase() *redEnvelopeUsecase {
+	uc := NewRedEnvelopeUsecase(suite.envelopeRepoMock, suite.userRepoMock, suite.holdRepoMock).(*redEnvelopeUsecase)
+	uc.randIntn = func(n int) int { return n - 1 }
+	return uc
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) activeEnvelope() *model.RedEnvelope {
+	return &model.RedEnvelope{
+		EnvelopeID:      1,
+		ClaimCode:       "ABCD2345",
+		CreatorID:       "1",
+		TotalAmount:     10000,
+		RemainingAmount: 10000,
+		Slots:           3,
+		RemainingSlots:  3,
+		SplitType:       "Equal",
+		Status:          "Active",
+		ExpiredAt:       time.Now().Add(time.Hour),
+	}
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestCreate_Success() {
+	user := dummySender
+	envelope := &model.RedEnvelope{TotalAmount: 50000, Slots: 5, SplitType: "Random", Message: "Selamat Lebaran"}
+	suite.userRepoMock.On("UpdateBalance", user.ID, 50000).Return(nil)
+	suite.envelopeRepoMock.On("Create", envelope).Return(nil)
+
+	err := suite.usecase().Create(&user, envelope)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), envelope.ClaimCode, envelopeClaimCodeSize)
+	assert.Equal(suite.T(), 5, envelope.RemainingSlots)
+	assert.Equal(suite.T(), "Active", envelope.Status)
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestCreate_SlotTooSmall() {
+	user := dummySender
+	envelope := &model.RedEnvelope{TotalAmount: 5000, Slots: 10}
+
+	err := suite.usecase().Create(&user, envelope)
+
+	assert.EqualError(suite.T(), err, "each slot must get at least 1,000")
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestCreate_InsufficientBalance() {
+	user := dummySender
+	envelope := &model.RedEnvelope{TotalAmount: 500000, Slots: 10}
+
+	err := suite.usecase().Create(&user, envelope)
+
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.envelopeRepoMock.AssertNotCalled(suite.T(), "Create", envelope)
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestShare_Equal() {
+	envelope := suite.activeEnvelope()
+	assert.Equal(suite.T(), 3333, suite.usecase().share(envelope))
+
+	envelope.RemainingSlots = 1
+	envelope.RemainingAmount = 3334
+	assert.Equal(suite.T(), 3334, suite.usecase().share(envelope))
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestShare_RandomLeavesMinimum() {
+	envelope := suite.activeEnvelope()
+	envelope.SplitType = "Random"
+	envelope.RemainingAmount = 5000
+
+	// Twice the average (3332) would not leave 1,000 for each of the other two slots
+	assert.Equal(suite.T(), 3000, suite.usecase().share(envelope))
+
+	envelope.RemainingAmount = 3000
+	assert.Equal(suite.T(), 1000, suite.usecase().share(envelope))
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestClaim_Success() {
+	user := dummySender
+	envelope := suite.activeEnvelope()
+	creator := &model.User{ID: "1", Name: "name1", Phone_Number: "08111111"}
+	suite.envelopeRepoMock.On("GetByCode", "ABCD2345").Return(envelope, nil)
+	suite.envelopeRepoMock.On("HasClaimed", 1, user.ID).Return(false, nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(creator, nil)
+	suite.envelopeRepoMock.On("Claim", envelope, mock.AnythingOfType("*model.Transfer")).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", user.ID, user.Balance+3333).Return(nil)
+
+	res, err := suite.usecase().Claim(&user, "abcd2345")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3333, res.Amount)
+	assert.Equal(suite.T(), "1", res.SenderID)
+	assert.Equal(suite.T(), user.ID, res.RecipientID)
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestClaim_AlreadyClaimed() {
+	user := dummySender
+	suite.envelopeRepoMock.On("GetByCode", "ABCD2345").Return(suite.activeEnvelope(), nil)
+	suite.envelopeRepoMock.On("HasClaimed", 1, user.ID).Return(true, nil)
+
+	res, err := suite.usecase().Claim(&user, "ABCD2345")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "envelope already claimed")
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestClaim_Expired() {
+	user := dummySender
+	envelope := suite.activeEnvelope()
+	envelope.ExpiredAt = time.Now().Add(-time.Minute)
+	suite.envelopeRepoMock.On("GetByCode", "ABCD2345").Return(envelope, nil)
+
+	res, err := suite.usecase().Claim(&user, "ABCD2345")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "envelope is no longer available")
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestClaim_OwnEnvelope() {
+	user := dummySender
+	envelope := suite.activeEnvelope()
+	envelope.CreatorID = user.ID
+	suite.envelopeRepoMock.On("GetByCode", "ABCD2345").Return(envelope, nil)
+
+	res, err := suite.usecase().Claim(&user, "ABCD2345")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "cannot claim your own envelope")
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestRefundExpired_Success() {
+	envelope := suite.activeEnvelope()
+	envelope.RemainingAmount = 6000
+	creator := &model.User{ID: "1", Balance: 1000}
+	suite.envelopeRepoMock.On("GetExpired", mock.AnythingOfType("time.Time")).Return([]*model.RedEnvelope{envelope}, nil)
+	suite.envelopeRepoMock.On("Refund", envelope).Return(nil)
+	suite.userRepoMock.On("GetByiD", "1").Return(creator, nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 7000).Return(nil)
+
+	refunded, err := suite.usecase().RefundExpired()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, refunded)
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) TestRefundExpired_ClaimedMeanwhile() {
+	envelope := suite.activeEnvelope()
+	suite.envelopeRepoMock.On("GetExpired", mock.AnythingOfType("time.Time")).Return([]*model.RedEnvelope{envelope}, nil)
+	suite.envelopeRepoMock.On("Refund", envelope).Return(errors.New("envelope is no longer available"))
+
+	refunded, err := suite.usecase().RefundExpired()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, refunded)
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", mock.Anything, mock.Anything)
+}
+
+func (suite *RedEnvelopeUsecaseTestSuite) SetupTest() {
+	suite.envelopeRepoMock = new(redEnvelopeRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.holdRepoMock.On("GetHeldAmount", mock.Anything).Return(0, nil)
+}
+
+func TestRedEnvelopeUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(RedEnvelopeUsecaseTestSuite))
+}
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	referralCodeSize        = 8
+	referralCodeAttempts    = 5
+	defaultReferrerReward   = 100
+	defaultRefereeReward    = 50
+	defaultReferralMinSpend = 50000
+)
+
+// ReferralConfig holds the tunable parts of the referral program. Rewards
+// are paid as points unless RewardType is "Balance". Values that are not
+// positive fall back to the defaults.
+type ReferralConfig struct {
+	RewardType     string
+	ReferrerReward int
+	RefereeReward  int
+	// MinAmount is the smallest first deposit or transfer that qualifies
+	// the referee.
+	MinAmount int
+}
+
+// referralQualifyingTypes are the transaction types that complete a referral.
+var referralQualifyingTypes = map[string]bool{
+	"Deposit":  true,
+	"Transfer": true,
+}
+
+type ReferralUsecase interface {
+	CheckCode(code string, phoneNumber string) (*model.User, error)
+	Enroll(referee *model.User, code string) (*model.Referral, error)
+	Qualify(refereeID string, transactionType string, transactionID int, amount int, counterpartyID string) (*model.Referral, error)
+	FindCode(userID string) (string, error)
+	FindDashboard(userID string) (*model.ReferralDashboard, error)
+}
+
+type referralUsecase struct {
+	referralRepo repository.ReferralRepository
+	userRepo     repository.UserRepository
+	pointUsecase PointUsecase
+	config       ReferralConfig
+	notify       func(token string, title string, body string) error
+}
+
+// normalizePhone strips the country prefix so 0812..., 62812... and
+// +62812... compare equal.
+func normalizePhone(phone string) string {
+	phone = strings.TrimPrefix(strings.TrimSpace(phone), "+")
+	if strings.HasPrefix(phone, "62") {
+		return phone[2:]
+	}
+	return strings.TrimPrefix(phone, "0")
+}
+
+// CheckCode validates a referral code before the referee is registered and
+// returns the referrer. A user cannot refer their own phone number.
+func (u *referralUsecase) CheckCode(code string, phoneNumber string) (*model.User, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	referrer, err := u.referralRepo.GetReferrerByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if normalizePhone(referrer.Phone_Number) == normalizePhone(phoneNumber) {
+		return nil, fmt.Errorf("referral not allowed")
+	}
+	return referrer, nil
+}
+
+// Enroll records a pending referral for a freshly registered user.
+func (u *referralUsecase) Enroll(referee *model.User, code string) (*model.Referral, error) {
+	referrer, err := u.CheckCode(code, referee.Phone_Number)
+	if err != nil {
+		return nil, err
+	}
+	if referrer.ID == referee.ID {
+		return nil, fmt.Errorf("referral not allowed")
+	}
+
+	referral := &model.Referral{
+		ReferrerID:  referrer.ID,
+		RefereeID:   referee.ID,
+		RefereeName: referee.Name,
+		CreatedAt:   time.Now(),
+	}
+	if err := u.referralRepo.Create(referral); err != nil {
+		return nil, err
+	}
+	return referral, nil
+}
+
+// Qualify completes the pending referral of a user on their first qualifying
+// transaction and pays both sides. It returns nil when there is nothing to
+// complete. A referral whose users share a device is rejected instead, as is
+// a transfer straight to the referrer, which would just move the money back.
+func (u *referralUsecase) Qualify(refereeID string, transactionType string, transactionID int, amount int, counterpartyID string) (*model.Referral, error) {
+	if !referralQualifyingTypes[transactionType] || amount < u.config.MinAmount {
+		return nil, nil
+	}
+	referral, err := u.referralRepo.GetPendingByReferee(refereeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrReferralNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if counterpartyID != "" && counterpartyID == referral.ReferrerID {
+		return nil, nil
+	}
+
+	referee, err := u.userRepo.GetByIDToken(referral.RefereeID)
+	if err != nil {
+		return nil, err
+	}
+	referrer, err := u.userRepo.GetByIDToken(referral.ReferrerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	referral.TransactionID = transactionID
+	referral.CompletedAt = &now
+	if referee.Token != "" && referee.Token == referrer.Token {
+		referral.Status = "Rejected"
+		referral.RejectReason = "same device"
+		if err := u.referralRepo.Complete(referral); err != nil {
+			return nil, err
+		}
+		return referral, nil
+	}
+
+	referral.Status = "Rewarded"
+	referral.RewardType = u.config.RewardType
+	referral.ReferrerReward = u.config.ReferrerReward
+	referral.RefereeReward = u.config.RefereeReward
+	if err := u.referralRepo.Complete(referral); err != nil {
+		return nil, err
+	}
+
+	if err := u.payReward(referral, referrer, referral.ReferrerReward, "Referral bonus for inviting "+referee.Name); err != nil {
+		return nil, err
+	}
+	if err := u.payReward(referral, referee, referral.RefereeReward, "Referral bonus for joining"); err != nil {
+		return nil, err
+	}
+
+	reward := u.formatReward(referral.ReferrerReward)
+	_ = u.notify(referrer.Token, "Bonus Referral", referee.Name+" telah bertransaksi. Anda mendapatkan "+reward)
+	_ = u.notify(referee.Token, "Bonus Referral", "Anda mendapatkan "+u.formatReward(referral.RefereeReward)+" dari program referral")
+	return referral, nil
+}
+
+func (u *referralUsecase) payReward(referral *model.Referral, user *model.User, amount int, description string) error {
+	if amount <= 0 {
+		return nil
+	}
+	if referral.RewardType == "Balance" {
+		reward := &model.ReferralReward{ReferralID: referral.ReferralID, UserID: user.ID, Amount: amount}
+		if err := u.referralRepo.RecordBalanceReward(reward); err != nil {
+			return err
+		}
+		if err := u.userRepo.UpdateBalance(user.ID, user.Balance+amount); err != nil {
+			return fmt.Errorf("failed to update user balance: %v", err)
+		}
+		user.Balance += amount
+		return nil
+	}
+
+	_, err := u.pointUsecase.Adjust(user.ID, &model.PointAdjustment{Points: amount, Description: description})
+	return err
+}
+
+func (u *referralUsecase) formatReward(amount int) string {
+	if u.config.RewardType == "Balance" {
+		return "Rp " + strconv.FormatFloat(float64(amount)/1000, 'f', 3, 64)
+	}
+	return strconv.Itoa(amount) + " poin"
+}
+
+// FindCode returns the referral code of a user, giving them one the first
+// time it is asked for.
+func (u *referralUsecase) FindCode(userID string) (string, error) {
+	code, err := u.referralRepo.GetCode(userID)
+	if err != nil || code != "" {
+		return code, err
+	}
+
+	for i := 0; i < referralCodeAttempts; i++ {
+		code, err = randomCode(envelopeClaimCodeChars, referralCodeSize)
+		if err != nil {
+			return "", err
+		}
+		err = u.referralRepo.SetCode(userID, code)
+		if err == nil {
+			return code, nil
+		}
+		if errors.Is(err, repository.ErrReferralCodeSet) {
+			return u.referralRepo.GetCode(userID)
+		}
+		if !errors.Is(err, repository.ErrReferralCodeUsed) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("failed to generate referral code")
+}
+
+func (u *referralUsecase) FindDashboard(userID string) (*model.ReferralDashboard, error) {
+	code, err := u.FindCode(userID)
+	if err != nil {
+		return nil, err
+	}
+	referrals, err := u.referralRepo.GetByReferrer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &model.ReferralDashboard{
+		ReferralCode: code,
+		RewardType:   u.config.RewardType,
+		Referrals:    referrals,
+	}
+	for _, referral := range referrals {
+		switch referral.Status {
+		case "Pending":
+			dashboard.Pending++
+		case "Rewarded":
+			dashboard.Rewarded++
+			dashboard.TotalEarned += referral.ReferrerReward
+		case "Rejected":
+			dashboard.Rejected++
+		}
+	}
+	return dashboard, nil
+}
+
+func NewReferralUsecase(referralRepo repository.ReferralRepository, userRepo repository.UserRepository, pointUsecase PointUsecase, config ReferralConfig) ReferralUsecase {
+	if config.RewardType != "Balance" {
+		config.RewardType = "Point"
+	}
+	if config.ReferrerReward <= 0 {
+		config.ReferrerReward = defaultReferrerReward
+	}
+	if config.RefereeReward <= 0 {
+		config.RefereeReward = defaultRefereeReward
+	}
+	if config.MinAmount <= 0 {
+		config.MinAmount = defaultReferralMinSpend
+	}
+	return &referralUsecase{
+		referralRepo: referralRepo,
+		userRepo:     userRepo,
+		pointUsecase: pointUsecase,
+		config:       config,
+		notify:       model.SendFCMNotification,
+	}
+}
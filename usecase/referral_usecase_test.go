@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type referralRepoMock struct {
+	mock.Mock
+}
+
+func (r *referralRepoMock) GetCode(userID string) (string, error) {
+	args := r.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (r *referralRepoMock) SetCode(userID string, code string) error {
+	args := r.Called(userID, code)
+	return args.Error(0)
+}
+
+func (r *referralRepoMock) GetReferrerByCode(code string) (*model.User, error) {
+	args := r.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (r *referralRepoMock) Create(referral *model.Referral) error {
+	args := r.Called(referral)
+	return args.Error(0)
+}
+
+func (r *referralRepoMock) GetPendingByReferee(refereeID string) (*model.Referral, error) {
+	args := r.Called(refereeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Referral), args.Error(1)
+}
+
+func (r *referralRepoMock) Complete(referral *model.Referral) error {
+	args := r.Called(referral)
+	return args.Error(0)
+}
+
+func (r *referralRepoMock) GetByReferrer(referrerID string) ([]*model.Referral, error) {
+	args := r.Called(referrerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Referral), args.Error(1)
+}
+
+func (r *referralRepoMock) RecordBalanceReward(reward *model.ReferralReward) error {
+	args := r.Called(reward)
+	return args.Error(0)
+}
+
+type ReferralUsecaseTestSuite struct {
+	referralRepoMock *referralRepoMock
+	userRepoMock     *userRepoMock
+	ledgerRepoMock   *pointLedgerRepoMock
+	suite.Suite
+}
+
+func (suite *ReferralUsecaseTestSuite) newUsecase(config ReferralConfig) ReferralUsecase {
+	pointUsecase := NewPointUsecase(new(pointRuleRepoMock), suite.ledgerRepoMock, suite.userRepoMock, PointConfig{ExpiryMonths: 12})
+	uc := NewReferralUsecase(suite.referralRepoMock, suite.userRepoMock, pointUsecase, config)
+	uc.(*referralUsecase).notify = func(token string, title string, body string) error {
+		return nil
+	}
+	return uc
+}
+
+func (suite *ReferralUsecaseTestSuite) TestNormalizePhone() {
+	assert.Equal(suite.T(), "81234567890", normalizePhone("081234567890"))
+	assert.Equal(suite.T(), "81234567890", normalizePhone("6281234567890"))
+	assert.Equal(suite.T(), "81234567890", normalizePhone("+6281234567890"))
+}
+
+func (suite *ReferralUsecaseTestSuite) TestCheckCode_SamePhone() {
+	suite.referralRepoMock.On("GetReferrerByCode", "ABCD2345").Return(&model.User{ID: "1", Phone_Number: "081234567890"}, nil)
+
+	res, err := suite.newUsecase(ReferralConfig{}).CheckCode(" abcd2345 ", "6281234567890")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "referral not allowed")
+}
+
+func (suite *ReferralUsecaseTestSuite) TestEnroll_Success() {
+	suite.referralRepoMock.On("GetReferrerByCode", "ABCD2345").Return(&model.User{ID: "1", Phone_Number: "081234567890"}, nil)
+	suite.referralRepoMock.On("Create", mock.AnythingOfType("*model.Referral")).Return(nil)
+
+	res, err := suite.newUsecase(ReferralConfig{}).Enroll(&model.User{ID: "2", Name: "name2", Phone_Number: "081299999999"}, "ABCD2345")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", res.ReferrerID)
+	assert.Equal(suite.T(), "2", res.RefereeID)
+}
+
+func (suite *ReferralUsecaseTestSuite) TestQualify_BelowMinimum() {
+	res, err := suite.newUsecase(ReferralConfig{MinAmount: 50000}).Qualify("2", "Deposit", 10, 10000, "")
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), res)
+	suite.referralRepoMock.AssertNotCalled(suite.T(), "GetPendingByReferee", mock.Anything)
+}
+
+func (suite *ReferralUsecaseTestSuite) TestQualify_NoPendingReferral() {
+	suite.referralRepoMock.On("GetPendingByReferee", "2").Return(nil, repository.ErrReferralNotFound)
+
+	res, err := suite.newUsecase(ReferralConfig{}).Qualify("2", "Deposit", 10, 100000, "")
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), res)
+}
+
+func (suite *ReferralUsecaseTestSuite) TestQualify_TransferToReferrerIgnored() {
+	suite.referralRepoMock.On("GetPendingByReferee", "2").Return(&model.Referral{ReferralID: 4, ReferrerID: "1", RefereeID: "2", Status: "Pending"}, nil)
+
+	res, err := suite.newUsecase(ReferralConfig{}).Qualify("2", "Transfer", 10, 100000, "1")
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), res)
+	suite.referralRepoMock.AssertNotCalled(suite.T(), "Complete", mock.Anything)
+}
+
+func (suite *ReferralUsecaseTestSuite) TestQualify_SameDeviceRejected() {
+	suite.referralRepoMock.On("GetPendingByReferee", "2").Return(&model.Referral{ReferralID: 4, ReferrerID: "1", RefereeID: "2", Status: "Pending"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "2").Return(&model.User{ID: "2", Token: "device-1"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "1").Return(&model.User{ID: "1", Token: "device-1"}, nil)
+	suite.referralRepoMock.On("Complete", mock.AnythingOfType("*model.Referral")).Return(nil)
+
+	res, err := suite.newUsecase(ReferralConfig{}).Qualify("2", "Deposit", 10, 100000, "")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Rejected", res.Status)
+	assert.Equal(suite.T(), "same device", res.RejectReason)
+	suite.ledgerRepoMock.AssertNotCalled(suite.T(), "Credit", mock.Anything)
+}
+
+func (suite *ReferralUsecaseTestSuite) TestQualify_PointReward() {
+	suite.referralRepoMock.On("GetPendingByReferee", "2").Return(&model.Referral{ReferralID: 4, ReferrerID: "1", RefereeID: "2", Status: "Pending"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "2").Return(&model.User{ID: "2", Name: "name2", Token: "device-2"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "1").Return(&model.User{ID: "1", Name: "name1", Token: "device-1"}, nil)
+	suite.userRepoMock.On("GetByiD", mock.AnythingOfType("string")).Return(&model.User{}, nil)
+	suite.referralRepoMock.On("Complete", mock.AnythingOfType("*model.Referral")).Return(nil)
+	var credited []int
+	suite.ledgerRepoMock.On("Credit", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		credited = append(credited, args.Get(0).(*model.PointLedger).Points)
+	})
+
+	res, err := suite.newUsecase(ReferralConfig{ReferrerReward: 200, RefereeReward: 100}).Qualify("2", "Transfer", 10, 100000, "3")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Rewarded", res.Status)
+	assert.Equal(suite.T(), "Point", res.RewardType)
+	assert.Equal(suite.T(), 10, res.TransactionID)
+	assert.Equal(suite.T(), []int{200, 100}, credited)
+}
+
+func (suite *ReferralUsecaseTestSuite) TestQualify_AlreadyCompleted() {
+	suite.referralRepoMock.On("GetPendingByReferee", "2").Return(&model.Referral{ReferralID: 4, ReferrerID: "1", RefereeID: "2", Status: "Pending"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "2").Return(&model.User{ID: "2"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "1").Return(&model.User{ID: "1"}, nil)
+	suite.referralRepoMock.On("Complete", mock.AnythingOfType("*model.Referral")).Return(errors.New("referral already completed"))
+
+	res, err := suite.newUsecase(ReferralConfig{RewardType: "Balance"}).Qualify("2", "Deposit", 10, 100000, "")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "referral already completed")
+	suite.referralRepoMock.AssertNotCalled(suite.T(), "RecordBalanceReward", mock.Anything)
+}
+
+func (suite *ReferralUsecaseTestSuite) TestQualify_BalanceReward() {
+	suite.referralRepoMock.On("GetPendingByReferee", "2").Return(&model.Referral{ReferralID: 4, ReferrerID: "1", RefereeID: "2", Status: "Pending"}, nil)
+	suite.userRepoMock.On("GetByIDToken", "2").Return(&model.User{ID: "2", Balance: 100000}, nil)
+	suite.userRepoMock.On("GetByIDToken", "1").Return(&model.User{ID: "1", Balance: 5000}, nil)
+	suite.referralRepoMock.On("Complete", mock.AnythingOfType("*model.Referral")).Return(nil)
+	suite.referralRepoMock.On("RecordBalanceReward", mock.AnythingOfType("*model.ReferralReward")).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 25000).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", "2", 110000).Return(nil)
+
+	res, err := suite.newUsecase(ReferralConfig{RewardType: "Balance", ReferrerReward: 20000, RefereeReward: 10000}).Qualify("2", "Deposit", 10, 100000, "")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Balance", res.RewardType)
+	suite.userRepoMock.AssertExpectations(suite.T())
+}
+
+func (suite *ReferralUsecaseTestSuite) TestFindCode_RetriesTakenCode() {
+	suite.referralRepoMock.On("GetCode", "1").Return("", nil)
+	suite.referralRepoMock.On("SetCode", "1", mock.AnythingOfType("string")).Return(repository.ErrReferralCodeUsed).Once()
+	suite.referralRepoMock.On("SetCode", "1", mock.AnythingOfType("string")).Return(nil).Once()
+
+	res, err := suite.newUsecase(ReferralConfig{}).FindCode("1")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), res, referralCodeSize)
+	suite.referralRepoMock.AssertNumberOfCalls(suite.T(), "SetCode", 2)
+}
+
+func (suite *ReferralUsecaseTestSuite) TestFindDashboard_Success() {
+	referrals := []*model.Referral{
+		{ReferralID: 3, Status: "Rewarded", ReferrerReward: 100},
+		{ReferralID: 2, Status: "Pending"},
+		{ReferralID: 1, Status: "Rejected"},
+	}
+	suite.referralRepoMock.On("GetCode", "1").Return("ABCD2345", nil)
+	suite.referralRepoMock.On("GetByReferrer", "1").Return(referrals, nil)
+
+	res, err := suite.newUsecase(ReferralConfig{}).FindDashboard("1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "ABCD2345", res.ReferralCode)
+	assert.Equal(suite.T(), 1, res.Pending)
+	assert.Equal(suite.T(), 1, res.Rewarded)
+	assert.Equal(suite.T(), 1, res.Rejected)
+	assert.Equal(suite.T(), 100, res.TotalEarned)
+}
+
+func (suite *ReferralUsecaseTestSuite) SetupTest() {
+	suite.referralRepoMock = new(referralRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.ledgerRepoMock = new(pointLedgerRepoMock)
+}
+
+func TestReferralUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ReferralUsecaseTestSuite))
+}
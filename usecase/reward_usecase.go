@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	maxVoucherCodeRows   = 5000
+	maxVoucherCodeLength = 64
+)
+
+type RewardUsecase interface {
+	UploadCodes(peID int, file io.Reader) (*model.VoucherCodeUpload, error)
+	Fulfill(redeem *model.Redeem) (*model.VoucherCode, error)
+	FindVouchers(userID string) ([]*model.VoucherCode, error)
+}
+
+type rewardUsecase struct {
+	pointExchangeRepo repository.PointExchangeRepository
+	voucherRepo       repository.VoucherCodeRepository
+	pointUsecase      PointUsecase
+	provider          model.RewardProvider
+}
+
+// voucherStatus tells whether a code can still be used at the given time.
+func voucherStatus(code *model.VoucherCode, at time.Time) string {
+	if code.ValidUntil != nil && !code.ValidUntil.After(at) {
+		return "Expired"
+	}
+	return "Active"
+}
+
+// parseVoucherCodes reads code[,valid_until] rows. A valid_until date keeps
+// the code usable until the end of that day. Blank, malformed, expired and
+// repeated rows are counted as skipped.
+func parseVoucherCodes(file io.Reader, now time.Time) ([]*model.VoucherCode, int, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid csv file")
+	}
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "code") {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, 0, fmt.Errorf("csv file is empty")
+	}
+	if len(records) > maxVoucherCodeRows {
+		return nil, 0, fmt.Errorf("maximum 5000 codes per upload")
+	}
+
+	var codes []*model.VoucherCode
+	skipped := 0
+	seen := make(map[string]bool)
+	for _, record := range records {
+		code := &model.VoucherCode{Code: strings.TrimSpace(record[0])}
+		if code.Code == "" || len(code.Code) > maxVoucherCodeLength || seen[code.Code] {
+			skipped++
+			continue
+		}
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(record[1]), now.Location())
+			if err != nil {
+				skipped++
+				continue
+			}
+			validUntil := day.AddDate(0, 0, 1).Add(-time.Second)
+			if !validUntil.After(now) {
+				skipped++
+				continue
+			}
+			code.ValidUntil = &validUntil
+		}
+		seen[code.Code] = true
+		codes = append(codes, code)
+	}
+	return codes, skipped, nil
+}
+
+func (u *rewardUsecase) UploadCodes(peID int, file io.Reader) (*model.VoucherCodeUpload, error) {
+	pe, err := u.pointExchangeRepo.GetByID(peID)
+	if err != nil {
+		return nil, err
+	}
+	if pe.FulfillmentType != "Voucher Code" {
+		return nil, fmt.Errorf("reward does not use voucher codes")
+	}
+
+	codes, skipped, err := parseVoucherCodes(file, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	added, err := u.voucherRepo.AddCodes(peID, codes)
+	if err != nil {
+		return nil, err
+	}
+	return &model.VoucherCodeUpload{
+		PEID:    peID,
+		Added:   added,
+		Skipped: skipped + len(codes) - added,
+	}, nil
+}
+
+// Fulfill delivers the reward of a successful redemption. Rewards without a
+// fulfillment type need nothing and return no code. When delivery fails the
+// redemption is marked failed and the points are given back.
+func (u *rewardUsecase) Fulfill(redeem *model.Redeem) (*model.VoucherCode, error) {
+	pe, err := u.pointExchangeRepo.GetByID(redeem.PEID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var code *model.VoucherCode
+	switch pe.FulfillmentType {
+	case "Voucher Code":
+		code, err = u.voucherRepo.Assign(pe.PE_ID, redeem.UserID, redeem.TransactionID, now)
+	case "Provider":
+		if u.provider == nil {
+			err = fmt.Errorf("no reward provider is configured")
+			break
+		}
+		code, err = u.provider.Issue(pe, redeem.UserID, "RDM"+strconv.Itoa(redeem.TransactionID))
+		if err == nil {
+			code.PEID = pe.PE_ID
+			code.UserID = redeem.UserID
+			code.TransactionID = redeem.TransactionID
+			code.AssignedAt = &now
+			err = u.voucherRepo.SaveIssued(code)
+		}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		if refundErr := u.refund(redeem, pe); refundErr != nil {
+			return nil, fmt.Errorf("failed to fulfill reward: %v; failed to refund: %v", err, refundErr)
+		}
+		return nil, fmt.Errorf("failed to fulfill reward: %v", err)
+	}
+
+	code.Reward = pe.Reward
+	code.Status = voucherStatus(code, now)
+	return code, nil
+}
+
+func (u *rewardUsecase) refund(redeem *model.Redeem, pe *model.PointExchange) error {
+	if err := u.voucherRepo.FailRedeem(redeem.TransactionID, pe.PE_ID); err != nil {
+		return err
+	}
+	redeem.Status = "Failed"
+	description := "Refund " + pe.Reward
+	if len(description) > maxPointDescription {
+		description = description[:maxPointDescription]
+	}
+	_, err := u.pointUsecase.Refund(redeem.UserID, redeem.TransactionID, redeem.Amount, redeem.PointsExpireAt, description)
+	return err
+}
+
+func (u *rewardUsecase) FindVouchers(userID string) ([]*model.VoucherCode, error) {
+	codes, err := u.voucherRepo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, code := range codes {
+		code.Status = voucherStatus(code, now)
+	}
+	return codes, nil
+}
+
+func NewRewardUsecase(pointExchangeRepo repository.PointExchangeRepository, voucherRepo repository.VoucherCodeRepository, pointUsecase PointUsecase, provider model.RewardProvider) RewardUsecase {
+	return &rewardUsecase{
+		pointExchangeRepo: pointExchangeRepo,
+		voucherRepo:       voucherRepo,
+		pointUsecase:      pointUsecase,
+		provider:          provider,
+	}
+}
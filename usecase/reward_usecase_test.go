@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type voucherCodeRepoMock struct {
+	mock.Mock
+}
+
+func (r *voucherCodeRepoMock) AddCodes(peID int, codes []*model.VoucherCode) (int, error) {
+	args := r.Called(peID, codes)
+	return args.Int(0), args.Error(1)
+}
+
+func (r *voucherCodeRepoMock) Assign(peID int, userID string, transactionID int, at time.Time) (*model.VoucherCode, error) {
+	args := r.Called(peID, userID, transactionID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.VoucherCode), args.Error(1)
+}
+
+func (r *voucherCodeRepoMock) SaveIssued(code *model.VoucherCode) error {
+	args := r.Called(code)
+	return args.Error(0)
+}
+
+func (r *voucherCodeRepoMock) GetByUser(userID string) ([]*model.VoucherCode, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.VoucherCode), args.Error(1)
+}
+
+func (r *voucherCodeRepoMock) FailRedeem(transactionID int, peID int) error {
+	args := r.Called(transactionID, peID)
+	return args.Error(0)
+}
+
+type rewardProviderMock struct {
+	mock.Mock
+}
+
+func (p *rewardProviderMock) Issue(reward *model.PointExchange, userID string, reference string) (*model.VoucherCode, error) {
+	args := p.Called(reward, userID, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.VoucherCode), args.Error(1)
+}
+
+type RewardUsecaseTestSuite struct {
+	pointExchangeRepoMock *pointExchangeRepoMock
+	voucherRepoMock       *voucherCodeRepoMock
+	ledgerRepoMock        *pointLedgerRepoMock
+	userRepoMock          *userRepoMock
+	providerMock          *rewardProviderMock
+	suite.Suite
+}
+
+func (suite *RewardUsecaseTestSuite) usecase() RewardUsecase {
+	pointUsecase := NewPointUsecase(new(pointRuleRepoMock), suite.ledgerRepoMock, suite.userRepoMock, PointConfig{ExpiryMonths: 12})
+	return NewRewardUsecase(suite.pointExchangeRepoMock, suite.voucherRepoMock, pointUsecase, suite.providerMock)
+}
+
+func (suite *RewardUsecaseTestSuite) TestParseVoucherCodes_SkipsBadRows() {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	file := "code,valid_until\nKOPI-001,2026-12-31\nKOPI-002\nKOPI-001\n,\nKOPI-003,31/12/2026\nKOPI-004,2026-10-18\n"
+
+	codes, skipped, err := parseVoucherCodes(strings.NewReader(file), now)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 4, skipped)
+	assert.Len(suite.T(), codes, 2)
+	assert.Equal(suite.T(), time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC), *codes[0].ValidUntil)
+	assert.Nil(suite.T(), codes[1].ValidUntil)
+}
+
+func (suite *RewardUsecaseTestSuite) TestUploadCodes_Success() {
+	suite.pointExchangeRepoMock.On("GetByID", 2).Return(&model.PointExchange{PE_ID: 2, FulfillmentType: "Voucher Code"}, nil)
+	suite.voucherRepoMock.On("AddCodes", 2, mock.AnythingOfType("[]*model.VoucherCode")).Return(1, nil)
+
+	res, err := suite.usecase().UploadCodes(2, strings.NewReader("KOPI-001\nKOPI-002\n"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &model.VoucherCodeUpload{PEID: 2, Added: 1, Skipped: 1}, res)
+}
+
+func (suite *RewardUsecaseTestSuite) TestUploadCodes_NotVoucherReward() {
+	suite.pointExchangeRepoMock.On("GetByID", 1).Return(&model.PointExchange{PE_ID: 1, FulfillmentType: "Provider"}, nil)
+
+	res, err := suite.usecase().UploadCodes(1, strings.NewReader("KOPI-001\n"))
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "reward does not use voucher codes")
+}
+
+func (suite *RewardUsecaseTestSuite) TestFulfill_AssignsPoolCode() {
+	redeem := &model.Redeem{TransactionID: 15, UserID: "1", PEID: 2, Amount: 200}
+	suite.pointExchangeRepoMock.On("GetByID", 2).Return(&model.PointExchange{PE_ID: 2, Reward: "Voucher Kopi", FulfillmentType: "Voucher Code"}, nil)
+	suite.voucherRepoMock.On("Assign", 2, "1", 15, mock.AnythingOfType("time.Time")).Return(&model.VoucherCode{CodeID: 4, PEID: 2, Code: "KOPI-001"}, nil)
+
+	code, err := suite.usecase().Fulfill(redeem)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "KOPI-001", code.Code)
+	assert.Equal(suite.T(), "Voucher Kopi", code.Reward)
+	assert.Equal(suite.T(), "Active", code.Status)
+}
+
+func (suite *RewardUsecaseTestSuite) TestFulfill_IssuesProviderCode() {
+	redeem := &model.Redeem{TransactionID: 15, UserID: "1", PEID: 1, Amount: 100}
+	pe := &model.PointExchange{PE_ID: 1, Reward: "10k Pulsa", FulfillmentType: "Provider"}
+	suite.pointExchangeRepoMock.On("GetByID", 1).Return(pe, nil)
+	suite.providerMock.On("Issue", pe, "1", "RDM15").Return(&model.VoucherCode{Code: "PULSA-XYZ"}, nil)
+	suite.voucherRepoMock.On("SaveIssued", mock.AnythingOfType("*model.VoucherCode")).Return(nil).Run(func(args mock.Arguments) {
+		code := args.Get(0).(*model.VoucherCode)
+		assert.Equal(suite.T(), 15, code.TransactionID)
+		assert.Equal(suite.T(), "1", code.UserID)
+	})
+
+	code, err := suite.usecase().Fulfill(redeem)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "PULSA-XYZ", code.Code)
+}
+
+func (suite *RewardUsecaseTestSuite) TestFulfill_NoFulfillment() {
+	suite.pointExchangeRepoMock.On("GetByID", 3).Return(&model.PointExchange{PE_ID: 3, FulfillmentType: "None"}, nil)
+
+	code, err := suite.usecase().Fulfill(&model.Redeem{TransactionID: 15, UserID: "1", PEID: 3})
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), code)
+}
+
+func (suite *RewardUsecaseTestSuite) TestFulfill_FailedRefundsPoints() {
+	expiresAt := time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC)
+	redeem := &model.Redeem{TransactionID: 15, UserID: "1", PEID: 2, Amount: 200, Status: "Success", PointsExpireAt: &expiresAt}
+	suite.pointExchangeRepoMock.On("GetByID", 2).Return(&model.PointExchange{PE_ID: 2, Reward: "Voucher Kopi", FulfillmentType: "Voucher Code"}, nil)
+	suite.voucherRepoMock.On("Assign", 2, "1", 15, mock.AnythingOfType("time.Time")).Return(nil, errors.New("no voucher code available"))
+	suite.voucherRepoMock.On("FailRedeem", 15, 2).Return(nil)
+	suite.ledgerRepoMock.On("Credit", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		entry := args.Get(0).(*model.PointLedger)
+		assert.Equal(suite.T(), 200, entry.Points)
+		assert.Equal(suite.T(), "Refund", entry.EntryType)
+		assert.Equal(suite.T(), 15, entry.TransactionID)
+		assert.Equal(suite.T(), &expiresAt, entry.ExpiresAt)
+		assert.Equal(suite.T(), "Refund Voucher Kopi", entry.Description)
+	})
+
+	code, err := suite.usecase().Fulfill(redeem)
+
+	assert.Nil(suite.T(), code)
+	assert.EqualError(suite.T(), err, "failed to fulfill reward: no voucher code available")
+	assert.Equal(suite.T(), "Failed", redeem.Status)
+}
+
+func (suite *RewardUsecaseTestSuite) TestFulfill_NoProviderRefundsPoints() {
+	redeem := &model.Redeem{TransactionID: 15, UserID: "1", PEID: 1, Amount: 100, Status: "Success"}
+	suite.pointExchangeRepoMock.On("GetByID", 1).Return(&model.PointExchange{PE_ID: 1, Reward: "10k Pulsa", FulfillmentType: "Provider"}, nil)
+	suite.voucherRepoMock.On("FailRedeem", 15, 1).Return(nil)
+	suite.ledgerRepoMock.On("Credit", mock.AnythingOfType("*model.PointLedger")).Return(nil)
+
+	pointUsecase := NewPointUsecase(new(pointRuleRepoMock), suite.ledgerRepoMock, suite.userRepoMock, PointConfig{ExpiryMonths: 12})
+	code, err := NewRewardUsecase(suite.pointExchangeRepoMock, suite.voucherRepoMock, pointUsecase, nil).Fulfill(redeem)
+
+	assert.Nil(suite.T(), code)
+	assert.EqualError(suite.T(), err, "failed to fulfill reward: no reward provider is configured")
+	assert.Equal(suite.T(), "Failed", redeem.Status)
+}
+
+func (suite *RewardUsecaseTestSuite) TestFindVouchers_MarksExpired() {
+	past := time.Now().Add(-time.Hour)
+	codes := []*model.VoucherCode{{CodeID: 4, Code: "KOPI-001"}, {CodeID: 3, Code: "KOPI-000", ValidUntil: &past}}
+	suite.voucherRepoMock.On("GetByUser", "1").Return(codes, nil)
+
+	res, err := suite.usecase().FindVouchers("1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Active", res[0].Status)
+	assert.Equal(suite.T(), "Expired", res[1].Status)
+}
+
+func (suite *RewardUsecaseTestSuite) SetupTest() {
+	suite.pointExchangeRepoMock = new(pointExchangeRepoMock)
+	suite.voucherRepoMock = new(voucherCodeRepoMock)
+	suite.ledgerRepoMock = new(pointLedgerRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.providerMock = new(rewardProviderMock)
+}
+
+func TestRewardUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(RewardUsecaseTestSuite))
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+	"github.com/ReygaFitra/inc-final-project.git/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTransferFee = 2500
+	minTransferAmount  = 10000
+	inquiryLifetime    = 5 * time.Minute
+)
+
+type TransferInquiryUseCase interface {
+	Inquiry(sender *model.User, recipient string, amount int) (*model.TransferInquiry, error)
+	Find(senderID string, token string) (*model.TransferInquiry, error)
+	Confirm(senderID string, token string) (*model.TransferInquiry, error)
+	QuoteFee(sender *model.User, amount int) int
+}
+
+type transferInquiryUseCase struct {
+	inquiryRepo repository.TransferInquiryRepository
+	holdRepo    repository.HoldRepository
+	badgeRepo   repository.BadgeRepository
+	resolver    RecipientResolver
+}
+
+// QuoteFee returns the fee of the matching transfer fee rule, falling back
+// to the flat fee when no rule covers the amount, less the discount of the
+// sender's badge.
+func (uc *transferInquiryUseCase) QuoteFee(sender *model.User, amount int) int {
+	fee := defaultTransferFee
+	if rule, err := uc.inquiryRepo.GetTransferFee(amount); err == nil {
+		fee = rule.Fee
+	}
+	return discountedFee(fee, badgeBenefits(uc.badgeRepo, sender.BadgeID))
+}
+
+func (uc *transferInquiryUseCase) Inquiry(sender *model.User, to string, amount int) (*model.TransferInquiry, error) {
+	if amount < minTransferAmount {
+		return nil, fmt.Errorf("minimum transfer amount is 10,000")
+	}
+
+	recipient, err := uc.resolver.Resolve(sender, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := uc.QuoteFee(sender, amount)
+	available, err := availableBalance(uc.holdRepo, sender)
+	if err != nil {
+		return nil, err
+	}
+	if available < amount+fee {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+
+	inquiry := &model.TransferInquiry{
+		InquiryToken:         uuid.New().String(),
+		SenderID:             sender.ID,
+		RecipientID:          recipient.ID,
+		RecipientPhoneNumber: recipient.Phone_Number,
+		RecipientName:        utils.MaskName(recipient.Name),
+		Amount:               amount,
+		Fee:                  fee,
+		TotalAmount:          amount + fee,
+		Status:               "Pending",
+		ExpiredAt:            time.Now().Add(inquiryLifetime),
+	}
+	if err := uc.inquiryRepo.Create(inquiry); err != nil {
+		return nil, err
+	}
+	return inquiry, nil
+}
+
+// Find returns a sender's inquiry that can still be confirmed, without
+// using it up, so a transfer can be checked before the token is claimed.
+func (uc *transferInquiryUseCase) Find(senderID string, token string) (*model.TransferInquiry, error) {
+	inquiry, err := uc.inquiryRepo.GetByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if inquiry.SenderID != senderID {
+		return nil, fmt.Errorf("inquiry not found")
+	}
+	if inquiry.Status != "Pending" || !time.Now().Before(inquiry.ExpiredAt) {
+		return nil, fmt.Errorf("inquiry expired or already used")
+	}
+	return inquiry, nil
+}
+
+func (uc *transferInquiryUseCase) Confirm(senderID string, token string) (*model.TransferInquiry, error) {
+	inquiry, err := uc.Find(senderID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.inquiryRepo.Claim(token, senderID); err != nil {
+		return nil, err
+	}
+	inquiry.Status = "Used"
+	return inquiry, nil
+}
+
+func NewTransferInquiryUseCase(inquiryRepo repository.TransferInquiryRepository, holdRepo repository.HoldRepository, badgeRepo repository.BadgeRepository, resolver RecipientResolver) TransferInquiryUseCase {
+	return &transferInquiryUseCase{
+		inquiryRepo: inquiryRepo,
+		holdRepo:    holdRepo,
+		badgeRepo:   badgeRepo,
+		resolver:    resolver,
+	}
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var dummySender = model.User{
+	ID:           "2",
+	Name:         "sender",
+	Phone_Number: "08222222222",
+	Balance:      100000,
+}
+
+type transferInquiryRepoMock struct {
+	mock.Mock
+}
+
+func (r *transferInquiryRepoMock) GetTransferFee(amount int) (*model.TransferFee, error) {
+	args := r.Called(amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TransferFee), args.Error(1)
+}
+
+func (r *transferInquiryRepoMock) Create(inquiry *model.TransferInquiry) error {
+	args := r.Called(inquiry)
+	return args.Error(0)
+}
+
+func (r *transferInquiryRepoMock) GetByToken(token string) (*model.TransferInquiry, error) {
+	args := r.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TransferInquiry), args.Error(1)
+}
+
+func (r *transferInquiryRepoMock) Claim(token string, senderID string) error {
+	args := r.Called(token, senderID)
+	return args.Error(0)
+}
+
+type TransferInquiryUseCaseTestSuite struct {
+	inquiryRepoMock *transferInquiryRepoMock
+	userRepoMock    *userRepoMock
+	holdRepoMock    *holdRepoMock
+	badgeRepoMock   *badgeRepoMock
+	suite.Suite
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestInquiry_Success() {
+	sender := dummySender
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.inquiryRepoMock.On("GetTransferFee", 50000).Return(&model.TransferFee{Fee: 1000}, nil)
+	suite.inquiryRepoMock.On("Create", mock.AnythingOfType("*model.TransferInquiry")).Return(nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Inquiry(&sender, "08111111", 50000)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "n****", res.RecipientName)
+	assert.Equal(suite.T(), 1000, res.Fee)
+	assert.Equal(suite.T(), 51000, res.TotalAmount)
+	assert.NotEmpty(suite.T(), res.InquiryToken)
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestInquiry_DefaultFee() {
+	sender := dummySender
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.inquiryRepoMock.On("GetTransferFee", 50000).Return(nil, errors.New("transfer fee not found"))
+	suite.inquiryRepoMock.On("Create", mock.AnythingOfType("*model.TransferInquiry")).Return(nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Inquiry(&sender, "08111111", 50000)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), defaultTransferFee, res.Fee)
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestInquiry_RecipientNotFound() {
+	sender := dummySender
+	suite.userRepoMock.On("GetByPhone", "0899").Return("", errors.New("phone not found"))
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Inquiry(&sender, "0899", 50000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "recipient not found")
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestInquiry_Self() {
+	sender := dummySender
+	sender.ID = "1"
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Inquiry(&sender, "08111111", 50000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "cannot transfer to yourself")
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestInquiry_InsufficientBalance() {
+	sender := dummySender
+	sender.Balance = 50000
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.inquiryRepoMock.On("GetTransferFee", 50000).Return(&model.TransferFee{Fee: 2500}, nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Inquiry(&sender, "08111111", 50000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "insufficient balance")
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestInquiry_BelowMinimum() {
+	sender := dummySender
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Inquiry(&sender, "08111111", 5000)
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "minimum transfer amount is 10,000")
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestConfirm_Success() {
+	inquiry := &model.TransferInquiry{InquiryToken: "token-1", SenderID: "2", Status: "Pending", ExpiredAt: time.Now().Add(time.Minute)}
+	suite.inquiryRepoMock.On("GetByToken", "token-1").Return(inquiry, nil)
+	suite.inquiryRepoMock.On("Claim", "token-1", "2").Return(nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Confirm("2", "token-1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Used", res.Status)
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestConfirm_OtherSender() {
+	inquiry := &model.TransferInquiry{InquiryToken: "token-1", SenderID: "2", Status: "Pending"}
+	suite.inquiryRepoMock.On("GetByToken", "token-1").Return(inquiry, nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Confirm("3", "token-1")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "inquiry not found")
+	suite.inquiryRepoMock.AssertNotCalled(suite.T(), "Claim", "token-1", "3")
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestConfirm_AlreadyUsed() {
+	inquiry := &model.TransferInquiry{InquiryToken: "token-1", SenderID: "2", Status: "Used"}
+	suite.inquiryRepoMock.On("GetByToken", "token-1").Return(inquiry, nil)
+	suite.inquiryRepoMock.On("Claim", "token-1", "2").Return(errors.New("inquiry expired or already used"))
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Confirm("2", "token-1")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "inquiry expired or already used")
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestFind_DoesNotClaim() {
+	inquiry := &model.TransferInquiry{InquiryToken: "token-1", SenderID: "2", Status: "Pending", ExpiredAt: time.Now().Add(time.Minute)}
+	suite.inquiryRepoMock.On("GetByToken", "token-1").Return(inquiry, nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Find("2", "token-1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Pending", res.Status)
+	suite.inquiryRepoMock.AssertNotCalled(suite.T(), "Claim", "token-1", "2")
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestFind_Expired() {
+	inquiry := &model.TransferInquiry{InquiryToken: "token-1", SenderID: "2", Status: "Pending", ExpiredAt: time.Now().Add(-time.Minute)}
+	suite.inquiryRepoMock.On("GetByToken", "token-1").Return(inquiry, nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Find("2", "token-1")
+
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "inquiry expired or already used")
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) TestInquiry_BadgeFeeDiscount() {
+	sender := dummySender
+	sender.BadgeID = 3
+	suite.badgeRepoMock = new(badgeRepoMock)
+	suite.badgeRepoMock.On("GetByID", 3).Return(&model.Badge{BadgeID: 3, FeeDiscountPercent: 50}, nil)
+	suite.userRepoMock.On("GetByPhone", "08111111").Return(nil, nil)
+	suite.inquiryRepoMock.On("GetTransferFee", 50000).Return(&model.TransferFee{Fee: 1000}, nil)
+	suite.inquiryRepoMock.On("Create", mock.AnythingOfType("*model.TransferInquiry")).Return(nil)
+
+	uc := NewTransferInquiryUseCase(suite.inquiryRepoMock, suite.holdRepoMock, suite.badgeRepoMock, NewRecipientResolver(suite.userRepoMock))
+	res, err := uc.Inquiry(&sender, "08111111", 50000)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 500, res.Fee)
+	assert.Equal(suite.T(), 50500, res.TotalAmount)
+}
+
+func (suite *TransferInquiryUseCaseTestSuite) SetupTest() {
+	suite.inquiryRepoMock = new(transferInquiryRepoMock)
+	suite.userRepoMock = new(userRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.holdRepoMock.On("GetHeldAmount", mock.Anything).Return(0, nil)
+	suite.badgeRepoMock = new(badgeRepoMock)
+	suite.badgeRepoMock.On("GetByID", mock.Anything).Return(nil, errors.New("badge not found"))
+}
+
+func TestTransferInquiryUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(TransferInquiryUseCaseTestSuite))
+}
@@ -1,13 +1,20 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ReygaFitra/inc-final-project.git/model"
 	"github.com/ReygaFitra/inc-final-project.git/repository"
 )
 
+const (
+	maxTransferNoteLength = 100
+	maxCategoryLength     = 30
+)
+
 var now = time.Now().Local()
 var date = now.Format("2006-01-02")
 
@@ -15,34 +22,41 @@ type TransactionUseCase interface {
 	CreateDepositBank(transaction *model.Deposit) error
 
 	CreateWithdrawal(transaction *model.Withdraw) error
-	CreateTransfer(sender *model.User, recipient *model.User, amount int) error
+	AvailableBalance(user *model.User) (int, error)
+	ValidateTransfer(transfer *model.Transfer) error
+	CreateTransfer(sender *model.User, recipient *model.User, transfer *model.Transfer) error
 	CreateRedeem(transaction *model.Redeem) error
 	FindTxById(userID string) ([]*model.Transaction, error)
 	FindByPeId(id int) (*model.PointExchange, error)
-	AssignBadge(user *model.User) error
-	UpdateDepositStatus(vaNumber, token string) error
+	UpdateDepositStatus(vaNumber, orderID string) error
+	FindDepositByOrderID(orderID string) (*model.Deposit, error)
+	CheckTransferSender(txID int, senderID string) error
+	AttachTransferFile(txID int, senderID string, url string) error
+	SetCategory(category *model.TransactionCategory) error
 }
 
 type transactionUseCase struct {
 	transactionRepo repository.TransactionRepository
 	userRepo        repository.UserRepository
+	holdRepo        repository.HoldRepository
+	pointLedgerRepo repository.PointLedgerRepository
+}
+
+func (uc *transactionUseCase) AvailableBalance(user *model.User) (int, error) {
+	return availableBalance(uc.holdRepo, user)
 }
 
-func (uc *transactionUseCase) UpdateDepositStatus(vaNumber, token string) error {
-	err := uc.transactionRepo.UpdateDepositStatus(vaNumber, token)
+func (uc *transactionUseCase) UpdateDepositStatus(vaNumber, orderID string) error {
+	err := uc.transactionRepo.UpdateDepositStatus(vaNumber, orderID)
 	if err != nil {
-		return fmt.Errorf("failed to update deposit status: %v", err)
+		return fmt.Errorf("failed to update deposit status: %w", err)
 	}
 
 	return nil
 }
 
-func (uc *transactionUseCase) AssignBadge(user *model.User) error {
-	err := uc.transactionRepo.AssignBadge(user)
-	if err != nil {
-		return fmt.Errorf("failed to assign badge: %v", err)
-	}
-	return nil
+func (uc *transactionUseCase) FindDepositByOrderID(orderID string) (*model.Deposit, error) {
+	return uc.transactionRepo.GetDepositByOrderID(orderID)
 }
 
 func (uc *transactionUseCase) FindByPeId(id int) (*model.PointExchange, error) {
@@ -54,20 +68,11 @@ func (uc *transactionUseCase) FindTxById(userID string) ([]*model.Transaction, e
 }
 
 func (uc *transactionUseCase) CreateDepositBank(transaction *model.Deposit) error {
-	user, err := uc.userRepo.GetByiD(transaction.UserID)
+	_, err := uc.userRepo.GetByiD(transaction.UserID)
 	if err != nil {
 		return fmt.Errorf("gagal mendapatkan data pengguna: %v", err)
 	}
 
-	// cek apakah pengguna memenuhi syarat untuk bonus poin
-	if transaction.Amount >= 50000 {
-		newPoint := user.Point + 20
-		_ = uc.userRepo.UpdatePoint(user.ID, newPoint)
-	} else if transaction.Amount < 50000 {
-		newPoint := user.Point
-		_ = uc.userRepo.UpdatePoint(user.ID, newPoint)
-	}
-
 	err = uc.transactionRepo.CreateDepositBank(transaction)
 	if err != nil {
 		return fmt.Errorf("gagal membuat transaksi deposit: %v", err)
@@ -83,7 +88,11 @@ func (uc *transactionUseCase) CreateWithdrawal(transaction *model.Withdraw) erro
 	}
 
 	// check user balance
-	if user.Balance < transaction.Amount {
+	available, err := uc.AvailableBalance(user)
+	if err != nil {
+		return fmt.Errorf("failed to get available balance: %v", err)
+	}
+	if available < transaction.Amount {
 		return fmt.Errorf("insufficient balance")
 	}
 
@@ -102,19 +111,40 @@ func (uc *transactionUseCase) CreateWithdrawal(transaction *model.Withdraw) erro
 
 	return nil
 }
-func (uc *transactionUseCase) CreateTransfer(sender *model.User, recipient *model.User, amount int) error {
-	// Update sender balance
-	newBalanceS := sender.Balance - amount - 2500
-	err := uc.userRepo.UpdateBalance(sender.ID, newBalanceS)
-	if err != nil {
+
+// ValidateTransfer checks the parts of a transfer the sender typed in, so it
+// can be rejected before the inquiry is used up.
+func (uc *transactionUseCase) ValidateTransfer(transfer *model.Transfer) error {
+	if len(transfer.Note) > maxTransferNoteLength {
+		return fmt.Errorf("note must be at most %d characters", maxTransferNoteLength)
+	}
+	return nil
+}
+
+func (uc *transactionUseCase) CreateTransfer(sender *model.User, recipient *model.User, transfer *model.Transfer) error {
+	amount := transfer.Amount
+	fee := transfer.Fee
+
+	if err := uc.ValidateTransfer(transfer); err != nil {
 		return err
 	}
 
 	// Validate sender balance
-	if sender.Balance < amount+2500 {
+	available, err := uc.AvailableBalance(sender)
+	if err != nil {
+		return fmt.Errorf("failed to get available balance: %v", err)
+	}
+	if available < amount+fee {
 		return fmt.Errorf("insufficient balance")
 	}
 
+	// Update sender balance
+	newBalanceS := sender.Balance - amount - fee
+	err = uc.userRepo.UpdateBalance(sender.ID, newBalanceS)
+	if err != nil {
+		return err
+	}
+
 	// Update recipient balance
 	newBalanceR := recipient.Balance + amount
 	err = uc.userRepo.UpdateBalance(recipient.ID, newBalanceR)
@@ -122,31 +152,45 @@ func (uc *transactionUseCase) CreateTransfer(sender *model.User, recipient *mode
 		return err
 	}
 
-	// Update sender's point based on transfer amount
-	var newPoint int
-	if amount >= 50000 {
-		newPoint = sender.Point + 20
-	} else {
-		newPoint = sender.Point
+	// Insert transaction
+	transfer.SenderID = sender.ID
+	transfer.RecipientID = recipient.ID
+	transfer.SenderPhoneNumber = sender.Phone_Number
+	transfer.RecipientPhoneNumber = recipient.Phone_Number
+	transfer.TransactionType = "Transfer"
+	transfer.TransactionDate = date
+	transfer.SenderName = sender.Username
+	transfer.RecipientName = recipient.Username
+	return uc.transactionRepo.CreateTransfer(transfer)
+}
+
+// CheckTransferSender makes sure a transfer was sent by the user before they
+// upload a receipt for it.
+func (uc *transactionUseCase) CheckTransferSender(txID int, senderID string) error {
+	isSender, err := uc.transactionRepo.IsTransferSender(txID, senderID)
+	if err != nil {
+		return err
+	}
+	if !isSender {
+		return fmt.Errorf("transfer not found")
 	}
-	err = uc.userRepo.UpdatePoint(sender.ID, newPoint)
+	return nil
+}
+
+func (uc *transactionUseCase) AttachTransferFile(txID int, senderID string, url string) error {
+	err := uc.transactionRepo.UpdateTransferAttachment(txID, senderID, url)
 	if err != nil {
 		return err
 	}
+	return nil
+}
 
-	// Insert transaction
-	newTransfer := model.Transfer{
-		SenderID:             sender.ID,
-		RecipientID:          recipient.ID,
-		SenderPhoneNumber:    sender.Phone_Number,
-		RecipientPhoneNumber: recipient.Phone_Number,
-		Amount:               amount,
-		TransactionType:      "Transfer",
-		TransactionDate:      date,
-		SenderName:           sender.Username,
-		RecipientName:        recipient.Username,
+func (uc *transactionUseCase) SetCategory(category *model.TransactionCategory) error {
+	category.Category = strings.TrimSpace(category.Category)
+	if category.Category == "" || len(category.Category) > maxCategoryLength {
+		return fmt.Errorf("category must be 1 - 30 characters")
 	}
-	return uc.transactionRepo.CreateTransfer(&newTransfer)
+	return uc.transactionRepo.SetCategory(category)
 }
 
 func (uc *transactionUseCase) CreateRedeem(transaction *model.Redeem) error {
@@ -159,6 +203,9 @@ func (uc *transactionUseCase) CreateRedeem(transaction *model.Redeem) error {
 	if err != nil {
 		return err
 	}
+	if !rewardAvailable(pointExchange, time.Now()) {
+		return fmt.Errorf("reward is not available")
+	}
 
 	// Check if point exchange reward and price match with transaction data
 	if pointExchange.Price != transaction.Amount {
@@ -170,25 +217,67 @@ func (uc *transactionUseCase) CreateRedeem(transaction *model.Redeem) error {
 		return fmt.Errorf("your point is not enough to redeem")
 	}
 
-	// update user point
-	newPoint := user.Point - transaction.Amount
-	err = uc.userRepo.UpdatePoint(user.ID, newPoint)
+	// reserve the reward before the points are taken
+	err = uc.transactionRepo.TakeRewardStock(pointExchange.PE_ID)
+	if err != nil {
+		return err
+	}
+
+	// burn the points, oldest lots first
+	burn := &model.PointLedger{
+		UserID:      user.ID,
+		EntryType:   "Burn",
+		Points:      transaction.Amount,
+		Description: "Redeem " + pointExchange.Reward,
+		CreatedAt:   time.Now(),
+	}
+	err = uc.pointLedgerRepo.Burn(burn)
 	if err != nil {
+		if stockErr := uc.transactionRepo.ReturnRewardStock(pointExchange.PE_ID); stockErr != nil {
+			return fmt.Errorf("%v; %v", err, stockErr)
+		}
+		if errors.Is(err, repository.ErrInsufficientPoints) {
+			return fmt.Errorf("your point is not enough to redeem")
+		}
 		return err
 	}
+	transaction.PointsExpireAt = burn.ExpiresAt
 
 	// insert transaction
 	err = uc.transactionRepo.CreateRedeem(transaction)
 	if err != nil {
-		return err
+		return uc.undoRedeem(transaction, pointExchange, burn, err)
 	}
 
 	return nil
 }
 
-func NewTransactionUseCase(transactionRepo repository.TransactionRepository, userRepo repository.UserRepository) TransactionUseCase {
+// undoRedeem gives back the points and the reward stock of a redemption that
+// could not be recorded. The points keep the expiry of the lots they were
+// burned from.
+func (uc *transactionUseCase) undoRedeem(transaction *model.Redeem, pointExchange *model.PointExchange, burn *model.PointLedger, err error) error {
+	refund := &model.PointLedger{
+		UserID:      transaction.UserID,
+		EntryType:   "Refund",
+		Points:      burn.Points,
+		Description: burn.Description,
+		ExpiresAt:   burn.ExpiresAt,
+		CreatedAt:   time.Now(),
+	}
+	if refundErr := uc.pointLedgerRepo.Credit(refund); refundErr != nil {
+		return fmt.Errorf("%v; %v", err, refundErr)
+	}
+	if stockErr := uc.transactionRepo.ReturnRewardStock(pointExchange.PE_ID); stockErr != nil {
+		return fmt.Errorf("%v; %v", err, stockErr)
+	}
+	return err
+}
+
+func NewTransactionUseCase(transactionRepo repository.TransactionRepository, userRepo repository.UserRepository, holdRepo repository.HoldRepository, pointLedgerRepo repository.PointLedgerRepository) TransactionUseCase {
 	return &transactionUseCase{
 		transactionRepo: transactionRepo,
 		userRepo:        userRepo,
+		holdRepo:        holdRepo,
+		pointLedgerRepo: pointLedgerRepo,
 	}
 }
@@ -3,9 +3,12 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -19,6 +22,8 @@ type transactionRepoMock struct {
 type TransactionUseCaseTestSuite struct {
 	transactionRepoMock *transactionRepoMock
 	userRepoMock        *userRepoMock
+	holdRepoMock        *holdRepoMock
+	pointLedgerRepoMock *pointLedgerRepoMock
 
 	suite.Suite
 }
@@ -74,26 +79,28 @@ var dummyTxWithdraw = []*model.Withdraw{
 func (suite *TransactionUseCaseTestSuite) SetupTest() {
 	suite.transactionRepoMock = new(transactionRepoMock)
 	suite.userRepoMock = new(userRepoMock)
+	suite.holdRepoMock = new(holdRepoMock)
+	suite.pointLedgerRepoMock = new(pointLedgerRepoMock)
 }
 
 func TestTransactionUseCaseTestSuite(t *testing.T) {
 	suite.Run(t, new(TransactionUseCaseTestSuite))
 }
-func (m *transactionRepoMock) AssignBadge(user *model.User) error {
-	args := m.Called(user)
+func (m *transactionRepoMock) UpdateDepositStatus(vaNumber, orderID string) error {
+	args := m.Called(vaNumber, orderID)
 
 	if args[0] != nil {
 		return args.Error(0)
 	}
 	return nil
 }
-func (m *transactionRepoMock) UpdateDepositStatus(vaNumber, token string) error {
-	args := m.Called(vaNumber, token)
 
-	if args[0] != nil {
-		return args.Error(0)
+func (m *transactionRepoMock) GetDepositByOrderID(orderID string) (*model.Deposit, error) {
+	args := m.Called(orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
 	}
-	return nil
+	return args.Get(0).(*model.Deposit), args.Error(1)
 }
 
 func (m *transactionRepoMock) GetTransactions(ID string) ([]*model.Transaction, error) {
@@ -137,15 +144,6 @@ func (m *transactionRepoMock) CreateRedeem(tx *model.Redeem) error {
 
 	return args.Error(0)
 }
-func (m *transactionRepoMock) GetAllPoint() ([]*model.PointExchange, error) {
-	args := m.Called()
-	if args.Error(1) != nil {
-		return nil, args.Error(1)
-	}
-
-	return args.Get(0).([]*model.PointExchange), args.Error(1)
-
-}
 func (m *transactionRepoMock) GetByPeId(id int) (*model.PointExchange, error) {
 	args := m.Called(id)
 
@@ -157,6 +155,31 @@ func (m *transactionRepoMock) GetByPeId(id int) (*model.PointExchange, error) {
 	return args.Get(0).(*model.PointExchange), args.Error(1)
 }
 
+func (m *transactionRepoMock) TakeRewardStock(peID int) error {
+	args := m.Called(peID)
+	return args.Error(0)
+}
+
+func (m *transactionRepoMock) ReturnRewardStock(peID int) error {
+	args := m.Called(peID)
+	return args.Error(0)
+}
+
+func (m *transactionRepoMock) IsTransferSender(txID int, senderID string) (bool, error) {
+	args := m.Called(txID, senderID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *transactionRepoMock) UpdateTransferAttachment(txID int, senderID string, url string) error {
+	args := m.Called(txID, senderID, url)
+	return args.Error(0)
+}
+
+func (m *transactionRepoMock) SetCategory(category *model.TransactionCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
 var senderID = "uint(1)"
 
 func (suite *TransactionUseCaseTestSuite) TestFindTxById_Success() {
@@ -166,7 +189,7 @@ func (suite *TransactionUseCaseTestSuite) TestFindTxById_Success() {
 	suite.transactionRepoMock.On("GetBySenderId", senderID).Return(expectedTxs, nil)
 
 	// call the method being tested
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	actualTxs, err := uc.FindTxById(senderID)
 
 	// assert the expected results
@@ -183,7 +206,7 @@ func (suite *TransactionUseCaseTestSuite) TestFindByPeId_Success() {
 	suite.transactionRepoMock.On("GetByPeId", 1).Return(expectedPEs, nil)
 
 	// call the method being tested
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	actualPEs, err := uc.FindByPeId(1)
 
 	// assert the expected results
@@ -194,6 +217,90 @@ func (suite *TransactionUseCaseTestSuite) TestFindByPeId_Success() {
 
 }
 
+func (suite *TransactionUseCaseTestSuite) TestCreateRedeem_TakesStockBeforeBurning() {
+	user := &model.User{ID: "1", Point: 500}
+	pointExchange := &model.PointExchange{PE_ID: 2, Reward: "Voucher Kopi", Price: 200, Active: true}
+	redeem := &model.Redeem{UserID: "1", PEID: 2, Amount: 200}
+	suite.userRepoMock.On("GetByiD", "1").Return(user, nil)
+	suite.transactionRepoMock.On("GetByPeId", 2).Return(pointExchange, nil)
+	suite.transactionRepoMock.On("TakeRewardStock", 2).Return(nil)
+	suite.pointLedgerRepoMock.On("Burn", mock.AnythingOfType("*model.PointLedger")).Return(nil)
+	suite.transactionRepoMock.On("CreateRedeem", redeem).Return(nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateRedeem(redeem)
+
+	assert.NoError(suite.T(), err)
+	suite.transactionRepoMock.AssertNotCalled(suite.T(), "ReturnRewardStock", mock.Anything)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestCreateRedeem_RewardNotAvailable() {
+	endsAt := time.Now().Add(-time.Hour)
+	pointExchange := &model.PointExchange{PE_ID: 2, Reward: "Voucher Kopi", Price: 200, Active: true, EndsAt: &endsAt}
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1", Point: 500}, nil)
+	suite.transactionRepoMock.On("GetByPeId", 2).Return(pointExchange, nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateRedeem(&model.Redeem{UserID: "1", PEID: 2, Amount: 200})
+
+	assert.EqualError(suite.T(), err, "reward is not available")
+	suite.transactionRepoMock.AssertNotCalled(suite.T(), "TakeRewardStock", mock.Anything)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestCreateRedeem_OutOfStock() {
+	pointExchange := &model.PointExchange{PE_ID: 2, Reward: "Voucher Kopi", Price: 200, Active: true}
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1", Point: 500}, nil)
+	suite.transactionRepoMock.On("GetByPeId", 2).Return(pointExchange, nil)
+	suite.transactionRepoMock.On("TakeRewardStock", 2).Return(errors.New("reward out of stock"))
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateRedeem(&model.Redeem{UserID: "1", PEID: 2, Amount: 200})
+
+	assert.EqualError(suite.T(), err, "reward out of stock")
+	suite.pointLedgerRepoMock.AssertNotCalled(suite.T(), "Burn", mock.Anything)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestCreateRedeem_BurnFailedReturnsStock() {
+	pointExchange := &model.PointExchange{PE_ID: 2, Reward: "Voucher Kopi", Price: 200, Active: true}
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1", Point: 500}, nil)
+	suite.transactionRepoMock.On("GetByPeId", 2).Return(pointExchange, nil)
+	suite.transactionRepoMock.On("TakeRewardStock", 2).Return(nil)
+	suite.pointLedgerRepoMock.On("Burn", mock.AnythingOfType("*model.PointLedger")).Return(repository.ErrInsufficientPoints)
+	suite.transactionRepoMock.On("ReturnRewardStock", 2).Return(nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateRedeem(&model.Redeem{UserID: "1", PEID: 2, Amount: 200})
+
+	assert.EqualError(suite.T(), err, "your point is not enough to redeem")
+	suite.transactionRepoMock.AssertCalled(suite.T(), "ReturnRewardStock", 2)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestCreateRedeem_RecordFailedRefundsPoints() {
+	expiresAt := time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC)
+	pointExchange := &model.PointExchange{PE_ID: 2, Reward: "Voucher Kopi", Price: 200, Active: true}
+	redeem := &model.Redeem{UserID: "1", PEID: 2, Amount: 200}
+	suite.userRepoMock.On("GetByiD", "1").Return(&model.User{ID: "1", Point: 500}, nil)
+	suite.transactionRepoMock.On("GetByPeId", 2).Return(pointExchange, nil)
+	suite.transactionRepoMock.On("TakeRewardStock", 2).Return(nil)
+	suite.pointLedgerRepoMock.On("Burn", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.PointLedger).ExpiresAt = &expiresAt
+	})
+	suite.transactionRepoMock.On("CreateRedeem", redeem).Return(errors.New("failed to insert redeem"))
+	suite.pointLedgerRepoMock.On("Credit", mock.AnythingOfType("*model.PointLedger")).Return(nil).Run(func(args mock.Arguments) {
+		entry := args.Get(0).(*model.PointLedger)
+		assert.Equal(suite.T(), "Refund", entry.EntryType)
+		assert.Equal(suite.T(), 200, entry.Points)
+		assert.Equal(suite.T(), &expiresAt, entry.ExpiresAt)
+	})
+	suite.transactionRepoMock.On("ReturnRewardStock", 2).Return(nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateRedeem(redeem)
+
+	assert.EqualError(suite.T(), err, "failed to insert redeem")
+	suite.transactionRepoMock.AssertCalled(suite.T(), "ReturnRewardStock", 2)
+}
+
 func (suite *TransactionUseCaseTestSuite) TestCreateDepositBank() {
 	user := dummyUsers[0]
 	bank := dummyTxBank[0]
@@ -210,7 +317,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateDepositBank() {
 
 	suite.transactionRepoMock.On("CreateDepositBank", bank).Return(nil)
 
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	err := uc.CreateDepositBank(bank)
 
 	// assert the expected results
@@ -221,7 +328,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateDepositBank_UserNotFound() {
 
 		Amount: 10000,
 	}
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	suite.userRepoMock.On("GetByiD", "transaction.SenderID").Return(nil, errors.New("user not found"))
 
 	err := uc.CreateDepositBank(transaction)
@@ -235,7 +342,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateDepositBank_UpdateBalanceErr
 
 		Amount: 10000,
 	}
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	suite.userRepoMock.On("GetByiD", "transaction.SenderID").Return(&model.User{}, nil)
 	suite.userRepoMock.On("UpdateBalance", mock.Anything, mock.Anything).Return(errors.New("balance update error"))
 
@@ -260,18 +367,114 @@ func (suite *TransactionUseCaseTestSuite) TestCreateDepositBank_Failed() {
 	suite.transactionRepoMock.On("CreateDepositBank", transaction).Return(expectedErr)
 
 	// Create the use case and call the function being tested
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	err := uc.CreateDepositBank(transaction)
 
 	// Verify that the function returns an error
 	assert.EqualError(suite.T(), err, fmt.Sprintf("failed to create deposit transaction: %v", expectedErr))
 }
 
+func (suite *TransactionUseCaseTestSuite) TestCreateTransfer_Success() {
+	sender := &model.User{ID: "1", Username: "sender", Balance: 100000}
+	recipient := &model.User{ID: "2", Username: "recipient", Balance: 0}
+	transfer := &model.Transfer{Amount: 50000, Fee: 2500, Note: "makan siang"}
+
+	suite.holdRepoMock.On("GetHeldAmount", "1").Return(0, nil)
+	suite.userRepoMock.On("UpdateBalance", "1", 47500).Return(nil)
+	suite.userRepoMock.On("UpdateBalance", "2", 50000).Return(nil)
+	suite.transactionRepoMock.On("CreateTransfer", transfer).Return(nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateTransfer(sender, recipient, transfer)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "makan siang", transfer.Note)
+	assert.Equal(suite.T(), "recipient", transfer.RecipientName)
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdatePoint", mock.Anything, mock.Anything)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestCreateTransfer_NoteTooLong() {
+	sender := &model.User{ID: "1", Balance: 100000}
+	recipient := &model.User{ID: "2"}
+	transfer := &model.Transfer{Amount: 50000, Fee: 2500, Note: strings.Repeat("a", 101)}
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateTransfer(sender, recipient, transfer)
+
+	assert.EqualError(suite.T(), err, "note must be at most 100 characters")
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", "1", mock.Anything)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestCreateTransfer_InsufficientBalance() {
+	sender := &model.User{ID: "1", Balance: 50000}
+	recipient := &model.User{ID: "2"}
+	transfer := &model.Transfer{Amount: 50000, Fee: 2500}
+	suite.holdRepoMock.On("GetHeldAmount", "1").Return(0, nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateTransfer(sender, recipient, transfer)
+
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", "1", mock.Anything)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestCreateTransfer_BalanceHeld() {
+	sender := &model.User{ID: "1", Balance: 100000}
+	recipient := &model.User{ID: "2"}
+	transfer := &model.Transfer{Amount: 50000, Fee: 2500}
+	suite.holdRepoMock.On("GetHeldAmount", "1").Return(60000, nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CreateTransfer(sender, recipient, transfer)
+
+	assert.EqualError(suite.T(), err, "insufficient balance")
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdateBalance", "1", mock.Anything)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestSetCategory_Success() {
+	category := &model.TransactionCategory{TransactionID: 1, UserID: "1", Category: " Food "}
+	suite.transactionRepoMock.On("SetCategory", category).Return(nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.SetCategory(category)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Food", category.Category)
+}
+
+func (suite *TransactionUseCaseTestSuite) TestSetCategory_Empty() {
+	category := &model.TransactionCategory{TransactionID: 1, UserID: "1", Category: "  "}
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.SetCategory(category)
+
+	assert.EqualError(suite.T(), err, "category must be 1 - 30 characters")
+}
+
+func (suite *TransactionUseCaseTestSuite) TestCheckTransferSender_OtherUser() {
+	suite.transactionRepoMock.On("IsTransferSender", 1, "2").Return(false, nil)
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.CheckTransferSender(1, "2")
+
+	assert.EqualError(suite.T(), err, "transfer not found")
+}
+
+func (suite *TransactionUseCaseTestSuite) TestAttachTransferFile_NotFound() {
+	suite.transactionRepoMock.On("UpdateTransferAttachment", 1, "2", "file/tx-1.png").Return(errors.New("transfer not found"))
+
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
+	err := uc.AttachTransferFile(1, "2", "file/tx-1.png")
+
+	assert.EqualError(suite.T(), err, "transfer not found")
+}
+
 func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal() {
 	user := dummyUsers[0]
 	withdraw := dummyTxWithdraw[0]
 
 	suite.userRepoMock.On("GetByiD", "withdraw.SenderID").Return(user, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
 
 	newBalance := user.Balance - withdraw.Amount
 
@@ -279,7 +482,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal() {
 
 	suite.transactionRepoMock.On("CreateWithdrawal", withdraw).Return(nil)
 
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	err := uc.CreateWithdrawal(withdraw)
 
 	// assert the expected results
@@ -290,7 +493,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_UserNotFound() {
 
 		Amount: 10000,
 	}
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	suite.userRepoMock.On("GetByiD", "transaction.SenderID").Return(nil, errors.New("user not found"))
 
 	err := uc.CreateWithdrawal(transaction)
@@ -309,8 +512,9 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_InsufficientBalan
 		Email:   "test@example.com",
 		Balance: 5000,
 	}
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	suite.userRepoMock.On("GetByiD", "transaction.SenderID").Return(user, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
 	suite.userRepoMock.On("UpdateBalance", user.ID, user.Balance-transaction.Amount).Return(nil)
 
 	err := uc.CreateWithdrawal(transaction)
@@ -329,8 +533,9 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_UpdateBalance() {
 		Email:   "test@example.com",
 		Balance: 15000,
 	}
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	suite.userRepoMock.On("GetByiD", "transaction.SenderID").Return(user, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
 	suite.userRepoMock.On("UpdateBalance", user.ID, user.Balance-transaction.Amount).Return(errors.New("failed to update user balance"))
 
 	err := uc.CreateWithdrawal(transaction)
@@ -349,8 +554,9 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_CreateTransaction
 		Email:   "test@example.com",
 		Balance: 15000,
 	}
-	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 	suite.userRepoMock.On("GetByiD", "transaction.SenderID").Return(user, nil)
+	suite.holdRepoMock.On("GetHeldAmount", user.ID).Return(0, nil)
 	suite.userRepoMock.On("UpdateBalance", user.ID, user.Balance-transaction.Amount).Return(nil)
 	suite.transactionRepoMock.On("CreateWithdrawal", transaction).Return(errors.New("failed to create withdrawal transaction"))
 
@@ -390,7 +596,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_CreateTransaction
 // 	suite.transactionRepoMock.On("CreateRedeem", transaction).
 // 		Return(nil)
 
-// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 
 // 	// call the use case
 // 	err := uc.CreateRedeem(transaction)
@@ -409,7 +615,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_CreateTransaction
 // 	}
 // 	suite.userRepoMock.On("GetByiD", transaction.SenderID).
 // 		Return(nil, errors.New("failed to get user by ID"))
-// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 
 // 	// call the use case
 // 	err := uc.CreateRedeem(transaction)
@@ -433,7 +639,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_CreateTransaction
 // 	}
 // 	suite.userRepoMock.On("GetByiD", user.ID).Return(user, nil)
 // 	suite.transactionRepoMock.On("GetByPeId", transaction.PointExchangeID).Return(nil, fmt.Errorf("point exchange with pe_id %d not found", transaction.PointExchangeID))
-// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 // 	// call the use case
 // 	err := uc.CreateRedeem(transaction)
 
@@ -464,7 +670,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_CreateTransaction
 // 	suite.userRepoMock.On("GetByiD", transaction.SenderID).Return(user, nil)
 // 	suite.transactionRepoMock.On("GetByPeId", transaction.PointExchangeID).Return(pointExchange, nil)
 
-// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 
 // 	// call the use case
 // 	err := uc.CreateRedeem(transaction)
@@ -497,7 +703,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_CreateTransaction
 // 	suite.userRepoMock.On("GetByiD", transaction.SenderID).Return(sender, nil)
 // 	suite.transactionRepoMock.On("GetByPeId", transaction.PointExchangeID).Return(pe, nil)
 
-// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 
 // 	// call the use case
 // 	err := uc.CreateRedeem(transaction)
@@ -531,7 +737,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_CreateTransaction
 // 	}, nil)
 // 	suite.userRepoMock.On("UpdatePoint", user.ID, user.Point-transaction.Point).Return(errors.New("failed to update point"))
 
-// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 
 // 	// call the use case
 // 	err := uc.CreateRedeem(transaction)
@@ -571,7 +777,7 @@ func (suite *TransactionUseCaseTestSuite) TestCreateWithdrawal_CreateTransaction
 // 	suite.transactionRepoMock.On("CreateRedeem", transaction).
 // 		Return(errors.New("err"))
 
-// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock)
+// 	uc := NewTransactionUseCase(suite.transactionRepoMock, suite.userRepoMock, suite.holdRepoMock, suite.pointLedgerRepoMock)
 
 // 	// call the use case
 // 	err := uc.CreateRedeem(transaction)
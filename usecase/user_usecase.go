@@ -3,6 +3,8 @@ package usecase
 import (
 	"fmt"
 	"log"
+	"regexp"
+	"strings"
 
 	"github.com/ReygaFitra/inc-final-project.git/model"
 	"github.com/ReygaFitra/inc-final-project.git/repository"
@@ -23,8 +25,11 @@ type UserUseCase interface {
 	SaveDeviceToken(userID string, token string) error
 	FindByiDToken(id string) (*model.User, error)
 	UpdateBalance(userID string, newBalance int) error
+	ClaimPaymentHandle(userID string, handle string) error
 }
 
+var paymentHandlePattern = regexp.MustCompile(`^[a-z0-9_.]{3,20}$`)
+
 type userUseCase struct {
 	userRepo repository.UserRepository
 }
@@ -56,6 +61,15 @@ func (uc *userUseCase) Login(email string, password string, token string) (*mode
 	}, nil
 }
 
+func (uc *userUseCase) ClaimPaymentHandle(userID string, handle string) error {
+	handle = strings.ToLower(strings.TrimPrefix(handle, "@"))
+	if !paymentHandlePattern.MatchString(handle) {
+		return fmt.Errorf("handle must be 3 - 20 characters of letters, numbers, dot or underscore")
+	}
+	// The unique index decides who gets a contested handle
+	return uc.userRepo.UpdatePaymentHandle(userID, handle)
+}
+
 func (uc *userUseCase) FindUsers() any {
 	return uc.userRepo.GetAll()
 }
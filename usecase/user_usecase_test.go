@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -142,6 +143,27 @@ func (r *userRepoMock) UpdatePoint(userID string, newPoint int) error {
 	return nil
 }
 
+func (r *userRepoMock) GetByEmail(email string) (*model.User, error) {
+	args := r.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (r *userRepoMock) GetByPaymentHandle(handle string) (*model.User, error) {
+	args := r.Called(handle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (r *userRepoMock) UpdatePaymentHandle(userID string, handle string) error {
+	args := r.Called(userID, handle)
+	return args.Error(0)
+}
+
 type UserUseCaseTestSuite struct {
 	userRepoMock *userRepoMock
 	suite.Suite
@@ -278,6 +300,26 @@ func (suite *UserUseCaseTestSuite) TestEditEmailPassword_Error() {
 	assert.Empty(suite.T(), res)
 }
 
+// Test ClaimPaymentHandle
+func (suite *UserUseCaseTestSuite) TestClaimPaymentHandle_Success() {
+	userUC := NewUserUseCase(suite.userRepoMock)
+	suite.userRepoMock.On("UpdatePaymentHandle", "1", "name_1").Return(nil)
+	err := userUC.ClaimPaymentHandle("1", "@Name_1")
+	assert.Nil(suite.T(), err)
+}
+func (suite *UserUseCaseTestSuite) TestClaimPaymentHandle_Taken() {
+	userUC := NewUserUseCase(suite.userRepoMock)
+	suite.userRepoMock.On("UpdatePaymentHandle", "1", "name_1").Return(repository.ErrPaymentHandleTaken)
+	err := userUC.ClaimPaymentHandle("1", "name_1")
+	assert.EqualError(suite.T(), err, "handle already taken")
+}
+func (suite *UserUseCaseTestSuite) TestClaimPaymentHandle_Invalid() {
+	userUC := NewUserUseCase(suite.userRepoMock)
+	err := userUC.ClaimPaymentHandle("1", "a b")
+	assert.NotNil(suite.T(), err)
+	suite.userRepoMock.AssertNotCalled(suite.T(), "UpdatePaymentHandle", "1", "a b")
+}
+
 // Test Login
 func (suite *UserUseCaseTestSuite) TestLogin_Success() {
 	user := &dummyUser[0]
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/ReygaFitra/inc-final-project.git/repository"
+)
+
+const (
+	WebhookPaymentSucceeded = "payment.succeeded"
+	WebhookRefundCreated    = "refund.created"
+	WebhookDepositSettled   = "deposit.settled"
+
+	maxWebhookSubscriptions = 10
+	maxWebhookURLLength     = 255
+	webhookSecretSize       = 32
+	webhookEventIDSize      = 24
+	webhookBatchSize        = 50
+	webhookMaxAttempts      = 8
+	webhookBaseDelay        = 30 * time.Second
+	webhookMaxDelay         = 6 * time.Hour
+	webhookStatusPending    = "Pending"
+	webhookStatusDelivered  = "Delivered"
+	webhookStatusFailed     = "Failed"
+)
+
+var webhookEventTypes = []string{WebhookPaymentSucceeded, WebhookRefundCreated, WebhookDepositSettled}
+
+type WebhookUsecase interface {
+	Subscribe(subscription *model.WebhookSubscription) error
+	FindSubscriptions(userID string) ([]*model.WebhookSubscription, error)
+	Unsubscribe(userID string, subscriptionID int) error
+	FindDeliveries(userID string, subscriptionID int) ([]*model.WebhookDelivery, error)
+	Redeliver(userID string, deliveryID int) (*model.WebhookDelivery, error)
+
+	Publish(userID string, eventType string, data interface{}) error
+	DeliverDue() (int, error)
+}
+
+type webhookUsecase struct {
+	webhookRepo repository.WebhookRepository
+	sender      model.WebhookSender
+}
+
+// webhookSignature is what subscribers receive in X-Webhook-Signature: the hex
+// HMAC-SHA256 of "timestamp.payload" keyed with the subscription secret.
+func webhookSignature(secret string, timestamp string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff is the wait before the next attempt: 30s doubled for every
+// failed attempt, capped at 6 hours.
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxDelay {
+			return webhookMaxDelay
+		}
+	}
+	return delay
+}
+
+func validWebhookEvent(eventType string) bool {
+	for _, known := range webhookEventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookHostAllowed rejects subscriber hosts that obviously point inside our
+// network. Names are checked again on their resolved address at delivery.
+func webhookHostAllowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return model.PublicWebhookIP(ip)
+	}
+	return true
+}
+
+func (u *webhookUsecase) Subscribe(subscription *model.WebhookSubscription) error {
+	subscription.URL = strings.TrimSpace(subscription.URL)
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" || len(subscription.URL) > maxWebhookURLLength {
+		return fmt.Errorf("url must be a valid http or https address")
+	}
+	if !webhookHostAllowed(target.Hostname()) {
+		return fmt.Errorf("url must not point to a private or local address")
+	}
+
+	var eventTypes []string
+	seen := map[string]bool{}
+	for _, eventType := range subscription.EventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !validWebhookEvent(eventType) {
+			return fmt.Errorf("unknown event type %s", eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	if len(eventTypes) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+
+	subscriptions, err := u.webhookRepo.GetSubscriptions(subscription.UserID)
+	if err != nil {
+		return err
+	}
+	active := 0
+	for _, existing := range subscriptions {
+		if existing.Active {
+			active++
+		}
+	}
+	if active >= maxWebhookSubscriptions {
+		return fmt.Errorf("maximum 10 webhook subscriptions per user")
+	}
+
+	secret, err := randomCode(apiKeyChars, webhookSecretSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+
+	subscription.EventTypes = eventTypes
+	subscription.Secret = "whsec_" + secret
+	subscription.Active = true
+	subscription.CreatedAt = time.Now()
+	return u.webhookRepo.CreateSubscription(subscription)
+}
+
+func (u *webhookUsecase) FindSubscriptions(userID string) ([]*model.WebhookSubscription, error) {
+	return u.webhookRepo.GetSubscriptions(userID)
+}
+
+func (u *webhookUsecase) Unsubscribe(userID string, subscriptionID int) error {
+	return u.webhookRepo.DeleteSubscription(subscriptionID, userID)
+}
+
+func (u *webhookUsecase) FindDeliveries(userID string, subscriptionID int) ([]*model.WebhookDelivery, error) {
+	if _, err := u.webhookRepo.GetSubscription(subscriptionID, userID); err != nil {
+		return nil, err
+	}
+	return u.webhookRepo.GetDeliveries(subscriptionID)
+}
+
+// Redeliver queues a delivered or failed event again; the scheduler sends it
+// on its next run.
+func (u *webhookUsecase) Redeliver(userID string, deliveryID int) (*model.WebhookDelivery, error) {
+	delivery, err := u.webhookRepo.GetDelivery(deliveryID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := u.webhookRepo.Redeliver(deliveryID, now); err != nil {
+		return nil, err
+	}
+	delivery.Status = webhookStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.DeliveredAt = nil
+	return delivery, nil
+}
+
+// Publish queues eventType for every active subscription of userID that
+// listens to it. Sending happens in DeliverDue.
+func (u *webhookUsecase) Publish(userID string, eventType string, data interface{}) error {
+	subscriptions, err := u.webhookRepo.GetSubscribers(userID, eventType)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	id, err := randomCode(apiKeyChars, webhookEventIDSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate event id: %v", err)
+	}
+	now := time.Now()
+	event := model.WebhookEvent{
+		EventID:   "evt_" + id,
+		EventType: eventType,
+		CreatedAt: now,
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %v", err)
+	}
+
+	for _, subscription := range subscriptions {
+		delivery := &model.WebhookDelivery{
+			SubscriptionID: subscription.SubscriptionID,
+			EventID:        event.EventID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         webhookStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+		if err := u.webhookRepo.CreateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue sends every pending delivery whose attempt is due and reports how
+// many were delivered. Failed attempts are rescheduled with exponential
+// backoff until webhookMaxAttempts is reached.
+func (u *webhookUsecase) DeliverDue() (int, error) {
+	deliveries, err := u.webhookRepo.GetDueDeliveries(time.Now(), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers := map[string]string{
+			"X-Webhook-Event":     delivery.EventType,
+			"X-Webhook-Delivery":  strconv.Itoa(delivery.DeliveryID),
+			"X-Webhook-Signature": "t=" + timestamp + ",v1=" + webhookSignature(delivery.Secret, timestamp, delivery.Payload),
+		}
+
+		statusCode, err := u.sender.Send(delivery.URL, headers, []byte(delivery.Payload))
+		now := time.Now()
+		delivery.Attempts++
+		delivery.LastStatusCode = statusCode
+		delivery.LastError = ""
+
+		switch {
+		case err == nil && statusCode >= 200 && statusCode < 300:
+			delivery.Status = webhookStatusDelivered
+			delivery.DeliveredAt = &now
+			delivered++
+		default:
+			if err != nil {
+				delivery.LastError = err.Error()
+			} else {
+				delivery.LastError = fmt.Sprintf("unexpected status code %d", statusCode)
+			}
+			if delivery.Attempts >= webhookMaxAttempts {
+				delivery.Status = webhookStatusFailed
+			} else {
+				delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+			}
+		}
+
+		if err := u.webhookRepo.UpdateDelivery(delivery); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+func NewWebhookUsecase(webhookRepo repository.WebhookRepository, sender model.WebhookSender) WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepo: webhookRepo,
+		sender:      sender,
+	}
+}
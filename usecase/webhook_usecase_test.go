@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ReygaFitra/inc-final-project.git/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type webhookRepoMock struct {
+	mock.Mock
+}
+
+func (r *webhookRepoMock) CreateSubscription(subscription *model.WebhookSubscription) error {
+	args := r.Called(subscription)
+	return args.Error(0)
+}
+
+func (r *webhookRepoMock) GetSubscriptions(userID string) ([]*model.WebhookSubscription, error) {
+	args := r.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookSubscription), args.Error(1)
+}
+
+func (r *webhookRepoMock) GetSubscription(subscriptionID int, userID string) (*model.WebhookSubscription, error) {
+	args := r.Called(subscriptionID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (r *webhookRepoMock) DeleteSubscription(subscriptionID int, userID string) error {
+	args := r.Called(subscriptionID, userID)
+	return args.Error(0)
+}
+
+func (r *webhookRepoMock) GetSubscribers(userID string, eventType string) ([]*model.WebhookSubscription, error) {
+	args := r.Called(userID, eventType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookSubscription), args.Error(1)
+}
+
+func (r *webhookRepoMock) CreateDelivery(delivery *model.WebhookDelivery) error {
+	args := r.Called(delivery)
+	return args.Error(0)
+}
+
+func (r *webhookRepoMock) GetDeliveries(subscriptionID int) ([]*model.WebhookDelivery, error) {
+	args := r.Called(subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (r *webhookRepoMock) GetDelivery(deliveryID int, userID string) (*model.WebhookDelivery, error) {
+	args := r.Called(deliveryID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDelivery), args.Error(1)
+}
+
+func (r *webhookRepoMock) GetDueDeliveries(now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	args := r.Called(now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (r *webhookRepoMock) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	args := r.Called(delivery)
+	return args.Error(0)
+}
+
+func (r *webhookRepoMock) Redeliver(deliveryID int, now time.Time) error {
+	args := r.Called(deliveryID, now)
+	return args.Error(0)
+}
+
+type webhookSenderMock struct {
+	mock.Mock
+}
+
+func (s *webhookSenderMock) Send(url string, headers map[string]string, body []byte) (int, error) {
+	args := s.Called(url, headers, body)
+	return args.Int(0), args.Error(1)
+}
+
+type WebhookUsecaseTestSuite struct {
+	repoMock   *webhookRepoMock
+	senderMock *webhookSenderMock
+	suite.Suite
+}
+
+func (suite *WebhookUsecaseTestSuite) TestSubscribe_Success() {
+	subscription := &model.WebhookSubscription{
+		UserID:     "1",
+		URL:        " https://example.com/hook ",
+		EventTypes: []string{"payment.succeeded", "payment.succeeded", "deposit.settled"},
+	}
+	suite.repoMock.On("GetSubscriptions", "1").Return([]*model.WebhookSubscription{}, nil)
+	suite.repoMock.On("CreateSubscription", subscription).Return(nil)
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	err := uc.Subscribe(subscription)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "https://example.com/hook", subscription.URL)
+	assert.Equal(suite.T(), []string{"payment.succeeded", "deposit.settled"}, subscription.EventTypes)
+	assert.True(suite.T(), strings.HasPrefix(subscription.Secret, "whsec_"))
+	assert.True(suite.T(), subscription.Active)
+}
+
+func (suite *WebhookUsecaseTestSuite) TestSubscribe_InvalidURL() {
+	subscription := &model.WebhookSubscription{UserID: "1", URL: "ftp://example.com", EventTypes: []string{"payment.succeeded"}}
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	err := uc.Subscribe(subscription)
+	assert.EqualError(suite.T(), err, "url must be a valid http or https address")
+}
+
+func (suite *WebhookUsecaseTestSuite) TestSubscribe_InternalAddress() {
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	for _, target := range []string{"http://localhost:8080/hook", "http://127.0.0.1/hook", "http://10.0.0.5/hook", "http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data", "http://[::1]/hook", "http://0.0.0.0/hook"} {
+		subscription := &model.WebhookSubscription{UserID: "1", URL: target, EventTypes: []string{"payment.succeeded"}}
+		err := uc.Subscribe(subscription)
+		assert.EqualError(suite.T(), err, "url must not point to a private or local address", target)
+	}
+	suite.repoMock.AssertNotCalled(suite.T(), "CreateSubscription", mock.Anything)
+}
+
+func (suite *WebhookUsecaseTestSuite) TestSubscribe_UnknownEvent() {
+	subscription := &model.WebhookSubscription{UserID: "1", URL: "https://example.com/hook", EventTypes: []string{"user.deleted"}}
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	err := uc.Subscribe(subscription)
+	assert.EqualError(suite.T(), err, "unknown event type user.deleted")
+}
+
+func (suite *WebhookUsecaseTestSuite) TestSubscribe_Limit() {
+	var existing []*model.WebhookSubscription
+	for i := 0; i < maxWebhookSubscriptions; i++ {
+		existing = append(existing, &model.WebhookSubscription{SubscriptionID: i + 1, Active: true})
+	}
+	subscription := &model.WebhookSubscription{UserID: "1", URL: "https://example.com/hook", EventTypes: []string{"refund.created"}}
+	suite.repoMock.On("GetSubscriptions", "1").Return(existing, nil)
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	err := uc.Subscribe(subscription)
+	assert.EqualError(suite.T(), err, "maximum 10 webhook subscriptions per user")
+}
+
+func (suite *WebhookUsecaseTestSuite) TestPublish_QueuesDeliveryPerSubscriber() {
+	subscribers := []*model.WebhookSubscription{{SubscriptionID: 1}, {SubscriptionID: 2}}
+	suite.repoMock.On("GetSubscribers", "1", WebhookPaymentSucceeded).Return(subscribers, nil)
+	suite.repoMock.On("CreateDelivery", mock.AnythingOfType("*model.WebhookDelivery")).Return(nil)
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	err := uc.Publish("1", WebhookPaymentSucceeded, map[string]int{"amount": 40000})
+	assert.Nil(suite.T(), err)
+	suite.repoMock.AssertNumberOfCalls(suite.T(), "CreateDelivery", 2)
+
+	first := suite.repoMock.Calls[1].Arguments.Get(0).(*model.WebhookDelivery)
+	second := suite.repoMock.Calls[2].Arguments.Get(0).(*model.WebhookDelivery)
+	assert.Equal(suite.T(), first.EventID, second.EventID)
+	assert.Equal(suite.T(), "Pending", first.Status)
+	assert.Contains(suite.T(), first.Payload, `"data":{"amount":40000}`)
+}
+
+func (suite *WebhookUsecaseTestSuite) TestPublish_NoSubscribers() {
+	suite.repoMock.On("GetSubscribers", "1", WebhookRefundCreated).Return([]*model.WebhookSubscription{}, nil)
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	err := uc.Publish("1", WebhookRefundCreated, nil)
+	assert.Nil(suite.T(), err)
+	suite.repoMock.AssertNotCalled(suite.T(), "CreateDelivery", mock.Anything)
+}
+
+func (suite *WebhookUsecaseTestSuite) TestDeliverDue_Delivered() {
+	delivery := &model.WebhookDelivery{DeliveryID: 5, EventType: WebhookPaymentSucceeded, Payload: `{"event_id":"evt_1"}`, URL: "https://example.com/hook", Secret: "whsec_1", Status: "Pending"}
+	suite.repoMock.On("GetDueDeliveries", mock.AnythingOfType("time.Time"), webhookBatchSize).Return([]*model.WebhookDelivery{delivery}, nil)
+	suite.senderMock.On("Send", delivery.URL, mock.MatchedBy(func(headers map[string]string) bool {
+		parts := strings.SplitN(headers["X-Webhook-Signature"], ",", 2)
+		if len(parts) != 2 {
+			return false
+		}
+		timestamp := strings.TrimPrefix(parts[0], "t=")
+		return parts[1] == "v1="+webhookSignature("whsec_1", timestamp, delivery.Payload) && headers["X-Webhook-Delivery"] == "5"
+	}), []byte(delivery.Payload)).Return(200, nil)
+	suite.repoMock.On("UpdateDelivery", delivery).Return(nil)
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	delivered, err := uc.DeliverDue()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, delivered)
+	assert.Equal(suite.T(), "Delivered", delivery.Status)
+	assert.Equal(suite.T(), 1, delivery.Attempts)
+	assert.NotNil(suite.T(), delivery.DeliveredAt)
+}
+
+func (suite *WebhookUsecaseTestSuite) TestDeliverDue_RetryWithBackoff() {
+	delivery := &model.WebhookDelivery{DeliveryID: 5, Attempts: 2, Payload: "{}", URL: "https://example.com/hook", Status: "Pending"}
+	suite.repoMock.On("GetDueDeliveries", mock.AnythingOfType("time.Time"), webhookBatchSize).Return([]*model.WebhookDelivery{delivery}, nil)
+	suite.senderMock.On("Send", delivery.URL, mock.Anything, mock.Anything).Return(503, nil)
+	suite.repoMock.On("UpdateDelivery", delivery).Return(nil)
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	before := time.Now()
+	delivered, err := uc.DeliverDue()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, delivered)
+	assert.Equal(suite.T(), "Pending", delivery.Status)
+	assert.Equal(suite.T(), 3, delivery.Attempts)
+	assert.Equal(suite.T(), "unexpected status code 503", delivery.LastError)
+	assert.True(suite.T(), !delivery.NextAttemptAt.Before(before.Add(2*time.Minute)))
+}
+
+func (suite *WebhookUsecaseTestSuite) TestDeliverDue_FailsAfterMaxAttempts() {
+	delivery := &model.WebhookDelivery{DeliveryID: 5, Attempts: webhookMaxAttempts - 1, Payload: "{}", URL: "https://example.com/hook", Status: "Pending"}
+	suite.repoMock.On("GetDueDeliveries", mock.AnythingOfType("time.Time"), webhookBatchSize).Return([]*model.WebhookDelivery{delivery}, nil)
+	suite.senderMock.On("Send", delivery.URL, mock.Anything, mock.Anything).Return(0, errors.New("connection refused"))
+	suite.repoMock.On("UpdateDelivery", delivery).Return(nil)
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	_, err := uc.DeliverDue()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Failed", delivery.Status)
+	assert.Equal(suite.T(), "connection refused", delivery.LastError)
+}
+
+func (suite *WebhookUsecaseTestSuite) TestBackoff() {
+	assert.Equal(suite.T(), 30*time.Second, webhookBackoff(1))
+	assert.Equal(suite.T(), 2*time.Minute, webhookBackoff(3))
+	assert.Equal(suite.T(), 6*time.Hour, webhookBackoff(20))
+}
+
+func (suite *WebhookUsecaseTestSuite) TestRedeliver_Success() {
+	delivery := &model.WebhookDelivery{DeliveryID: 5, Status: "Failed", Attempts: 8}
+	suite.repoMock.On("GetDelivery", 5, "1").Return(delivery, nil)
+	suite.repoMock.On("Redeliver", 5, mock.AnythingOfType("time.Time")).Return(nil)
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	res, err := uc.Redeliver("1", 5)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Pending", res.Status)
+	assert.Equal(suite.T(), 0, res.Attempts)
+}
+
+func (suite *WebhookUsecaseTestSuite) TestFindDeliveries_NotOwner() {
+	suite.repoMock.On("GetSubscription", 1, "2").Return(nil, errors.New("webhook subscription not found"))
+	uc := NewWebhookUsecase(suite.repoMock, suite.senderMock)
+	res, err := uc.FindDeliveries("2", 1)
+	assert.Nil(suite.T(), res)
+	assert.EqualError(suite.T(), err, "webhook subscription not found")
+}
+
+func (suite *WebhookUsecaseTestSuite) SetupTest() {
+	suite.repoMock = new(webhookRepoMock)
+	suite.senderMock = new(webhookSenderMock)
+}
+
+func TestWebhookUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookUsecaseTestSuite))
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "strings"
+
+// MaskName keeps the first letter of every word and hides the rest,
+// e.g. "Reyga Fitra" becomes "R**** F****".
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		if len(runes) <= 1 {
+			continue
+		}
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}